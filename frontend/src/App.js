@@ -11,6 +11,8 @@ import ResetPassword from './components/ResetPassword';
 import ChangePassword from './components/ChangePassword';
 import RecoverAccount from './components/RecoverAccount';
 import VerifyMagicLink from './components/VerifyMagicLink';
+import LoginNotMe from './components/LoginNotMe';
+import EmailChangeAction from './components/EmailChangeAction';
 import Artists from './components/Artists';
 import ArtistDetail from './components/ArtistDetail';
 import Albums from './components/Albums';
@@ -22,6 +24,7 @@ import Notifications from './components/Notifications';
 import Profile from './components/Profile';
 import ActivityHistory from './components/ActivityHistory';
 import Analytics from './components/Analytics';
+import Trash from './components/Trash';
 import ProtectedRoute from './components/ProtectedRoute';
 import './App.css';
 
@@ -40,6 +43,9 @@ function App() {
             <Route path="/reset-password" element={<ResetPassword />} />
             <Route path="/recover-account" element={<RecoverAccount />} />
             <Route path="/verify-magic-link" element={<VerifyMagicLink />} />
+            <Route path="/login/not-me" element={<LoginNotMe />} />
+            <Route path="/email/change/confirm" element={<EmailChangeAction action="confirm" />} />
+            <Route path="/email/change/cancel" element={<EmailChangeAction action="cancel" />} />
             <Route
               path="/change-password"
               element={
@@ -87,6 +93,14 @@ function App() {
                 </ProtectedRoute>
               }
             />
+            <Route
+              path="/trash"
+              element={
+                <ProtectedRoute>
+                  <Trash />
+                </ProtectedRoute>
+              }
+            />
             <Route path="*" element={<Navigate to="/" replace />} />
           </Routes>
         </div>
@@ -1,11 +1,16 @@
 import React, { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
+import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
+import RevisionHistory from './RevisionHistory';
 
 const AlbumDetail = () => {
   const { id } = useParams();
   const navigate = useNavigate();
+  const { isAdmin } = useAuth();
   const [album, setAlbum] = useState(null);
+  const [imageUploading, setImageUploading] = useState(false);
+  const [imageError, setImageError] = useState('');
   const [songs, setSongs] = useState([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
@@ -35,6 +40,33 @@ const AlbumDetail = () => {
     }
   };
 
+  const handleImageUpload = async (e) => {
+    const file = e.target.files[0];
+    e.target.value = '';
+    if (!file) return;
+    setImageUploading(true);
+    setImageError('');
+    try {
+      const result = await api.uploadImage('albums', id, file);
+      setAlbum({ ...album, imageUrls: result.imageUrls });
+    } catch (err) {
+      setImageError(err.message || 'Greška pri otpremanju omota');
+    } finally {
+      setImageUploading(false);
+    }
+  };
+
+  const handleImageDelete = async () => {
+    if (!window.confirm('Da li ste sigurni da želite da uklonite omot albuma?')) return;
+    setImageError('');
+    try {
+      await api.deleteImage('albums', id);
+      setAlbum({ ...album, imageUrls: undefined });
+    } catch (err) {
+      setImageError(err.message || 'Greška pri uklanjanju omota');
+    }
+  };
+
   const formatDuration = (seconds) => {
     const mins = Math.floor(seconds / 60);
     const secs = seconds % 60;
@@ -105,21 +137,58 @@ const AlbumDetail = () => {
 
           <div style={{ display: 'flex', alignItems: 'flex-start', gap: '30px', flexWrap: 'wrap' }}>
             {/* Album Cover */}
-            <div style={{
-              width: '200px',
-              height: '200px',
-              borderRadius: '20px',
-              background: `linear-gradient(135deg, 
-                hsl(${(album.id.charCodeAt(0) * 137.508) % 360}, 70%, 60%) 0%, 
-                hsl(${(album.id.charCodeAt(0) * 137.508 + 60) % 360}, 70%, 50%) 100%)`,
-              display: 'flex',
-              alignItems: 'center',
-              justifyContent: 'center',
-              fontSize: '80px',
-              boxShadow: '0 10px 30px rgba(0,0,0,0.2)',
-              flexShrink: 0
-            }}>
-              💿
+            <div style={{ flexShrink: 0, width: '200px' }}>
+              {album.imageUrls ? (
+                <img
+                  src={api.getImageUrl(album.imageUrls, '300')}
+                  srcSet={`${api.getImageUrl(album.imageUrls, '300')} 1x, ${api.getImageUrl(album.imageUrls, '640')} 2x`}
+                  alt={album.name}
+                  style={{
+                    width: '200px',
+                    height: '200px',
+                    borderRadius: '20px',
+                    objectFit: 'cover',
+                    boxShadow: '0 10px 30px rgba(0,0,0,0.2)',
+                    display: 'block'
+                  }}
+                />
+              ) : (
+                <div style={{
+                  width: '200px',
+                  height: '200px',
+                  borderRadius: '20px',
+                  background: `linear-gradient(135deg, 
+                    hsl(${(album.id.charCodeAt(0) * 137.508) % 360}, 70%, 60%) 0%, 
+                    hsl(${(album.id.charCodeAt(0) * 137.508 + 60) % 360}, 70%, 50%) 100%)`,
+                  display: 'flex',
+                  alignItems: 'center',
+                  justifyContent: 'center',
+                  fontSize: '80px',
+                  boxShadow: '0 10px 30px rgba(0,0,0,0.2)'
+                }}>
+                  💿
+                </div>
+              )}
+              {isAdmin() && (
+                <div style={{ marginTop: '12px', display: 'flex', flexDirection: 'column', gap: '8px' }}>
+                  <label className="btn btn-secondary" style={{ textAlign: 'center', cursor: 'pointer', fontSize: '14px' }}>
+                    {imageUploading ? 'Otpremanje...' : (album.imageUrls ? '🖼️ Promeni omot' : '🖼️ Dodaj omot')}
+                    <input
+                      type="file"
+                      accept="image/jpeg,image/png,image/gif,image/webp"
+                      onChange={handleImageUpload}
+                      disabled={imageUploading}
+                      style={{ display: 'none' }}
+                    />
+                  </label>
+                  {album.imageUrls && (
+                    <button className="btn btn-danger" onClick={handleImageDelete} style={{ fontSize: '14px' }}>
+                      Ukloni omot
+                    </button>
+                  )}
+                  {imageError && <div className="error" style={{ fontSize: '13px' }}>{imageError}</div>}
+                </div>
+              )}
             </div>
 
             {/* Album Info */}
@@ -180,6 +249,8 @@ const AlbumDetail = () => {
                   </div>
                 )}
               </div>
+
+              {isAdmin() && <RevisionHistory collection="albums" id={album.id} onRollback={loadAlbum} />}
             </div>
           </div>
         </div>
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
+import { searchCatalog } from '../utils/catalogSearch';
 
 const Albums = () => {
   const [albums, setAlbums] = useState([]);
@@ -37,14 +38,15 @@ const Albums = () => {
     filterAlbums();
   }, [albums, searchTerm, selectedGenre]);
 
-  const filterAlbums = () => {
+  const filterAlbums = async () => {
     let filtered = albums;
 
-    // Filter by search term
+    // Search term: ranked server-side search (prefix and typo tolerant)
     if (searchTerm) {
-      filtered = filtered.filter(album =>
-        album.name.toLowerCase().includes(searchTerm.toLowerCase())
-      );
+      filtered = await searchCatalog(albums, searchTerm, 'album');
+      if (filtered === null) {
+        return;
+      }
     }
 
     // Filter by genre
@@ -537,7 +539,14 @@ const Albums = () => {
                     e.currentTarget.style.transform = 'scale(1) rotate(0deg)';
                   }}
                   >
-                    💿
+                    {album.imageUrls ? (
+                      <img
+                        src={api.getImageUrl(album.imageUrls, '300')}
+                        alt={album.name}
+                        loading="lazy"
+                        style={{ width: '100%', height: '100%', objectFit: 'cover', borderRadius: '20px' }}
+                      />
+                    ) : '💿'}
                   </div>
 
                   {/* Album Name */}
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
+import RevisionHistory from './RevisionHistory';
 
 const ArtistDetail = () => {
   const { id } = useParams();
@@ -14,6 +15,8 @@ const ArtistDetail = () => {
   const [subscriptionMessage, setSubscriptionMessage] = useState('');
   const [isSubscribing, setIsSubscribing] = useState(false);
   const [isSubscribed, setIsSubscribed] = useState(false);
+  const [imageUploading, setImageUploading] = useState(false);
+  const [imageError, setImageError] = useState('');
 
   useEffect(() => {
     loadArtist();
@@ -34,6 +37,33 @@ const ArtistDetail = () => {
     }
   };
 
+  const handleImageUpload = async (e) => {
+    const file = e.target.files[0];
+    e.target.value = '';
+    if (!file) return;
+    setImageUploading(true);
+    setImageError('');
+    try {
+      const result = await api.uploadImage('artists', id, file);
+      setArtist({ ...artist, imageUrls: result.imageUrls });
+    } catch (err) {
+      setImageError(err.message || 'Greška pri otpremanju slike');
+    } finally {
+      setImageUploading(false);
+    }
+  };
+
+  const handleImageDelete = async () => {
+    if (!window.confirm('Da li ste sigurni da želite da uklonite sliku izvođača?')) return;
+    setImageError('');
+    try {
+      await api.deleteImage('artists', id);
+      setArtist({ ...artist, imageUrls: undefined });
+    } catch (err) {
+      setImageError(err.message || 'Greška pri uklanjanju slike');
+    }
+  };
+
   const loadAlbums = async () => {
     try {
       const data = await api.getAlbumsByArtist(id);
@@ -114,7 +144,17 @@ const ArtistDetail = () => {
           ← Nazad na izvođače
         </button>
         <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', marginBottom: '10px' }}>
-          <h2 style={{ margin: 0 }}>{artist.name}</h2>
+          <div style={{ display: 'flex', alignItems: 'center', gap: '16px' }}>
+            {artist.imageUrls && (
+              <img
+                src={api.getImageUrl(artist.imageUrls, '300')}
+                srcSet={`${api.getImageUrl(artist.imageUrls, '300')} 1x, ${api.getImageUrl(artist.imageUrls, '640')} 2x`}
+                alt={artist.name}
+                style={{ width: '120px', height: '120px', borderRadius: '50%', objectFit: 'cover' }}
+              />
+            )}
+            <h2 style={{ margin: 0 }}>{artist.name}</h2>
+          </div>
           {isAuthenticated && !isAdmin() && (
             <button
               className={isSubscribed ? "btn btn-secondary" : "btn btn-primary"}
@@ -128,6 +168,26 @@ const ArtistDetail = () => {
             </button>
           )}
         </div>
+        {isAdmin() && (
+          <div style={{ display: 'flex', gap: '10px', alignItems: 'center', marginBottom: '10px' }}>
+            <label className="btn btn-secondary" style={{ cursor: 'pointer' }}>
+              {imageUploading ? 'Otpremanje...' : (artist.imageUrls ? '🖼️ Promeni sliku' : '🖼️ Dodaj sliku')}
+              <input
+                type="file"
+                accept="image/jpeg,image/png,image/gif,image/webp"
+                onChange={handleImageUpload}
+                disabled={imageUploading}
+                style={{ display: 'none' }}
+              />
+            </label>
+            {artist.imageUrls && (
+              <button className="btn btn-danger" onClick={handleImageDelete}>
+                Ukloni sliku
+              </button>
+            )}
+            {imageError && <div className="error">{imageError}</div>}
+          </div>
+        )}
         {subscriptionMessage && (
           <div className="success" style={{ marginTop: '10px', marginBottom: '10px' }}>
             {subscriptionMessage}
@@ -142,6 +202,7 @@ const ArtistDetail = () => {
             ))}
           </div>
         )}
+        {isAdmin() && <RevisionHistory collection="artists" id={artist.id} onRollback={loadArtist} />}
       </div>
 
       {albums.length > 0 && (
@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
+import { searchCatalog } from '../utils/catalogSearch';
 
 const Artists = () => {
   const [artists, setArtists] = useState([]);
@@ -34,14 +35,15 @@ const Artists = () => {
     filterArtists();
   }, [artists, searchTerm, selectedGenre]);
 
-  const filterArtists = () => {
+  const filterArtists = async () => {
     let filtered = artists;
 
-    // Filter by search term
+    // Search term: ranked server-side search (prefix and typo tolerant)
     if (searchTerm) {
-      filtered = filtered.filter(artist =>
-        artist.name.toLowerCase().includes(searchTerm.toLowerCase())
-      );
+      filtered = await searchCatalog(artists, searchTerm, 'artist');
+      if (filtered === null) {
+        return;
+      }
     }
 
     // Filter by genre
@@ -476,7 +478,14 @@ const Artists = () => {
                     e.currentTarget.style.transform = 'scale(1) rotate(0deg)';
                   }}
                   >
-                    🎤
+                    {artist.imageUrls ? (
+                      <img
+                        src={api.getImageUrl(artist.imageUrls, '300')}
+                        alt={artist.name}
+                        loading="lazy"
+                        style={{ width: '100%', height: '100%', objectFit: 'cover', borderRadius: '20px' }}
+                      />
+                    ) : '🎤'}
                   </div>
 
                   {/* Artist Name */}
@@ -2,7 +2,9 @@ import React, { useState, useRef, useEffect } from 'react';
 import api from '../services/api';
 import './AudioPlayer.css';
 
-const AudioPlayer = ({ songId, songName, audioFileUrl }) => {
+const AudioPlayer = ({ songId, songName, audioFile, onTimeUpdate }) => {
+  // Only audio hosted elsewhere is played by URL, stored files go through the stream endpoint
+  const audioFileUrl = audioFile && audioFile.backend === 'external' ? audioFile.key : '';
   const [isPlaying, setIsPlaying] = useState(false);
   const [currentTime, setCurrentTime] = useState(0);
   const [duration, setDuration] = useState(0);
@@ -13,6 +15,9 @@ const AudioPlayer = ({ songId, songName, audioFileUrl }) => {
   
   const audioRef = useRef(null);
   const progressBarRef = useRef(null);
+  // Latest onTimeUpdate callback (e.g. synced lyrics), read by the timeupdate listener
+  const onTimeUpdateRef = useRef(onTimeUpdate);
+  onTimeUpdateRef.current = onTimeUpdate;
 
   // Validate URL on mount and when audioFileUrl changes
   useEffect(() => {
@@ -31,7 +36,12 @@ const AudioPlayer = ({ songId, songName, audioFileUrl }) => {
     const audio = audioRef.current;
     if (!audio) return;
 
-    const updateTime = () => setCurrentTime(audio.currentTime);
+    const updateTime = () => {
+      setCurrentTime(audio.currentTime);
+      if (onTimeUpdateRef.current) {
+        onTimeUpdateRef.current(audio.currentTime);
+      }
+    };
     const updateDuration = () => setDuration(audio.duration);
     const handleLoadStart = () => setIsLoading(true);
     const handleCanPlay = () => setIsLoading(false);
@@ -140,15 +150,6 @@ const AudioPlayer = ({ songId, songName, audioFileUrl }) => {
       return null;
     }
     
-    // If song has HDFS path (starts with /audio/), use stream endpoint with cache-busting
-    if (audioFileUrl && (audioFileUrl.startsWith('/audio/') || audioFileUrl.startsWith('hdfs://'))) {
-      const baseUrl = api.getStreamUrl(songId);
-      // Remove existing timestamp and add new one
-      const url = baseUrl.split('&t=')[0].split('?t=')[0];
-      const separator = url.includes('?') ? '&' : '?';
-      return `${url}${separator}t=${cacheBuster}`;
-    }
-    
     // If song has external URL (http/https), use it directly with cache-busting
     if (audioFileUrl && (audioFileUrl.startsWith('http://') || audioFileUrl.startsWith('https://'))) {
       // Add cache-busting parameter to external URLs too
@@ -173,7 +174,7 @@ const AudioPlayer = ({ songId, songName, audioFileUrl }) => {
       
       {getAudioUrl() ? (
         <audio
-          key={audioFileUrl || songId} // Force re-render when audioFileUrl changes
+          key={audioFile ? `${audioFile.backend}:${audioFile.key}` : songId} // Force re-render when the audio file changes
           ref={audioRef}
           src={getAudioUrl()}
           preload="metadata"
@@ -0,0 +1,78 @@
+import React, { useState } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import api from '../services/api';
+
+// Confirmation page for the links of an email change: "confirm" (sent to the new address) or
+// "cancel" (sent to the current address). Nothing changes until the user presses the button (POST).
+const EmailChangeAction = ({ action }) => {
+  const [searchParams] = useSearchParams();
+  const [status, setStatus] = useState('confirm'); // confirm, success, error
+  const [message, setMessage] = useState('');
+  const [loading, setLoading] = useState(false);
+  const navigate = useNavigate();
+  const token = searchParams.get('token');
+  const isConfirm = action === 'confirm';
+
+  const handleSubmit = async () => {
+    setLoading(true);
+    try {
+      if (isConfirm) {
+        await api.confirmEmailChange(token);
+        setMessage('Email adresa je promenjena. Prijavite se ponovo.');
+      } else {
+        await api.cancelEmailChange(token);
+        setMessage('Promena email adrese je otkazana.');
+      }
+      setStatus('success');
+    } catch (err) {
+      setStatus('error');
+      setMessage(err.message || 'Link je nevažeći ili je istekao.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="container">
+      <div className="card">
+        <h2>{isConfirm ? 'Potvrda nove email adrese' : 'Otkazivanje promene email adrese'}</h2>
+        {!token && (
+          <div className="error">
+            <p>Token nije pronađen u URL-u. Proverite link.</p>
+          </div>
+        )}
+        {token && status === 'confirm' && (
+          <div>
+            <p>
+              {isConfirm
+                ? 'Potvrdite da želite da ova adresa postane email adresa vašeg naloga. Bićete odjavljeni sa svih uređaja.'
+                : 'Potvrdite da želite da otkažete započetu promenu email adrese.'}
+            </p>
+            <button
+              className={isConfirm ? 'btn btn-primary' : 'btn btn-danger'}
+              onClick={handleSubmit}
+              disabled={loading}
+            >
+              {loading ? 'Obrada...' : isConfirm ? 'Potvrdi promenu' : 'Otkaži promenu'}
+            </button>
+          </div>
+        )}
+        {status === 'success' && (
+          <div className="success">
+            <p>{message}</p>
+            <button className="btn btn-primary" onClick={() => navigate('/login')} style={{ marginTop: '10px' }}>
+              Idi na prijavu
+            </button>
+          </div>
+        )}
+        {status === 'error' && (
+          <div className="error">
+            <p>{message}</p>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default EmailChangeAction;
@@ -0,0 +1,72 @@
+import React, { useState } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import api from '../services/api';
+
+// Confirmation page for the "this wasn't me" link of a suspicious-login alert.
+// Sessions are revoked only after the user confirms (POST), never by opening the link.
+const LoginNotMe = () => {
+  const [searchParams] = useSearchParams();
+  const [status, setStatus] = useState('confirm'); // confirm, success, error
+  const [message, setMessage] = useState('');
+  const [loading, setLoading] = useState(false);
+  const navigate = useNavigate();
+  const token = searchParams.get('token');
+
+  const handleConfirm = async () => {
+    setLoading(true);
+    try {
+      const data = await api.reportLoginNotMe(token);
+      setStatus('success');
+      setMessage(data.message || 'Odjavljeni ste sa svih uređaja. Proverite email da resetujete lozinku.');
+    } catch (err) {
+      setStatus('error');
+      setMessage(err.message || 'Link je nevažeći ili je istekao.');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="container">
+      <div className="card">
+        <h2>Sumnjiva prijava</h2>
+        {!token && (
+          <div className="error">
+            <p>Token nije pronađen u URL-u. Proverite link.</p>
+          </div>
+        )}
+        {token && status === 'confirm' && (
+          <div>
+            <p>
+              Ako se niste vi prijavili, potvrdite ispod. Bićete odjavljeni sa svih uređaja i moraćete
+              da resetujete lozinku pre sledeće prijave.
+            </p>
+            <button className="btn btn-danger" onClick={handleConfirm} disabled={loading}>
+              {loading ? 'Obrada...' : 'Nisam to bio/la ja - odjavi me svuda'}
+            </button>
+            <button
+              className="btn btn-secondary"
+              onClick={() => navigate('/')}
+              disabled={loading}
+              style={{ marginLeft: '10px' }}
+            >
+              Odustani
+            </button>
+          </div>
+        )}
+        {status === 'success' && (
+          <div className="success">
+            <p>{message}</p>
+          </div>
+        )}
+        {status === 'error' && (
+          <div className="error">
+            <p>{message}</p>
+          </div>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default LoginNotMe;
@@ -0,0 +1,243 @@
+import React, { useState, useEffect, useRef } from 'react';
+import api from '../services/api';
+
+// Tekst pesme: izbor jezika, isticanje trenutnog stiha za sinhronizovan tekst (LRC)
+// i unos/brisanje teksta za administratora
+const Lyrics = ({ songId, currentTimeMs, isAdmin }) => {
+  const [languages, setLanguages] = useState([]);
+  const [language, setLanguage] = useState('');
+  const [lines, setLines] = useState([]);
+  const [error, setError] = useState('');
+  const [editing, setEditing] = useState(false);
+  const [editLanguage, setEditLanguage] = useState('');
+  const [editText, setEditText] = useState('');
+  const [editFormat, setEditFormat] = useState('');
+  const [saving, setSaving] = useState(false);
+  const activeLineRef = useRef(null);
+
+  useEffect(() => {
+    loadLanguages();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [songId]);
+
+  useEffect(() => {
+    if (language) {
+      loadLyrics(language);
+    } else {
+      setLines([]);
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [songId, language]);
+
+  const loadLanguages = async (preferred) => {
+    try {
+      const data = await api.getLyricsLanguages(songId);
+      const list = Array.isArray(data) ? data : [];
+      setLanguages(list);
+      if (preferred && list.some(l => l.language === preferred)) {
+        setLanguage(preferred);
+      } else if (!list.some(l => l.language === language)) {
+        setLanguage(list.length > 0 ? list[0].language : '');
+      }
+    } catch (err) {
+      setLanguages([]);
+      setLanguage('');
+    }
+  };
+
+  const loadLyrics = async (lang) => {
+    try {
+      setError('');
+      setLines(await api.getLyrics(songId, lang));
+    } catch (err) {
+      setLines([]);
+      setError(err.message || 'Greška pri učitavanju teksta');
+    }
+  };
+
+  const synced = lines.length > 0 && lines[0].startMs !== undefined;
+  const activeIndex = synced && currentTimeMs !== undefined
+    ? lines.findIndex(line => currentTimeMs >= line.startMs && (line.endMs === undefined || currentTimeMs < line.endMs))
+    : -1;
+
+  useEffect(() => {
+    if (activeLineRef.current) {
+      activeLineRef.current.scrollIntoView({ block: 'nearest', behavior: 'smooth' });
+    }
+  }, [activeIndex]);
+
+  const startEditing = () => {
+    setEditLanguage(language || 'sr');
+    setEditFormat('');
+    setEditText(lines.map(line => {
+      if (line.startMs === undefined) {
+        return line.text;
+      }
+      const minutes = String(Math.floor(line.startMs / 60000)).padStart(2, '0');
+      const seconds = String(Math.floor(line.startMs / 1000) % 60).padStart(2, '0');
+      const hundredths = String(Math.floor(line.startMs % 1000 / 10)).padStart(2, '0');
+      return `[${minutes}:${seconds}.${hundredths}]${line.text}`;
+    }).join('\n'));
+    setError('');
+    setEditing(true);
+  };
+
+  const handleSave = async () => {
+    const lang = editLanguage.trim().toLowerCase();
+    if (!lang || !editText.trim()) {
+      setError('Jezik i tekst su obavezni');
+      return;
+    }
+    try {
+      setSaving(true);
+      setError('');
+      await api.setLyrics(songId, lang, editText, editFormat || undefined);
+      setEditing(false);
+      await loadLanguages(lang);
+      if (lang === language) {
+        await loadLyrics(lang);
+      }
+    } catch (err) {
+      setError(err.message || 'Greška pri čuvanju teksta');
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const handleDelete = async () => {
+    if (!language || !window.confirm(`Obrisati tekst pesme (${language})?`)) {
+      return;
+    }
+    try {
+      setError('');
+      await api.deleteLyrics(songId, language);
+      setLanguage('');
+      await loadLanguages();
+    } catch (err) {
+      setError(err.message || 'Greška pri brisanju teksta');
+    }
+  };
+
+  if (languages.length === 0 && !isAdmin) {
+    return null;
+  }
+
+  const buttonStyle = {
+    padding: '8px 16px',
+    borderRadius: '8px',
+    border: 'none',
+    cursor: 'pointer',
+    fontWeight: '600',
+    fontSize: '14px'
+  };
+
+  return (
+    <div>
+      <div style={{ display: 'flex', alignItems: 'center', gap: '12px', marginBottom: '16px', flexWrap: 'wrap' }}>
+        {languages.length > 1 && (
+          <select
+            value={language}
+            onChange={(e) => setLanguage(e.target.value)}
+            style={{ padding: '8px 12px', borderRadius: '8px', border: '1px solid #ddd', fontSize: '14px' }}
+          >
+            {languages.map(l => (
+              <option key={l.language} value={l.language}>
+                {l.language}{l.synced ? ' (sinhronizovan)' : ''}
+              </option>
+            ))}
+          </select>
+        )}
+        {isAdmin && !editing && (
+          <>
+            <button
+              onClick={startEditing}
+              style={{ ...buttonStyle, background: 'linear-gradient(135deg, #667eea 0%, #764ba2 100%)', color: 'white' }}
+            >
+              {languages.length > 0 ? 'Izmeni tekst' : 'Dodaj tekst'}
+            </button>
+            {language && (
+              <button onClick={handleDelete} style={{ ...buttonStyle, background: '#ff4757', color: 'white' }}>
+                Obriši tekst
+              </button>
+            )}
+          </>
+        )}
+      </div>
+
+      {error && (
+        <div style={{ color: '#c62828', marginBottom: '12px', fontSize: '14px' }}>{error}</div>
+      )}
+
+      {editing ? (
+        <div>
+          <div style={{ display: 'flex', gap: '12px', marginBottom: '12px', flexWrap: 'wrap' }}>
+            <input
+              type="text"
+              value={editLanguage}
+              onChange={(e) => setEditLanguage(e.target.value)}
+              placeholder="Jezik (npr. sr, en)"
+              style={{ padding: '8px 12px', borderRadius: '8px', border: '1px solid #ddd', fontSize: '14px', width: '160px' }}
+            />
+            <select
+              value={editFormat}
+              onChange={(e) => setEditFormat(e.target.value)}
+              style={{ padding: '8px 12px', borderRadius: '8px', border: '1px solid #ddd', fontSize: '14px' }}
+            >
+              <option value="">Automatski format</option>
+              <option value="lrc">LRC (sinhronizovan)</option>
+              <option value="plain">Običan tekst</option>
+            </select>
+          </div>
+          <textarea
+            value={editText}
+            onChange={(e) => setEditText(e.target.value)}
+            rows={14}
+            placeholder={'[00:12.50]Prvi stih\n[00:17.20]Drugi stih\n\nili običan tekst bez vremena'}
+            style={{
+              width: '100%',
+              padding: '12px',
+              borderRadius: '8px',
+              border: '1px solid #ddd',
+              fontFamily: 'monospace',
+              fontSize: '14px',
+              boxSizing: 'border-box'
+            }}
+          />
+          <div style={{ display: 'flex', gap: '12px', marginTop: '12px' }}>
+            <button
+              onClick={handleSave}
+              disabled={saving}
+              style={{ ...buttonStyle, background: 'linear-gradient(135deg, #667eea 0%, #764ba2 100%)', color: 'white', opacity: saving ? 0.6 : 1 }}
+            >
+              {saving ? 'Čuvanje...' : 'Sačuvaj'}
+            </button>
+            <button onClick={() => setEditing(false)} style={{ ...buttonStyle, background: '#eee', color: '#333' }}>
+              Otkaži
+            </button>
+          </div>
+        </div>
+      ) : lines.length > 0 ? (
+        <div style={{ maxHeight: '360px', overflowY: 'auto', lineHeight: '1.8', fontSize: '16px' }}>
+          {lines.map((line, index) => (
+            <div
+              key={index}
+              ref={index === activeIndex ? activeLineRef : null}
+              style={{
+                minHeight: '1.8em',
+                color: index === activeIndex ? '#667eea' : synced ? '#888' : '#333',
+                fontWeight: index === activeIndex ? '700' : '400',
+                transition: 'color 0.2s'
+              }}
+            >
+              {line.text}
+            </div>
+          ))}
+        </div>
+      ) : (
+        <p style={{ color: '#888', margin: 0 }}>Tekst pesme nije dodat.</p>
+      )}
+    </div>
+  );
+};
+
+export default Lyrics;
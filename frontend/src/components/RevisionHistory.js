@@ -0,0 +1,129 @@
+import React, { useState, useEffect } from 'react';
+import api from '../services/api';
+
+const ACTIONS = {
+  create: 'Kreirano',
+  update: 'Izmenjeno',
+  rollback: 'Vraćeno',
+  initial: 'Početno stanje',
+};
+
+const formatValue = (value) => {
+  if (value === null || value === undefined || value === '') {
+    return '—';
+  }
+  if (Array.isArray(value)) {
+    return value.join(', ');
+  }
+  return String(value);
+};
+
+// Istorija izmena pesme, albuma ili izvođača za administratora, sa vraćanjem na izabranu reviziju
+// (collection: 'songs', 'albums' ili 'artists')
+const RevisionHistory = ({ collection, id, onRollback }) => {
+  const [revisions, setRevisions] = useState([]);
+  const [nextCursor, setNextCursor] = useState(null);
+  const [open, setOpen] = useState(false);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+
+  useEffect(() => {
+    setRevisions([]);
+    setNextCursor(null);
+    if (open) {
+      loadRevisions();
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [collection, id, open]);
+
+  const loadRevisions = async (before) => {
+    try {
+      setLoading(true);
+      setError('');
+      const data = await api.getRevisions(collection, id, before);
+      const items = Array.isArray(data.items) ? data.items : [];
+      setRevisions(before ? [...revisions, ...items] : items);
+      setNextCursor(data.nextCursor || null);
+    } catch (err) {
+      setError(err.message || 'Greška pri učitavanju istorije izmena');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleRollback = async (revision) => {
+    if (!window.confirm(`Vratiti stanje iz revizije #${revision.number}?`)) {
+      return;
+    }
+    try {
+      setError('');
+      await api.rollbackRevision(collection, id, revision.number);
+      setMessage(`Vraćeno stanje iz revizije #${revision.number}`);
+      await loadRevisions();
+      if (onRollback) {
+        onRollback();
+      }
+    } catch (err) {
+      setError(err.message || 'Greška pri vraćanju revizije');
+    }
+  };
+
+  return (
+    <div style={{ marginTop: '20px' }}>
+      <button className="btn btn-secondary" onClick={() => { setMessage(''); setOpen(!open); }}>
+        {open ? 'Sakrij istoriju izmena' : 'Istorija izmena'}
+      </button>
+
+      {open && (
+        <div style={{ marginTop: '15px' }}>
+          {error && <div className="error">{error}</div>}
+          {message && <div className="success">{message}</div>}
+
+          {!loading && revisions.length === 0 && !error && <p>Nema zabeleženih izmena.</p>}
+
+          {revisions.map((revision, idx) => (
+            <div key={revision.id} className="list-item" style={{ cursor: 'default' }}>
+              <div style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', gap: '20px' }}>
+                <div>
+                  <strong>#{revision.number} {ACTIONS[revision.action] || revision.action}</strong>
+                  {revision.restoredFrom ? ` (iz revizije #${revision.restoredFrom})` : ''}
+                  <p style={{ fontSize: '12px', color: '#666', marginTop: '5px' }}>
+                    {new Date(revision.createdAt).toLocaleString()}
+                    {revision.authorId && ` · ${revision.authorId}`}
+                  </p>
+                </div>
+                {idx > 0 && (
+                  <button className="btn btn-primary" onClick={() => handleRollback(revision)}>
+                    Vrati ovo stanje
+                  </button>
+                )}
+              </div>
+              {revision.action !== 'initial' && revision.changes && revision.changes.length > 0 && (
+                <ul style={{ marginTop: '10px', fontSize: '14px' }}>
+                  {revision.changes.map(change => (
+                    <li key={change.field}>
+                      <strong>{change.field}:</strong>{' '}
+                      {revision.action === 'create'
+                        ? formatValue(change.new)
+                        : `${formatValue(change.old)} → ${formatValue(change.new)}`}
+                    </li>
+                  ))}
+                </ul>
+              )}
+            </div>
+          ))}
+
+          {loading && <p>Učitavanje...</p>}
+          {nextCursor && !loading && (
+            <button className="btn btn-secondary" onClick={() => loadRevisions(nextCursor)}>
+              Starije izmene
+            </button>
+          )}
+        </div>
+      )}
+    </div>
+  );
+};
+
+export default RevisionHistory;
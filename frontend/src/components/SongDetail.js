@@ -3,6 +3,8 @@ import { useParams, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
 import AudioPlayer from './AudioPlayer';
+import Lyrics from './Lyrics';
+import RevisionHistory from './RevisionHistory';
 
 const SongDetail = () => {
   const { id } = useParams();
@@ -14,6 +16,7 @@ const SongDetail = () => {
   const [userRating, setUserRating] = useState(null);
   const [ratingMessage, setRatingMessage] = useState('');
   const [isRating, setIsRating] = useState(false);
+  const [playbackMs, setPlaybackMs] = useState(0);
 
   useEffect(() => {
     loadSong();
@@ -535,10 +538,52 @@ const SongDetail = () => {
           <AudioPlayer 
             songId={song.id} 
             songName={song.name} 
-            audioFileUrl={song.audioFileUrl} 
+            audioFile={song.audioFile} 
+            onTimeUpdate={(seconds) => setPlaybackMs(Math.floor(seconds * 1000))}
           />
         </div>
+
+        {/* Lyrics Section */}
+        {(isAdmin() || (song.lyricsLanguages && song.lyricsLanguages.length > 0)) && (
+          <div style={{
+            background: 'linear-gradient(135deg, rgba(255,255,255,0.95) 0%, rgba(255,255,255,0.9) 100%)',
+            backdropFilter: 'blur(10px)',
+            borderRadius: '20px',
+            padding: '40px',
+            marginBottom: '30px',
+            boxShadow: '0 20px 60px rgba(0,0,0,0.15)',
+            border: '1px solid rgba(255,255,255,0.3)'
+          }}>
+            <div style={{ display: 'flex', alignItems: 'center', gap: '12px', marginBottom: '24px' }}>
+              <span style={{ fontSize: '32px' }}>📝</span>
+              <h2 style={{
+                margin: 0,
+                fontSize: '24px',
+                fontWeight: '700',
+                color: '#333'
+              }}>
+                Tekst pesme
+              </h2>
+            </div>
+            <Lyrics songId={song.id} currentTimeMs={playbackMs} isAdmin={isAdmin()} />
+          </div>
+        )}
         
+        {/* Revision History Section */}
+        {isAdmin() && (
+          <div style={{
+            background: 'linear-gradient(135deg, rgba(255,255,255,0.95) 0%, rgba(255,255,255,0.9) 100%)',
+            backdropFilter: 'blur(10px)',
+            borderRadius: '20px',
+            padding: '40px',
+            marginBottom: '30px',
+            boxShadow: '0 20px 60px rgba(0,0,0,0.15)',
+            border: '1px solid rgba(255,255,255,0.3)'
+          }}>
+            <RevisionHistory collection="songs" id={song.id} onRollback={loadSong} />
+          </div>
+        )}
+
         {/* Rating Section */}
         {renderRatingStars() && (
           <div style={{
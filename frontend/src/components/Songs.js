@@ -2,6 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import api from '../services/api';
+import { searchCatalog } from '../utils/catalogSearch';
 
 const Songs = () => {
   const [songs, setSongs] = useState([]);
@@ -54,14 +55,15 @@ const Songs = () => {
     filterSongs();
   }, [songs, searchTerm, selectedGenre]);
 
-  const filterSongs = () => {
+  const filterSongs = async () => {
     let filtered = songs;
 
-    // Filter by search term
+    // Search term: ranked server-side search (prefix and typo tolerant)
     if (searchTerm) {
-      filtered = filtered.filter(song =>
-        song.name.toLowerCase().includes(searchTerm.toLowerCase())
-      );
+      filtered = await searchCatalog(songs, searchTerm, 'song');
+      if (filtered === null) {
+        return;
+      }
     }
 
     // Filter by genre
@@ -213,7 +215,7 @@ const Songs = () => {
       genre: song.genre || '',
       albumId: song.albumId || song.albumID || '',
       selectedArtistIds: song.artistIds || song.artistIDs || [],
-      audioFileUrl: song.audioFileUrl || '',
+      audioFileUrl: song.audioFile && song.audioFile.backend === 'external' ? song.audioFile.key : '',
     });
     setAudioFile(null);
     setShowForm(true);
@@ -0,0 +1,141 @@
+import React, { useState, useEffect } from 'react';
+import { useAuth } from '../context/AuthContext';
+import api from '../services/api';
+
+const COLLECTIONS = [
+  { key: 'songs', label: 'Pesme' },
+  { key: 'albums', label: 'Albumi' },
+  { key: 'artists', label: 'Izvođači' },
+];
+
+// Korpa: obrisane pesme, albumi i izvođači ostaju ovde do trajnog brisanja
+const Trash = () => {
+  const { isAdmin } = useAuth();
+  const [collection, setCollection] = useState('songs');
+  const [items, setItems] = useState([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+  // Pesme i albumi koje izvođač deli sa drugim izvođačima: ostaju bez njega ili se brišu
+  const [sharedSongs, setSharedSongs] = useState('detach');
+
+  useEffect(() => {
+    if (isAdmin()) {
+      loadTrash();
+    } else {
+      setError('Samo administrator može da vidi korpu');
+      setLoading(false);
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [collection]);
+
+  const loadTrash = async () => {
+    try {
+      setLoading(true);
+      setError('');
+      const data = await api.getTrash(collection);
+      setItems(Array.isArray(data) ? data : []);
+    } catch (err) {
+      setError(err.message || 'Greška pri učitavanju korpe');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleRestore = async (item) => {
+    try {
+      setError('');
+      await api.restoreFromTrash(collection, item.id);
+      setItems(items.filter(i => i.id !== item.id));
+      setMessage(`"${item.name}" je vraćen(a)`);
+    } catch (err) {
+      setError(err.message || 'Greška pri vraćanju');
+    }
+  };
+
+  const handlePurge = async (item) => {
+    const cascade = collection === 'albums'
+      ? ' Biće obrisane i sve pesme albuma.'
+      : collection === 'artists'
+        ? ' Biće obrisani i albumi i pesme izvođača.'
+        : '';
+    if (!window.confirm(`Trajno obrisati "${item.name}"?${cascade} Ovo se ne može poništiti.`)) {
+      return;
+    }
+    try {
+      setError('');
+      await api.purgeFromTrash(collection, item.id, collection === 'artists' ? sharedSongs : undefined);
+      setItems(items.filter(i => i.id !== item.id));
+      setMessage(`"${item.name}" je trajno obrisan(a)`);
+    } catch (err) {
+      setError(err.message || 'Greška pri trajnom brisanju');
+    }
+  };
+
+  return (
+    <div className="container">
+      <div className="card">
+        <h2>Korpa</h2>
+        <p style={{ color: '#666' }}>
+          Obrisane stavke se trajno brišu kada istekne rok čuvanja.
+        </p>
+
+        <div style={{ display: 'flex', gap: '10px', margin: '20px 0' }}>
+          {COLLECTIONS.map(c => (
+            <button
+              key={c.key}
+              className={collection === c.key ? 'btn btn-primary' : 'btn btn-secondary'}
+              onClick={() => { setMessage(''); setCollection(c.key); }}
+            >
+              {c.label}
+            </button>
+          ))}
+        </div>
+
+        {collection === 'artists' && (
+          <div style={{ marginBottom: '20px' }}>
+            <label style={{ marginRight: '10px' }}>Zajedničke pesme i albumi:</label>
+            <select value={sharedSongs} onChange={(e) => setSharedSongs(e.target.value)}>
+              <option value="detach">zadrži ih bez izvođača</option>
+              <option value="delete">obriši ih</option>
+            </select>
+          </div>
+        )}
+
+        {error && <div className="error">{error}</div>}
+        {message && <div className="success">{message}</div>}
+
+        {loading ? (
+          <p>Učitavanje...</p>
+        ) : items.length === 0 ? (
+          <p>Korpa je prazna.</p>
+        ) : (
+          <div>
+            {items.map(item => (
+              <div key={item.id} className="list-item" style={{ display: 'flex', justifyContent: 'space-between', alignItems: 'center', gap: '20px' }}>
+                <div>
+                  <h3>{item.name}</h3>
+                  <p style={{ fontSize: '12px', color: '#666', marginTop: '5px' }}>
+                    Obrisano: {new Date(item.deletedAt).toLocaleString()}
+                    {' · '}
+                    Trajno brisanje: {new Date(item.purgeAt).toLocaleString()}
+                  </p>
+                </div>
+                <div style={{ display: 'flex', gap: '10px' }}>
+                  <button className="btn btn-primary" onClick={() => handleRestore(item)}>
+                    Vrati
+                  </button>
+                  <button className="btn btn-danger" onClick={() => handlePurge(item)}>
+                    Obriši trajno
+                  </button>
+                </div>
+              </div>
+            ))}
+          </div>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default Trash;
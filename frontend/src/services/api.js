@@ -1,5 +1,12 @@
+import { solveChallenge } from '../utils/pow';
+
 const API_BASE_URL = process.env.REACT_APP_API_URL || 'http://localhost:8081';
 
+// Audio files above this size are uploaded with the resumable (tus) protocol
+const RESUMABLE_UPLOAD_THRESHOLD = 8 * 1024 * 1024;
+const RESUMABLE_CHUNK_SIZE = 4 * 1024 * 1024;
+const RESUMABLE_MAX_RETRIES = 5;
+
 // HTTPS je omogućen za komunikaciju sa API Gateway-em
 // Za development sa self-signed sertifikatima, browser će tražiti potvrdu
 
@@ -10,12 +17,14 @@ class ApiService {
 
   async request(endpoint, options = {}) {
     const url = `${this.baseURL}${endpoint}`;
+    // withNextCursor: return { items, nextCursor } for paginated list endpoints
+    const { withNextCursor, ...fetchOptions } = options;
     const config = {
       headers: {
         'Content-Type': 'application/json',
-        ...options.headers,
+        ...fetchOptions.headers,
       },
-      ...options,
+      ...fetchOptions,
     };
 
     // Add auth token if available
@@ -66,17 +75,37 @@ class ApiService {
         
         throw new Error(error);
       }
-      
+
+      if (withNextCursor) {
+        return { items: Array.isArray(data) ? data : [], nextCursor: response.headers.get('X-Next-Cursor') };
+      }
       return data;
     } catch (error) {
       throw error;
     }
   }
 
+  // Fetches and solves a proof-of-work challenge for public endpoints that send emails
+  async proofOfWorkHeaders(action) {
+    const params = new URLSearchParams({ action });
+    const response = await fetch(`${this.baseURL}/api/pow/challenge?${params.toString()}`);
+    if (!response.ok) {
+      throw new Error('Nije moguće dobiti sigurnosni izazov. Pokušajte ponovo.');
+    }
+    const { challenge, difficulty } = await response.json();
+    const solution = await solveChallenge(challenge, difficulty);
+    return {
+      'Content-Type': 'application/json',
+      'X-PoW-Challenge': challenge,
+      'X-PoW-Solution': solution,
+    };
+  }
+
   // Users Service
   async register(userData) {
     return this.request('/api/users/register', {
       method: 'POST',
+      headers: await this.proofOfWorkHeaders('register'),
       body: JSON.stringify(userData),
     });
   }
@@ -84,6 +113,7 @@ class ApiService {
   async requestOTP(credentials) {
     return this.request('/api/users/login/request-otp', {
       method: 'POST',
+      headers: await this.proofOfWorkHeaders('login_otp'),
       body: JSON.stringify(credentials),
     });
   }
@@ -105,6 +135,7 @@ class ApiService {
   async requestPasswordReset(email) {
     return this.request('/api/users/password/reset/request', {
       method: 'POST',
+      headers: await this.proofOfWorkHeaders('password_reset'),
       body: JSON.stringify({ email }),
     });
   }
@@ -126,6 +157,7 @@ class ApiService {
   async requestMagicLink(email) {
     return this.request('/api/users/recover/request', {
       method: 'POST',
+      headers: await this.proofOfWorkHeaders('recover'),
       body: JSON.stringify({ email }),
     });
   }
@@ -136,6 +168,27 @@ class ApiService {
     return this.request(`/api/users/recover/verify?${params.toString()}`);
   }
 
+  async confirmEmailChange(token) {
+    return this.request('/api/users/email/change/confirm', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
+  async cancelEmailChange(token) {
+    return this.request('/api/users/email/change/cancel', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
+  async reportLoginNotMe(token) {
+    return this.request('/api/users/login/not-me', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
   async logout() {
     return this.request('/api/users/logout', {
       method: 'POST',
@@ -143,8 +196,8 @@ class ApiService {
   }
 
   // Content Service - Artists
-  async getArtists() {
-    return this.request('/api/content/artists');
+  async getArtists(params = {}) {
+    return this.getAllPages('/api/content/artists', params);
   }
 
   async getArtist(id) {
@@ -172,8 +225,8 @@ class ApiService {
   }
 
   // Content Service - Albums
-  async getAlbums() {
-    return this.request('/api/content/albums');
+  async getAlbums(params = {}) {
+    return this.getAllPages('/api/content/albums', params);
   }
 
   async getAlbum(id) {
@@ -181,7 +234,7 @@ class ApiService {
   }
 
   async getAlbumsByArtist(artistId) {
-    return this.request(`/api/content/albums/by-artist?artistId=${artistId}`);
+    return this.getAllPages('/api/content/albums/by-artist', { artistId });
   }
 
   async createAlbum(albumData) {
@@ -204,9 +257,47 @@ class ApiService {
     });
   }
 
+  // Content Service - Paginated lists
+  // params: limit, cursor, sort (name, -name, createdAt, releaseDate, rating), genre, artistId, albumId
+  // Returns { items, nextCursor }; nextCursor is null on the last page
+  async getPage(endpoint, params = {}) {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== null && value !== '') {
+        query.set(key, value);
+      }
+    });
+    const separator = endpoint.includes('?') ? '&' : '?';
+    return this.request(`${endpoint}${separator}${query.toString()}`, { withNextCursor: true });
+  }
+
+  // Loads every page of a list endpoint
+  async getAllPages(endpoint, params = {}) {
+    let items = [];
+    let cursor = null;
+    do {
+      const page = await this.getPage(endpoint, { ...params, limit: 200, cursor });
+      items = items.concat(page.items);
+      cursor = page.nextCursor;
+    } while (cursor);
+    return items;
+  }
+
+  // Content Service - Search
+  // params: q, type (song,album,artist), genre, yearFrom, yearTo, artistId, limit, offset
+  async searchCatalog(params) {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== null && value !== '') {
+        query.set(key, value);
+      }
+    });
+    return this.request(`/api/content/search?${query.toString()}`);
+  }
+
   // Content Service - Songs
-  async getSongs() {
-    return this.request('/api/content/songs');
+  async getSongs(params = {}) {
+    return this.getAllPages('/api/content/songs', params);
   }
 
   async getSong(id) {
@@ -214,7 +305,7 @@ class ApiService {
   }
 
   async getSongsByAlbum(albumId) {
-    return this.request(`/api/content/songs/by-album?albumId=${albumId}`);
+    return this.getAllPages('/api/content/songs/by-album', { albumId });
   }
 
   async createSong(songData) {
@@ -232,6 +323,12 @@ class ApiService {
   }
 
   async uploadAudioFile(songId, audioFile) {
+    // Large files (e.g. FLAC) go in resumable chunks, a dropped connection does not restart the upload
+    if (audioFile.size > RESUMABLE_UPLOAD_THRESHOLD) {
+      await this.uploadAudioFileResumable(songId, audioFile);
+      return { songId, fileName: audioFile.name, fileSize: audioFile.size };
+    }
+
     const formData = new FormData();
     formData.append('audio', audioFile);
     formData.append('songId', songId);
@@ -257,12 +354,130 @@ class ApiService {
     return response.json();
   }
 
+  // Resumable upload (tus 1.0): the upload URL is kept in localStorage, so a failed or interrupted
+  // upload of the same file continues from the offset the server reports
+  async uploadAudioFileResumable(songId, audioFile, onProgress) {
+    const token = localStorage.getItem('token');
+    if (!token) {
+      throw new Error('Not authenticated');
+    }
+    const tusHeaders = { 'Authorization': `Bearer ${token}`, 'Tus-Resumable': '1.0.0' };
+    const storageKey = `tusUpload:${songId}:${audioFile.name}:${audioFile.size}:${audioFile.lastModified}`;
+    const encode = (value) => btoa(unescape(encodeURIComponent(value)));
+
+    let uploadUrl = localStorage.getItem(storageKey);
+    let offset = null;
+    if (uploadUrl) {
+      const response = await fetch(uploadUrl, { method: 'HEAD', headers: tusHeaders });
+      offset = response.ok ? parseInt(response.headers.get('Upload-Offset'), 10) : null;
+    }
+    if (offset === null) {
+      const metadata = [`songId ${encode(songId)}`, `filename ${encode(audioFile.name)}`];
+      if (window.crypto && window.crypto.subtle) {
+        const digest = await window.crypto.subtle.digest('SHA-256', await audioFile.arrayBuffer());
+        metadata.push(`checksum ${encode('sha256 ' + btoa(String.fromCharCode(...new Uint8Array(digest))))}`);
+      }
+      const response = await fetch(`${this.baseURL}/api/content/uploads`, {
+        method: 'POST',
+        headers: {
+          ...tusHeaders,
+          'Upload-Length': String(audioFile.size),
+          'Upload-Metadata': metadata.join(','),
+        },
+      });
+      if (response.status !== 201) {
+        throw new Error((await response.text()) || 'Upload failed');
+      }
+      uploadUrl = `${this.baseURL}${response.headers.get('Location')}`;
+      localStorage.setItem(storageKey, uploadUrl);
+      offset = 0;
+    }
+
+    let retries = 0;
+    for (;;) {
+      const chunk = audioFile.slice(offset, offset + RESUMABLE_CHUNK_SIZE);
+      let response;
+      try {
+        response = await fetch(uploadUrl, {
+          method: 'PATCH',
+          headers: {
+            ...tusHeaders,
+            'Content-Type': 'application/offset+octet-stream',
+            'Upload-Offset': String(offset),
+          },
+          body: chunk,
+        });
+      } catch (err) {
+        response = null;
+      }
+
+      if (response && response.status === 204) {
+        offset = parseInt(response.headers.get('Upload-Offset'), 10);
+        retries = 0;
+        if (onProgress) {
+          onProgress(offset / audioFile.size);
+        }
+        if (offset === audioFile.size) {
+          break;
+        }
+        continue;
+      }
+      // A checksum mismatch of the whole file or a missing upload cannot be resumed
+      if (response && (response.status === 460 || response.status === 404 || response.status === 413)) {
+        localStorage.removeItem(storageKey);
+        throw new Error((await response.text()) || 'Upload failed');
+      }
+      if (++retries > RESUMABLE_MAX_RETRIES) {
+        throw new Error((response && (await response.text())) || 'Upload failed');
+      }
+      // Ask the server how much arrived before retrying
+      await new Promise(resolve => setTimeout(resolve, 1000 * retries));
+      const head = await fetch(uploadUrl, { method: 'HEAD', headers: tusHeaders }).catch(() => null);
+      if (head && head.ok) {
+        offset = parseInt(head.headers.get('Upload-Offset'), 10);
+      }
+    }
+
+    localStorage.removeItem(storageKey);
+  }
+
   async deleteSong(id) {
     return this.request(`/api/content/songs/${id}`, {
       method: 'DELETE',
     });
   }
 
+  // Trash of deleted songs, albums and artists (collection: 'songs', 'albums' or 'artists')
+  async getTrash(collection) {
+    return this.request(`/api/content/trash/${collection}`);
+  }
+
+  async restoreFromTrash(collection, id) {
+    return this.request(`/api/content/trash/${collection}/${id}/restore`, {
+      method: 'POST',
+    });
+  }
+
+  async purgeFromTrash(collection, id, sharedSongs) {
+    const query = sharedSongs ? `?sharedSongs=${sharedSongs}` : '';
+    return this.request(`/api/content/trash/${collection}/${id}${query}`, {
+      method: 'DELETE',
+    });
+  }
+
+  // Revision history of a song, album or artist (collection: 'songs', 'albums' or 'artists'),
+  // newest first; before is the nextCursor of the previous page
+  async getRevisions(collection, id, before) {
+    const query = before ? `?before=${before}` : '';
+    return this.request(`/api/content/${collection}/${id}/revisions${query}`, { withNextCursor: true });
+  }
+
+  async rollbackRevision(collection, id, number) {
+    return this.request(`/api/content/${collection}/${id}/revisions/${number}/rollback`, {
+      method: 'POST',
+    });
+  }
+
   getStreamUrl(songId) {
     const token = localStorage.getItem('token');
     // Don't add timestamp here - it will be added by AudioPlayer when needed
@@ -272,11 +487,200 @@ class ApiService {
     return `${this.baseURL}/api/content/songs/${songId}/stream`;
   }
 
+  // HLS master playlist, available when song.hlsAvailable is true (MP3/AAC uploads)
+  getHlsUrl(songId) {
+    return `${this.baseURL}/api/content/songs/${songId}/hls/master.m3u8`;
+  }
+
+  async regenerateHls(songId) {
+    return this.request(`/api/content/songs/${songId}/hls`, {
+      method: 'POST',
+    });
+  }
+
+  // Move the song's audio, HLS output and cover to another storage backend (hdfs, local, s3)
+  async migrateSongStorage(songId, backend) {
+    return this.request(`/api/content/songs/${songId}/storage`, {
+      method: 'POST',
+      body: JSON.stringify({ backend }),
+    });
+  }
+
+  // Cover embedded in the uploaded audio file (song.audio.cover is set when there is one)
+  getSongCoverUrl(songId) {
+    return `${this.baseURL}/api/content/songs/${songId}/cover`;
+  }
+
+  // Album artwork and artist photos: imageUrls of an album or artist maps the sizes 64, 300 and 640 to URLs
+  getImageUrl(imageUrls, size = '300') {
+    if (!imageUrls || !imageUrls[size]) {
+      return null;
+    }
+    return `${this.baseURL}${imageUrls[size]}`;
+  }
+
+  // collection is 'albums' or 'artists'; JPEG, PNG, GIF or WebP up to 10 MB
+  async uploadImage(collection, id, imageFile) {
+    const formData = new FormData();
+    formData.append('image', imageFile);
+
+    const token = localStorage.getItem('token');
+    if (!token) {
+      throw new Error('Not authenticated');
+    }
+
+    const response = await fetch(`${this.baseURL}/api/content/${collection}/${id}/image`, {
+      method: 'POST',
+      headers: {
+        'Authorization': `Bearer ${token}`,
+      },
+      body: formData,
+    });
+
+    if (!response.ok) {
+      const message = await response.text().catch(() => '');
+      throw new Error(message.trim() || 'Image upload failed');
+    }
+
+    return response.json();
+  }
+
+  async deleteImage(collection, id) {
+    return this.request(`/api/content/${collection}/${id}/image`, {
+      method: 'DELETE',
+    });
+  }
+
+  // Lyrics: languages of a song's lyrics ([{ language, synced, lines, updatedAt }])
+  async getLyricsLanguages(songId) {
+    return this.request(`/api/content/songs/${songId}/lyrics`);
+  }
+
+  // Lyrics in one language as lines ({ text, startMs, endMs }, offsets only for synced lyrics)
+  async getLyrics(songId, language) {
+    const response = await fetch(`${this.baseURL}/api/content/songs/${songId}/lyrics/${language}`);
+    if (!response.ok) {
+      throw new Error((await response.text()).trim() || 'Failed to load lyrics');
+    }
+    const text = await response.text();
+    return text.split('\n').filter(line => line.trim() !== '').map(line => JSON.parse(line));
+  }
+
+  // text is LRC ("[mm:ss.xx]line") or plain text; format 'lrc' or 'plain', detected when omitted
+  async setLyrics(songId, language, text, format) {
+    return this.request(`/api/content/songs/${songId}/lyrics/${language}`, {
+      method: 'PUT',
+      body: JSON.stringify({ text, format }),
+    });
+  }
+
+  async deleteLyrics(songId, language) {
+    return this.request(`/api/content/songs/${songId}/lyrics/${language}`, {
+      method: 'DELETE',
+    });
+  }
+
   // Get most played songs (2.12)
   async getMostPlayedSongs(limit = 10) {
     return this.request(`/api/content/songs/most-played?limit=${limit}`);
   }
 
+  // Content Service - Playlists
+  async getMyPlaylists() {
+    return this.request('/api/content/playlists');
+  }
+
+  async getPublicPlaylists(ownerId) {
+    const query = ownerId ? `?ownerId=${encodeURIComponent(ownerId)}` : '';
+    return this.request(`/api/content/playlists/public${query}`);
+  }
+
+  async getPlaylist(id) {
+    return this.request(`/api/content/playlists/${id}`);
+  }
+
+  // playlistData: name, description, coverUrl, visibility (public/private), collaborative
+  async createPlaylist(playlistData) {
+    return this.request('/api/content/playlists', {
+      method: 'POST',
+      body: JSON.stringify(playlistData),
+    });
+  }
+
+  // Only the fields that are present are changed
+  async updatePlaylist(id, changes) {
+    return this.request(`/api/content/playlists/${id}`, {
+      method: 'PUT',
+      body: JSON.stringify(changes),
+    });
+  }
+
+  async deletePlaylist(id) {
+    return this.request(`/api/content/playlists/${id}`, {
+      method: 'DELETE',
+    });
+  }
+
+  // position is 1-based, without it the song is appended
+  async addSongToPlaylist(playlistId, songId, position) {
+    return this.request(`/api/content/playlists/${playlistId}/songs`, {
+      method: 'POST',
+      body: JSON.stringify(position ? { songId, position } : { songId }),
+    });
+  }
+
+  async movePlaylistSong(playlistId, entryId, position) {
+    return this.request(`/api/content/playlists/${playlistId}/songs/${entryId}`, {
+      method: 'PUT',
+      body: JSON.stringify({ position }),
+    });
+  }
+
+  async removePlaylistSong(playlistId, entryId) {
+    return this.request(`/api/content/playlists/${playlistId}/songs/${entryId}`, {
+      method: 'DELETE',
+    });
+  }
+
+  async addPlaylistEditor(playlistId, userId) {
+    return this.request(`/api/content/playlists/${playlistId}/editors`, {
+      method: 'POST',
+      body: JSON.stringify({ userId }),
+    });
+  }
+
+  async removePlaylistEditor(playlistId, userId) {
+    return this.request(`/api/content/playlists/${playlistId}/editors/${userId}`, {
+      method: 'DELETE',
+    });
+  }
+
+  // Content Service - Library (liked songs, albums and artists)
+  // type: song, album or artist; returns { liked, likeCount }
+  async likeItem(type, id) {
+    return this.request(`/api/content/library/${type}s/${id}`, {
+      method: 'PUT',
+    });
+  }
+
+  async unlikeItem(type, id) {
+    return this.request(`/api/content/library/${type}s/${id}`, {
+      method: 'DELETE',
+    });
+  }
+
+  // params: type (song, album, artist, empty for all), limit, cursor
+  // Returns { items, nextCursor }, most recently added first
+  async getLibrary(params = {}) {
+    return this.getPage('/api/content/library', params);
+  }
+
+  // Returns { id: true/false } for the given ids
+  async getLibraryContains(type, ids) {
+    const query = new URLSearchParams({ type, ids: ids.join(',') });
+    return this.request(`/api/content/library/contains?${query.toString()}`);
+  }
+
   // Analytics Service (1.15)
   // Direktno pozivamo analytics-service jer API Gateway trenutno ne prosleđuje podatke ispravno
   async getUserActivities(limit = 50, type = null, userId) {
@@ -0,0 +1,25 @@
+import api from '../services/api';
+
+// Latest request per type, so a slow response for an older search term is ignored
+const latestRequest = {};
+
+// Searches the catalog on the server (ranked, prefix and typo tolerant) and returns the matching
+// items from the already loaded list in relevance order.
+// Returns null if a newer search of the same type was started in the meantime.
+// Falls back to a simple client-side name match if the search endpoint is unavailable.
+export async function searchCatalog(items, term, type) {
+  const requestId = (latestRequest[type] || 0) + 1;
+  latestRequest[type] = requestId;
+
+  let matches;
+  try {
+    const data = await api.searchCatalog({ q: term, type, limit: 50 });
+    const byId = new Map(items.map((item) => [item.id, item]));
+    matches = (data.results || []).map((hit) => byId.get(hit.id)).filter(Boolean);
+  } catch (err) {
+    const lowered = term.toLowerCase();
+    matches = items.filter((item) => item.name.toLowerCase().includes(lowered));
+  }
+
+  return latestRequest[type] === requestId ? matches : null;
+}
@@ -0,0 +1,47 @@
+// Proof-of-work solver for the API Gateway challenges
+// The gateway issues a challenge and a difficulty (number of leading zero bits);
+// we search for a solution such that SHA-256(challenge + ":" + solution) has that many zero bits.
+
+const BATCH_SIZE = 2000;
+
+function leadingZeroBits(bytes) {
+  let bits = 0;
+  for (let i = 0; i < bytes.length; i++) {
+    const b = bytes[i];
+    if (b === 0) {
+      bits += 8;
+      continue;
+    }
+    bits += Math.clz32(b) - 24;
+    break;
+  }
+  return bits;
+}
+
+// Solves the challenge; hashes are computed in batches so the UI stays responsive
+export async function solveChallenge(challenge, difficulty) {
+  const encoder = new TextEncoder();
+  let counter = 0;
+
+  for (;;) {
+    const candidates = [];
+    for (let i = 0; i < BATCH_SIZE; i++) {
+      candidates.push((counter++).toString(16));
+    }
+
+    const hashes = await Promise.all(
+      candidates.map((solution) =>
+        crypto.subtle.digest('SHA-256', encoder.encode(`${challenge}:${solution}`))
+      )
+    );
+
+    for (let i = 0; i < hashes.length; i++) {
+      if (leadingZeroBits(new Uint8Array(hashes[i])) >= difficulty) {
+        return candidates[i];
+      }
+    }
+
+    // Yield to the event loop between batches
+    await new Promise((resolve) => setTimeout(resolve, 0));
+  }
+}
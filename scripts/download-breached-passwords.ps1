@@ -0,0 +1,36 @@
+# PowerShell skripta za preuzimanje kompletnog korpusa provaljenih lozinki (Have I Been Pwned, range API)
+# Svaki fajl data/breached-passwords/{PREFIX} sadrži linije "SUFFIX:COUNT" za 5 hex znakova SHA-1 heša.
+# users-service bez korpusa proverava samo ugrađenu listu najčešćih lozinki.
+# Kompletan korpus ima 16^5 fajlova (oko 40 GB) i preuzimanje traje satima; -Parallel zahteva PowerShell 7.
+#
+# Primer:
+#   .\scripts\download-breached-passwords.ps1
+#   $env:BREACHED_PASSWORDS_DIR = "/app/data/breached-passwords"   # pa docker-compose.override.yml sa volume-om
+
+param(
+    [string]$OutputDir = "data/breached-passwords",
+    [int]$ThrottleLimit = 32
+)
+
+New-Item -ItemType Directory -Force -Path $OutputDir | Out-Null
+$outputPath = (Resolve-Path $OutputDir).Path
+
+Write-Host "Preuzimanje korpusa u $outputPath ..." -ForegroundColor Cyan
+
+0..0xFFFFF | ForEach-Object -ThrottleLimit $ThrottleLimit -Parallel {
+    $prefix = $_.ToString("X5")
+    $file = Join-Path $using:outputPath $prefix
+    if (Test-Path $file) { return }
+    for ($attempt = 1; $attempt -le 5; $attempt++) {
+        try {
+            Invoke-WebRequest -Uri "https://api.pwnedpasswords.com/range/$prefix" -OutFile "$file.tmp" -UseBasicParsing
+            Move-Item -Force "$file.tmp" $file
+            return
+        } catch {
+            Start-Sleep -Seconds $attempt
+        }
+    }
+    Write-Host "ERROR: $prefix nije preuzet" -ForegroundColor Red
+}
+
+Write-Host "Gotovo. Ponovo pokrenite skriptu da se preuzmu fajlovi koji nedostaju." -ForegroundColor Green
@@ -1,5 +1,18 @@
 # PowerShell skripta za eksport podataka iz baza u JSON format
 # Ovi fajlovi se mogu commit-ovati u git i deliti sa timom
+# Katalog (izvođači, albumi, pesme) se izvozi preko API-ja content-service (admin token), ostale baze preko mongoexport
+#
+# Primer:
+#   .\scripts\export-data.ps1 -Token $adminToken
+#   .\scripts\export-data.ps1 -Token $adminToken -WithAudio   # arhiva sa audio fajlovima
+
+param(
+    # JWT administratora (catalog.*.write); podrazumevano iz promenljive MUSIC_ADMIN_TOKEN
+    [string]$Token = $env:MUSIC_ADMIN_TOKEN,
+    [string]$Gateway = "http://localhost:8081",
+    # Izvozi i upload-ovane audio fajlove u catalog.zip
+    [switch]$WithAudio
+)
 
 Write-Host "========================================" -ForegroundColor Cyan
 Write-Host "  EKSPORT PODATAKA IZ BAZA" -ForegroundColor Cyan
@@ -18,6 +31,12 @@ if ([string]::IsNullOrEmpty($contentContainer)) {
     exit 1
 }
 
+if ([string]::IsNullOrEmpty($Token)) {
+    Write-Host "ERROR: Potreban je admin token za eksport kataloga!" -ForegroundColor Red
+    Write-Host "Pokrenite: .\scripts\export-data.ps1 -Token <JWT> (ili postavite MUSIC_ADMIN_TOKEN)" -ForegroundColor Yellow
+    exit 1
+}
+
 # Kreiraj folder za eksportovane podatke
 $exportDir = "scripts\seed-data"
 if (-not (Test-Path $exportDir)) {
@@ -29,25 +48,21 @@ Write-Host ""
 Write-Host "Eksportujem podatke..." -ForegroundColor Yellow
 Write-Host ""
 
-# Eksport Content baze - Artists
-Write-Host "1. Eksport Artists..." -ForegroundColor Cyan
-docker exec $contentContainer mongoexport --db=music_streaming --collection=artists --jsonArray --pretty | Out-File -FilePath "$exportDir\artists.json" -Encoding UTF8
-if ($LASTEXITCODE -eq 0) {
-    Write-Host "   [OK] Artists eksportovani" -ForegroundColor Green
-}
-
-# Eksport Content baze - Albums
-Write-Host "2. Eksport Albums..." -ForegroundColor Cyan
-docker exec $contentContainer mongoexport --db=music_streaming --collection=albums --jsonArray --pretty | Out-File -FilePath "$exportDir\albums.json" -Encoding UTF8
-if ($LASTEXITCODE -eq 0) {
-    Write-Host "   [OK] Albums eksportovani" -ForegroundColor Green
+# Eksport kataloga preko API-ja - izvođači, albumi i pesme u jednom fajlu (sa -WithAudio i audio fajlovi)
+Write-Host "1-3. Eksport kataloga (Artists, Albums, Songs)..." -ForegroundColor Cyan
+$headers = @{ Authorization = "Bearer $Token" }
+if ($WithAudio) {
+    $catalogFile = "$exportDir\catalog.zip"
+    $exportUrl = "$Gateway/api/content/catalog/export?format=ndjson&audio=true"
+} else {
+    $catalogFile = "$exportDir\catalog.ndjson"
+    $exportUrl = "$Gateway/api/content/catalog/export?format=ndjson"
 }
-
-# Eksport Content baze - Songs
-Write-Host "3. Eksport Songs..." -ForegroundColor Cyan
-docker exec $contentContainer mongoexport --db=music_streaming --collection=songs --jsonArray --pretty | Out-File -FilePath "$exportDir\songs.json" -Encoding UTF8
-if ($LASTEXITCODE -eq 0) {
-    Write-Host "   [OK] Songs eksportovani" -ForegroundColor Green
+try {
+    Invoke-WebRequest -Uri $exportUrl -Headers $headers -OutFile $catalogFile -UseBasicParsing
+    Write-Host "   [OK] Katalog eksportovan u $catalogFile" -ForegroundColor Green
+} catch {
+    Write-Host "   [ERROR] Eksport kataloga nije uspeo: $($_.Exception.Message)" -ForegroundColor Red
 }
 
 # Eksport Ratings baze
@@ -77,9 +92,7 @@ Write-Host "  EKSPORT ZAVRSEN!" -ForegroundColor Green
 Write-Host "========================================" -ForegroundColor Green
 Write-Host ""
 Write-Host "Eksportovani fajlovi:" -ForegroundColor Yellow
-Write-Host "  - $exportDir\artists.json" -ForegroundColor White
-Write-Host "  - $exportDir\albums.json" -ForegroundColor White
-Write-Host "  - $exportDir\songs.json" -ForegroundColor White
+Write-Host "  - $catalogFile" -ForegroundColor White
 Write-Host "  - $exportDir\ratings.json" -ForegroundColor White
 Write-Host "  - $exportDir\subscriptions.json" -ForegroundColor White
 Write-Host "  - $exportDir\users.json" -ForegroundColor White
@@ -87,7 +100,7 @@ Write-Host ""
 Write-Host "Sledeci koraci:" -ForegroundColor Yellow
 Write-Host "  1. Proverite eksportovane fajlove" -ForegroundColor White
 Write-Host "  2. Commit-ujte ih u git:" -ForegroundColor White
-Write-Host "     git add scripts/seed-data/*.json" -ForegroundColor Cyan
+Write-Host "     git add scripts/seed-data/*.json scripts/seed-data/catalog.ndjson" -ForegroundColor Cyan
 Write-Host "     git commit -m 'Update seed data'" -ForegroundColor Cyan
 Write-Host "     git push" -ForegroundColor Cyan
 Write-Host ""
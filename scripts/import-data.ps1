@@ -1,5 +1,19 @@
 # PowerShell skripta za import podataka iz eksportovanih JSON fajlova
 # Koristi se nakon git pull da se učitaju najnoviji podaci
+# Katalog (catalog.ndjson ili catalog.zip sa audio fajlovima) se uvozi preko API-ja content-service: postojeće
+# stavke se ažuriraju, nove kreiraju, a pretplate i preporuke dobijaju događaje kao pri ručnom unosu
+#
+# Primer:
+#   .\scripts\import-data.ps1 -Token $adminToken -DryRun   # samo izveštaj šta bi se promenilo
+#   .\scripts\import-data.ps1 -Token $adminToken
+
+param(
+    # JWT administratora (catalog.*.write); podrazumevano iz promenljive MUSIC_ADMIN_TOKEN
+    [string]$Token = $env:MUSIC_ADMIN_TOKEN,
+    [string]$Gateway = "http://localhost:8081",
+    # Proverava katalog bez upisa
+    [switch]$DryRun
+)
 
 Write-Host "========================================" -ForegroundColor Cyan
 Write-Host "  IMPORT PODATAKA U BAZE" -ForegroundColor Cyan
@@ -34,37 +48,44 @@ Write-Host ""
 Write-Host "Importujem podatke..." -ForegroundColor Yellow
 Write-Host ""
 
-# Import Artists
-if (Test-Path "$exportDir\artists.json") {
-    Write-Host "1. Import Artists..." -ForegroundColor Cyan
-    Get-Content "$exportDir\artists.json" | docker exec -i $contentContainer mongoimport --db=music_streaming --collection=artists --jsonArray --drop
-    if ($LASTEXITCODE -eq 0) {
-        Write-Host "   ✓ Artists importovani" -ForegroundColor Green
-    }
-} else {
-    Write-Host "   ⚠ artists.json ne postoji, preskačem..." -ForegroundColor Yellow
+# Import kataloga preko API-ja (arhiva sa audio fajlovima ima prednost)
+$catalogFile = $null
+$contentType = "application/x-ndjson"
+if (Test-Path "$exportDir\catalog.zip") {
+    $catalogFile = "$exportDir\catalog.zip"
+    $contentType = "application/zip"
+} elseif (Test-Path "$exportDir\catalog.ndjson") {
+    $catalogFile = "$exportDir\catalog.ndjson"
 }
 
-# Import Albums
-if (Test-Path "$exportDir\albums.json") {
-    Write-Host "2. Import Albums..." -ForegroundColor Cyan
-    Get-Content "$exportDir\albums.json" | docker exec -i $contentContainer mongoimport --db=music_streaming --collection=albums --jsonArray --drop
-    if ($LASTEXITCODE -eq 0) {
-        Write-Host "   ✓ Albums importovani" -ForegroundColor Green
-    }
+if ($null -eq $catalogFile) {
+    Write-Host "   [WARN] catalog.ndjson ne postoji, preskačem katalog..." -ForegroundColor Yellow
+} elseif ([string]::IsNullOrEmpty($Token)) {
+    Write-Host "   [WARN] Nije zadat admin token (-Token ili MUSIC_ADMIN_TOKEN), preskačem katalog..." -ForegroundColor Yellow
 } else {
-    Write-Host "   ⚠ albums.json ne postoji, preskačem..." -ForegroundColor Yellow
-}
-
-# Import Songs
-if (Test-Path "$exportDir\songs.json") {
-    Write-Host "3. Import Songs..." -ForegroundColor Cyan
-    Get-Content "$exportDir\songs.json" | docker exec -i $contentContainer mongoimport --db=music_streaming --collection=songs --jsonArray --drop
-    if ($LASTEXITCODE -eq 0) {
-        Write-Host "   ✓ Songs importovani" -ForegroundColor Green
+    Write-Host "1-3. Import kataloga (Artists, Albums, Songs) iz $catalogFile..." -ForegroundColor Cyan
+    $importUrl = "$Gateway/api/content/catalog/import?format=ndjson"
+    if ($DryRun) {
+        $importUrl += "&dryRun=true"
+    }
+    try {
+        $report = Invoke-RestMethod -Method Post -Uri $importUrl -Headers @{ Authorization = "Bearer $Token" } -ContentType $contentType -InFile $catalogFile
+        foreach ($type in @("artists", "albums", "songs")) {
+            $c = $report.$type
+            Write-Host ("   {0}: kreirano {1}, izmenjeno {2}, bez promene {3}, neuspešno {4}" -f $type, $c.created, $c.updated, $c.unchanged, $c.failed) -ForegroundColor White
+        }
+        Write-Host "   Audio fajlova: $($report.audioFiles)" -ForegroundColor White
+        foreach ($err in $report.errors) {
+            Write-Host ("   [ERROR] {0}:{1} {2} {3} - {4}" -f $err.file, $err.line, $err.type, $err.id, $err.error) -ForegroundColor Red
+        }
+        if ($DryRun) {
+            Write-Host "   [OK] Provera završena, ništa nije upisano (-DryRun)" -ForegroundColor Green
+        } else {
+            Write-Host "   [OK] Katalog importovan" -ForegroundColor Green
+        }
+    } catch {
+        Write-Host "   [ERROR] Import kataloga nije uspeo: $($_.Exception.Message)" -ForegroundColor Red
     }
-} else {
-    Write-Host "   ⚠ songs.json ne postoji, preskačem..." -ForegroundColor Yellow
 }
 
 # Import Ratings
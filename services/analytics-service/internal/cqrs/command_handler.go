@@ -33,6 +33,8 @@ func (ch *CommandHandler) HandleCommand(ctx context.Context, cmd Command) *Comma
 		event = ch.handleSubscribeToArtistCommand(ctx, c)
 	case *UnsubscribeFromArtistCommand:
 		event = ch.handleUnsubscribeFromArtistCommand(ctx, c)
+	case *LikeCommand:
+		event = ch.handleLikeCommand(ctx, c)
 	default:
 		return &CommandResult{
 			Success: false,
@@ -125,3 +127,59 @@ func (ch *CommandHandler) handleUnsubscribeFromArtistCommand(ctx context.Context
 		Payload:   payload,
 	}
 }
+
+// likeEventTypes maps the like target to its liked and unliked event types
+var likeEventTypes = map[string][2]model.EventType{
+	store.LikeTargetSong:   {model.EventTypeSongLiked, model.EventTypeSongUnliked},
+	store.LikeTargetAlbum:  {model.EventTypeAlbumLiked, model.EventTypeAlbumUnliked},
+	store.LikeTargetArtist: {model.EventTypeArtistLiked, model.EventTypeArtistUnliked},
+}
+
+func (ch *CommandHandler) handleLikeCommand(ctx context.Context, cmd *LikeCommand) *model.UserEvent {
+	eventTypes, ok := likeEventTypes[cmd.Target]
+	if !ok || cmd.ItemID == "" {
+		return nil
+	}
+	eventType := eventTypes[0]
+	if cmd.Unlike {
+		eventType = eventTypes[1]
+	}
+
+	payload := make(map[string]interface{})
+	switch cmd.Target {
+	case store.LikeTargetSong:
+		payload["songId"] = cmd.ItemID
+		if cmd.Name != "" {
+			payload["songName"] = cmd.Name
+		}
+		if cmd.AlbumID != "" {
+			payload["albumId"] = cmd.AlbumID
+		}
+	case store.LikeTargetAlbum:
+		payload["albumId"] = cmd.ItemID
+		if cmd.Name != "" {
+			payload["albumName"] = cmd.Name
+		}
+	case store.LikeTargetArtist:
+		payload["artistId"] = cmd.ItemID
+		if cmd.Name != "" {
+			payload["artistName"] = cmd.Name
+		}
+	}
+	if cmd.Genre != "" {
+		payload["genre"] = cmd.Genre
+	}
+	if cmd.Target != store.LikeTargetArtist && cmd.ArtistID != "" {
+		payload["artistId"] = cmd.ArtistID
+		if cmd.ArtistName != "" {
+			payload["artistName"] = cmd.ArtistName
+		}
+	}
+
+	return &model.UserEvent{
+		EventType: eventType,
+		StreamID:  cmd.UserID,
+		Timestamp: cmd.GetTimestamp(),
+		Payload:   payload,
+	}
+}
@@ -89,6 +89,31 @@ func (c *UnsubscribeFromArtistCommand) GetTimestamp() time.Time {
 	return c.Timestamp
 }
 
+// LikeCommand represents a command to like or unlike a song, album or artist (library)
+type LikeCommand struct {
+	UserID     string
+	Target     string // store.LikeTargetSong, LikeTargetAlbum or LikeTargetArtist
+	ItemID     string
+	Name       string
+	Genre      string
+	ArtistID   string
+	ArtistName string
+	AlbumID    string
+	Unlike     bool
+	Timestamp  time.Time
+}
+
+func (c *LikeCommand) GetUserID() string {
+	return c.UserID
+}
+
+func (c *LikeCommand) GetTimestamp() time.Time {
+	if c.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return c.Timestamp
+}
+
 // CommandResult represents the result of executing a command
 type CommandResult struct {
 	Event   *model.UserEvent
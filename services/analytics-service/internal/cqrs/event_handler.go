@@ -11,6 +11,7 @@ import (
 	"analytics-service/config"
 	"analytics-service/internal/model"
 	"analytics-service/internal/store"
+	"shared/pagination"
 )
 
 // EventHandler handles events and updates read model (CQRS - 2.15)
@@ -52,6 +53,10 @@ func (eh *EventHandler) HandleEvent(ctx context.Context, event *model.UserEvent)
 		handleErr = eh.handleArtistSubscribedEvent(ctx, event)
 	case model.EventTypeArtistUnsubscribed:
 		handleErr = eh.handleArtistUnsubscribedEvent(ctx, event)
+	case model.EventTypeSongLiked, model.EventTypeSongUnliked,
+		model.EventTypeAlbumLiked, model.EventTypeAlbumUnliked,
+		model.EventTypeArtistLiked, model.EventTypeArtistUnliked:
+		handleErr = eh.handleLikeEvent(ctx, event)
 	default:
 		log.Printf("Unknown event type: %s", event.EventType)
 		return nil
@@ -173,6 +178,30 @@ func (eh *EventHandler) handleArtistUnsubscribedEvent(ctx context.Context, event
 	return eh.projectionStore.UnsubscribeFromArtist(ctx, event.StreamID, artistID)
 }
 
+func (eh *EventHandler) handleLikeEvent(ctx context.Context, event *model.UserEvent) error {
+	var target, idKey string
+	liked := false
+	switch event.EventType {
+	case model.EventTypeSongLiked, model.EventTypeSongUnliked:
+		target, idKey, liked = store.LikeTargetSong, "songId", event.EventType == model.EventTypeSongLiked
+	case model.EventTypeAlbumLiked, model.EventTypeAlbumUnliked:
+		target, idKey, liked = store.LikeTargetAlbum, "albumId", event.EventType == model.EventTypeAlbumLiked
+	default:
+		target, idKey, liked = store.LikeTargetArtist, "artistId", event.EventType == model.EventTypeArtistLiked
+	}
+
+	itemID, _ := event.Payload[idKey].(string)
+	if itemID == "" {
+		return nil
+	}
+
+	// Keep the artist name for the analytics of liked artists
+	artistID, _ := event.Payload["artistId"].(string)
+	artistName, _ := event.Payload["artistName"].(string)
+
+	return eh.projectionStore.SetLiked(ctx, event.StreamID, target, itemID, liked, artistID, artistName)
+}
+
 // Helper methods to fetch data from content service
 
 func (eh *EventHandler) fetchArtistNames(ctx context.Context, artistIDs []string) map[string]string {
@@ -188,23 +217,17 @@ func (eh *EventHandler) fetchArtistNames(ctx context.Context, artistIDs []string
 		Transport: tr,
 	}
 	
-	artistsURL := eh.config.ContentServiceURL + "/artists"
-	artistsReq, err := http.NewRequestWithContext(ctx, "GET", artistsURL, nil)
+	// The artist list is paginated, FetchAll follows the cursors
+	artistsBody, err := pagination.FetchAll(ctx, client, eh.config.ContentServiceURL+"/artists")
 	if err != nil {
 		return make(map[string]string)
 	}
 	
-	artistsResp, err := client.Do(artistsReq)
-	if err != nil || artistsResp == nil || artistsResp.StatusCode != http.StatusOK {
-		return make(map[string]string)
-	}
-	defer artistsResp.Body.Close()
-	
 	var allArtists []struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	}
-	if json.NewDecoder(artistsResp.Body).Decode(&allArtists) != nil {
+	if json.Unmarshal(artistsBody, &allArtists) != nil {
 		return make(map[string]string)
 	}
 	
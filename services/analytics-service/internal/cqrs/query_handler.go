@@ -14,6 +14,7 @@ import (
 	"analytics-service/config"
 	"analytics-service/internal/model"
 	"analytics-service/internal/store"
+	"shared/pagination"
 )
 
 // QueryHandler handles queries and reads from read model (CQRS Query Side - 2.15)
@@ -69,6 +70,11 @@ func (qh *QueryHandler) handleGetUserAnalyticsQuery(ctx context.Context, query *
 	// Get subscribed artists count
 	analytics.SubscribedArtistsCount = len(projection.SubscribedArtists)
 	
+	// Library counts
+	analytics.LikedSongsCount = len(projection.LikedSongs)
+	analytics.LikedAlbumsCount = len(projection.LikedAlbums)
+	analytics.LikedArtistsCount = len(projection.LikedArtists)
+	
 	// If we need additional data (artist names), fetch from content service
 	if len(analytics.Top5Artists) > 0 {
 		qh.enrichArtistNames(ctx, analytics, projection)
@@ -119,35 +125,31 @@ func (qh *QueryHandler) getTop5Artists(projection *store.AnalyticsProjection) []
 			Transport: tr,
 		}
 		
-		artistsURL := qh.config.ContentServiceURL + "/artists"
-		artistsReq, err := http.NewRequestWithContext(ctx, "GET", artistsURL, nil)
+		// The artist list is paginated, FetchAll follows the cursors
+		artistsBody, err := pagination.FetchAll(ctx, client, qh.config.ContentServiceURL+"/artists")
 		if err == nil {
-			artistsResp, err := client.Do(artistsReq)
-			if err == nil && artistsResp != nil && artistsResp.StatusCode == http.StatusOK {
-				var allArtists []struct {
-					ID   string `json:"id"`
-					Name string `json:"name"`
+			var allArtists []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(artistsBody, &allArtists) == nil {
+				// Build artist name map
+				artistNameMap := make(map[string]string)
+				for _, artist := range allArtists {
+					artistNameMap[artist.ID] = artist.Name
 				}
-				if json.NewDecoder(artistsResp.Body).Decode(&allArtists) == nil {
-					// Build artist name map
-					artistNameMap := make(map[string]string)
-					for _, artist := range allArtists {
-						artistNameMap[artist.ID] = artist.Name
-					}
-					
-					// Update artist names in the list
-					for i := range artists {
-						if artists[i].Name == "" || strings.HasPrefix(artists[i].Name, "Umetnik ") {
-							if name, ok := artistNameMap[artists[i].ID]; ok && name != "" {
-								artists[i].Name = name
-							} else if artists[i].Name == "" {
-								// Only use fallback if we couldn't fetch the name
-								artists[i].Name = "Umetnik " + artists[i].ID[:min(8, len(artists[i].ID))] + "..."
-							}
+				
+				// Update artist names in the list
+				for i := range artists {
+					if artists[i].Name == "" || strings.HasPrefix(artists[i].Name, "Umetnik ") {
+						if name, ok := artistNameMap[artists[i].ID]; ok && name != "" {
+							artists[i].Name = name
+						} else if artists[i].Name == "" {
+							// Only use fallback if we couldn't fetch the name
+							artists[i].Name = "Umetnik " + artists[i].ID[:min(8, len(artists[i].ID))] + "..."
 						}
 					}
 				}
-				artistsResp.Body.Close()
 			}
 		}
 	}
@@ -185,23 +187,17 @@ func (qh *QueryHandler) enrichArtistNames(ctx context.Context, analytics *model.
 		Transport: tr,
 	}
 	
-	artistsURL := qh.config.ContentServiceURL + "/artists"
-	artistsReq, err := http.NewRequestWithContext(ctx, "GET", artistsURL, nil)
+	// The artist list is paginated, FetchAll follows the cursors
+	artistsBody, err := pagination.FetchAll(ctx, client, qh.config.ContentServiceURL+"/artists")
 	if err != nil {
 		return
 	}
 	
-	artistsResp, err := client.Do(artistsReq)
-	if err != nil || artistsResp == nil || artistsResp.StatusCode != http.StatusOK {
-		return
-	}
-	defer artistsResp.Body.Close()
-	
 	var allArtists []struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	}
-	if json.NewDecoder(artistsResp.Body).Decode(&allArtists) != nil {
+	if json.Unmarshal(artistsBody, &allArtists) != nil {
 		return
 	}
 	
@@ -14,6 +14,7 @@ import (
 	"analytics-service/internal/cqrs"
 	"analytics-service/internal/model"
 	"analytics-service/internal/store"
+	"shared/pagination"
 )
 
 type ActivityHandler struct {
@@ -211,6 +212,41 @@ func (h *ActivityHandler) activityToCommand(activity *model.UserActivity) cqrs.C
 			ArtistID:  activity.ArtistID,
 			Timestamp: activity.Timestamp,
 		}
+	case model.ActivityTypeSongLiked, model.ActivityTypeSongUnliked:
+		return &cqrs.LikeCommand{
+			UserID:     activity.UserID,
+			Target:     store.LikeTargetSong,
+			ItemID:     activity.SongID,
+			Name:       activity.SongName,
+			Genre:      activity.Genre,
+			ArtistID:   activity.ArtistID,
+			ArtistName: activity.ArtistName,
+			AlbumID:    activity.AlbumID,
+			Unlike:     activity.Type == model.ActivityTypeSongUnliked,
+			Timestamp:  activity.Timestamp,
+		}
+	case model.ActivityTypeAlbumLiked, model.ActivityTypeAlbumUnliked:
+		return &cqrs.LikeCommand{
+			UserID:     activity.UserID,
+			Target:     store.LikeTargetAlbum,
+			ItemID:     activity.AlbumID,
+			Name:       activity.AlbumName,
+			Genre:      activity.Genre,
+			ArtistID:   activity.ArtistID,
+			ArtistName: activity.ArtistName,
+			Unlike:     activity.Type == model.ActivityTypeAlbumUnliked,
+			Timestamp:  activity.Timestamp,
+		}
+	case model.ActivityTypeArtistLiked, model.ActivityTypeArtistUnliked:
+		return &cqrs.LikeCommand{
+			UserID:    activity.UserID,
+			Target:    store.LikeTargetArtist,
+			ItemID:    activity.ArtistID,
+			Name:      activity.ArtistName,
+			Genre:     activity.Genre,
+			Unlike:    activity.Type == model.ActivityTypeArtistUnliked,
+			Timestamp: activity.Timestamp,
+		}
 	default:
 		return nil
 	}
@@ -238,6 +274,12 @@ func (h *ActivityHandler) activityToEvent(activity *model.UserActivity) *model.U
 	if activity.ArtistName != "" {
 		payload["artistName"] = activity.ArtistName
 	}
+	if activity.AlbumID != "" {
+		payload["albumId"] = activity.AlbumID
+	}
+	if activity.AlbumName != "" {
+		payload["albumName"] = activity.AlbumName
+	}
 	
 	return &model.UserEvent{
 		EventType: model.EventType(activity.Type),
@@ -466,32 +508,28 @@ func (h *ActivityHandler) GetUserAnalyticsLegacy(w http.ResponseWriter, r *http.
 	}
 
 	// Load all songs from content service at once
+	// (the song list is paginated, FetchAll follows the cursors)
 	if len(songIDs) > 0 {
-		songsURL := h.Config.ContentServiceURL + "/songs"
-		songsReq, err := http.NewRequestWithContext(ctx, "GET", songsURL, nil)
+		songsBody, err := pagination.FetchAll(ctx, client, h.Config.ContentServiceURL+"/songs")
 		if err == nil {
-			songsResp, err := client.Do(songsReq)
-			if err == nil && songsResp != nil && songsResp.StatusCode == http.StatusOK {
-				var allSongs []struct {
-					ID        string   `json:"id"`
-					Genre     string   `json:"genre"`
-					ArtistIDs []string `json:"artistIds"`
-				}
-				if json.NewDecoder(songsResp.Body).Decode(&allSongs) == nil {
-					// Build song map for quick lookup
-					for _, song := range allSongs {
-						if songIDs[song.ID] {
-							songMap[song.ID] = struct {
-								Genre     string
-								ArtistIDs []string
-							}{
-								Genre:     song.Genre,
-								ArtistIDs: song.ArtistIDs,
-							}
+			var allSongs []struct {
+				ID        string   `json:"id"`
+				Genre     string   `json:"genre"`
+				ArtistIDs []string `json:"artistIds"`
+			}
+			if json.Unmarshal(songsBody, &allSongs) == nil {
+				// Build song map for quick lookup
+				for _, song := range allSongs {
+					if songIDs[song.ID] {
+						songMap[song.ID] = struct {
+							Genre     string
+							ArtistIDs []string
+						}{
+							Genre:     song.Genre,
+							ArtistIDs: song.ArtistIDs,
 						}
 					}
 				}
-				songsResp.Body.Close()
 			}
 		}
 	}
@@ -545,25 +583,20 @@ func (h *ActivityHandler) GetUserAnalyticsLegacy(w http.ResponseWriter, r *http.
 	}
 	artists := make([]artistData, 0, len(artistCount))
 	
-	// Fetch all artists from content service to get names
-	artistsURL := h.Config.ContentServiceURL + "/artists"
-	artistsReq, err := http.NewRequestWithContext(ctx, "GET", artistsURL, nil)
+	// Fetch all artists from content service to get names (all pages)
+	artistsBody, err := pagination.FetchAll(ctx, client, h.Config.ContentServiceURL+"/artists")
 	if err == nil {
-		artistsResp, err := client.Do(artistsReq)
-		if err == nil && artistsResp != nil && artistsResp.StatusCode == http.StatusOK {
-			var allArtists []struct {
-				ID   string `json:"id"`
-				Name string `json:"name"`
-			}
-			if json.NewDecoder(artistsResp.Body).Decode(&allArtists) == nil {
-				// Build artist name map
-				for _, artist := range allArtists {
-					if artistNames[artist.ID] == "" {
-						artistNames[artist.ID] = artist.Name
-					}
+		var allArtists []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(artistsBody, &allArtists) == nil {
+			// Build artist name map
+			for _, artist := range allArtists {
+				if artistNames[artist.ID] == "" {
+					artistNames[artist.ID] = artist.Name
 				}
 			}
-			artistsResp.Body.Close()
 		}
 	}
 	
@@ -17,6 +17,13 @@ const (
 	ActivityTypeGenreUnsubscribed ActivityType = "GENRE_UNSUBSCRIBED"
 	ActivityTypeArtistSubscribed ActivityType = "ARTIST_SUBSCRIBED"
 	ActivityTypeArtistUnsubscribed ActivityType = "ARTIST_UNSUBSCRIBED"
+	// Library (likes)
+	ActivityTypeSongLiked     ActivityType = "SONG_LIKED"
+	ActivityTypeSongUnliked   ActivityType = "SONG_UNLIKED"
+	ActivityTypeAlbumLiked    ActivityType = "ALBUM_LIKED"
+	ActivityTypeAlbumUnliked  ActivityType = "ALBUM_UNLIKED"
+	ActivityTypeArtistLiked   ActivityType = "ARTIST_LIKED"
+	ActivityTypeArtistUnliked ActivityType = "ARTIST_UNLIKED"
 )
 
 // UserActivity represents a user activity record
@@ -33,6 +40,8 @@ type UserActivity struct {
 	Genre     string `json:"genre,omitempty" bson:"genre,omitempty"`
 	ArtistID  string `json:"artistId,omitempty" bson:"artistId,omitempty"`
 	ArtistName string `json:"artistName,omitempty" bson:"artistName,omitempty"`
+	AlbumID   string `json:"albumId,omitempty" bson:"albumId,omitempty"`
+	AlbumName string `json:"albumName,omitempty" bson:"albumName,omitempty"`
 }
 
 // MarshalJSON customizes JSON marshaling to convert ObjectID to string
@@ -8,6 +8,9 @@ type UserAnalytics struct {
 	SongsPlayedByGenre        map[string]int     `json:"songsPlayedByGenre"`
 	Top5Artists               []ArtistPlayCount  `json:"top5Artists"`
 	SubscribedArtistsCount    int                `json:"subscribedArtistsCount"`
+	LikedSongsCount           int                `json:"likedSongsCount"`
+	LikedAlbumsCount          int                `json:"likedAlbumsCount"`
+	LikedArtistsCount         int                `json:"likedArtistsCount"`
 }
 
 // ArtistPlayCount represents an artist with play count
@@ -17,6 +17,12 @@ const (
 	EventTypeGenreUnsubscribed EventType = "GENRE_UNSUBSCRIBED"
 	EventTypeArtistSubscribed  EventType = "ARTIST_SUBSCRIBED"
 	EventTypeArtistUnsubscribed EventType = "ARTIST_UNSUBSCRIBED"
+	EventTypeSongLiked          EventType = "SONG_LIKED"
+	EventTypeSongUnliked        EventType = "SONG_UNLIKED"
+	EventTypeAlbumLiked         EventType = "ALBUM_LIKED"
+	EventTypeAlbumUnliked       EventType = "ALBUM_UNLIKED"
+	EventTypeArtistLiked        EventType = "ARTIST_LIKED"
+	EventTypeArtistUnliked      EventType = "ARTIST_UNLIKED"
 )
 
 // UserEvent represents an immutable event in the event store (2.14 Event Sourcing)
@@ -64,6 +70,12 @@ func (e *UserEvent) ToUserActivity() *UserActivity {
 	if artistName, ok := e.Payload["artistName"].(string); ok {
 		activity.ArtistName = artistName
 	}
+	if albumID, ok := e.Payload["albumId"].(string); ok {
+		activity.AlbumID = albumID
+	}
+	if albumName, ok := e.Payload["albumName"].(string); ok {
+		activity.AlbumName = albumName
+	}
 	
 	return activity
 }
@@ -87,6 +99,9 @@ type UserActivityState struct {
 	TotalRatingsGiven   int                    `json:"totalRatingsGiven"`
 	SubscribedGenres    []string               `json:"subscribedGenres"`
 	SubscribedArtists   []string               `json:"subscribedArtists"`
+	LikedSongs          []string               `json:"likedSongs"`
+	LikedAlbums         []string               `json:"likedAlbums"`
+	LikedArtists        []string               `json:"likedArtists"`
 	LastActivityTime    *time.Time             `json:"lastActivityTime,omitempty"`
 	ActivityBreakdown   map[string]int         `json:"activityBreakdown"` // Count by activity type
 	RecentActivities    []*UserActivity         `json:"recentActivities,omitempty"`
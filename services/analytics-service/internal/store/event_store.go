@@ -179,6 +179,9 @@ func (es *EventStore) ReplayEvents(ctx context.Context, streamID string) (*model
 		UserID:            streamID,
 		SubscribedGenres:  make([]string, 0),
 		SubscribedArtists: make([]string, 0),
+		LikedSongs:        make([]string, 0),
+		LikedAlbums:       make([]string, 0),
+		LikedArtists:      make([]string, 0),
 		ActivityBreakdown: make(map[string]int),
 		RecentActivities:  make([]*model.UserActivity, 0),
 	}
@@ -272,6 +275,24 @@ func (es *EventStore) applyEvent(state *model.UserActivityState, event *model.Us
 		}
 		activity := event.ToUserActivity()
 		state.RecentActivities = append(state.RecentActivities, activity)
+
+	case model.EventTypeSongLiked, model.EventTypeSongUnliked:
+		if songID, ok := event.Payload["songId"].(string); ok {
+			state.LikedSongs = setMember(state.LikedSongs, songID, event.EventType == model.EventTypeSongLiked)
+		}
+		state.RecentActivities = append(state.RecentActivities, event.ToUserActivity())
+		
+	case model.EventTypeAlbumLiked, model.EventTypeAlbumUnliked:
+		if albumID, ok := event.Payload["albumId"].(string); ok {
+			state.LikedAlbums = setMember(state.LikedAlbums, albumID, event.EventType == model.EventTypeAlbumLiked)
+		}
+		state.RecentActivities = append(state.RecentActivities, event.ToUserActivity())
+		
+	case model.EventTypeArtistLiked, model.EventTypeArtistUnliked:
+		if artistID, ok := event.Payload["artistId"].(string); ok {
+			state.LikedArtists = setMember(state.LikedArtists, artistID, event.EventType == model.EventTypeArtistLiked)
+		}
+		state.RecentActivities = append(state.RecentActivities, event.ToUserActivity())
 	}
 	
 	// Keep only last 50 recent activities
@@ -279,3 +300,19 @@ func (es *EventStore) applyEvent(state *model.UserActivityState, event *model.Us
 		state.RecentActivities = state.RecentActivities[len(state.RecentActivities)-50:]
 	}
 }
+
+// setMember adds the value to or removes it from the list, keeping each value once
+func setMember(list []string, value string, present bool) []string {
+	for i, v := range list {
+		if v == value {
+			if present {
+				return list
+			}
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	if present {
+		list = append(list, value)
+	}
+	return list
+}
@@ -51,6 +51,9 @@ type AnalyticsProjection struct {
 	ArtistPlayCounts        map[string]int     `bson:"artistPlayCounts"` // artistID -> count
 	ArtistNames              map[string]string  `bson:"artistNames"`       // artistID -> name
 	SubscribedArtists        map[string]bool    `bson:"subscribedArtists"` // artistID -> true
+	LikedSongs               map[string]bool    `bson:"likedSongs"`        // songID -> true
+	LikedAlbums              map[string]bool    `bson:"likedAlbums"`       // albumID -> true
+	LikedArtists             map[string]bool    `bson:"likedArtists"`      // artistID -> true
 	LastUpdated              time.Time          `bson:"lastUpdated"`
 	LastProcessedEventVersion int64            `bson:"lastProcessedEventVersion"` // Last event version processed
 }
@@ -67,6 +70,9 @@ func (ps *ProjectionStore) GetProjection(ctx context.Context, userID string) (*A
 			ArtistPlayCounts:       make(map[string]int),
 			ArtistNames:           make(map[string]string),
 			SubscribedArtists:     make(map[string]bool),
+			LikedSongs:            make(map[string]bool),
+			LikedAlbums:           make(map[string]bool),
+			LikedArtists:          make(map[string]bool),
 			LastUpdated:           time.Now(),
 		}, nil
 	}
@@ -87,6 +93,15 @@ func (ps *ProjectionStore) GetProjection(ctx context.Context, userID string) (*A
 	if projection.SubscribedArtists == nil {
 		projection.SubscribedArtists = make(map[string]bool)
 	}
+	if projection.LikedSongs == nil {
+		projection.LikedSongs = make(map[string]bool)
+	}
+	if projection.LikedAlbums == nil {
+		projection.LikedAlbums = make(map[string]bool)
+	}
+	if projection.LikedArtists == nil {
+		projection.LikedArtists = make(map[string]bool)
+	}
 	
 	return &projection, nil
 }
@@ -112,6 +127,15 @@ func (ps *ProjectionStore) UpsertProjection(ctx context.Context, projection *Ana
 	if projection.SubscribedArtists == nil {
 		projection.SubscribedArtists = make(map[string]bool)
 	}
+	if projection.LikedSongs == nil {
+		projection.LikedSongs = make(map[string]bool)
+	}
+	if projection.LikedAlbums == nil {
+		projection.LikedAlbums = make(map[string]bool)
+	}
+	if projection.LikedArtists == nil {
+		projection.LikedArtists = make(map[string]bool)
+	}
 	
 	filter := bson.M{"userId": projection.UserID}
 	update := bson.M{
@@ -123,6 +147,9 @@ func (ps *ProjectionStore) UpsertProjection(ctx context.Context, projection *Ana
 			"artistPlayCounts":           projection.ArtistPlayCounts,
 			"artistNames":                projection.ArtistNames,
 			"subscribedArtists":          projection.SubscribedArtists,
+			"likedSongs":                 projection.LikedSongs,
+			"likedAlbums":                projection.LikedAlbums,
+			"likedArtists":               projection.LikedArtists,
 			"lastUpdated":                projection.LastUpdated,
 			"lastProcessedEventVersion":  projection.LastProcessedEventVersion,
 		},
@@ -247,6 +274,40 @@ func (ps *ProjectionStore) UnsubscribeFromArtist(ctx context.Context, userID str
 	return ps.UpsertProjection(ctx, projection)
 }
 
+// Like targets of SetLiked
+const (
+	LikeTargetSong   = "song"
+	LikeTargetAlbum  = "album"
+	LikeTargetArtist = "artist"
+)
+
+// SetLiked adds the item to or removes it from the user's liked songs, albums or artists
+func (ps *ProjectionStore) SetLiked(ctx context.Context, userID string, target string, itemID string, liked bool, artistID string, artistName string) error {
+	projection, err := ps.GetProjection(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	likes := projection.LikedSongs
+	switch target {
+	case LikeTargetAlbum:
+		likes = projection.LikedAlbums
+	case LikeTargetArtist:
+		likes = projection.LikedArtists
+	}
+	if liked {
+		likes[itemID] = true
+	} else {
+		delete(likes, itemID)
+	}
+
+	if artistID != "" && artistName != "" {
+		projection.ArtistNames[artistID] = artistName
+	}
+
+	return ps.UpsertProjection(ctx, projection)
+}
+
 // UpdateLastProcessedEventVersion updates the last processed event version for a user
 func (ps *ProjectionStore) UpdateLastProcessedEventVersion(ctx context.Context, userID string, version int64) error {
 	filter := bson.M{"userId": userID}
@@ -7,15 +7,20 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"api-gateway/config"
 	"api-gateway/internal/logger"
 	"api-gateway/internal/middleware"
+	"shared/authz"
+	"shared/pagination"
 	"shared/tracing"
 
 	"go.opentelemetry.io/otel/propagation"
@@ -29,7 +34,8 @@ func enableCORS(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Access-Control-Allow-Origin", origin)
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-PoW-Challenge, X-PoW-Solution")
+	w.Header().Set("Access-Control-Expose-Headers", pagination.HeaderNextCursor)
 	// Ne postavljaj Access-Control-Allow-Credentials ako je origin "*" jer browser to ne dozvoljava
 	if origin != "*" {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
@@ -41,6 +47,9 @@ func proxyRequest(w http.ResponseWriter, r *http.Request, targetURL string, appL
 	// Dodaj CORS headers
 	enableCORS(w, r)
 
+	// Svi logovi tokom impersonacije su označeni (impersonator + ciljni korisnik)
+	appLogger = middleware.RequestLogger(r, appLogger)
+
 	// Handle preflight OPTIONS request
 	if r.Method == "OPTIONS" {
 		w.WriteHeader(http.StatusOK)
@@ -110,6 +119,25 @@ func proxyRequest(w http.ResponseWriter, r *http.Request, targetURL string, appL
 		}
 	}
 
+	// Prosledi IP adresu klijenta (koristi se za rate limiting i procenu rizika prijave)
+	if clientIP, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := r.Header.Get("X-Forwarded-For"); prior != "" {
+			req.Header.Set("X-Forwarded-For", prior+", "+clientIP)
+		} else {
+			req.Header.Set("X-Forwarded-For", clientIP)
+		}
+		req.Header.Set("X-Real-IP", clientIP)
+	}
+
+	// Prosledi impersonatora backend servisima (nikad ne veruj vrednosti koju je poslao klijent)
+	req.Header.Del(middleware.HeaderImpersonatorID)
+	req.Header.Del(middleware.HeaderImpersonatedUser)
+	req.Header.Del("X-Internal-Key")
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims); ok && claims != nil && claims.ImpersonatorID != "" {
+		req.Header.Set(middleware.HeaderImpersonatorID, claims.ImpersonatorID)
+		req.Header.Set(middleware.HeaderImpersonatedUser, claims.UserID)
+	}
+
 	// Slanje zahteva
 	// Konfiguriši HTTP klijent da ignoriše sertifikate za inter-service komunikaciju
 	// (jer koristimo samopotpisane sertifikate)
@@ -189,6 +217,202 @@ func proxyRequest(w http.ResponseWriter, r *http.Request, targetURL string, appL
 	w.Write(responseBody)
 }
 
+// streamClient prosleđuje audio stream: bez ukupnog timeout-a (pesma traje duže od bilo kog timeout-a),
+// ograničeno je samo čekanje na headers, a prekid klijenta otkazuje zahtev preko context-a
+var streamClient = tracing.HTTPClient(&http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+})
+
+// proxyStream prosleđuje audio stream bez učitavanja celog fajla u memoriju.
+// Range/If-Range se prosleđuju, a 206/416 i Content-Range se vraćaju klijentu nepromenjeni.
+func proxyStream(w http.ResponseWriter, r *http.Request, targetURL string, appLogger *logger.Logger) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.URL.RawQuery != "" {
+		targetURL = targetURL + "?" + r.URL.RawQuery
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, nil)
+	if err != nil {
+		http.Error(w, "Failed to create request", http.StatusInternalServerError)
+		return
+	}
+	if propagator := tracing.GetPropagator(); propagator != nil {
+		propagator.Inject(r.Context(), propagation.HeaderCarrier(req.Header))
+	}
+	for _, key := range []string{"Authorization", "Range", "If-Range", "If-None-Match", "User-Agent", "Accept"} {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		if r.Context().Err() == nil {
+			log.Printf("Stream request to %s failed: %v", targetURL, err)
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		if strings.HasPrefix(key, "Access-Control-") {
+			continue
+		}
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	enableCORS(w, r)
+	// Range headers moraju biti čitljivi i za cross-origin audio element
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Range, Content-Length, Accept-Ranges, ETag, Digest")
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// proxyImage prosleđuje zahteve za slike albuma i izvođača: sličice se šalju kao stream (keš headers i ETag
+// ostaju od content-service), a upload i brisanje traže dozvolu za izmenu kataloga
+func proxyImage(w http.ResponseWriter, r *http.Request, targetURL string, permission string, cfg *config.Config, appLogger *logger.Logger) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		proxyStream(w, r, targetURL, appLogger)
+		return
+	}
+	middleware.RequirePermission(permission, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, targetURL, appLogger)
+	})(w, r)
+}
+
+// tusHeaders su headers tus protokola koji se prosleđuju u oba smera
+var tusHeaders = []string{"Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Tus-Checksum-Algorithm",
+	"Upload-Length", "Upload-Offset", "Upload-Metadata", "Upload-Checksum", "Upload-Expires", "Location"}
+
+// uploadChunkTimeout ograničava jedan PATCH nastavljivog upload-a; prekinut chunk se nastavlja od poslednjeg offset-a
+const uploadChunkTimeout = 2 * time.Minute
+
+// enableTusCORS dozvoljava tus metode i headers (HEAD, PATCH, Upload-*) za upload iz browser-a
+func enableTusCORS(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	w.Header().Set("Access-Control-Allow-Methods", "POST, HEAD, PATCH, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata, Upload-Checksum")
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join(tusHeaders, ", "))
+}
+
+// proxyUpload prosleđuje zahteve nastavljivog upload-a (tus) ka content-service: chunk se prosleđuje kao
+// stream, a Location se prepisuje na putanju gateway-a
+func proxyUpload(w http.ResponseWriter, r *http.Request, targetURL string, appLogger *logger.Logger) {
+	enableTusCORS(w, r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), uploadChunkTimeout)
+	defer cancel()
+
+	var body io.Reader
+	if r.Method == http.MethodPatch {
+		body = r.Body
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, targetURL, body)
+	if err != nil {
+		http.Error(w, "Failed to create request", http.StatusInternalServerError)
+		return
+	}
+	req.ContentLength = r.ContentLength
+	if r.Method != http.MethodPatch {
+		req.ContentLength = 0
+	}
+	if propagator := tracing.GetPropagator(); propagator != nil {
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	for _, key := range append([]string{"Authorization", "Content-Type"}, tusHeaders...) {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		if r.Context().Err() == nil {
+			log.Printf("Upload request to %s failed: %v", targetURL, err)
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, key := range append([]string{"Content-Type", "Cache-Control"}, tusHeaders...) {
+		if value := resp.Header.Get(key); value != "" {
+			w.Header().Set(key, value)
+		}
+	}
+	if location := resp.Header.Get("Location"); strings.HasPrefix(location, "/uploads/") {
+		w.Header().Set("Location", "/api/content"+location)
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// catalogImportTimeout ograničava uvoz kataloga: content-service odgovara tek kada obradi sve redove i audio fajlove
+const catalogImportTimeout = 30 * time.Minute
+
+// catalogImportClient čeka na odgovor uvoza bez ograničenja na headers, ceo zahtev ograničava context
+var catalogImportClient = tracing.HTTPClient(&http.Client{
+	Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	},
+})
+
+// proxyCatalogImport prosleđuje uvoz kataloga (NDJSON, CSV ili zip arhiva sa audio fajlovima) kao stream,
+// bez učitavanja cele arhive u memoriju
+func proxyCatalogImport(w http.ResponseWriter, r *http.Request, targetURL string, appLogger *logger.Logger) {
+	enableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.URL.RawQuery != "" {
+		targetURL = targetURL + "?" + r.URL.RawQuery
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), catalogImportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, r.Method, targetURL, r.Body)
+	if err != nil {
+		http.Error(w, "Failed to create request", http.StatusInternalServerError)
+		return
+	}
+	req.ContentLength = r.ContentLength
+	if propagator := tracing.GetPropagator(); propagator != nil {
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	for _, key := range []string{"Authorization", "Content-Type", "User-Agent"} {
+		if value := r.Header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	resp, err := catalogImportClient.Do(req)
+	if err != nil {
+		if r.Context().Err() == nil {
+			log.Printf("Catalog import request to %s failed: %v", targetURL, err)
+			http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
 // extractServiceName extracts service name from URL
 func extractServiceName(url string) string {
 	if strings.Contains(url, "users-service") {
@@ -206,15 +430,19 @@ func extractServiceName(url string) string {
 }
 
 // composeSongsWithRatings implements API Composition pattern
-// Combines songs from content-service with ratings from ratings-service
+// Combines a page of songs from content-service with ratings from ratings-service
 func composeSongsWithRatings(w http.ResponseWriter, r *http.Request, cfg *config.Config, appLogger *logger.Logger) {
 	enableCORS(w, r)
 
-	// Step 1: Get songs from content-service
+	// Step 1: Get a page of songs from content-service (pagination, sort and filters are passed through)
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	contentReq, err := http.NewRequestWithContext(ctx, "GET", cfg.ContentServiceURL+"/songs", nil)
+	contentURL := cfg.ContentServiceURL + "/songs"
+	if r.URL.RawQuery != "" {
+		contentURL += "?" + r.URL.RawQuery
+	}
+	contentReq, err := http.NewRequestWithContext(ctx, "GET", contentURL, nil)
 	if err != nil {
 		http.Error(w, "Failed to create request", http.StatusInternalServerError)
 		return
@@ -239,7 +467,9 @@ func composeSongsWithRatings(w http.ResponseWriter, r *http.Request, cfg *config
 	defer contentResp.Body.Close()
 
 	if contentResp.StatusCode != http.StatusOK {
-		http.Error(w, "Failed to get songs", contentResp.StatusCode)
+		// Invalid cursor or sort is returned to the client as it is
+		detail, _ := io.ReadAll(io.LimitReader(contentResp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(detail)), contentResp.StatusCode)
 		return
 	}
 
@@ -328,7 +558,10 @@ func composeSongsWithRatings(w http.ResponseWriter, r *http.Request, cfg *config
 		}
 	}
 
-	// Step 4: Return composed response
+	// Step 4: Return composed response with the cursor of the next page
+	if next := contentResp.Header.Get(pagination.HeaderNextCursor); next != "" {
+		w.Header().Set(pagination.HeaderNextCursor, next)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(songs)
@@ -450,7 +683,8 @@ func composeSongsByAlbumWithRatings(w http.ResponseWriter, r *http.Request, cfg
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	contentURL := cfg.ContentServiceURL + "/songs/by-album?albumId=" + albumID
+	// Pagination and sort parameters are passed through together with albumId
+	contentURL := cfg.ContentServiceURL + "/songs/by-album?" + r.URL.RawQuery
 	contentReq, err := http.NewRequestWithContext(ctx, "GET", contentURL, nil)
 	if err != nil {
 		http.Error(w, "Failed to create request", http.StatusInternalServerError)
@@ -476,7 +710,9 @@ func composeSongsByAlbumWithRatings(w http.ResponseWriter, r *http.Request, cfg
 	defer contentResp.Body.Close()
 
 	if contentResp.StatusCode != http.StatusOK {
-		http.Error(w, "Failed to get songs", contentResp.StatusCode)
+		// Invalid cursor or sort is returned to the client as it is
+		detail, _ := io.ReadAll(io.LimitReader(contentResp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(detail)), contentResp.StatusCode)
 		return
 	}
 
@@ -487,7 +723,7 @@ func composeSongsByAlbumWithRatings(w http.ResponseWriter, r *http.Request, cfg
 		return
 	}
 
-	// Step 2: For each song, get average rating and count from ratings-service
+	// Step 2: For each song on the page, get average rating and count from ratings-service
 	type ratingResult struct {
 		index        int
 		averageRating float64
@@ -562,12 +798,121 @@ func composeSongsByAlbumWithRatings(w http.ResponseWriter, r *http.Request, cfg
 		}
 	}
 
-	// Step 4: Return composed response
+	// Step 4: Return composed response with the cursor of the next page
+	if next := contentResp.Header.Get(pagination.HeaderNextCursor); next != "" {
+		w.Header().Set(pagination.HeaderNextCursor, next)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(songs)
 }
 
+// composeSearchWithRatings implements API Composition pattern for catalog search
+// Song hits from content-service search get their average rating and count from ratings-service
+func composeSearchWithRatings(w http.ResponseWriter, r *http.Request, cfg *config.Config, appLogger *logger.Logger) {
+	enableCORS(w, r)
+
+	// Step 1: Search in content-service (query parameters are passed through)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	contentURL := cfg.ContentServiceURL + "/search?" + r.URL.RawQuery
+	contentReq, err := http.NewRequestWithContext(ctx, "GET", contentURL, nil)
+	if err != nil {
+		http.Error(w, "Failed to create request", http.StatusInternalServerError)
+		return
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: tr,
+	}
+	// Wrap client with tracing (2.10)
+	client = tracing.HTTPClient(client)
+
+	contentResp, err := client.Do(contentReq)
+	if err != nil {
+		log.Printf("Error calling content-service: %v", err)
+		http.Error(w, "Content service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer contentResp.Body.Close()
+
+	if contentResp.StatusCode != http.StatusOK {
+		// Validation errors (e.g. missing q) are returned to the client as they are
+		detail, _ := io.ReadAll(io.LimitReader(contentResp.Body, 1024))
+		http.Error(w, strings.TrimSpace(string(detail)), contentResp.StatusCode)
+		return
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(contentResp.Body).Decode(&result); err != nil {
+		log.Printf("Error decoding search results: %v", err)
+		http.Error(w, "Failed to decode search results", http.StatusInternalServerError)
+		return
+	}
+	hits, _ := result["results"].([]interface{})
+
+	// Step 2: For each song hit, get average rating and count from ratings-service (in parallel)
+	ratingClient := tracing.HTTPClient(&http.Client{
+		Timeout:   3 * time.Second,
+		Transport: tr,
+	})
+
+	var wg sync.WaitGroup
+	for _, h := range hits {
+		hit, ok := h.(map[string]interface{})
+		if !ok || hit["type"] != "song" {
+			continue
+		}
+		songID, _ := hit["id"].(string)
+
+		// Default values if ratings-service is unavailable or the song has no ratings
+		hit["averageRating"] = 0.0
+		hit["ratingCount"] = 0
+
+		wg.Add(1)
+		go func(hit map[string]interface{}, songID string) {
+			defer wg.Done()
+
+			ratingCtx, ratingCancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer ratingCancel()
+
+			ratingURL := cfg.RatingsServiceURL + "/average-rating?songId=" + url.QueryEscape(songID)
+			ratingReq, err := http.NewRequestWithContext(ratingCtx, "GET", ratingURL, nil)
+			if err != nil {
+				return
+			}
+			ratingResp, err := ratingClient.Do(ratingReq)
+			if err != nil {
+				return
+			}
+			defer ratingResp.Body.Close()
+
+			if ratingResp.StatusCode != http.StatusOK {
+				return
+			}
+			var ratingData map[string]interface{}
+			if err := json.NewDecoder(ratingResp.Body).Decode(&ratingData); err != nil {
+				return
+			}
+			avg, _ := ratingData["averageRating"].(float64)
+			count, _ := ratingData["ratingCount"].(float64)
+			hit["averageRating"] = avg
+			hit["ratingCount"] = int(count)
+		}(hit, songID)
+	}
+	wg.Wait()
+
+	// Step 3: Return composed response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
 func main() {
 	cfg := config.Load()
 
@@ -592,6 +937,9 @@ func main() {
 	}
 	defer appLogger.Close()
 
+	// Sync revoked sessions from users-service (tokens are stateless JWTs)
+	go middleware.StartSessionRevocationSync(context.Background(), cfg.UsersServiceURL, cfg.InternalAPIKey, 30*time.Second)
+
 	mux := http.NewServeMux()
 
 	// Health endpoint
@@ -608,43 +956,28 @@ func main() {
 	// Global rate limiting: 100 requests per minute per IP (DoS protection)
 	globalRateLimit := middleware.RateLimit(100, 1*time.Minute)
 
-	// Helper function to check if user is not admin
-	requireNonAdmin := func(next http.HandlerFunc) http.HandlerFunc {
-		return middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
-			claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
-			if !ok || claims == nil {
-				enableCORS(w, r)
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			// Check if user is NOT admin
-			if claims.Role == "ADMIN" {
-				enableCORS(w, r)
-				http.Error(w, "admin users cannot perform this action", http.StatusForbidden)
-				return
-			}
+	// Proof-of-work on public endpoints that send emails (email bombing protection)
+	pow := middleware.NewProofOfWork(cfg)
 
-			next(w, r)
-		})
-	}
+	// GET /api/pow/challenge?action=register|login_otp|recover|password_reset - signed challenge
+	mux.HandleFunc("/api/pow/challenge", globalRateLimit(pow.ChallengeHandler))
 
 	// USERS SERVICE ROUTES
 	mux.HandleFunc("/api/users/health", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/health", appLogger)
 	}))
 
-	mux.HandleFunc("/api/users/register", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/users/register", globalRateLimit(middleware.RequireProofOfWork("register", pow, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/register", appLogger)
-	}))
+	})))
 
 	mux.HandleFunc("/api/users/verify-email", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/verify-email", appLogger)
 	}))
 
-	mux.HandleFunc("/api/users/login/request-otp", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/users/login/request-otp", globalRateLimit(middleware.RequireProofOfWork("login_otp", pow, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/login/request-otp", appLogger)
-	}))
+	})))
 
 	mux.HandleFunc("/api/users/login/verify-otp", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/login/verify-otp", appLogger)
@@ -658,33 +991,118 @@ func main() {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/password/change", appLogger)
 	})))
 
-	mux.HandleFunc("/api/users/password/reset/request", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/users/password/reset/request", globalRateLimit(middleware.RequireProofOfWork("password_reset", pow, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/password/reset/request", appLogger)
-	}))
+	})))
 
 	mux.HandleFunc("/api/users/password/reset", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/password/reset", appLogger)
 	}))
 
+	// Email change endpoints
+	// POST /api/users/email/change - request change (requires auth)
+	// POST /api/users/email/change/confirm - confirm from new address (token authenticated)
+	// POST /api/users/email/change/cancel - cancel from current address (token authenticated)
+	// The emailed links open frontend pages that send these requests
+	mux.HandleFunc("/api/users/email/change", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/email/change", appLogger)
+	})))
+	mux.HandleFunc("/api/users/email/change/confirm", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodOptions {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		proxyRequest(w, r, cfg.UsersServiceURL+"/email/change/confirm", appLogger)
+	}))
+	mux.HandleFunc("/api/users/email/change/cancel", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodOptions {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		proxyRequest(w, r, cfg.UsersServiceURL+"/email/change/cancel", appLogger)
+	}))
+
+	// Phone number and OTP delivery channel (requires auth)
+	// POST /api/users/phone - set phone and send verification SMS, DELETE removes it
+	// POST /api/users/phone/verify - confirm phone with the SMS code
+	// GET/PUT /api/users/otp/channel - show or choose email/sms for login codes
+	mux.HandleFunc("/api/users/phone", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/phone", appLogger)
+	})))
+	mux.HandleFunc("/api/users/phone/verify", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/phone/verify", appLogger)
+	})))
+	mux.HandleFunc("/api/users/otp/channel", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/otp/channel", appLogger)
+	})))
+
 	// Magic link endpoints (account recovery)
-	mux.HandleFunc("/api/users/recover/request", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/users/recover/request", globalRateLimit(middleware.RequireProofOfWork("recover", pow, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/recover/request", appLogger)
-	}))
+	})))
 	mux.HandleFunc("/api/users/recover/verify", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.UsersServiceURL+"/recover/verify", appLogger)
 	}))
 
+	// POST /api/users/login/not-me - "this wasn't me" from a suspicious-login alert, sent by the
+	// confirmation page the email links to (token authenticated)
+	mux.HandleFunc("/api/users/login/not-me", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodOptions {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		proxyRequest(w, r, cfg.UsersServiceURL+"/login/not-me", appLogger)
+	}))
+
+	// GET /api/users/admin/security/high-risk-logins - high-risk login report (requires audit.read)
+	mux.HandleFunc("/api/users/admin/security/high-risk-logins", globalRateLimit(middleware.RequirePermission(authz.AuditRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/security/high-risk-logins", appLogger)
+	})))
+
+	// Admin: email outbox delivery status (requires mail.manage)
+	// GET /api/users/admin/mail/outbox - list queued/sent/failed emails
+	// GET /api/users/admin/mail/outbox/{id} - delivery status of one email
+	// POST /api/users/admin/mail/outbox/{id}/retry - requeue a failed email
+	mux.HandleFunc("/api/users/admin/mail/outbox", globalRateLimit(middleware.RequirePermission(authz.MailManage, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/mail/outbox", appLogger)
+	})))
+	mux.HandleFunc("/api/users/admin/mail/outbox/", globalRateLimit(middleware.RequirePermission(authz.MailManage, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/api/users/admin/mail/outbox/"):]
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/mail/outbox/"+path, appLogger)
+	})))
+
+	// POST /api/users/admin/impersonate - mint a short-lived "view as user" token (requires users.impersonate)
+	mux.HandleFunc("/api/users/admin/impersonate", globalRateLimit(middleware.RequirePermission(authz.UsersImpersonate, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/impersonate", appLogger)
+	})))
+
+	// Admin: roles and permissions
+	// GET /api/users/admin/roles - list roles and grantable permissions (requires roles.manage)
+	// GET/PUT/DELETE /api/users/admin/roles/{name} - view, create/update or delete a role (requires roles.manage)
+	// PUT /api/users/admin/users/{id}/role - assign a role to a user (requires users.manage)
+	mux.HandleFunc("/api/users/admin/roles", globalRateLimit(middleware.RequirePermission(authz.RolesManage, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/roles", appLogger)
+	})))
+	mux.HandleFunc("/api/users/admin/roles/", globalRateLimit(middleware.RequirePermission(authz.RolesManage, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/api/users/admin/roles/"):]
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/roles/"+path, appLogger)
+	})))
+	mux.HandleFunc("/api/users/admin/users/", globalRateLimit(middleware.RequirePermission(authz.UsersManage, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/api/users/admin/users/"):]
+		proxyRequest(w, r, cfg.UsersServiceURL+"/admin/users/"+path, appLogger)
+	})))
+
 	// CONTENT SERVICE ROUTES
 	mux.HandleFunc("/api/content/health", func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.ContentServiceURL+"/health", appLogger)
 	})
 
 	// Artists routes
-	// GET /api/content/artists - get all artists (public)
-	// POST /api/content/artists - create artist (admin only)
+	// GET /api/content/artists - list artists (public, paginated: limit, cursor, sort, genre)
+	// POST /api/content/artists - create artist (requires catalog.artist.write)
 	mux.HandleFunc("/api/content/artists", globalRateLimit(middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
-			middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			middleware.RequirePermission(authz.CatalogArtistWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				proxyRequest(w, r, cfg.ContentServiceURL+"/artists", appLogger)
 			})(w, r)
 		} else {
@@ -693,11 +1111,19 @@ func main() {
 	})))
 
 	// GET /api/content/artists/{id} - get artist by ID (public)
-	// PUT /api/content/artists/{id} - update artist (admin only)
+	// PUT /api/content/artists/{id} - update artist (requires catalog.artist.write)
+	// GET /api/content/artists/{id}/image/{64|300|640} - slika izvođača (public)
+	// POST, DELETE /api/content/artists/{id}/image - postavlja ili uklanja sliku izvođača (requires catalog.artist.write)
+	// GET /api/content/artists/{id}/revisions[/{number}] - istorija izmena izvođača (requires catalog.artist.write)
+	// POST /api/content/artists/{id}/revisions/{number}/rollback - vraća izvođača na izabranu reviziju (requires catalog.artist.write)
 	mux.HandleFunc("/api/content/artists/", globalRateLimit(middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path[len("/api/content/artists/"):]
-		if r.Method == http.MethodPut || r.Method == http.MethodDelete {
-			middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(path, "/image") {
+			proxyImage(w, r, cfg.ContentServiceURL+"/artists/"+path, authz.CatalogArtistWrite, cfg, appLogger)
+			return
+		}
+		if r.Method == http.MethodPut || r.Method == http.MethodDelete || (strings.Contains(path, "/revisions") && r.Method != "OPTIONS") {
+			middleware.RequirePermission(authz.CatalogArtistWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				proxyRequest(w, r, cfg.ContentServiceURL+"/artists/"+path, appLogger)
 			})(w, r)
 		} else {
@@ -706,11 +1132,11 @@ func main() {
 	})))
 
 	// Album routes
-	// GET /api/content/albums - get all albums (public)
-	// POST /api/content/albums - create album (admin only)
+	// GET /api/content/albums - list albums (public, paginated: limit, cursor, sort, genre, artistId)
+	// POST /api/content/albums - create album (requires catalog.album.write)
 	mux.HandleFunc("/api/content/albums", globalRateLimit(middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
-			middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			middleware.RequirePermission(authz.CatalogAlbumWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				proxyRequest(w, r, cfg.ContentServiceURL+"/albums", appLogger)
 			})(w, r)
 		} else {
@@ -724,8 +1150,23 @@ func main() {
 	}))
 
 	// GET /api/content/albums/{id} - get album by ID
+	// GET /api/content/albums/{id}/image/{64|300|640} - omot albuma (public)
+	// POST, DELETE /api/content/albums/{id}/image - postavlja ili uklanja omot albuma (requires catalog.album.write)
+	// GET /api/content/albums/{id}/revisions[/{number}] - istorija izmena albuma (requires catalog.album.write)
+	// POST /api/content/albums/{id}/revisions/{number}/rollback - vraća album na izabranu reviziju (requires catalog.album.write)
 	mux.HandleFunc("/api/content/albums/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path[len("/api/content/albums/"):]
+		if strings.Contains(path, "/image") {
+			proxyImage(w, r, cfg.ContentServiceURL+"/albums/"+path, authz.CatalogAlbumWrite, cfg, appLogger)
+			return
+		}
+		// Istorija izmena albuma (admin)
+		if strings.Contains(path, "/revisions") && r.Method != "OPTIONS" {
+			middleware.RequirePermission(authz.CatalogAlbumWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/albums/"+path, appLogger)
+			})(w, r)
+			return
+		}
 		proxyRequest(w, r, cfg.ContentServiceURL+"/albums/"+path, appLogger)
 	})
 
@@ -754,8 +1195,21 @@ func main() {
 		}
 	}))
 
-	// GET /api/content/songs - get all songs with ratings (API Composition)
-	// POST /api/content/songs - create song (admin only)
+	// GET /api/content/search?q=... - search songs, albums and artists, song hits with ratings (API Composition)
+	mux.HandleFunc("/api/content/search", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			composeSearchWithRatings(w, r, cfg, appLogger)
+		} else if r.Method == "OPTIONS" {
+			enableCORS(w, r)
+			w.WriteHeader(http.StatusOK)
+		} else {
+			enableCORS(w, r)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// GET /api/content/songs - list songs with ratings (API Composition, paginated: limit, cursor, sort, genre, artistId, albumId)
+	// POST /api/content/songs - create song (requires catalog.song.write)
 	mux.HandleFunc("/api/content/songs", globalRateLimit(middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Handle preflight OPTIONS request
 		if r.Method == "OPTIONS" {
@@ -765,7 +1219,7 @@ func main() {
 		}
 
 		if r.Method == http.MethodPost {
-			middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				proxyRequest(w, r, cfg.ContentServiceURL+"/songs", appLogger)
 			})(w, r)
 		} else if r.Method == http.MethodGet {
@@ -778,9 +1232,17 @@ func main() {
 	})))
 
 	// GET /api/content/songs/{id} - get song by ID
-	// PUT /api/content/songs/{id} - update song (admin only)
-	// DELETE /api/content/songs/{id} - delete song via saga (admin only) (2.13)
+	// PUT /api/content/songs/{id} - update song (requires catalog.song.write)
+	// DELETE /api/content/songs/{id} - delete song via saga (requires catalog.song.write) (2.13)
 	// GET /api/content/songs/{id}/stream - stream song audio (public)
+	// GET /api/content/songs/{id}/cover - omot iz audio fajla (public)
+	// GET /api/content/songs/{id}/hls/master.m3u8 - HLS stream (public)
+	// POST /api/content/songs/{id}/hls - ponovo generiše HLS (requires catalog.song.write)
+	// POST /api/content/songs/{id}/storage - premešta fajlove pesme na drugi storage backend (requires catalog.song.write)
+	// GET /api/content/songs/{id}/lyrics[/{language}] - jezici sa tekstom, tekst kao JSON linije (public)
+	// PUT, DELETE /api/content/songs/{id}/lyrics/{language} - postavlja ili uklanja tekst pesme (requires catalog.song.write)
+	// GET /api/content/songs/{id}/revisions[/{number}] - istorija izmena pesme (requires catalog.song.write)
+	// POST /api/content/songs/{id}/revisions/{number}/rollback - vraća pesmu na izabranu reviziju (requires catalog.song.write)
 	mux.HandleFunc("/api/content/songs/", func(w http.ResponseWriter, r *http.Request) {
 		// Handle preflight OPTIONS request
 		if r.Method == "OPTIONS" {
@@ -795,15 +1257,65 @@ func main() {
 		if strings.HasSuffix(path, "/stream") {
 			// Use OptionalAuth to extract userID if token is present (for activity logging 1.15)
 			middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyStream(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			})(w, r)
+			return
+		}
+
+		// HLS playliste i segmenti se prosleđuju kao stream (javno, OptionalAuth zbog evidencije slušanja)
+		if strings.Contains(path, "/hls/") {
+			middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyStream(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			})(w, r)
+			return
+		}
+
+		// Ponovno generisanje HLS izlaza (admin)
+		if strings.HasSuffix(path, "/hls") && r.Method == http.MethodPost {
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			})(w, r)
+			return
+		}
+
+		// Premeštanje fajlova pesme na drugi storage backend (admin)
+		if strings.HasSuffix(path, "/storage") && r.Method == http.MethodPost {
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			})(w, r)
+			return
+		}
+
+		// Tekstovi pesama: čitanje je javno, postavljanje i brisanje traži dozvolu za izmenu pesama
+		if strings.Contains(path, "/lyrics") {
+			if r.Method == http.MethodGet {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+				return
+			}
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
 			})(w, r)
 			return
 		}
 
+		// Istorija izmena pesme i vraćanje na reviziju (admin)
+		if strings.Contains(path, "/revisions") {
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			})(w, r)
+			return
+		}
+
+		// Omot iz audio fajla pesme (javno)
+		if strings.HasSuffix(path, "/cover") && r.Method == http.MethodGet {
+			proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
+			return
+		}
+
 		// Check if this is an upload request (2.11)
 		if strings.HasSuffix(path, "/upload") && r.Method == http.MethodPost {
 			log.Printf("Upload request detected: %s", path)
-			middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 				targetURL := cfg.ContentServiceURL + "/songs/" + path
 				log.Printf("Proxying upload to: %s", targetURL)
 				proxyRequest(w, r, targetURL, appLogger)
@@ -817,11 +1329,11 @@ func main() {
 				// API Composition: Combine song from content-service with ratings from ratings-service
 				composeSongWithRatings(w, r, path, cfg, appLogger)
 			} else if r.Method == http.MethodPut {
-				middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 					proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
 				})(w, r)
 			} else if r.Method == http.MethodDelete {
-				middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 					proxyRequest(w, r, cfg.ContentServiceURL+"/songs/"+path, appLogger)
 				})(w, r)
 			} else {
@@ -836,6 +1348,127 @@ func main() {
 		http.Error(w, "invalid request", http.StatusBadRequest)
 	})
 
+	// Playlist routes - content-service checks ownership (owner, editors of collaborative playlists)
+	// GET /api/content/playlists - playlists the user owns or edits (requires authentication)
+	// POST /api/content/playlists - create playlist (requires authentication)
+	mux.HandleFunc("/api/content/playlists", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.ContentServiceURL+"/playlists", appLogger)
+	})))
+
+	// GET /api/content/playlists/public?ownerId={id} - list public playlists (public)
+	// GET /api/content/playlists/{id} - get playlist with songs (public, private ones only for owner and editors)
+	// PUT, DELETE /api/content/playlists/{id}, POST /api/content/playlists/{id}/songs,
+	// PUT, DELETE /api/content/playlists/{id}/songs/{entryId}, POST /api/content/playlists/{id}/editors,
+	// DELETE /api/content/playlists/{id}/editors/{userId} - change playlist (requires authentication)
+	mux.HandleFunc("/api/content/playlists/", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path[len("/api/content/playlists/"):]
+
+		// Internal endpoints are only for saga-service
+		if path == "internal" || strings.HasPrefix(path, "internal/") {
+			enableCORS(w, r)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			middleware.OptionalAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+				proxyRequest(w, r, cfg.ContentServiceURL+"/playlists/"+path, appLogger)
+			})(w, r)
+			return
+		}
+		middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			proxyRequest(w, r, cfg.ContentServiceURL+"/playlists/"+path, appLogger)
+		})(w, r)
+	}))
+
+	// Library routes - the user's liked songs, albums and artists (requires authentication)
+	// GET /api/content/library?type=song|album|artist&limit=&cursor= - liked items, most recently added first
+	// GET /api/content/library/contains?type=song&ids=a,b - which of the items are liked
+	// PUT, DELETE /api/content/library/{songs|albums|artists}/{id} - like, unlike
+	mux.HandleFunc("/api/content/library", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.ContentServiceURL+"/library", appLogger)
+	})))
+	mux.HandleFunc("/api/content/library/", globalRateLimit(middleware.RequireAuth(cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		proxyRequest(w, r, cfg.ContentServiceURL+"/library/"+r.URL.Path[len("/api/content/library/"):], appLogger)
+	})))
+
+	// GET /api/content/audio/integrity?verify=full - izveštaj o nedostajućim i oštećenim audio fajlovima (requires catalog.song.write)
+	mux.HandleFunc("/api/content/audio/integrity", middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+		// Potpuna provera čita sve fajlove, traje duže od običnog timeout-a
+		proxyStream(w, r, cfg.ContentServiceURL+"/audio/integrity", appLogger)
+	}))
+
+	// Nastavljivi upload audio fajlova (tus 1.0) - veliki fajlovi se šalju u delovima
+	// OPTIONS /api/content/uploads - tus mogućnosti servera (public)
+	// POST /api/content/uploads - kreira upload za pesmu (requires catalog.song.write)
+	// HEAD, PATCH, DELETE /api/content/uploads/{id} - offset, slanje dela, odustajanje (requires catalog.song.write)
+	uploadRoute := func(w http.ResponseWriter, r *http.Request) {
+		target := cfg.ContentServiceURL + strings.TrimPrefix(r.URL.Path, "/api/content")
+		if r.Method == http.MethodOptions {
+			// CORS preflight odgovara gateway, tus OPTIONS prosleđujemo
+			if r.Header.Get("Access-Control-Request-Method") != "" {
+				enableTusCORS(w, r)
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			proxyUpload(w, r, target, appLogger)
+			return
+		}
+		middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			proxyUpload(w, r, target, appLogger)
+		})(w, r)
+	}
+	mux.HandleFunc("/api/content/uploads", uploadRoute)
+	mux.HandleFunc("/api/content/uploads/", uploadRoute)
+
+	// Izvoz i uvoz kataloga između okruženja (requires catalog.artist.write, catalog.album.write i catalog.song.write)
+	// GET /api/content/catalog/export?format=ndjson|csv&type=artists|albums|songs&audio=true - NDJSON, CSV ili zip arhiva
+	// POST /api/content/catalog/import?format=ndjson|csv&type=artists|albums|songs&dryRun=true - izveštaj o uvozu
+	catalogWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		guarded := middleware.RequirePermission(authz.CatalogArtistWrite, cfg, appLogger)(
+			middleware.RequirePermission(authz.CatalogAlbumWrite, cfg, appLogger)(
+				middleware.RequirePermission(authz.CatalogSongWrite, cfg, appLogger)(next)))
+		return func(w http.ResponseWriter, r *http.Request) {
+			// CORS preflight ne nosi token
+			if r.Method == "OPTIONS" {
+				next(w, r)
+				return
+			}
+			guarded(w, r)
+		}
+	}
+	mux.HandleFunc("/api/content/catalog/export", globalRateLimit(catalogWrite(func(w http.ResponseWriter, r *http.Request) {
+		// Izvoz sa audio fajlovima je veliki, šalje se kao stream
+		proxyStream(w, r, cfg.ContentServiceURL+"/catalog/export", appLogger)
+	})))
+	mux.HandleFunc("/api/content/catalog/import", globalRateLimit(catalogWrite(func(w http.ResponseWriter, r *http.Request) {
+		proxyCatalogImport(w, r, cfg.ContentServiceURL+"/catalog/import", appLogger)
+	})))
+
+	// Korpa obrisanih pesama, albuma i izvođača - brišu se trajno posle isteka roka čuvanja
+	// GET /api/content/trash/{songs|albums|artists} - obrisane stavke (requires catalog.*.write kolekcije)
+	// POST /api/content/trash/{songs|albums|artists}/{id}/restore - vraćanje stavke
+	// DELETE /api/content/trash/{songs|albums|artists}/{id} - trajno brisanje, album i izvođač zajedno sa pesmama
+	// (?sharedSongs=detach|delete za pesme i albume koje izvođač deli sa drugima)
+	mux.HandleFunc("/api/content/trash/", globalRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/content/trash/")
+		var permission string
+		switch strings.SplitN(path, "/", 2)[0] {
+		case "songs":
+			permission = authz.CatalogSongWrite
+		case "albums":
+			permission = authz.CatalogAlbumWrite
+		case "artists":
+			permission = authz.CatalogArtistWrite
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		middleware.RequirePermission(permission, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+			proxyRequest(w, r, cfg.ContentServiceURL+"/trash/"+path, appLogger)
+		})(w, r)
+	}))
+
 	// NOTIFICATIONS SERVICE ROUTES
 	mux.HandleFunc("/api/notifications/health", func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.NotificationsServiceURL+"/health", appLogger)
@@ -876,15 +1509,15 @@ func main() {
 		proxyRequest(w, r, cfg.SubscriptionsServiceURL+"/subscriptions"+query, appLogger)
 	})))
 
-	// POST /api/subscriptions/subscribe-artist - subscribe to artist (requires auth, non-admin only)
-	// DELETE /api/subscriptions/subscribe-artist - unsubscribe from artist (requires auth, non-admin only)
-	mux.HandleFunc("/api/subscriptions/subscribe-artist", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// POST /api/subscriptions/subscribe-artist - subscribe to artist (requires subscriptions.write)
+	// DELETE /api/subscriptions/subscribe-artist - unsubscribe from artist (requires subscriptions.write)
+	mux.HandleFunc("/api/subscriptions/subscribe-artist", globalRateLimit(middleware.RequirePermission(authz.SubscriptionsWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.SubscriptionsServiceURL+"/subscribe-artist", appLogger)
 	})))
 
-	// POST /api/subscriptions/subscribe-genre - subscribe to genre (requires auth, non-admin only)
-	// DELETE /api/subscriptions/subscribe-genre - unsubscribe from genre (requires auth, non-admin only)
-	mux.HandleFunc("/api/subscriptions/subscribe-genre", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// POST /api/subscriptions/subscribe-genre - subscribe to genre (requires subscriptions.write)
+	// DELETE /api/subscriptions/subscribe-genre - unsubscribe from genre (requires subscriptions.write)
+	mux.HandleFunc("/api/subscriptions/subscribe-genre", globalRateLimit(middleware.RequirePermission(authz.SubscriptionsWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		proxyRequest(w, r, cfg.SubscriptionsServiceURL+"/subscribe-genre", appLogger)
 	})))
 
@@ -893,8 +1526,8 @@ func main() {
 		proxyRequest(w, r, cfg.RatingsServiceURL+"/health", appLogger)
 	}))
 
-	// POST /api/ratings/rate-song - rate/update a song (requires auth, non-admin only)
-	mux.HandleFunc("/api/ratings/rate-song", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// POST /api/ratings/rate-song - rate/update a song (requires ratings.write)
+	mux.HandleFunc("/api/ratings/rate-song", globalRateLimit(middleware.RequirePermission(authz.RatingsWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Get userId from JWT token
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
@@ -916,8 +1549,8 @@ func main() {
 		proxyRequest(w, r, targetURL, appLogger)
 	})))
 
-	// DELETE /api/ratings/delete-rating - delete a rating (requires auth, non-admin only)
-	mux.HandleFunc("/api/ratings/delete-rating", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// DELETE /api/ratings/delete-rating - delete a rating (requires ratings.write)
+	mux.HandleFunc("/api/ratings/delete-rating", globalRateLimit(middleware.RequirePermission(authz.RatingsWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Get userId from JWT token
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
@@ -944,8 +1577,8 @@ func main() {
 		proxyRequest(w, r, cfg.RatingsServiceURL+"/average-rating", appLogger)
 	}))
 
-	// GET /api/ratings/get-rating - get user's rating for a song (requires auth, non-admin only)
-	mux.HandleFunc("/api/ratings/get-rating", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// GET /api/ratings/get-rating - get user's rating for a song (requires ratings.write)
+	mux.HandleFunc("/api/ratings/get-rating", globalRateLimit(middleware.RequirePermission(authz.RatingsWrite, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Get userId from JWT token
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
@@ -967,8 +1600,8 @@ func main() {
 		proxyRequest(w, r, targetURL, appLogger)
 	})))
 
-	// GET /api/ratings/recommendations - get personalized recommendations (requires auth, non-admin only)
-	mux.HandleFunc("/api/ratings/recommendations", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// GET /api/ratings/recommendations - get personalized recommendations (requires activity.read)
+	mux.HandleFunc("/api/ratings/recommendations", globalRateLimit(middleware.RequirePermission(authz.ActivityRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Get userId from JWT token
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
@@ -997,8 +1630,8 @@ func main() {
 	})))
 
 	// ANALYTICS SERVICE ROUTES (1.15)
-	// GET /api/analytics/activities - get user activities (requires auth, non-admin only)
-	mux.HandleFunc("/api/analytics/activities", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// GET /api/analytics/activities - get user activities (requires activity.read)
+	mux.HandleFunc("/api/analytics/activities", globalRateLimit(middleware.RequirePermission(authz.ActivityRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Get userId from JWT token
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
@@ -1024,8 +1657,8 @@ func main() {
 		proxyRequest(w, r, targetURL, appLogger)
 	})))
 
-	// GET /api/analytics/analytics - get user analytics (1.16) (requires auth, non-admin only)
-	mux.HandleFunc("/api/analytics/analytics", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	// GET /api/analytics/analytics - get user analytics (1.16) (requires activity.read)
+	mux.HandleFunc("/api/analytics/analytics", globalRateLimit(middleware.RequirePermission(authz.ActivityRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		// Add CORS headers
 		enableCORS(w, r)
 
@@ -1055,7 +1688,7 @@ func main() {
 
 	// EVENT SOURCING ROUTES (2.14)
 	// GET /api/analytics/events/stream - get event stream for a user
-	mux.HandleFunc("/api/analytics/events/stream", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/analytics/events/stream", globalRateLimit(middleware.RequirePermission(authz.ActivityRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
 			log.Printf("Failed to get user claims from context")
@@ -1077,7 +1710,7 @@ func main() {
 	})))
 
 	// GET /api/analytics/events/replay - replay events to reconstruct state
-	mux.HandleFunc("/api/analytics/events/replay", globalRateLimit(requireNonAdmin(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/api/analytics/events/replay", globalRateLimit(middleware.RequirePermission(authz.ActivityRead, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 		if !ok || claims == nil {
 			log.Printf("Failed to get user claims from context")
@@ -1099,8 +1732,8 @@ func main() {
 	})))
 
 	// SAGA SERVICE ROUTES (2.13)
-	// POST /api/sagas/delete-song - start saga transaction for song deletion (admin only)
-	mux.HandleFunc("/api/sagas/delete-song", globalRateLimit(middleware.RequireRole("ADMIN", cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
+	// POST /api/sagas/delete-song - start saga transaction for song deletion (requires sagas.run)
+	mux.HandleFunc("/api/sagas/delete-song", globalRateLimit(middleware.RequirePermission(authz.SagasRun, cfg, appLogger)(func(w http.ResponseWriter, r *http.Request) {
 		enableCORS(w, r)
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 type Config struct {
 	Port                    string
@@ -13,6 +16,13 @@ type Config struct {
 	RecommendationServiceURL string
 	AnalyticsServiceURL     string
 	SagaServiceURL          string
+	InternalAPIKey          string // Sent as X-Internal-Key on calls to internal endpoints of the services
+	// Proof-of-work challenge on email-sending public endpoints
+	PoWEnabled        bool
+	PoWSecret         string // HMAC key for challenges (defaults to JWTSecret)
+	PoWBaseDifficulty int    // Leading zero bits required without recent abuse
+	PoWMaxDifficulty  int    // Upper bound while under abuse
+	PoWChallengeTTL   int    // Seconds a challenge stays valid
 }
 
 func Load() *Config {
@@ -66,6 +76,40 @@ func Load() *Config {
 		jwtSecret = "your-secret-key-change-in-production" // Default, should match users-service
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match users-service
+	}
+
+	powSecret := os.Getenv("POW_SECRET")
+	if powSecret == "" {
+		powSecret = jwtSecret
+	}
+
+	powBaseDifficulty := 16
+	if v := os.Getenv("POW_BASE_DIFFICULTY"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 && parsed <= 32 {
+			powBaseDifficulty = parsed
+		}
+	}
+
+	powMaxDifficulty := 22
+	if v := os.Getenv("POW_MAX_DIFFICULTY"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= powBaseDifficulty && parsed <= 32 {
+			powMaxDifficulty = parsed
+		}
+	}
+	if powMaxDifficulty < powBaseDifficulty {
+		powMaxDifficulty = powBaseDifficulty
+	}
+
+	powChallengeTTL := 120
+	if v := os.Getenv("POW_CHALLENGE_TTL_SECONDS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			powChallengeTTL = parsed
+		}
+	}
+
 	return &Config{
 		Port:                     port,
 		JWTSecret:                jwtSecret,
@@ -77,5 +121,11 @@ func Load() *Config {
 		RecommendationServiceURL: recommendationURL,
 		AnalyticsServiceURL:      analyticsURL,
 		SagaServiceURL:           sagaURL,
+		InternalAPIKey:           internalAPIKey,
+		PoWEnabled:               os.Getenv("POW_ENABLED") != "false",
+		PoWSecret:                powSecret,
+		PoWBaseDifficulty:        powBaseDifficulty,
+		PoWMaxDifficulty:         powMaxDifficulty,
+		PoWChallengeTTL:          powChallengeTTL,
 	}
 }
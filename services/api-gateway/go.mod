@@ -4,7 +4,20 @@ go 1.21
 
 require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
+	go.opentelemetry.io/otel v1.19.0
 	shared v0.0.0
 )
 
+require (
+	github.com/felixge/httpsnoop v1.0.3 // indirect
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.45.0 // indirect
+	go.opentelemetry.io/otel/exporters/jaeger v1.16.0 // indirect
+	go.opentelemetry.io/otel/metric v1.19.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.16.0 // indirect
+	go.opentelemetry.io/otel/trace v1.19.0 // indirect
+	golang.org/x/sys v0.8.0 // indirect
+)
+
 replace shared => ../shared
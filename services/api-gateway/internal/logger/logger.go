@@ -35,6 +35,7 @@ const (
 	EventExpiredToken         EventType = "EXPIRED_TOKEN"
 	EventAdminActivity        EventType = "ADMIN_ACTIVITY"
 	EventTLSFailure           EventType = "TLS_FAILURE"
+	EventImpersonation        EventType = "IMPERSONATION"
 )
 
 // Logger is a structured logger with file rotation and security features
@@ -50,6 +51,10 @@ type Logger struct {
 	mu            sync.Mutex
 	file          *os.File
 	checksums     map[string]string // File path -> SHA256 checksum
+
+	// Set on loggers returned by WithFields: entries are written through root with extra fields
+	root   *Logger
+	fields map[string]interface{}
 }
 
 var (
@@ -221,8 +226,46 @@ func sanitizeMessage(message string) string {
 	return message
 }
 
+// WithFields returns a logger that adds the given fields to every entry it writes.
+// The returned logger shares files and rotation with the original one.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	root := l
+	merged := make(map[string]interface{}, len(fields))
+	if l.root != nil {
+		root = l.root
+		for k, v := range l.fields {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{root: root, fields: merged}
+}
+
+// WithImpersonation marks every entry as written on behalf of an impersonation session
+func (l *Logger) WithImpersonation(impersonatorID string, targetUserID string) *Logger {
+	return l.WithFields(map[string]interface{}{
+		"impersonation":      true,
+		"impersonatorID":     impersonatorID,
+		"impersonatedUserID": targetUserID,
+	})
+}
+
 // Log logs a structured event
 func (l *Logger) Log(level LogLevel, eventType EventType, message string, fields map[string]interface{}) {
+	if l.root != nil {
+		merged := make(map[string]interface{}, len(l.fields)+len(fields))
+		for k, v := range l.fields {
+			merged[k] = v
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+		l.root.Log(level, eventType, message, merged)
+		return
+	}
+
 	// Check if rotation is needed
 	if l.file != nil {
 		if err := l.rotateLog(); err != nil {
@@ -347,6 +390,20 @@ func (l *Logger) LogAdminActivity(adminID string, action string, resource string
 		fields)
 }
 
+// LogImpersonation logs an action performed during an admin impersonation session
+func (l *Logger) LogImpersonation(impersonatorID string, targetUserID string, action string, details map[string]interface{}) {
+	fields := map[string]interface{}{
+		"impersonation":      true,
+		"impersonatorID":     impersonatorID,
+		"impersonatedUserID": targetUserID,
+		"action":             action,
+	}
+	for k, v := range details {
+		fields[k] = v
+	}
+	l.Log(LevelAudit, EventImpersonation, "Impersonation", fields)
+}
+
 // LogTLSFailure logs a TLS connection failure
 func (l *Logger) LogTLSFailure(service string, errorMsg string, remoteAddr string) {
 	l.Log(LevelError, EventTLSFailure, "TLS connection failure",
@@ -11,6 +11,7 @@ import (
 
 	"api-gateway/config"
 	"api-gateway/internal/logger"
+	"shared/authz"
 )
 
 type contextKey string
@@ -21,9 +22,66 @@ type UserClaims struct {
 	UserID   string `json:"userId"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// Permissions resolved from the role by users-service at login
+	Permissions []string `json:"permissions"`
+	// Set only on impersonation tokens issued by users-service
+	ImpersonatorID     string `json:"impersonatorId,omitempty"`
+	ImpersonationWrite bool   `json:"impersonationWrite,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Response and forwarded request headers marking an impersonation session
+const (
+	HeaderImpersonatedBy   = "X-Impersonated-By"
+	HeaderImpersonatedUser = authz.HeaderImpersonatedUser
+	// Forwarded to the services, which add it to their audit log (authz.ImpersonationFrom)
+	HeaderImpersonatorID = authz.HeaderImpersonatorID
+)
+
+// withClaims stores the claims and the authorization principal derived from them
+func withClaims(ctx context.Context, claims *UserClaims) context.Context {
+	ctx = context.WithValue(ctx, UserContextKey, claims)
+	return authz.WithPrincipal(ctx, &authz.Principal{
+		UserID:         claims.UserID,
+		Role:           claims.Role,
+		Permissions:    claims.Permissions,
+		ImpersonatorID: claims.ImpersonatorID,
+	})
+}
+
+// markImpersonation adds the impersonation headers to the response so the UI can show a banner
+func markImpersonation(w http.ResponseWriter, claims *UserClaims) {
+	w.Header().Set(HeaderImpersonatedBy, claims.ImpersonatorID)
+	w.Header().Set(HeaderImpersonatedUser, claims.UserID)
+	w.Header().Add("Access-Control-Expose-Headers", HeaderImpersonatedBy+", "+HeaderImpersonatedUser)
+}
+
+// isRevoked reports whether the token's session was revoked. Impersonation tokens are also revoked
+// together with the impersonator's sessions.
+func isRevoked(claims *UserClaims) bool {
+	if claims.IssuedAt == nil {
+		return false
+	}
+	return IsSessionRevoked(claims.UserID, claims.IssuedAt.Time) ||
+		(claims.ImpersonatorID != "" && IsSessionRevoked(claims.ImpersonatorID, claims.IssuedAt.Time))
+}
+
+// isReadOnlyMethod reports whether the method does not modify state
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// RequestLogger returns a logger that marks every entry when the request runs under impersonation
+func RequestLogger(r *http.Request, log *logger.Logger) *logger.Logger {
+	if log == nil {
+		return nil
+	}
+	if claims, ok := r.Context().Value(UserContextKey).(*UserClaims); ok && claims != nil && claims.ImpersonatorID != "" {
+		return log.WithImpersonation(claims.ImpersonatorID, claims.UserID)
+	}
+	return log
+}
+
 // enableCORS adds CORS headers to the response
 func enableCORS(w http.ResponseWriter, r *http.Request) {
 	origin := r.Header.Get("Origin")
@@ -32,7 +90,7 @@ func enableCORS(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Access-Control-Allow-Origin", origin)
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-PoW-Challenge, X-PoW-Solution")
 	// Ne postavljaj Access-Control-Allow-Credentials ako je origin "*" jer browser to ne dozvoljava
 	if origin != "*" {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
@@ -149,9 +207,42 @@ func JWTAuth(cfg *config.Config, log *logger.Logger) func(http.HandlerFunc) http
 				return
 			}
 
+			// Check if the session was revoked (e.g. user reported a suspicious login)
+			if isRevoked(claims) {
+				if log != nil {
+					log.LogInvalidToken(tokenPrefix, "revoked session", ipAddress)
+				}
+				enableCORS(w, r)
+				http.Error(w, "session revoked", http.StatusUnauthorized)
+				return
+			}
+
+			// Impersonation sessions are marked on every response and read-only unless explicitly allowed
+			if claims.ImpersonatorID != "" {
+				markImpersonation(w, claims)
+				impLog := log
+				if log != nil {
+					impLog = log.WithImpersonation(claims.ImpersonatorID, claims.UserID)
+				}
+				if !isReadOnlyMethod(r.Method) && !claims.ImpersonationWrite {
+					if impLog != nil {
+						impLog.LogAccessControlFailure(claims.UserID, r.URL.Path, r.Method, "write blocked during read-only impersonation")
+					}
+					enableCORS(w, r)
+					http.Error(w, "forbidden: impersonation session is read-only", http.StatusForbidden)
+					return
+				}
+				if impLog != nil {
+					impLog.LogImpersonation(claims.ImpersonatorID, claims.UserID, "request", map[string]interface{}{
+						"method": r.Method,
+						"path":   r.URL.Path,
+						"ip":     ipAddress,
+					})
+				}
+			}
+
 			// Add user claims to context
-			ctx := context.WithValue(r.Context(), UserContextKey, claims)
-			next(w, r.WithContext(ctx))
+			next(w, r.WithContext(withClaims(r.Context(), claims)))
 		}
 	}
 }
@@ -161,33 +252,22 @@ func RequireAuth(cfg *config.Config, log *logger.Logger) func(http.HandlerFunc)
 	return JWTAuth(cfg, log)
 }
 
-// RequireRole checks if the user has the required role
-func RequireRole(requiredRole string, cfg *config.Config, log *logger.Logger) func(http.HandlerFunc) http.HandlerFunc {
-	return func(next http.HandlerFunc) http.HandlerFunc {
-		auth := JWTAuth(cfg, log)
-		return auth(func(w http.ResponseWriter, r *http.Request) {
-			claims, ok := r.Context().Value(UserContextKey).(*UserClaims)
-			if !ok || claims == nil {
-				if log != nil {
-					log.LogAccessControlFailure("", r.URL.Path, r.Method, "missing user claims in context")
-				}
-				enableCORS(w, r)
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			if claims.Role != requiredRole {
-				if log != nil {
-					log.LogAccessControlFailure(claims.UserID, r.URL.Path, r.Method, 
-						"insufficient permissions: required role "+requiredRole+", user role "+claims.Role)
-				}
-				enableCORS(w, r)
-				http.Error(w, "forbidden: "+requiredRole+" access required", http.StatusForbidden)
-				return
-			}
+// RequirePermission requires a valid JWT token whose permissions include the given one
+func RequirePermission(permission string, cfg *config.Config, log *logger.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	denied := func(w http.ResponseWriter, r *http.Request, p *authz.Principal, status int, reason string) {
+		userID := ""
+		if p != nil {
+			userID = p.UserID
+		}
+		if log != nil {
+			log.LogAccessControlFailure(userID, r.URL.Path, r.Method, "insufficient permissions: "+reason)
+		}
+		enableCORS(w, r)
+		authz.DefaultDenied(w, r, p, status, reason)
+	}
 
-			next(w, r)
-		})
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return JWTAuth(cfg, log)(authz.RequirePermission(permission, denied)(next))
 	}
 }
 
@@ -224,9 +304,15 @@ func OptionalAuth(cfg *config.Config, log *logger.Logger) func(http.HandlerFunc)
 							if log != nil {
 								log.LogExpiredToken(claims.UserID, getClientIP(r))
 							}
+						} else if isRevoked(claims) {
+							if log != nil {
+								log.LogInvalidToken(tokenPrefix, "revoked session", getClientIP(r))
+							}
 						} else {
-							ctx := context.WithValue(r.Context(), UserContextKey, claims)
-							r = r.WithContext(ctx)
+							if claims.ImpersonatorID != "" {
+								markImpersonation(w, claims)
+							}
+							r = r.WithContext(withClaims(r.Context(), claims))
 						}
 					} else if err != nil && log != nil {
 						reason := err.Error()
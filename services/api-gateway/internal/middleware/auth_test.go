@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"api-gateway/config"
+	"shared/authz"
+)
+
+const testJWTSecret = "test-secret"
+
+func signTestToken(t *testing.T, claims UserClaims, issuedAt time.Time) string {
+	t.Helper()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestAuthImpersonation(t *testing.T) {
+	cfg := &config.Config{JWTSecret: testJWTSecret}
+	issuedAt := time.Now().Add(-time.Hour)
+
+	sessionRevocations.mu.Lock()
+	sessionRevocations.revokedAt["revoked-admin"] = time.Now()
+	sessionRevocations.mu.Unlock()
+	t.Cleanup(func() {
+		sessionRevocations.mu.Lock()
+		delete(sessionRevocations.revokedAt, "revoked-admin")
+		sessionRevocations.mu.Unlock()
+	})
+
+	tests := []struct {
+		name   string
+		claims UserClaims
+		method string
+		// Expected JWTAuth status and whether OptionalAuth passes the principal on
+		wantStatus    int
+		wantPrincipal bool
+	}{
+		{
+			name:          "regular session",
+			claims:        UserClaims{UserID: "listener", Role: "USER"},
+			method:        http.MethodPost,
+			wantStatus:    http.StatusOK,
+			wantPrincipal: true,
+		},
+		{
+			name:          "read-only impersonation reads",
+			claims:        UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "admin"},
+			method:        http.MethodGet,
+			wantStatus:    http.StatusOK,
+			wantPrincipal: true,
+		},
+		{
+			name:          "read-only impersonation writes",
+			claims:        UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "admin"},
+			method:        http.MethodPost,
+			wantStatus:    http.StatusForbidden,
+			wantPrincipal: true,
+		},
+		{
+			name:          "write impersonation writes",
+			claims:        UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "admin", ImpersonationWrite: true},
+			method:        http.MethodPost,
+			wantStatus:    http.StatusOK,
+			wantPrincipal: true,
+		},
+		{
+			name:          "impersonator sessions revoked",
+			claims:        UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "revoked-admin"},
+			method:        http.MethodGet,
+			wantStatus:    http.StatusUnauthorized,
+			wantPrincipal: false,
+		},
+		{
+			name:          "user sessions revoked",
+			claims:        UserClaims{UserID: "revoked-admin", Role: "ADMIN"},
+			method:        http.MethodGet,
+			wantStatus:    http.StatusUnauthorized,
+			wantPrincipal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signTestToken(t, tt.claims, issuedAt)
+			newRequest := func() *http.Request {
+				req := httptest.NewRequest(tt.method, "/api/ratings", nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				return req
+			}
+
+			rec := httptest.NewRecorder()
+			JWTAuth(cfg, nil)(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})(rec, newRequest())
+			if rec.Code != tt.wantStatus {
+				t.Errorf("JWTAuth status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			gotPrincipal := false
+			OptionalAuth(cfg, nil)(func(w http.ResponseWriter, r *http.Request) {
+				_, gotPrincipal = authz.PrincipalFrom(r.Context())
+			})(httptest.NewRecorder(), newRequest())
+			if gotPrincipal != tt.wantPrincipal {
+				t.Errorf("OptionalAuth principal = %v, want %v", gotPrincipal, tt.wantPrincipal)
+			}
+		})
+	}
+}
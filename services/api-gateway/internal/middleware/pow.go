@@ -0,0 +1,319 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api-gateway/config"
+	"api-gateway/internal/logger"
+)
+
+// Proof-of-work protects public endpoints that send emails (registration, OTP, recovery)
+// from being used for email bombing, without an external CAPTCHA service.
+//
+// The client fetches a challenge, finds a solution such that
+// SHA-256(challenge + ":" + solution) starts with `difficulty` zero bits,
+// and sends both in the X-PoW-Challenge and X-PoW-Solution headers.
+// Challenges are stateless: the parameters are in the challenge itself and signed with HMAC.
+// Only the nonces of used challenges are remembered (until they expire) to prevent replay.
+
+const (
+	HeaderPoWChallenge = "X-PoW-Challenge"
+	HeaderPoWSolution  = "X-PoW-Solution"
+
+	powVersion           = "1"
+	powIPAbuseWindow     = 10 * time.Minute
+	powGlobalAbuseWindow = 1 * time.Minute
+)
+
+// Actions that can be protected by a proof-of-work challenge
+var PoWActions = map[string]bool{
+	"register":       true,
+	"login_otp":      true,
+	"recover":        true,
+	"password_reset": true,
+}
+
+var (
+	ErrPoWMissing   = errors.New("proof of work required")
+	ErrPoWMalformed = errors.New("malformed challenge")
+	ErrPoWSignature = errors.New("invalid challenge signature")
+	ErrPoWExpired   = errors.New("challenge expired")
+	ErrPoWMismatch  = errors.New("challenge issued for a different action or client")
+	ErrPoWReplayed  = errors.New("challenge already used")
+	ErrPoWTooWeak   = errors.New("solution does not meet the difficulty")
+)
+
+// PoWChallenge is returned by the challenge endpoint
+type PoWChallenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	Algorithm  string    `json:"algorithm"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type ProofOfWork struct {
+	enabled        bool
+	secret         []byte
+	baseDifficulty int
+	maxDifficulty  int
+	ttl            time.Duration
+
+	// Recent protected requests per "ip|action" and per "*|action", used to scale difficulty
+	ipAbuse     *RateLimiter
+	globalAbuse *RateLimiter
+
+	mu   sync.Mutex
+	used map[string]time.Time // nonce -> challenge expiry
+}
+
+func NewProofOfWork(cfg *config.Config) *ProofOfWork {
+	p := &ProofOfWork{
+		enabled:        cfg.PoWEnabled,
+		secret:         []byte(cfg.PoWSecret),
+		baseDifficulty: cfg.PoWBaseDifficulty,
+		maxDifficulty:  cfg.PoWMaxDifficulty,
+		ttl:            time.Duration(cfg.PoWChallengeTTL) * time.Second,
+		ipAbuse:        NewRateLimiter(1<<30, powIPAbuseWindow),
+		globalAbuse:    NewRateLimiter(1<<30, powGlobalAbuseWindow),
+		used:           make(map[string]time.Time),
+	}
+
+	go p.cleanup()
+
+	return p
+}
+
+func (p *ProofOfWork) cleanup() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.mu.Lock()
+		now := time.Now()
+		for nonce, expires := range p.used {
+			if now.After(expires) {
+				delete(p.used, nonce)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// Enabled reports whether challenges are enforced
+func (p *ProofOfWork) Enabled() bool {
+	return p.enabled
+}
+
+// Difficulty returns the number of leading zero bits required for the client and action.
+// Each doubling of recent requests from the same IP adds one bit, a global surge adds more.
+func (p *ProofOfWork) Difficulty(action, ip string) int {
+	difficulty := p.baseDifficulty
+
+	if n := p.ipAbuse.Count(ip + "|" + action); n > 3 {
+		difficulty += bits.Len(uint(n)) - 2
+	}
+
+	switch global := p.globalAbuse.Count("*|" + action); {
+	case global > 300:
+		difficulty += 4
+	case global > 60:
+		difficulty += 2
+	}
+
+	if difficulty > p.maxDifficulty {
+		difficulty = p.maxDifficulty
+	}
+	return difficulty
+}
+
+// recordAttempt counts a request to a protected endpoint towards the abuse level
+func (p *ProofOfWork) recordAttempt(action, ip string) {
+	p.ipAbuse.Allow(ip + "|" + action)
+	p.globalAbuse.Allow("*|" + action)
+}
+
+func (p *ProofOfWork) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// bindIP returns a short hash of the client IP so the challenge does not expose it
+func (p *ProofOfWork) bindIP(ip string) string {
+	return p.sign("ip:" + ip)[:16]
+}
+
+// Issue creates a signed challenge for the action and client
+func (p *ProofOfWork) Issue(action, ip string) (*PoWChallenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	difficulty := p.Difficulty(action, ip)
+	expiresAt := time.Now().Add(p.ttl)
+	payload := strings.Join([]string{
+		powVersion,
+		action,
+		p.bindIP(ip),
+		strconv.Itoa(difficulty),
+		strconv.FormatInt(expiresAt.Unix(), 10),
+		hex.EncodeToString(nonce),
+	}, ":")
+
+	return &PoWChallenge{
+		Challenge:  payload + ":" + p.sign(payload),
+		Difficulty: difficulty,
+		Algorithm:  "SHA-256",
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks the challenge signature, binding and expiry, the solution and that it was not used before
+func (p *ProofOfWork) Verify(action, ip, challenge, solution string) error {
+	if challenge == "" || solution == "" {
+		return ErrPoWMissing
+	}
+	if len(challenge) > 256 || len(solution) > 64 {
+		return ErrPoWMalformed
+	}
+
+	parts := strings.Split(challenge, ":")
+	if len(parts) != 7 || parts[0] != powVersion {
+		return ErrPoWMalformed
+	}
+	payload := strings.Join(parts[:6], ":")
+	if !hmac.Equal([]byte(parts[6]), []byte(p.sign(payload))) {
+		return ErrPoWSignature
+	}
+
+	difficulty, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return ErrPoWMalformed
+	}
+	expires, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		return ErrPoWMalformed
+	}
+	expiresAt := time.Unix(expires, 0)
+	if time.Now().After(expiresAt) {
+		return ErrPoWExpired
+	}
+	if parts[1] != action || !hmac.Equal([]byte(parts[2]), []byte(p.bindIP(ip))) {
+		return ErrPoWMismatch
+	}
+
+	sum := sha256.Sum256([]byte(challenge + ":" + solution))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return ErrPoWTooWeak
+	}
+
+	// Each challenge may be used once
+	nonce := parts[5]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, seen := p.used[nonce]; seen {
+		return ErrPoWReplayed
+	}
+	p.used[nonce] = expiresAt
+	return nil
+}
+
+func leadingZeroBits(hash []byte) int {
+	n := 0
+	for _, b := range hash {
+		if b == 0 {
+			n += 8
+			continue
+		}
+		n += bits.LeadingZeros8(b)
+		break
+	}
+	return n
+}
+
+// remoteIP returns the address of the direct client; X-Forwarded-For is not trusted here
+// because the gateway is the edge and the header is client controlled.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// ChallengeHandler issues proof-of-work challenges
+// GET /api/pow/challenge?action=register|login_otp|recover|password_reset
+func (p *ProofOfWork) ChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	action := r.URL.Query().Get("action")
+	if !PoWActions[action] {
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := p.Issue(action, remoteIP(r))
+	if err != nil {
+		http.Error(w, "failed to create challenge", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(challenge)
+}
+
+// RequireProofOfWork rejects requests without a valid solved challenge for the action
+func RequireProofOfWork(action string, p *ProofOfWork, log *logger.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !p.Enabled() || r.Method == http.MethodOptions {
+				next(w, r)
+				return
+			}
+
+			ip := remoteIP(r)
+			p.recordAttempt(action, ip)
+
+			err := p.Verify(action, ip, r.Header.Get(HeaderPoWChallenge), r.Header.Get(HeaderPoWSolution))
+			if err != nil {
+				if log != nil {
+					log.LogAccessControlFailure("", r.URL.Path, r.Method, fmt.Sprintf("proof of work failed: %v (ip %s)", err, ip))
+				}
+				enableCORS(w, r)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusPreconditionRequired)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"error":      err.Error(),
+					"action":     action,
+					"difficulty": p.Difficulty(action, ip),
+				})
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
@@ -78,6 +78,21 @@ func (rl *RateLimiter) Allow(key string) bool {
 	return true
 }
 
+// Count returns the number of requests recorded for the key within the window
+func (rl *RateLimiter) Count(key string) int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	now := time.Now()
+	count := 0
+	for _, t := range rl.requests[key] {
+		if now.Sub(t) < rl.window {
+			count++
+		}
+	}
+	return count
+}
+
 // RateLimit middleware limits requests per IP address (DoS protection)
 func RateLimit(maxReqs int, window time.Duration) func(http.HandlerFunc) http.HandlerFunc {
 	limiter := NewRateLimiter(maxReqs, window)
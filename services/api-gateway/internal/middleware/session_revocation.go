@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"shared/tracing"
+)
+
+// SessionRevocations keeps the set of users whose tokens issued before a point in time are no longer valid.
+// JWTs are stateless, so users-service publishes revocations ("this wasn't me", email change, ...)
+// and the gateway syncs them periodically.
+type SessionRevocations struct {
+	mu        sync.RWMutex
+	revokedAt map[string]time.Time
+	lastSync  time.Time
+}
+
+var sessionRevocations = &SessionRevocations{revokedAt: make(map[string]time.Time)}
+
+// IsSessionRevoked reports whether a token issued at issuedAt for userID has been revoked
+func IsSessionRevoked(userID string, issuedAt time.Time) bool {
+	sessionRevocations.mu.RLock()
+	defer sessionRevocations.mu.RUnlock()
+
+	revokedAt, ok := sessionRevocations.revokedAt[userID]
+	if !ok {
+		return false
+	}
+	// JWT iat has second precision
+	return issuedAt.Before(revokedAt.Truncate(time.Second))
+}
+
+// StartSessionRevocationSync polls users-service for revoked sessions until ctx is cancelled
+func StartSessionRevocationSync(ctx context.Context, usersServiceURL, internalAPIKey string, interval time.Duration) {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	client = tracing.HTTPClient(client)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := syncSessionRevocations(ctx, client, usersServiceURL, internalAPIKey); err != nil {
+			log.Printf("Session revocation sync failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func syncSessionRevocations(ctx context.Context, client *http.Client, usersServiceURL, internalAPIKey string) error {
+	sessionRevocations.mu.RLock()
+	since := sessionRevocations.lastSync
+	sessionRevocations.mu.RUnlock()
+
+	// Overlap the window a little so revocations written during the previous request are not missed
+	url := fmt.Sprintf("%s/internal/sessions/revocations?since=%d", usersServiceURL, since.Add(-time.Minute).Unix())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Internal-Key", internalAPIKey)
+	syncStarted := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("users-service returned %d", resp.StatusCode)
+	}
+
+	var revocations []struct {
+		UserID    string    `json:"userId"`
+		RevokedAt time.Time `json:"revokedAt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&revocations); err != nil {
+		return err
+	}
+
+	sessionRevocations.mu.Lock()
+	defer sessionRevocations.mu.Unlock()
+	for _, rev := range revocations {
+		if rev.RevokedAt.After(sessionRevocations.revokedAt[rev.UserID]) {
+			sessionRevocations.revokedAt[rev.UserID] = rev.RevokedAt
+		}
+	}
+	sessionRevocations.lastSync = syncStarted
+	return nil
+}
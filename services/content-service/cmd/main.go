@@ -1,20 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"content-service/config"
 	"content-service/internal/cache"
 	"content-service/internal/handler"
 	"content-service/internal/logger"
 	"content-service/internal/middleware"
+	"content-service/internal/model"
 	"content-service/internal/storage"
 	"content-service/internal/store"
+	"content-service/internal/upload"
+	"shared/authz"
 	"shared/tracing"
 )
 
@@ -54,10 +59,68 @@ func main() {
 	artistRepo := store.NewArtistRepository(dbStore.Database)
 	albumRepo := store.NewAlbumRepository(dbStore.Database)
 	songRepo := store.NewSongRepository(dbStore.Database)
-
-	// Initialize HDFS client (2.11)
-	hdfsClient := storage.NewHDFSClient(cfg.HDFSNamenodeURL)
-	log.Println("HDFS client initialized")
+	audioBlobRepo := store.NewAudioBlobRepository(dbStore.Database)
+	lyricsRepo := store.NewLyricsRepository(dbStore.Database)
+	searchRepo := store.NewSearchRepository(dbStore.Database)
+	playlistRepo := store.NewPlaylistRepository(dbStore.Database)
+	likeRepo := store.NewLikeRepository(dbStore.Database)
+	revisionRepo := store.NewRevisionRepository(dbStore.Database)
+
+	// Indexes for catalog lists and search
+	indexCtx, indexCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := songRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create song indexes: %v", err)
+	}
+	if err := albumRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create album indexes: %v", err)
+	}
+	if err := artistRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create artist indexes: %v", err)
+	}
+	if err := searchRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create search indexes: %v", err)
+	}
+	if err := playlistRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create playlist indexes: %v", err)
+	}
+	if err := likeRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create library indexes: %v", err)
+	}
+	if err := lyricsRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create lyrics indexes: %v", err)
+	}
+	if err := revisionRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create revision indexes: %v", err)
+	}
+	indexCancel()
+
+	// Audio storage backends: HDFS (2.11) and the local disk are always available, S3 when configured.
+	// New uploads go to STORAGE_BACKEND, existing files are read from the backend they were stored in.
+	stores := storage.NewStores(cfg.StorageBackend)
+	stores.Register(storage.BackendHDFS, storage.NewHDFSStore(storage.NewHDFSClient(cfg.HDFSNamenodeURL)))
+	stores.Register(storage.BackendLocal, storage.NewLocalStore(cfg.LocalStorageDir))
+	if cfg.S3Endpoint != "" {
+		stores.Register(storage.BackendS3, storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}))
+	}
+	if stores.Default() == nil {
+		log.Fatalf("Storage backend %q is not configured", cfg.StorageBackend)
+	}
+	log.Printf("Audio storage initialized, new uploads go to %s", cfg.StorageBackend)
+
+	// Songs created before storage references stored a path or URL in audioFileUrl
+	migrateCtx, migrateCancel := context.WithTimeout(context.Background(), 60*time.Second)
+	if migrated, err := songRepo.MigrateAudioFileURLs(migrateCtx); err != nil {
+		log.Printf("Warning: Failed to migrate song audio references: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Migrated audio references of %d songs", migrated)
+	}
+	migrateCancel()
 
 	// Initialize Redis cache (2.12)
 	redisCache, err := cache.NewRedisCache(cfg.RedisURL)
@@ -70,10 +133,34 @@ func main() {
 	}
 
 	// Initialize handlers
-	artistHandler := handler.NewArtistHandler(artistRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, appLogger)
-	albumHandler := handler.NewAlbumHandler(albumRepo, artistRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, appLogger)
-	songHandler := handler.NewSongHandler(songRepo, albumRepo, artistRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, cfg.RatingsServiceURL, cfg.AnalyticsServiceURL, cfg.SagaServiceURL, appLogger, hdfsClient, redisCache)
+	artistHandler := handler.NewArtistHandler(artistRepo, albumRepo, songRepo, revisionRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, appLogger, stores)
+	albumHandler := handler.NewAlbumHandler(albumRepo, artistRepo, songRepo, revisionRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, appLogger, stores)
+	imageHandler := handler.NewImageHandler(albumRepo, artistRepo, stores, appLogger)
+	searchHandler := handler.NewSearchHandler(searchRepo, appLogger)
+	playlistHandler := handler.NewPlaylistHandler(playlistRepo, songRepo, appLogger)
+	libraryHandler := handler.NewLibraryHandler(likeRepo, songRepo, albumRepo, artistRepo, cfg.AnalyticsServiceURL, cfg.RecommendationServiceURL, appLogger)
+	songHandler := handler.NewSongHandler(songRepo, audioBlobRepo, lyricsRepo, likeRepo, revisionRepo, albumRepo, artistRepo, cfg.SubscriptionsServiceURL, cfg.RecommendationServiceURL, cfg.RatingsServiceURL, cfg.AnalyticsServiceURL, cfg.SagaServiceURL, appLogger, stores, cfg.AudioVerifySampleRate, redisCache)
 	
+	// Resumable (tus) uploads are kept on the local disk until complete; stale ones expire
+	uploads, err := upload.NewStore(cfg.UploadDir, time.Duration(cfg.UploadExpirationHours)*time.Hour)
+	if err != nil {
+		log.Fatalf("Failed to initialize upload store: %v", err)
+	}
+	uploadHandler := handler.NewUploadHandler(songHandler, uploads)
+	go handler.StartUploadExpiry(context.Background(), uploads)
+
+	// Deleted songs, albums and artists stay in the trash for TRASH_RETENTION_DAYS, then they are purged
+	trashHandler := handler.NewTrashHandler(songHandler, albumHandler, artistHandler, time.Duration(cfg.TrashRetentionDays)*24*time.Hour, cfg.SharedSongPolicy, appLogger)
+	go handler.StartTrashPurge(context.Background(), trashHandler)
+	// Audio blobs without songs are deleted after a grace period
+	go handler.StartAudioBlobCleanup(context.Background(), songHandler)
+
+	// Every create, update and rollback of a song, album or artist is kept as a revision
+	revisionHandler := handler.NewRevisionHandler(revisionRepo, songHandler, albumHandler, artistHandler)
+
+	// Catalog export and import between environments, imports limited to CATALOG_IMPORT_MAX_MB
+	catalogTransferHandler := handler.NewCatalogTransferHandler(songHandler, albumHandler, artistHandler, int64(cfg.CatalogImportMaxMB)<<20, appLogger)
+
 	// Initialize most played handler (2.12)
 	var mostPlayedHandler *handler.MostPlayedHandler
 	if redisCache != nil {
@@ -88,46 +175,52 @@ func main() {
 		w.Write([]byte("content-service is running"))
 	})
 
-	// Static file server for music files
-	mux.HandleFunc("/music/", func(w http.ResponseWriter, r *http.Request) {
-		filePath := strings.TrimPrefix(r.URL.Path, "/music/")
-		if filePath == "" {
-			http.Error(w, "file path is required", http.StatusBadRequest)
-			return
-		}
+	// GET /audio/integrity?verify=full - report missing and corrupted audio files (requires JWT with catalog.song.write)
+	mux.HandleFunc("/audio/integrity", middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.AudioIntegrity)))
 
-		// For security, only allow specific file extensions
-		ext := strings.ToLower(filepath.Ext(filePath))
-		allowedExts := map[string]bool{
-			".mp3":  true,
-			".wav":  true,
-			".ogg":  true,
-			".m4a":  true,
-			".flac": true,
+	// Resumable audio uploads (tus 1.0)
+	// OPTIONS /uploads - tus capabilities (public)
+	// POST /uploads - create an upload for a song (requires JWT with catalog.song.write)
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			uploadHandler.Options(w, r)
+			return
 		}
+		middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(uploadHandler.CreateUpload))(w, r)
+	})
 
-		if !allowedExts[ext] {
-			http.Error(w, "file type not allowed", http.StatusForbidden)
+	// HEAD /uploads/{id} - offset to resume from
+	// PATCH /uploads/{id} - append a chunk, the last one stores the file
+	// DELETE /uploads/{id} - abandon the upload
+	// (all require JWT with catalog.song.write)
+	mux.HandleFunc("/uploads/", func(w http.ResponseWriter, r *http.Request) {
+		var next http.HandlerFunc
+		switch r.Method {
+		case http.MethodOptions:
+			uploadHandler.Options(w, r)
+			return
+		case http.MethodHead:
+			next = uploadHandler.GetUpload
+		case http.MethodPatch:
+			next = uploadHandler.PatchUpload
+		case http.MethodDelete:
+			next = uploadHandler.TerminateUpload
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-
-		// Serve files from a music directory (adjust path as needed)
-		musicDir := "./music" // Create this folder in your service directory
-		fullPath := filepath.Join(musicDir, filePath)
-
-		// Check if file exists
-		http.ServeFile(w, r, fullPath)
+		middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(next))(w, r)
 	})
 
 	// Album routes
-	// GET /albums - get all albums (public)
-	// POST /albums - create album (admin only, requires JWT)
+	// GET /albums - list albums (public, paginated: limit, cursor, sort, genre, artistId)
+	// POST /albums - create album (requires JWT with catalog.album.write)
 	mux.HandleFunc("/albums", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			albumHandler.GetAllAlbums(w, r)
 		case http.MethodPost:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(albumHandler.CreateAlbum))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogAlbumWrite)(albumHandler.CreateAlbum))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -137,8 +230,11 @@ func main() {
 	mux.HandleFunc("/albums/by-artist", albumHandler.GetAlbumsByArtist)
 
 	// GET /albums/{id} - get album by ID (public)
-	// PUT /albums/{id} - update album (admin only, requires JWT)
-	// DELETE /albums/{id} - delete album (admin only, requires JWT)
+	// PUT /albums/{id} - update album (requires JWT with catalog.album.write)
+	// DELETE /albums/{id} - move album to the trash (requires JWT with catalog.album.write)
+	// GET /albums/{id}/image/{64|300|640} - album artwork thumbnail (public)
+	// POST, DELETE /albums/{id}/image - upload (multipart "image") or remove artwork (requires JWT with catalog.album.write)
+	// GET /albums/{id}/revisions[/{number}], POST /albums/{id}/revisions/{number}/rollback - edit history (requires JWT with catalog.album.write)
 	mux.HandleFunc("/albums/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/albums/")
 		if path == "" {
@@ -146,27 +242,41 @@ func main() {
 			return
 		}
 
+		if strings.Contains(path, "/image") {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				imageHandler.GetImage(w, r)
+				return
+			}
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogAlbumWrite)(imageHandler.Image))(w, r)
+			return
+		}
+
+		if strings.Contains(path, "/revisions") {
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogAlbumWrite)(revisionHandler.Revisions))(w, r)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			albumHandler.GetAlbum(w, r)
 		case http.MethodPut:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(albumHandler.UpdateAlbum))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogAlbumWrite)(albumHandler.UpdateAlbum))(w, r)
 		case http.MethodDelete:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(albumHandler.DeleteAlbum))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogAlbumWrite)(albumHandler.DeleteAlbum))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
 	// Song routes
-	// GET /songs - get all songs (public)
-	// POST /songs - create song (admin only, requires JWT)
+	// GET /songs - list songs (public, paginated: limit, cursor, sort, genre, artistId, albumId)
+	// POST /songs - create song (requires JWT with catalog.song.write)
 	mux.HandleFunc("/songs", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			songHandler.GetAllSongs(w, r)
 		case http.MethodPost:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(songHandler.CreateSong))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.CreateSong))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -176,10 +286,17 @@ func main() {
 	mux.HandleFunc("/songs/by-album", songHandler.GetSongsByAlbum)
 
 	// GET /songs/{id} - get song by ID (public)
-	// PUT /songs/{id} - update song (admin only, requires JWT)
-	// DELETE /songs/{id} - delete song (admin only, requires JWT)
+	// PUT /songs/{id} - update song (requires JWT with catalog.song.write)
+	// DELETE /songs/{id} - move song to the trash (requires JWT with catalog.song.write)
 	// GET /songs/{id}/stream - stream song audio (public)
-	// POST /songs/{id}/upload - upload audio file to HDFS (admin only, requires JWT) (2.11)
+	// GET /songs/{id}/cover - cover image embedded in the audio file (public)
+	// GET /songs/{id}/hls/{master.m3u8|playlist.m3u8|segment_NNNNN.mp3} - HLS stream (public)
+	// POST /songs/{id}/hls - regenerate HLS output (requires JWT with catalog.song.write)
+	// POST /songs/{id}/storage - move the song's files to another storage backend (requires JWT with catalog.song.write)
+	// POST /songs/{id}/upload - upload audio file to the storage backend (requires JWT with catalog.song.write) (2.11)
+	// GET /songs/{id}/lyrics - languages with lyrics, GET /songs/{id}/lyrics/{language} - lyrics as JSON lines (public)
+	// PUT, DELETE /songs/{id}/lyrics/{language} - set (LRC or plain) or remove lyrics (requires JWT with catalog.song.write)
+	// GET /songs/{id}/revisions[/{number}], POST /songs/{id}/revisions/{number}/rollback - edit history (requires JWT with catalog.song.write)
 	mux.HandleFunc("/songs/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/songs/")
 		if path == "" {
@@ -187,6 +304,40 @@ func main() {
 			return
 		}
 
+		// Lyrics are public, setting them requires catalog.song.write
+		if strings.Contains(path, "/lyrics") {
+			if r.Method == http.MethodGet {
+				songHandler.LyricsRoute(w, r)
+				return
+			}
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.LyricsRoute))(w, r)
+			return
+		}
+
+		// Edit history and rollback
+		if strings.Contains(path, "/revisions") {
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(revisionHandler.Revisions))(w, r)
+			return
+		}
+
+		// HLS playlists and segments (public, the master playlist counts the play)
+		if strings.Contains(path, "/hls/") {
+			middleware.OptionalAuth(cfg)(songHandler.GetHLS)(w, r)
+			return
+		}
+
+		// Regenerate the HLS output from the uploaded file
+		if strings.HasSuffix(path, "/hls") {
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.RegenerateHLS))(w, r)
+			return
+		}
+
+		// Move the uploaded files to another storage backend
+		if strings.HasSuffix(path, "/storage") {
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.MigrateStorage))(w, r)
+			return
+		}
+
 		// Check if this is a streaming request
 		if strings.HasSuffix(path, "/stream") {
 			songID := strings.TrimSuffix(path, "/stream")
@@ -196,11 +347,17 @@ func main() {
 			return
 		}
 
+		// Cover image embedded in the uploaded audio file
+		if strings.HasSuffix(path, "/cover") {
+			songHandler.GetCover(w, r)
+			return
+		}
+
 		// Check if this is an upload request (2.11)
 		if strings.HasSuffix(path, "/upload") {
 			songID := strings.TrimSuffix(path, "/upload")
 			r.URL.Path = "/songs/" + songID + "/upload"
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(songHandler.UploadAudio))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.UploadAudio))(w, r)
 			return
 		}
 
@@ -208,9 +365,9 @@ func main() {
 		case http.MethodGet:
 			songHandler.GetSong(w, r)
 		case http.MethodPut:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(songHandler.UpdateSong))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.UpdateSong))(w, r)
 		case http.MethodDelete:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(songHandler.DeleteSong))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogSongWrite)(songHandler.DeleteSong))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -235,8 +392,8 @@ func main() {
 		json.NewEncoder(w).Encode(map[string]bool{"exists": exists})
 	})
 
-	// Direct delete endpoint for saga-service (2.13) - bypasses saga logic
-	mux.HandleFunc("/songs/internal/delete", func(w http.ResponseWriter, r *http.Request) {
+	// Direct delete endpoint for saga-service (2.13, X-Internal-Key) - bypasses saga logic
+	mux.HandleFunc("/songs/internal/delete", authz.RequireInternalKey(cfg.InternalAPIKey, func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 			return
@@ -248,7 +405,11 @@ func main() {
 			return
 		}
 
-		// Direct deletion from MongoDB (for saga-service use only)
+		// Direct deletion from MongoDB (for saga-service use only), songs are purged from the trash
+		song, err := songRepo.GetDeleted(r.Context(), songID)
+		if err != nil {
+			song, _ = songRepo.GetByID(r.Context(), songID)
+		}
 		if err := songRepo.Delete(r.Context(), songID); err != nil {
 			if err.Error() == "song not found" {
 				http.Error(w, "song not found", http.StatusNotFound)
@@ -257,9 +418,46 @@ func main() {
 			http.Error(w, "failed to delete song: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		// Deleting from MongoDB is the last saga step, the audio file is no longer needed
+		if song != nil {
+			songHandler.ReleaseSongAudio(song)
+			songHandler.DeleteSongLyrics(songID)
+			songHandler.DeleteLikes(model.LikeTypeSong, songID)
+		}
 
 		w.WriteHeader(http.StatusNoContent)
-	})
+	}))
+
+	// Song data for the saga backup (2.13, X-Internal-Key), songs in the trash included
+	// GET /songs/internal/backup?songId={id}
+	mux.HandleFunc("/songs/internal/backup", authz.RequireInternalKey(cfg.InternalAPIKey, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		songID := r.URL.Query().Get("songId")
+		if songID == "" {
+			http.Error(w, "songId parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		song, err := songRepo.GetByID(r.Context(), songID)
+		if err != nil {
+			song, err = songRepo.GetDeleted(r.Context(), songID)
+		}
+		if err != nil {
+			http.Error(w, "song not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(song)
+	}))
+
+	// Rating update endpoint for ratings-service (X-Internal-Key) - keeps the average used for sorting by rating
+	// PUT /songs/internal/rating?songId={id} {"averageRating": 4.5, "ratingCount": 2}
+	mux.HandleFunc("/songs/internal/rating", authz.RequireInternalKey(cfg.InternalAPIKey, songHandler.UpdateRating))
 
 	// Most played songs endpoint (2.12)
 	if mostPlayedHandler != nil {
@@ -279,23 +477,110 @@ func main() {
 		})
 	}
 
+	// GET /search?q= - full-text search across songs, albums and artists (public)
+	mux.HandleFunc("/search", searchHandler.Search)
+
+	// Playlist routes (owner-based authorization, checked by the handler)
+	// GET /playlists - playlists the caller owns or edits (requires JWT)
+	// POST /playlists - create playlist (requires JWT)
+	mux.HandleFunc("/playlists", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middleware.JWTAuth(cfg)(playlistHandler.GetMyPlaylists)(w, r)
+		case http.MethodPost:
+			middleware.JWTAuth(cfg)(playlistHandler.CreatePlaylist)(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// GET /playlists/public?ownerId={id} - list public playlists (public)
+	mux.HandleFunc("/playlists/public", playlistHandler.GetPublicPlaylists)
+
+	// Saga-service endpoints for the song deletion saga (2.13, X-Internal-Key)
+	// DELETE /playlists/internal/songs?songId={id} - remove a deleted song from all playlists
+	// POST /playlists/internal/songs/restore - compensation, put the removed entries back
+	mux.HandleFunc("/playlists/internal/songs", authz.RequireInternalKey(cfg.InternalAPIKey, playlistHandler.RemoveSongFromAll))
+	mux.HandleFunc("/playlists/internal/songs/restore", authz.RequireInternalKey(cfg.InternalAPIKey, playlistHandler.RestoreSongEntries))
+
+	// GET /playlists/{id} - get playlist with songs (public playlists for everyone, private for owner and editors)
+	// PUT /playlists/{id} - rename / change description, cover, visibility, collaboration (owner)
+	// DELETE /playlists/{id} - delete playlist (owner)
+	// POST /playlists/{id}/songs - add song (owner, editors of collaborative playlists)
+	// PUT /playlists/{id}/songs/{entryId} - move song to position (owner, editors)
+	// DELETE /playlists/{id}/songs/{entryId} - remove song (owner, editors)
+	// POST /playlists/{id}/editors - invite editor (owner)
+	// DELETE /playlists/{id}/editors/{userId} - remove editor (owner, or the editor leaving)
+	mux.HandleFunc("/playlists/", func(w http.ResponseWriter, r *http.Request) {
+		id, sub, subID := handler.ExtractPlaylistPath(r.URL.Path)
+		if id == "" {
+			http.Error(w, "playlist ID is required", http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case sub == "" && r.Method == http.MethodGet:
+			middleware.OptionalAuth(cfg)(playlistHandler.GetPlaylist)(w, r)
+		case sub == "" && r.Method == http.MethodPut:
+			middleware.JWTAuth(cfg)(playlistHandler.UpdatePlaylist)(w, r)
+		case sub == "" && r.Method == http.MethodDelete:
+			middleware.JWTAuth(cfg)(playlistHandler.DeletePlaylist)(w, r)
+		case sub == "songs" && subID == "" && r.Method == http.MethodPost:
+			middleware.JWTAuth(cfg)(playlistHandler.AddSong)(w, r)
+		case sub == "songs" && subID != "" && r.Method == http.MethodPut:
+			middleware.JWTAuth(cfg)(playlistHandler.MoveSong)(w, r)
+		case sub == "songs" && subID != "" && r.Method == http.MethodDelete:
+			middleware.JWTAuth(cfg)(playlistHandler.RemoveSong)(w, r)
+		case sub == "editors" && subID == "" && r.Method == http.MethodPost:
+			middleware.JWTAuth(cfg)(playlistHandler.AddEditor)(w, r)
+		case sub == "editors" && subID != "" && r.Method == http.MethodDelete:
+			middleware.JWTAuth(cfg)(playlistHandler.RemoveEditor)(w, r)
+		case sub == "" || sub == "songs" || sub == "editors":
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
+
+	// Library routes (the caller's own library, requires JWT)
+	// GET /library?type=song|album|artist - liked items, most recently added first (paginated: limit, cursor)
+	// GET /library/contains?type=song&ids=a,b - which of the items are liked
+	mux.HandleFunc("/library", middleware.JWTAuth(cfg)(libraryHandler.GetLibrary))
+	mux.HandleFunc("/library/contains", middleware.JWTAuth(cfg)(libraryHandler.Contains))
+
+	// PUT /library/{songs|albums|artists}/{id} - like
+	// DELETE /library/{songs|albums|artists}/{id} - unlike
+	mux.HandleFunc("/library/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			middleware.JWTAuth(cfg)(libraryHandler.Like)(w, r)
+		case http.MethodDelete:
+			middleware.JWTAuth(cfg)(libraryHandler.Unlike)(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
 	// Artist routes
-	// GET /artists - get all artists (public)
-	// POST /artists - create artist (admin only, requires JWT)
+	// GET /artists - list artists (public, paginated: limit, cursor, sort, genre)
+	// POST /artists - create artist (requires JWT with catalog.artist.write)
 	mux.HandleFunc("/artists", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			artistHandler.GetAllArtists(w, r)
 		case http.MethodPost:
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(artistHandler.CreateArtist))(w, r)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(artistHandler.CreateArtist))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
 	// GET /artists/{id} - get artist by ID (public)
-	// PUT /artists/{id} - update artist (admin only, requires JWT)
-	// DELETE /artists/{id} - delete artist (admin only, requires JWT)
+	// PUT /artists/{id} - update artist (requires JWT with catalog.artist.write)
+	// DELETE /artists/{id} - move artist to the trash (requires JWT with catalog.artist.write)
+	// GET /artists/{id}/image/{64|300|640} - artist photo thumbnail (public)
+	// POST, DELETE /artists/{id}/image - upload (multipart "image") or remove photo (requires JWT with catalog.artist.write)
+	// GET /artists/{id}/revisions[/{number}], POST /artists/{id}/revisions/{number}/rollback - edit history (requires JWT with catalog.artist.write)
 	mux.HandleFunc("/artists/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/artists/")
 		if path == "" {
@@ -303,20 +588,77 @@ func main() {
 			return
 		}
 
+		if strings.Contains(path, "/image") {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				imageHandler.GetImage(w, r)
+				return
+			}
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(imageHandler.Image))(w, r)
+			return
+		}
+
+		if strings.Contains(path, "/revisions") {
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(revisionHandler.Revisions))(w, r)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			artistHandler.GetArtist(w, r)
 		case http.MethodPut:
-			// PUT /artists/{id} - update artist (admin only, requires JWT)
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(artistHandler.UpdateArtist))(w, r)
+			// PUT /artists/{id} - update artist (requires JWT with catalog.artist.write)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(artistHandler.UpdateArtist))(w, r)
 		case http.MethodDelete:
-			// DELETE /artists/{id} - delete artist (admin only, requires JWT)
-			middleware.JWTAuth(cfg)(middleware.AdminOnly(artistHandler.DeleteArtist))(w, r)
+			// DELETE /artists/{id} - move artist to the trash (requires JWT with catalog.artist.write)
+			middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(artistHandler.DeleteArtist))(w, r)
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
+	// Trash of deleted catalog items (requires JWT with the write permission of the collection)
+	// GET /trash/{songs|albums|artists} - deleted items with the time they will be purged
+	// POST /trash/{songs|albums|artists}/{id}/restore - restore an item
+	// DELETE /trash/{songs|albums|artists}/{id} - delete an item permanently
+	mux.HandleFunc("/trash/", func(w http.ResponseWriter, r *http.Request) {
+		collection, _, _ := handler.ExtractTrashPath(r.URL.Path)
+		var permission string
+		switch collection {
+		case handler.TrashSongs:
+			permission = authz.CatalogSongWrite
+		case handler.TrashAlbums:
+			permission = authz.CatalogAlbumWrite
+		case handler.TrashArtists:
+			permission = authz.CatalogArtistWrite
+		default:
+			http.Error(w, "collection must be songs, albums or artists", http.StatusNotFound)
+			return
+		}
+		middleware.JWTAuth(cfg)(middleware.RequirePermission(permission)(trashHandler.Trash))(w, r)
+	})
+
+	// Album and artist deletion saga endpoints (saga-service only, X-Internal-Key; trash included)
+	// GET /catalog/internal/backup?albumId={id} or ?artistId={id}&sharedSongs=detach|delete - what the deletion removes
+	// POST /catalog/internal/detach - remove the artist from its shared albums and songs
+	// POST /catalog/internal/delete - delete the backed-up songs, albums and artist, keeping their files
+	// POST /catalog/internal/restore - write the backup back (compensation)
+	// POST /catalog/internal/release - delete the files of the deleted songs, albums and artist
+	mux.HandleFunc("/catalog/internal/backup", authz.RequireInternalKey(cfg.InternalAPIKey, trashHandler.CatalogBackup))
+	mux.HandleFunc("/catalog/internal/detach", authz.RequireInternalKey(cfg.InternalAPIKey, trashHandler.DetachCatalog))
+	mux.HandleFunc("/catalog/internal/delete", authz.RequireInternalKey(cfg.InternalAPIKey, trashHandler.DeleteCatalog))
+	mux.HandleFunc("/catalog/internal/restore", authz.RequireInternalKey(cfg.InternalAPIKey, trashHandler.RestoreCatalog))
+	mux.HandleFunc("/catalog/internal/release", authz.RequireInternalKey(cfg.InternalAPIKey, trashHandler.ReleaseCatalog))
+
+	// Catalog export and import (requires JWT with the write permissions of songs, albums and artists)
+	// GET /catalog/export?format=ndjson|csv&type=artists|albums|songs&audio=true - NDJSON, CSV or zip archive
+	// POST /catalog/import?format=ndjson|csv&type=artists|albums|songs&dryRun=true - NDJSON, CSV or zip archive
+	catalogWrite := func(next http.HandlerFunc) http.HandlerFunc {
+		return middleware.JWTAuth(cfg)(middleware.RequirePermission(authz.CatalogArtistWrite)(
+			middleware.RequirePermission(authz.CatalogAlbumWrite)(middleware.RequirePermission(authz.CatalogSongWrite)(next))))
+	}
+	mux.HandleFunc("/catalog/export", catalogWrite(catalogTransferHandler.Export))
+	mux.HandleFunc("/catalog/import", catalogWrite(catalogTransferHandler.Import))
+
 	log.Println("Content service running on port", cfg.Port)
 	
 	// Support HTTPS if certificates are provided
@@ -329,7 +671,7 @@ func main() {
 			Handler: mux,
 		}
 		// Wrap server handler with tracing middleware
-		server.Handler = tracing.HTTPMiddleware(mux)
+		server.Handler = tracing.HTTPMiddleware(middleware.ImpersonationAudit(appLogger, mux))
 		if err := server.ListenAndServeTLS(certFile, keyFile); err != nil {
 			if appLogger != nil {
 				appLogger.LogTLSFailure("content-service", err.Error(), "")
@@ -339,7 +681,7 @@ func main() {
 	} else {
 		log.Println("Starting HTTP server on port", cfg.Port)
 		// Wrap mux with tracing middleware
-		handler := tracing.HTTPMiddleware(mux)
+		handler := tracing.HTTPMiddleware(middleware.ImpersonationAudit(appLogger, mux))
 		log.Fatal(http.ListenAndServe(":"+cfg.Port, handler))
 	}
 }
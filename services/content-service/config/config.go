@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 type Config struct {
 	Port                    string
@@ -12,8 +15,29 @@ type Config struct {
 	RatingsServiceURL       string
 	AnalyticsServiceURL     string
 	HDFSNamenodeURL         string
+	// Audio storage: backend for new uploads (hdfs, local or s3) and the settings of each backend
+	StorageBackend  string
+	LocalStorageDir string
+	S3Endpoint      string
+	S3Bucket        string
+	S3Region        string
+	S3AccessKey     string
+	S3SecretKey     string
+	// Resumable (tus) uploads: directory of partial uploads and hours without progress before one expires
+	UploadDir             string
+	UploadExpirationHours int
+	// Share (0-1) of complete audio reads whose SHA-256 is verified
+	AudioVerifySampleRate float64
+	// Days deleted songs, albums and artists stay in the trash before they are purged
+	TrashRetentionDays int
+	// What purging an artist does with songs and albums shared with other artists: "detach" removes
+	// the artist from them, "delete" deletes them too
+	SharedSongPolicy        string
+	// Largest catalog import (NDJSON, CSV or archive with audio) accepted, in megabytes
+	CatalogImportMaxMB int
 	RedisURL                string
 	SagaServiceURL          string
+	InternalAPIKey          string // Expected X-Internal-Key on internal endpoints (saga-service, ratings-service)
 }
 
 func Load() *Config {
@@ -62,6 +86,59 @@ func Load() *Config {
 		hdfsNamenodeURL = "http://hdfs-namenode:9870"
 	}
 
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = "hdfs"
+	}
+
+	localStorageDir := os.Getenv("LOCAL_STORAGE_DIR")
+	if localStorageDir == "" {
+		localStorageDir = "/app/storage"
+	}
+
+	s3Bucket := os.Getenv("S3_BUCKET")
+	if s3Bucket == "" {
+		s3Bucket = "music"
+	}
+
+	uploadDir := os.Getenv("UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "/app/uploads"
+	}
+
+	uploadExpirationHours := 24
+	if hours := os.Getenv("UPLOAD_EXPIRATION_HOURS"); hours != "" {
+		if parsedHours, err := strconv.Atoi(hours); err == nil && parsedHours > 0 {
+			uploadExpirationHours = parsedHours
+		}
+	}
+
+	audioVerifySampleRate := 0.1
+	if rate := os.Getenv("AUDIO_VERIFY_SAMPLE_RATE"); rate != "" {
+		if parsedRate, err := strconv.ParseFloat(rate, 64); err == nil && parsedRate >= 0 && parsedRate <= 1 {
+			audioVerifySampleRate = parsedRate
+		}
+	}
+
+	trashRetentionDays := 30
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		if parsedDays, err := strconv.Atoi(days); err == nil && parsedDays > 0 {
+			trashRetentionDays = parsedDays
+		}
+	}
+
+	sharedSongPolicy := os.Getenv("SHARED_SONG_POLICY")
+	if sharedSongPolicy != "delete" {
+		sharedSongPolicy = "detach"
+	}
+
+	catalogImportMaxMB := 2048
+	if size := os.Getenv("CATALOG_IMPORT_MAX_MB"); size != "" {
+		if parsedSize, err := strconv.Atoi(size); err == nil && parsedSize > 0 {
+			catalogImportMaxMB = parsedSize
+		}
+	}
+
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
 		redisURL = "redis:6379"
@@ -72,6 +149,11 @@ func Load() *Config {
 		sagaServiceURL = "http://saga-service:8008"
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match saga-service and ratings-service
+	}
+
 	return &Config{
 		Port:                     port,
 		MongoDBURI:               mongoURI,
@@ -82,7 +164,21 @@ func Load() *Config {
 		RatingsServiceURL:        ratingsServiceURL,
 		AnalyticsServiceURL:      analyticsServiceURL,
 		HDFSNamenodeURL:          hdfsNamenodeURL,
+		StorageBackend:           storageBackend,
+		LocalStorageDir:          localStorageDir,
+		S3Endpoint:               os.Getenv("S3_ENDPOINT"), // S3 is only available when set
+		S3Bucket:                 s3Bucket,
+		S3Region:                 os.Getenv("S3_REGION"),
+		S3AccessKey:              os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:              os.Getenv("S3_SECRET_KEY"),
+		UploadDir:                uploadDir,
+		UploadExpirationHours:    uploadExpirationHours,
+		AudioVerifySampleRate:    audioVerifySampleRate,
+		TrashRetentionDays:       trashRetentionDays,
+		SharedSongPolicy:         sharedSongPolicy,
+		CatalogImportMaxMB:       catalogImportMaxMB,
 		RedisURL:                 redisURL,
 		SagaServiceURL:           sagaServiceURL,
+		InternalAPIKey:           internalAPIKey,
 	}
 }
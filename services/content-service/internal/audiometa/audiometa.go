@@ -0,0 +1,144 @@
+// Package audiometa reads the technical properties and the embedded tags of uploaded audio files
+// (MP3, FLAC, Ogg Vorbis/Opus, M4A and WAV) without external dependencies.
+package audiometa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	ErrInvalidFile       = errors.New("invalid or truncated audio file")
+)
+
+// Metadata is what could be read from an audio file; unknown values are zero
+type Metadata struct {
+	Format     string // mp3, flac, ogg, opus, m4a or wav
+	Duration   time.Duration
+	Bitrate    int // average, kbit/s
+	SampleRate int // Hz
+	Channels   int
+	Tags       Tags
+}
+
+// Tags are the ID3, Vorbis comment, iTunes or RIFF INFO tags of the file
+type Tags struct {
+	Title       string
+	Artist      string
+	Album       string
+	TrackNumber int
+	Year        int
+	Cover       *Picture
+}
+
+// Picture is an embedded cover image
+type Picture struct {
+	MIMEType string
+	Data     []byte
+}
+
+// Extension returns the file extension for the image type
+func (p *Picture) Extension() string {
+	if p.MIMEType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// Parse detects the format from the file contents and reads its metadata
+func Parse(data []byte) (*Metadata, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return parseFLAC(data)
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return parseOgg(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return parseMP4(data)
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return parseWAV(data)
+	case bytes.HasPrefix(data, []byte("ID3")) || len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		// FLAC files sometimes carry a leading ID3 tag as well
+		if skip := id3Size(data); skip > 0 && skip < len(data) && bytes.HasPrefix(data[skip:], []byte("fLaC")) {
+			return parseFLAC(data[skip:])
+		}
+		return parseMP3(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// averageBitrate returns the bitrate in kbit/s of audio bytes played over the duration
+func averageBitrate(audioBytes int64, d time.Duration) int {
+	if audioBytes <= 0 || d <= 0 {
+		return 0
+	}
+	return int(float64(audioBytes)*8/d.Seconds()/1000 + 0.5)
+}
+
+// samplesDuration converts a number of samples at the sample rate to a duration
+func samplesDuration(samples int64, sampleRate int) time.Duration {
+	if samples <= 0 || sampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(samples) / float64(sampleRate) * float64(time.Second))
+}
+
+// parseLeadingInt reads the number at the start of values like "3/12" (track) or "2021-05-04" (date)
+func parseLeadingInt(s string) int {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if end == 0 {
+		return 0
+	}
+	if end > 0 {
+		s = s[:end]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// parseYear reads the year of a date tag; only plausible years are accepted
+func parseYear(s string) int {
+	year := parseLeadingInt(s)
+	if year < 1000 || year > 9999 {
+		return 0
+	}
+	return year
+}
+
+// cleanText trims whitespace and the NUL padding of fixed-size fields
+func cleanText(s string) string {
+	return strings.TrimSpace(strings.TrimRight(s, "\x00"))
+}
+
+// merge fills the tags that are still empty from other (e.g. ID3v1 after ID3v2)
+func (t *Tags) merge(other Tags) {
+	if t.Title == "" {
+		t.Title = other.Title
+	}
+	if t.Artist == "" {
+		t.Artist = other.Artist
+	}
+	if t.Album == "" {
+		t.Album = other.Album
+	}
+	if t.TrackNumber == 0 {
+		t.TrackNumber = other.TrackNumber
+	}
+	if t.Year == 0 {
+		t.Year = other.Year
+	}
+	if t.Cover == nil {
+		t.Cover = other.Cover
+	}
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidFile, fmt.Sprintf(format, args...))
+}
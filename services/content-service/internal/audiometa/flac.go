@@ -0,0 +1,159 @@
+package audiometa
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+)
+
+// FLAC metadata block types
+const (
+	flacStreamInfo    = 0
+	flacVorbisComment = 4
+	flacPicture       = 6
+)
+
+func parseFLAC(data []byte) (*Metadata, error) {
+	meta := &Metadata{Format: "flac"}
+	var totalSamples int64
+
+	pos := 4 // "fLaC"
+	foundStreamInfo := false
+	for {
+		if pos+4 > len(data) {
+			return nil, invalid("FLAC metadata is truncated")
+		}
+		header := data[pos]
+		length := int(data[pos+1])<<16 | int(data[pos+2])<<8 | int(data[pos+3])
+		pos += 4
+		if pos+length > len(data) {
+			return nil, invalid("FLAC metadata block is truncated")
+		}
+		block := data[pos : pos+length]
+		pos += length
+
+		switch header & 0x7F {
+		case flacStreamInfo:
+			if len(block) < 18 {
+				return nil, invalid("FLAC STREAMINFO is too short")
+			}
+			// 20 bits sample rate, 3 bits channels-1, 5 bits bits per sample-1, 36 bits total samples
+			v := binary.BigEndian.Uint64(block[10:18])
+			meta.SampleRate = int(v >> 44)
+			meta.Channels = int(v>>41&0x7) + 1
+			totalSamples = int64(v & 0xFFFFFFFFF)
+			foundStreamInfo = true
+		case flacVorbisComment:
+			meta.Tags.merge(parseVorbisComment(block))
+		case flacPicture:
+			if picture, pictureType := parseFLACPicture(block); picture != nil && (meta.Tags.Cover == nil || pictureType == 3) {
+				meta.Tags.Cover = picture
+			}
+		}
+
+		if header&0x80 != 0 { // last metadata block
+			break
+		}
+	}
+	if !foundStreamInfo {
+		return nil, invalid("FLAC STREAMINFO is missing")
+	}
+
+	meta.Duration = samplesDuration(totalSamples, meta.SampleRate)
+	meta.Bitrate = averageBitrate(int64(len(data)-pos), meta.Duration)
+	return meta, nil
+}
+
+// parseFLACPicture decodes a FLAC PICTURE block (also used base64 encoded in Vorbis comments)
+func parseFLACPicture(b []byte) (*Picture, uint32) {
+	read := func(n int) []byte {
+		if n < 0 || len(b) < n {
+			b = nil
+			return nil
+		}
+		v := b[:n]
+		b = b[n:]
+		return v
+	}
+	readUint32 := func() int {
+		v := read(4)
+		if v == nil {
+			return -1
+		}
+		return int(binary.BigEndian.Uint32(v))
+	}
+
+	pictureType := readUint32()
+	mime := read(readUint32())
+	read(readUint32()) // description
+	read(16)           // width, height, depth, colors
+	data := read(readUint32())
+	if pictureType < 0 || len(data) == 0 {
+		return nil, 0
+	}
+	mimeType := strings.ToLower(string(mime))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = sniffImage(data)
+	}
+	return &Picture{MIMEType: mimeType, Data: data}, uint32(pictureType)
+}
+
+// parseVorbisComment reads a Vorbis comment block (FLAC, Ogg Vorbis and Opus): a vendor string
+// followed by KEY=value fields, all with little endian lengths
+func parseVorbisComment(b []byte) Tags {
+	var tags Tags
+	if len(b) < 8 {
+		return tags
+	}
+	vendorLength := int(binary.LittleEndian.Uint32(b))
+	if 4+vendorLength+4 > len(b) {
+		return tags
+	}
+	b = b[4+vendorLength:]
+	count := int(binary.LittleEndian.Uint32(b))
+	b = b[4:]
+
+	for i := 0; i < count && len(b) >= 4; i++ {
+		length := int(binary.LittleEndian.Uint32(b))
+		if 4+length > len(b) || length < 0 {
+			break
+		}
+		field := string(b[4 : 4+length])
+		b = b[4+length:]
+
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToUpper(key) {
+		case "TITLE":
+			if tags.Title == "" {
+				tags.Title = value
+			}
+		case "ARTIST":
+			if tags.Artist == "" {
+				tags.Artist = value
+			}
+		case "ALBUM":
+			if tags.Album == "" {
+				tags.Album = value
+			}
+		case "TRACKNUMBER":
+			tags.TrackNumber = parseLeadingInt(value)
+		case "DATE", "YEAR":
+			if year := parseYear(value); year != 0 {
+				tags.Year = year
+			}
+		case "METADATA_BLOCK_PICTURE":
+			raw, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				continue
+			}
+			if picture, pictureType := parseFLACPicture(raw); picture != nil && (tags.Cover == nil || pictureType == 3) {
+				tags.Cover = picture
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,354 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// AudioFrame is one independently decodable frame of compressed audio
+type AudioFrame struct {
+	Data    []byte // for AAC the raw frame with an ADTS header in front
+	Samples int
+}
+
+// FrameStream is the sequence of audio frames of an MP3 or AAC file, used to cut the file into
+// segments at frame boundaries without re-encoding
+type FrameStream struct {
+	Codec      string // "mp3" or "aac"
+	ObjectType int    // MPEG-4 audio object type of AAC (2 = AAC-LC)
+	SampleRate int
+	Channels   int
+	Frames     []AudioFrame
+}
+
+// Frames splits an MP3 or M4A (AAC) file into its audio frames.
+// Other formats, including Apple Lossless in M4A, return ErrUnsupportedFormat.
+func Frames(data []byte) (*FrameStream, error) {
+	switch {
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return mp4AACFrames(data)
+	case bytes.HasPrefix(data, []byte("ID3")) || len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		if skip := id3Size(data); skip > 0 && skip < len(data) && bytes.HasPrefix(data[skip:], []byte("fLaC")) {
+			return nil, ErrUnsupportedFormat
+		}
+		return mp3Frames(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+func mp3Frames(data []byte) (*FrameStream, error) {
+	start := id3Size(data)
+	end := len(data)
+	if _, ok := parseID3v1(data); ok {
+		end -= 128
+	}
+	if start > end {
+		return nil, invalid("ID3 tag is larger than the file")
+	}
+	data = data[:end]
+
+	pos, first, ok := findFirstFrame(data, start)
+	if !ok {
+		return nil, invalid("no MPEG audio frame found")
+	}
+	stream := &FrameStream{Codec: "mp3", SampleRate: first.sampleRate, Channels: first.channels}
+
+	// The Xing/Info frame carries no audio
+	if _, _, ok := vbrHeader(data[pos:], first); ok {
+		pos += first.size
+	}
+
+	for pos+4 <= len(data) {
+		f, ok := parseMPEGFrame(data[pos:])
+		if !ok || f.sampleRate != first.sampleRate || f.layer != first.layer {
+			// Garbage between frames: resynchronise on the next valid frame
+			next, _, found := findFirstFrame(data, pos+1)
+			if !found {
+				break
+			}
+			pos = next
+			continue
+		}
+		if pos+f.size > len(data) {
+			break // truncated last frame
+		}
+		stream.Frames = append(stream.Frames, AudioFrame{Data: data[pos : pos+f.size], Samples: f.samples})
+		pos += f.size
+	}
+	if len(stream.Frames) == 0 {
+		return nil, invalid("no MPEG audio frame found")
+	}
+	return stream, nil
+}
+
+// ADTS sampling frequency indexes
+var adtsSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+func mp4AACFrames(data []byte) (*FrameStream, error) {
+	moov := mp4Child(data, "moov")
+	if moov == nil {
+		return nil, invalid("MP4 movie box is missing")
+	}
+
+	var stbl, mdhd []byte
+	for _, trak := range mp4Atoms(moov) {
+		if trak.kind != "trak" {
+			continue
+		}
+		mdia := mp4Child(trak.body, "mdia")
+		if hdlr := mp4Child(mdia, "hdlr"); len(hdlr) >= 12 && string(hdlr[8:12]) == "soun" {
+			stbl = mp4Path(mdia, "minf", "stbl")
+			mdhd = mp4Child(mdia, "mdhd")
+			break
+		}
+	}
+	if stbl == nil {
+		return nil, invalid("MP4 file has no audio track")
+	}
+
+	// Sample entry: only AAC (mp4a) can be packed into ADTS
+	stsd := mp4Child(stbl, "stsd")
+	if len(stsd) < 8 {
+		return nil, invalid("MP4 sample description is missing")
+	}
+	entries := mp4Atoms(stsd[8:])
+	if len(entries) == 0 || entries[0].kind != "mp4a" || len(entries[0].body) < 28 {
+		return nil, ErrUnsupportedFormat
+	}
+	objectType, rateIndex, channelConfig, ok := parseESDS(mp4Child(entries[0].body[28:], "esds"))
+	if !ok || rateIndex >= len(adtsSampleRates) || objectType < 1 || objectType > 4 {
+		return nil, ErrUnsupportedFormat
+	}
+
+	// Samples live in the media data, so their sizes cannot add up to more than it holds
+	sizes := mp4SampleSizes(mp4Child(stbl, "stsz"), mp4MediaSize(data))
+	offsets := mp4SampleOffsets(stbl, sizes)
+	if len(sizes) == 0 || len(offsets) != len(sizes) {
+		return nil, invalid("MP4 sample tables are missing or inconsistent")
+	}
+	durations := mp4SampleDurations(mp4Child(stbl, "stts"), len(sizes))
+	sampleRate := adtsSampleRates[rateIndex]
+	// Durations are in the track timescale, which is normally the sample rate
+	if timescale := mp4Timescale(mdhd); timescale > 0 && timescale != sampleRate {
+		for i, d := range durations {
+			durations[i] = int(int64(d) * int64(sampleRate) / int64(timescale))
+		}
+	}
+
+	stream := &FrameStream{
+		Codec:      "aac",
+		ObjectType: objectType,
+		SampleRate: sampleRate,
+		Channels:   channelConfig,
+		Frames:     make([]AudioFrame, 0, min(len(sizes), maxPreallocatedSamples)),
+	}
+	for i, size := range sizes {
+		offset := offsets[i]
+		if offset < 0 || offset+int64(size) > int64(len(data)) {
+			break // truncated file
+		}
+		frame := make([]byte, 7+size)
+		writeADTSHeader(frame, objectType, rateIndex, channelConfig)
+		copy(frame[7:], data[offset:offset+int64(size)])
+		stream.Frames = append(stream.Frames, AudioFrame{Data: frame, Samples: durations[i]})
+	}
+	if len(stream.Frames) == 0 {
+		return nil, invalid("MP4 audio data is missing")
+	}
+	return stream, nil
+}
+
+// parseESDS reads the AudioSpecificConfig from the decoder configuration of an esds box
+func parseESDS(b []byte) (objectType, rateIndex, channelConfig int, ok bool) {
+	if len(b) < 4 {
+		return 0, 0, 0, false
+	}
+	b = b[4:] // full box header
+
+	// descriptor reads a tag and its variable length size (7 bits per byte)
+	descriptor := func() (byte, []byte) {
+		if len(b) < 2 {
+			return 0, nil
+		}
+		tag := b[0]
+		size, i := 0, 1
+		for ; i < 5 && i < len(b); i++ {
+			size = size<<7 | int(b[i]&0x7F)
+			if b[i]&0x80 == 0 {
+				i++
+				break
+			}
+		}
+		if i+size > len(b) {
+			return 0, nil
+		}
+		body := b[i : i+size]
+		return tag, body
+	}
+
+	tag, es := descriptor()
+	if tag != 0x03 || len(es) < 3 {
+		return 0, 0, 0, false
+	}
+	flags := es[2]
+	es = es[3:]
+	if flags&0x80 != 0 && len(es) >= 2 { // stream dependence
+		es = es[2:]
+	}
+	if flags&0x40 != 0 && len(es) >= 1 { // URL
+		es = es[min(len(es), 1+int(es[0])):]
+	}
+	if flags&0x20 != 0 && len(es) >= 2 { // OCR stream
+		es = es[2:]
+	}
+
+	b = es
+	tag, config := descriptor()
+	if tag != 0x04 || len(config) < 13 {
+		return 0, 0, 0, false
+	}
+	b = config[13:]
+	tag, asc := descriptor()
+	if tag != 0x05 || len(asc) < 2 {
+		return 0, 0, 0, false
+	}
+
+	// AudioSpecificConfig: 5 bits object type, 4 bits sampling frequency index, 4 bits channels
+	objectType = int(asc[0] >> 3)
+	rateIndex = int(asc[0]&0x07)<<1 | int(asc[1]>>7)
+	channelConfig = int(asc[1]>>3) & 0x0F
+	return objectType, rateIndex, channelConfig, objectType != 31 && rateIndex != 15
+}
+
+// writeADTSHeader writes a 7 byte ADTS header (no CRC) for a frame of len(frame) bytes including the header
+func writeADTSHeader(frame []byte, objectType, rateIndex, channelConfig int) {
+	length := len(frame)
+	frame[0] = 0xFF
+	frame[1] = 0xF1 // MPEG-4, layer 0, no CRC
+	frame[2] = byte((objectType-1)<<6 | rateIndex<<2 | channelConfig>>2)
+	frame[3] = byte((channelConfig&3)<<6 | length>>11)
+	frame[4] = byte(length >> 3)
+	frame[5] = byte((length&7)<<5 | 0x1F) // buffer fullness 0x7FF (VBR)
+	frame[6] = 0xFC
+}
+
+// maxPreallocatedSamples caps the capacity reserved for sample tables up front; longer tables grow
+// as they are read
+const maxPreallocatedSamples = 1 << 16
+
+// mp4MediaSize is the size of the media data (mdat) boxes of a file
+func mp4MediaSize(data []byte) int64 {
+	var size int64
+	for _, atom := range mp4Atoms(data) {
+		if atom.kind == "mdat" {
+			size += int64(len(atom.body))
+		}
+	}
+	return size
+}
+
+// mp4SampleSizes reads the stsz box. The count comes from the file, so a table whose samples add up to
+// more than limit bytes (the media data) is rejected before anything is allocated for it.
+func mp4SampleSizes(stsz []byte, limit int64) []int {
+	if len(stsz) < 12 {
+		return nil
+	}
+	fixed := int64(binary.BigEndian.Uint32(stsz[4:8]))
+	count := int64(binary.BigEndian.Uint32(stsz[8:12]))
+	if fixed == 0 && 12+count*4 > int64(len(stsz)) || fixed != 0 && count*fixed > limit {
+		return nil
+	}
+	sizes := make([]int, 0, min(count, maxPreallocatedSamples))
+	var total int64
+	for i := int64(0); i < count; i++ {
+		size := fixed
+		if fixed == 0 {
+			size = int64(binary.BigEndian.Uint32(stsz[12+i*4:]))
+		}
+		if total += size; total > limit {
+			return nil
+		}
+		sizes = append(sizes, int(size))
+	}
+	return sizes
+}
+
+// mp4SampleOffsets computes the file offset of every sample from the chunk offsets (stco/co64)
+// and the sample-to-chunk table (stsc)
+func mp4SampleOffsets(stbl []byte, sizes []int) []int64 {
+	var chunks []int64
+	if stco := mp4Child(stbl, "stco"); len(stco) >= 8 {
+		count := int(binary.BigEndian.Uint32(stco[4:8]))
+		for i := 0; i < count && 8+i*4+4 <= len(stco); i++ {
+			chunks = append(chunks, int64(binary.BigEndian.Uint32(stco[8+i*4:])))
+		}
+	} else if co64 := mp4Child(stbl, "co64"); len(co64) >= 8 {
+		count := int(binary.BigEndian.Uint32(co64[4:8]))
+		for i := 0; i < count && 8+i*8+8 <= len(co64); i++ {
+			chunks = append(chunks, int64(binary.BigEndian.Uint64(co64[8+i*8:])))
+		}
+	}
+
+	stsc := mp4Child(stbl, "stsc")
+	if len(chunks) == 0 || len(stsc) < 8 {
+		return nil
+	}
+	type run struct{ firstChunk, samplesPerChunk int }
+	var runs []run
+	count := int(binary.BigEndian.Uint32(stsc[4:8]))
+	for i := 0; i < count && 8+i*12+12 <= len(stsc); i++ {
+		e := stsc[8+i*12:]
+		runs = append(runs, run{int(binary.BigEndian.Uint32(e[0:4])), int(binary.BigEndian.Uint32(e[4:8]))})
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	offsets := make([]int64, 0, len(sizes))
+	r := 0
+	for chunk := 1; chunk <= len(chunks) && len(offsets) < len(sizes); chunk++ {
+		for r+1 < len(runs) && runs[r+1].firstChunk <= chunk {
+			r++
+		}
+		offset := chunks[chunk-1]
+		for s := 0; s < runs[r].samplesPerChunk && len(offsets) < len(sizes); s++ {
+			offsets = append(offsets, offset)
+			offset += int64(sizes[len(offsets)-1])
+		}
+	}
+	return offsets
+}
+
+// mp4Timescale reads the timescale of an mdhd box
+func mp4Timescale(mdhd []byte) int {
+	if len(mdhd) >= 24 && mdhd[0] == 1 {
+		return int(binary.BigEndian.Uint32(mdhd[20:24]))
+	}
+	if len(mdhd) >= 16 {
+		return int(binary.BigEndian.Uint32(mdhd[12:16]))
+	}
+	return 0
+}
+
+// mp4SampleDurations reads the stts box; AAC frames default to 1024 samples
+func mp4SampleDurations(stts []byte, n int) []int {
+	durations := make([]int, n)
+	for i := range durations {
+		durations[i] = 1024
+	}
+	if len(stts) < 8 {
+		return durations
+	}
+	count := int(binary.BigEndian.Uint32(stts[4:8]))
+	i := 0
+	for e := 0; e < count && 8+e*8+8 <= len(stts); e++ {
+		samples := int(binary.BigEndian.Uint32(stts[8+e*8:]))
+		delta := int(binary.BigEndian.Uint32(stts[12+e*8:]))
+		for j := 0; j < samples && i < n; j++ {
+			durations[i] = delta
+			i++
+		}
+	}
+	return durations
+}
@@ -0,0 +1,268 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3Size returns the size of the ID3v2 tag at the start of data, including header and footer, or 0
+func id3Size(data []byte) int {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return 0
+	}
+	size := 10 + synchsafe(data[6:10])
+	if data[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size
+}
+
+// synchsafe decodes a 4 byte integer with 7 significant bits per byte
+func synchsafe(b []byte) int {
+	return int(b[0]&0x7F)<<21 | int(b[1]&0x7F)<<14 | int(b[2]&0x7F)<<7 | int(b[3]&0x7F)
+}
+
+// removeUnsync reverses the ID3 unsynchronisation scheme (0xFF 0x00 -> 0xFF)
+func removeUnsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+// parseID3v2 reads the ID3v2.2, 2.3 or 2.4 tag at the start of data
+func parseID3v2(data []byte) Tags {
+	var tags Tags
+	size := id3Size(data)
+	if size == 0 || size > len(data) {
+		return tags
+	}
+	version := data[3]
+	flags := data[5]
+	body := data[10:size]
+	if flags&0x10 != 0 {
+		body = body[:len(body)-10]
+	}
+	if version < 4 && flags&0x80 != 0 {
+		body = removeUnsync(body)
+	}
+
+	// Extended header
+	if flags&0x40 != 0 && len(body) >= 4 {
+		skip := 0
+		if version == 3 {
+			skip = int(binary.BigEndian.Uint32(body[0:4])) + 4
+		} else if version == 4 {
+			skip = synchsafe(body[0:4])
+		}
+		if skip > len(body) {
+			return tags
+		}
+		body = body[skip:]
+	}
+
+	headerSize := 10
+	if version == 2 {
+		headerSize = 6
+	}
+	var coverType byte = 0xFF
+	for len(body) >= headerSize && body[0] != 0 {
+		var id string
+		var frameSize int
+		var formatFlags byte
+		if version == 2 {
+			id = string(body[0:3])
+			frameSize = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		} else {
+			id = string(body[0:4])
+			if version == 4 {
+				frameSize = synchsafe(body[4:8])
+			} else {
+				frameSize = int(binary.BigEndian.Uint32(body[4:8]))
+			}
+			formatFlags = body[9]
+		}
+		if frameSize <= 0 || headerSize+frameSize > len(body) {
+			break
+		}
+		frame := body[headerSize : headerSize+frameSize]
+		body = body[headerSize+frameSize:]
+
+		if version == 3 {
+			if formatFlags&0xC0 != 0 { // compressed or encrypted
+				continue
+			}
+			if formatFlags&0x20 != 0 && len(frame) > 0 { // grouping identity
+				frame = frame[1:]
+			}
+		}
+		if version == 4 {
+			if formatFlags&0x0C != 0 { // compressed or encrypted
+				continue
+			}
+			if formatFlags&0x40 != 0 && len(frame) > 0 { // grouping identity
+				frame = frame[1:]
+			}
+			if formatFlags&0x01 != 0 && len(frame) >= 4 { // data length indicator
+				frame = frame[4:]
+			}
+			if formatFlags&0x02 != 0 {
+				frame = removeUnsync(frame)
+			}
+		}
+
+		switch id {
+		case "TIT2", "TT2":
+			tags.Title = id3Text(frame)
+		case "TPE1", "TP1":
+			tags.Artist = id3Text(frame)
+		case "TALB", "TAL":
+			tags.Album = id3Text(frame)
+		case "TRCK", "TRK":
+			tags.TrackNumber = parseLeadingInt(id3Text(frame))
+		case "TYER", "TYE", "TDRC":
+			if year := parseYear(id3Text(frame)); year != 0 {
+				tags.Year = year
+			}
+		case "APIC", "PIC":
+			// Prefer the front cover (picture type 3) over other pictures
+			if picture, pictureType := id3Picture(frame, id == "PIC"); picture != nil && (tags.Cover == nil || pictureType == 3 && coverType != 3) {
+				tags.Cover = picture
+				coverType = pictureType
+			}
+		}
+	}
+	return tags
+}
+
+// id3Text decodes a text frame; of multiple values only the first is used
+func id3Text(frame []byte) string {
+	if len(frame) < 1 {
+		return ""
+	}
+	text := id3Decode(frame[0], frame[1:])
+	if i := strings.IndexByte(text, 0); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// id3Decode decodes text in the given ID3 encoding: 0 ISO-8859-1, 1 UTF-16 with BOM, 2 UTF-16BE, 3 UTF-8
+func id3Decode(encoding byte, b []byte) string {
+	switch encoding {
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(b) >= 2 && (b[0] == 0xFF && b[1] == 0xFE || b[0] == 0xFE && b[1] == 0xFF) {
+			bigEndian = b[0] == 0xFE
+			b = b[2:]
+		}
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			if bigEndian {
+				units = append(units, binary.BigEndian.Uint16(b[i:]))
+			} else {
+				units = append(units, binary.LittleEndian.Uint16(b[i:]))
+			}
+		}
+		return string(utf16.Decode(units))
+	case 3:
+		return string(b)
+	default:
+		return latin1(b)
+	}
+}
+
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// id3Terminator returns the index after the NUL terminator of a string in the encoding, or -1
+func id3Terminator(encoding byte, b []byte) int {
+	if encoding == 1 || encoding == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return i + 2
+			}
+		}
+		return -1
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return i + 1
+	}
+	return -1
+}
+
+// id3Picture decodes an APIC frame (or PIC in ID3v2.2) and returns the picture and its type
+func id3Picture(frame []byte, v22 bool) (*Picture, byte) {
+	if len(frame) < 4 {
+		return nil, 0
+	}
+	encoding := frame[0]
+	rest := frame[1:]
+
+	var mime string
+	if v22 {
+		switch strings.ToUpper(string(rest[0:3])) {
+		case "PNG":
+			mime = "image/png"
+		default:
+			mime = "image/jpeg"
+		}
+		rest = rest[3:]
+	} else {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			return nil, 0
+		}
+		mime = strings.ToLower(latin1(rest[:end]))
+		rest = rest[end+1:]
+	}
+	if len(rest) < 1 {
+		return nil, 0
+	}
+	pictureType := rest[0]
+	rest = rest[1:]
+
+	end := id3Terminator(encoding, rest)
+	if end < 0 || end >= len(rest) {
+		return nil, 0
+	}
+	data := rest[end:]
+	if mime == "" || !strings.Contains(mime, "/") {
+		mime = sniffImage(data)
+	}
+	return &Picture{MIMEType: mime, Data: data}, pictureType
+}
+
+// sniffImage detects PNG images, everything else is treated as JPEG
+func sniffImage(data []byte) string {
+	if bytes.HasPrefix(data, []byte("\x89PNG")) {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// parseID3v1 reads the 128 byte ID3v1 tag at the end of data, if there is one
+func parseID3v1(data []byte) (Tags, bool) {
+	if len(data) < 128 {
+		return Tags{}, false
+	}
+	tag := data[len(data)-128:]
+	if string(tag[0:3]) != "TAG" {
+		return Tags{}, false
+	}
+	tags := Tags{
+		Title:  cleanText(latin1(tag[3:33])),
+		Artist: cleanText(latin1(tag[33:63])),
+		Album:  cleanText(latin1(tag[63:93])),
+		Year:   parseYear(latin1(tag[93:97])),
+	}
+	// ID3v1.1 stores the track in the last byte of the comment
+	if tag[125] == 0 && tag[126] != 0 {
+		tags.TrackNumber = int(tag[126])
+	}
+	return tags, true
+}
@@ -0,0 +1,179 @@
+package audiometa
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// MPEG audio versions as encoded in the frame header
+const (
+	mpeg25 = 0
+	mpeg2  = 2
+	mpeg1  = 3
+)
+
+// Bitrates in kbit/s by [MPEG1?][layer][index]; layer is 1-3
+var mpegBitrates = [2][4][16]int{
+	{ // MPEG2 and 2.5
+		{},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+	{ // MPEG1
+		{},
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+}
+
+var mpegSampleRates = map[int][3]int{
+	mpeg1:  {44100, 48000, 32000},
+	mpeg2:  {22050, 24000, 16000},
+	mpeg25: {11025, 12000, 8000},
+}
+
+// mpegFrame is a decoded MPEG audio frame header
+type mpegFrame struct {
+	version    int
+	layer      int // 1, 2 or 3
+	bitrate    int // kbit/s
+	sampleRate int
+	channels   int
+	size       int // bytes including the header
+	samples    int // samples per frame
+}
+
+// parseMPEGFrame decodes the 4 byte frame header at the start of b
+func parseMPEGFrame(b []byte) (mpegFrame, bool) {
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mpegFrame{}, false
+	}
+	version := int(b[1]>>3) & 3
+	layerBits := int(b[1]>>1) & 3
+	bitrateIndex := int(b[2] >> 4)
+	rateIndex := int(b[2]>>2) & 3
+	if version == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mpegFrame{}, false
+	}
+
+	f := mpegFrame{version: version, layer: 4 - layerBits}
+	isMPEG1 := 0
+	if version == mpeg1 {
+		isMPEG1 = 1
+	}
+	f.bitrate = mpegBitrates[isMPEG1][f.layer][bitrateIndex]
+	f.sampleRate = mpegSampleRates[version][rateIndex]
+	f.channels = 2
+	if b[3]>>6 == 3 {
+		f.channels = 1
+	}
+
+	padding := int(b[2]>>1) & 1
+	switch {
+	case f.layer == 1:
+		f.samples = 384
+		f.size = (12*f.bitrate*1000/f.sampleRate + padding) * 4
+	case f.layer == 3 && version != mpeg1:
+		f.samples = 576
+		f.size = 72*f.bitrate*1000/f.sampleRate + padding
+	default:
+		f.samples = 1152
+		f.size = 144*f.bitrate*1000/f.sampleRate + padding
+	}
+	return f, f.size > 4
+}
+
+// findFirstFrame returns the offset and header of the first frame that is followed by another valid frame
+func findFirstFrame(data []byte, from int) (int, mpegFrame, bool) {
+	for i := from; i+4 <= len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		f, ok := parseMPEGFrame(data[i:])
+		if !ok {
+			continue
+		}
+		next := i + f.size
+		if next+4 <= len(data) {
+			if g, ok := parseMPEGFrame(data[next:]); !ok || g.sampleRate != f.sampleRate || g.layer != f.layer {
+				continue
+			}
+		}
+		return i, f, true
+	}
+	return 0, mpegFrame{}, false
+}
+
+func parseMP3(data []byte) (*Metadata, error) {
+	meta := &Metadata{Format: "mp3"}
+
+	start := id3Size(data)
+	if start > len(data) {
+		return nil, invalid("ID3 tag is larger than the file")
+	}
+	if start > 0 {
+		meta.Tags = parseID3v2(data)
+	}
+	end := len(data)
+	if v1, ok := parseID3v1(data); ok {
+		meta.Tags.merge(v1)
+		end -= 128
+	}
+
+	offset, frame, ok := findFirstFrame(data[:end], start)
+	if !ok {
+		return nil, invalid("no MPEG audio frame found")
+	}
+	meta.SampleRate = frame.sampleRate
+	meta.Channels = frame.channels
+	audioBytes := int64(end - offset)
+
+	// VBR files have a Xing/Info or VBRI header in the first frame with the frame count
+	if frames, byteCount, ok := vbrHeader(data[offset:end], frame); ok && frames > 0 {
+		meta.Duration = samplesDuration(int64(frames)*int64(frame.samples), frame.sampleRate)
+		if byteCount > 0 {
+			audioBytes = int64(byteCount)
+		}
+		meta.Bitrate = averageBitrate(audioBytes, meta.Duration)
+		return meta, nil
+	}
+
+	// Constant bitrate: the duration follows from the size
+	meta.Bitrate = frame.bitrate
+	meta.Duration = time.Duration(float64(audioBytes) * 8 / float64(frame.bitrate*1000) * float64(time.Second))
+	return meta, nil
+}
+
+// vbrHeader reads the frame and byte counts of a Xing/Info or VBRI header in the first frame
+func vbrHeader(b []byte, f mpegFrame) (frames, byteCount int, ok bool) {
+	// The Xing header follows the side information, whose size depends on version and channels
+	sideInfo := 17
+	switch {
+	case f.version == mpeg1 && f.channels == 2:
+		sideInfo = 32
+	case f.version != mpeg1 && f.channels == 1:
+		sideInfo = 9
+	}
+	if x := 4 + sideInfo; len(b) >= x+12 && (string(b[x:x+4]) == "Xing" || string(b[x:x+4]) == "Info") {
+		flags := binary.BigEndian.Uint32(b[x+4:])
+		pos := x + 8
+		if flags&1 != 0 && len(b) >= pos+4 {
+			frames = int(binary.BigEndian.Uint32(b[pos:]))
+			pos += 4
+		}
+		if flags&2 != 0 && len(b) >= pos+4 {
+			byteCount = int(binary.BigEndian.Uint32(b[pos:]))
+		}
+		return frames, byteCount, true
+	}
+
+	// VBRI (Fraunhofer) is always 32 bytes after the header
+	if len(b) >= 36+18 && string(b[36:40]) == "VBRI" {
+		byteCount = int(binary.BigEndian.Uint32(b[46:]))
+		frames = int(binary.BigEndian.Uint32(b[50:]))
+		return frames, byteCount, true
+	}
+	return 0, 0, false
+}
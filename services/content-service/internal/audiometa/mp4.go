@@ -0,0 +1,186 @@
+package audiometa
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// mp4Atom is a box of an MP4/M4A file
+type mp4Atom struct {
+	kind string
+	body []byte
+}
+
+// mp4Atoms splits b into its top level atoms
+func mp4Atoms(b []byte) []mp4Atom {
+	var atoms []mp4Atom
+	for len(b) >= 8 {
+		size := int64(binary.BigEndian.Uint32(b[0:4]))
+		kind := string(b[4:8])
+		header := int64(8)
+		switch size {
+		case 0: // extends to the end of the file
+			size = int64(len(b))
+		case 1: // 64-bit size follows the type
+			if len(b) < 16 {
+				return atoms
+			}
+			size = int64(binary.BigEndian.Uint64(b[8:16]))
+			header = 16
+		}
+		if size < header || size > int64(len(b)) {
+			// mdat of a truncated file still counts towards the bitrate
+			if kind == "mdat" {
+				atoms = append(atoms, mp4Atom{kind: kind, body: b[header:]})
+			}
+			return atoms
+		}
+		atoms = append(atoms, mp4Atom{kind: kind, body: b[header:size]})
+		b = b[size:]
+	}
+	return atoms
+}
+
+// mp4Child returns the body of the first child atom of the given type
+func mp4Child(b []byte, kind string) []byte {
+	for _, atom := range mp4Atoms(b) {
+		if atom.kind == kind {
+			return atom.body
+		}
+	}
+	return nil
+}
+
+// mp4Path follows a path of nested atoms
+func mp4Path(b []byte, kinds ...string) []byte {
+	for _, kind := range kinds {
+		if b = mp4Child(b, kind); b == nil {
+			return nil
+		}
+	}
+	return b
+}
+
+func parseMP4(data []byte) (*Metadata, error) {
+	meta := &Metadata{Format: "m4a"}
+
+	var moov []byte
+	var mdatSize int64
+	for _, atom := range mp4Atoms(data) {
+		switch atom.kind {
+		case "moov":
+			moov = atom.body
+		case "mdat":
+			mdatSize += int64(len(atom.body))
+		}
+	}
+	if moov == nil {
+		return nil, invalid("MP4 movie box is missing")
+	}
+
+	for _, trak := range mp4Atoms(moov) {
+		if trak.kind != "trak" {
+			continue
+		}
+		mdia := mp4Child(trak.body, "mdia")
+		if hdlr := mp4Child(mdia, "hdlr"); len(hdlr) < 12 || string(hdlr[8:12]) != "soun" {
+			continue
+		}
+		meta.Duration = mp4Duration(mp4Child(mdia, "mdhd"))
+		if stsd := mp4Path(mdia, "minf", "stbl", "stsd"); len(stsd) >= 8 {
+			// Full box header and entry count, then the first sample entry
+			if entries := mp4Atoms(stsd[8:]); len(entries) > 0 {
+				entry := entries[0] // mp4a (AAC) or alac
+				// Audio sample entry: 6 reserved, 2 data reference index, 8 reserved,
+				// 2 channels, 2 sample size, 4 reserved, 4 sample rate (16.16)
+				if len(entry.body) >= 28 {
+					meta.Channels = int(binary.BigEndian.Uint16(entry.body[16:18]))
+					meta.SampleRate = int(binary.BigEndian.Uint32(entry.body[24:28]) >> 16)
+				}
+			}
+		}
+		break
+	}
+	if meta.Duration == 0 {
+		meta.Duration = mp4Duration(mp4Child(moov, "mvhd"))
+	}
+
+	if udta := mp4Child(moov, "udta"); udta != nil {
+		if m := mp4Child(udta, "meta"); m != nil {
+			// meta is a full box, except in some QuickTime files where hdlr follows directly
+			if len(m) >= 8 && string(m[4:8]) != "hdlr" {
+				m = m[4:]
+			}
+			meta.Tags = parseILST(mp4Child(m, "ilst"))
+		}
+	}
+
+	meta.Bitrate = averageBitrate(mdatSize, meta.Duration)
+	return meta, nil
+}
+
+// mp4Duration reads the duration of an mvhd or mdhd box, both with version 0 and 1 layouts
+func mp4Duration(b []byte) time.Duration {
+	if len(b) < 4 {
+		return 0
+	}
+	var timescale, duration uint64
+	if b[0] == 1 {
+		if len(b) < 32 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(b[20:24]))
+		duration = binary.BigEndian.Uint64(b[24:32])
+	} else {
+		if len(b) < 20 {
+			return 0
+		}
+		timescale = uint64(binary.BigEndian.Uint32(b[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	return samplesDuration(int64(duration), int(timescale))
+}
+
+// parseILST reads the iTunes metadata items
+func parseILST(ilst []byte) Tags {
+	var tags Tags
+	for _, item := range mp4Atoms(ilst) {
+		data := mp4Child(item.body, "data")
+		if len(data) < 8 {
+			continue
+		}
+		// data: 4 bytes type indicator, 4 bytes locale
+		dataType := binary.BigEndian.Uint32(data[0:4]) & 0xFFFFFF
+		value := data[8:]
+
+		switch item.kind {
+		case "\xa9nam":
+			tags.Title = cleanText(string(value))
+		case "\xa9ART":
+			tags.Artist = cleanText(string(value))
+		case "aART":
+			if tags.Artist == "" {
+				tags.Artist = cleanText(string(value))
+			}
+		case "\xa9alb":
+			tags.Album = cleanText(string(value))
+		case "\xa9day":
+			tags.Year = parseYear(string(value))
+		case "trkn":
+			// 2 reserved, 2 track number, 2 total
+			if len(value) >= 4 {
+				tags.TrackNumber = int(binary.BigEndian.Uint16(value[2:4]))
+			}
+		case "covr":
+			if len(value) == 0 || tags.Cover != nil {
+				continue
+			}
+			mime := sniffImage(value)
+			if dataType == 14 {
+				mime = "image/png"
+			}
+			tags.Cover = &Picture{MIMEType: mime, Data: value}
+		}
+	}
+	return tags
+}
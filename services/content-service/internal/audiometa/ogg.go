@@ -0,0 +1,133 @@
+package audiometa
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// oggPage is the part of an Ogg page header needed to reassemble packets
+type oggPage struct {
+	granule  int64
+	serial   uint32
+	segments []byte
+	body     []byte
+	size     int // header and body
+}
+
+func readOggPage(data []byte) (oggPage, bool) {
+	if len(data) < 27 || string(data[0:4]) != "OggS" {
+		return oggPage{}, false
+	}
+	count := int(data[26])
+	if len(data) < 27+count {
+		return oggPage{}, false
+	}
+	segments := data[27 : 27+count]
+	bodySize := 0
+	for _, s := range segments {
+		bodySize += int(s)
+	}
+	start := 27 + count
+	if len(data) < start+bodySize {
+		return oggPage{}, false
+	}
+	return oggPage{
+		granule:  int64(binary.LittleEndian.Uint64(data[6:14])),
+		serial:   binary.LittleEndian.Uint32(data[14:18]),
+		segments: segments,
+		body:     data[start : start+bodySize],
+		size:     start + bodySize,
+	}, true
+}
+
+// oggHeaderPackets reassembles the first n packets of the logical stream with the given serial
+func oggHeaderPackets(data []byte, serial uint32, n int) [][]byte {
+	var packets [][]byte
+	var current []byte
+	for pos := 0; pos < len(data) && len(packets) < n; {
+		page, ok := readOggPage(data[pos:])
+		if !ok {
+			break
+		}
+		pos += page.size
+		if page.serial != serial {
+			continue
+		}
+		offset := 0
+		for _, s := range page.segments {
+			current = append(current, page.body[offset:offset+int(s)]...)
+			offset += int(s)
+			// A segment shorter than 255 bytes ends the packet
+			if s < 255 {
+				packets = append(packets, current)
+				current = nil
+				if len(packets) == n {
+					break
+				}
+			}
+		}
+	}
+	return packets
+}
+
+// lastGranule returns the granule position of the last page of the stream, which is its length in samples
+func lastGranule(data []byte, serial uint32) int64 {
+	end := len(data)
+	for {
+		i := bytes.LastIndex(data[:end], []byte("OggS"))
+		if i < 0 {
+			return 0
+		}
+		if page, ok := readOggPage(data[i:]); ok && page.serial == serial && page.granule > 0 {
+			return page.granule
+		}
+		end = i
+	}
+}
+
+func parseOgg(data []byte) (*Metadata, error) {
+	first, ok := readOggPage(data)
+	if !ok {
+		return nil, invalid("Ogg page is truncated")
+	}
+	packets := oggHeaderPackets(data, first.serial, 2)
+	if len(packets) < 1 {
+		return nil, invalid("Ogg stream has no header packet")
+	}
+	id := packets[0]
+
+	meta := &Metadata{}
+	var sampleRate int
+	var preSkip int64
+	var commentPrefix string
+	switch {
+	case len(id) >= 30 && string(id[0:7]) == "\x01vorbis":
+		meta.Format = "ogg"
+		meta.Channels = int(id[11])
+		sampleRate = int(binary.LittleEndian.Uint32(id[12:16]))
+		meta.SampleRate = sampleRate
+		if nominal := int32(binary.LittleEndian.Uint32(id[20:24])); nominal > 0 {
+			meta.Bitrate = int(nominal) / 1000
+		}
+		commentPrefix = "\x03vorbis"
+	case len(id) >= 19 && string(id[0:8]) == "OpusHead":
+		meta.Format = "opus"
+		meta.Channels = int(id[9])
+		preSkip = int64(binary.LittleEndian.Uint16(id[10:12]))
+		meta.SampleRate = int(binary.LittleEndian.Uint32(id[12:16])) // input rate, informational
+		sampleRate = 48000                                           // Opus granules always count 48 kHz samples
+		commentPrefix = "OpusTags"
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	if len(packets) > 1 && bytes.HasPrefix(packets[1], []byte(commentPrefix)) {
+		meta.Tags = parseVorbisComment(packets[1][len(commentPrefix):])
+	}
+
+	meta.Duration = samplesDuration(lastGranule(data, first.serial)-preSkip, sampleRate)
+	if meta.Bitrate == 0 {
+		meta.Bitrate = averageBitrate(int64(len(data)), meta.Duration)
+	}
+	return meta, nil
+}
@@ -0,0 +1,86 @@
+package audiometa
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+func parseWAV(data []byte) (*Metadata, error) {
+	meta := &Metadata{Format: "wav"}
+	var byteRate, dataSize int64
+
+	pos := 12 // "RIFF", size, "WAVE"
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		pos += 8
+		end := pos + size
+		if size < 0 || end > len(data) {
+			// A truncated data chunk still tells how much audio there is
+			if id == "data" {
+				dataSize = int64(len(data) - pos)
+			}
+			break
+		}
+		chunk := data[pos:end]
+
+		switch id {
+		case "fmt ":
+			if len(chunk) < 16 {
+				return nil, invalid("WAV fmt chunk is too short")
+			}
+			meta.Channels = int(binary.LittleEndian.Uint16(chunk[2:4]))
+			meta.SampleRate = int(binary.LittleEndian.Uint32(chunk[4:8]))
+			byteRate = int64(binary.LittleEndian.Uint32(chunk[8:12]))
+		case "data":
+			dataSize = int64(size)
+		case "LIST":
+			if len(chunk) >= 4 && string(chunk[0:4]) == "INFO" {
+				meta.Tags.merge(parseRIFFInfo(chunk[4:]))
+			}
+		case "id3 ", "ID3 ":
+			meta.Tags.merge(parseID3v2(chunk))
+		}
+
+		// Chunks are padded to an even size
+		pos = end + size%2
+	}
+	if byteRate == 0 {
+		return nil, invalid("WAV fmt chunk is missing")
+	}
+
+	meta.Duration = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	meta.Bitrate = int(byteRate * 8 / 1000)
+	return meta, nil
+}
+
+// parseRIFFInfo reads the sub-chunks of a LIST/INFO chunk
+func parseRIFFInfo(b []byte) Tags {
+	var tags Tags
+	for len(b) >= 8 {
+		id := string(b[0:4])
+		size := int(binary.LittleEndian.Uint32(b[4:8]))
+		if size < 0 || 8+size > len(b) {
+			break
+		}
+		value := cleanText(latin1(b[8 : 8+size]))
+		b = b[8+size:]
+		if size%2 == 1 && len(b) > 0 {
+			b = b[1:]
+		}
+
+		switch id {
+		case "INAM":
+			tags.Title = value
+		case "IART":
+			tags.Artist = value
+		case "IPRD":
+			tags.Album = value
+		case "ITRK", "IPRT":
+			tags.TrackNumber = parseLeadingInt(value)
+		case "ICRD":
+			tags.Year = parseYear(value)
+		}
+	}
+	return tags
+}
@@ -10,4 +10,7 @@ type AlbumResponse struct {
 	ArtistIDs   []string  `json:"artistIds"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	LikeCount   int       `json:"likeCount"`
+	// Album artwork by thumbnail size ("64", "300", "640"); omitted when there is none
+	ImageURLs map[string]string `json:"imageUrls,omitempty"`
 }
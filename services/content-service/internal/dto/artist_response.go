@@ -7,6 +7,9 @@ type ArtistResponse struct {
 	Name      string   `json:"name"`
 	Biography string   `json:"biography"`
 	Genres    []string `json:"genres"`
+	LikeCount int      `json:"likeCount"`
+	// Artist photo by thumbnail size ("64", "300", "640"); omitted when there is none
+	ImageURLs map[string]string `json:"imageUrls,omitempty"`
 }
 
 func ToArtistResponse(artist *model.Artist) *ArtistResponse {
@@ -15,5 +18,7 @@ func ToArtistResponse(artist *model.Artist) *ArtistResponse {
 		Name:      artist.Name,
 		Biography: artist.Biography,
 		Genres:    artist.Genres,
+		LikeCount: artist.LikeCount,
+		ImageURLs: ImageURLs("artists", artist.ID, artist.Image),
 	}
 }
@@ -0,0 +1,15 @@
+package dto
+
+import "content-service/internal/model"
+
+// CatalogBackup is what deleting an album or artist removes from the catalog. saga-service keeps it
+// to put the documents back when the deletion is compensated.
+type CatalogBackup struct {
+	Artist *model.Artist  `json:"artist,omitempty"`
+	Albums []*model.Album `json:"albums"`
+	Songs  []*model.Song  `json:"songs"`
+	// Albums and songs shared with other artists that only lose the deleted artist, as they were
+	// before it was removed from them
+	DetachedAlbums []*model.Album `json:"detachedAlbums"`
+	DetachedSongs  []*model.Song  `json:"detachedSongs"`
+}
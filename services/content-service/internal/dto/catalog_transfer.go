@@ -0,0 +1,88 @@
+package dto
+
+import "time"
+
+// Record types of a catalog export, the "type" of each NDJSON line
+const (
+	CatalogRecordArtist = "artist"
+	CatalogRecordAlbum  = "album"
+	CatalogRecordSong   = "song"
+)
+
+// CatalogArtistRecord is an artist in a catalog export or import
+type CatalogArtistRecord struct {
+	Type      string   `json:"type"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Biography string   `json:"biography"`
+	Genres    []string `json:"genres"`
+}
+
+// CatalogAlbumRecord is an album in a catalog export or import; ArtistIDs are the IDs of the
+// exporting catalog
+type CatalogAlbumRecord struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ReleaseDate time.Time `json:"releaseDate"`
+	Genre       string    `json:"genre"`
+	ArtistIDs   []string  `json:"artistIds"`
+}
+
+// CatalogSongRecord is a song in a catalog export or import. AudioFileURL is audio hosted elsewhere;
+// AudioFile is the path of the uploaded audio inside an export archive (audio/{id}.{ext}).
+type CatalogSongRecord struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Duration     int      `json:"duration"`
+	Genre        string   `json:"genre"`
+	AlbumID      string   `json:"albumId"`
+	ArtistIDs    []string `json:"artistIds"`
+	AudioFileURL string   `json:"audioFileUrl,omitempty"`
+	AudioFile    string   `json:"audioFile,omitempty"`
+}
+
+// CatalogExportManifest describes an export archive
+type CatalogExportManifest struct {
+	ExportedAt time.Time `json:"exportedAt"`
+	Format     string    `json:"format"`
+	Artists    int       `json:"artists"`
+	Albums     int       `json:"albums"`
+	Songs      int       `json:"songs"`
+	AudioFiles int       `json:"audioFiles"`
+	// Songs whose uploaded audio could not be read from storage; they are exported without it
+	MissingAudio []string `json:"missingAudio"`
+}
+
+// CatalogImportCounts counts the outcome of the rows of one type
+type CatalogImportCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Failed    int `json:"failed"`
+}
+
+// CatalogImportError is a row that was not imported
+type CatalogImportError struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Type  string `json:"type,omitempty"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+// CatalogImportReport is the result of an import; in a dry run nothing is written and the counts say
+// what the import would do
+type CatalogImportReport struct {
+	DryRun  bool                `json:"dryRun"`
+	Artists CatalogImportCounts `json:"artists"`
+	Albums  CatalogImportCounts `json:"albums"`
+	Songs   CatalogImportCounts `json:"songs"`
+	// Uploaded audio files stored from the archive
+	AudioFiles int `json:"audioFiles"`
+	// IDMap maps the imported IDs that matched an existing item by name to the ID it has here, per
+	// type (artist, album, song)
+	IDMap  map[string]map[string]string `json:"idMap"`
+	Errors []CatalogImportError         `json:"errors"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type CreatePlaylistRequest struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	CoverURL      string `json:"coverUrl,omitempty"`
+	Visibility    string `json:"visibility"` // public or private, defaults to private
+	Collaborative bool   `json:"collaborative"`
+}
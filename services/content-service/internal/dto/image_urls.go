@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"fmt"
+	"strconv"
+
+	"content-service/internal/model"
+)
+
+// gatewayPrefix is where clients reach content-service through the API gateway
+const gatewayPrefix = "/api/content"
+
+// ImageURLs returns the URL of each thumbnail size of an album or artist image, keyed by size.
+// The version query changes with every upload, so the URLs can be cached indefinitely.
+func ImageURLs(collection, id string, image *model.Image) map[string]string {
+	if image == nil || len(image.Thumbnails) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(image.Thumbnails))
+	for _, thumbnail := range image.Thumbnails {
+		urls[strconv.Itoa(thumbnail.Size)] = fmt.Sprintf("%s/%s/%s/image/%d?v=%s", gatewayPrefix, collection, id, thumbnail.Size, image.Version)
+	}
+	return urls
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// LibraryItemResponse is one liked song, album or artist; exactly one of Song, Album and Artist is set
+type LibraryItemResponse struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id"`
+	AddedAt time.Time       `json:"addedAt"`
+	Song    *SongResponse   `json:"song,omitempty"`
+	Album   *AlbumResponse  `json:"album,omitempty"`
+	Artist  *ArtistResponse `json:"artist,omitempty"`
+}
+
+type LikeResponse struct {
+	Liked     bool `json:"liked"`
+	LikeCount int  `json:"likeCount"`
+}
@@ -0,0 +1,5 @@
+package dto
+
+type MigrateStorageRequest struct {
+	Backend string `json:"backend"` // hdfs, local or s3
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type PlaylistEntryResponse struct {
+	ID       string    `json:"id"`
+	Position int       `json:"position"`
+	SongID   string    `json:"songId"`
+	AddedBy  string    `json:"addedBy"`
+	AddedAt  time.Time `json:"addedAt"`
+	// Song is omitted when the song no longer exists
+	Song *SongResponse `json:"song,omitempty"`
+}
+
+type PlaylistResponse struct {
+	ID            string    `json:"id"`
+	OwnerID       string    `json:"ownerId"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CoverURL      string    `json:"coverUrl,omitempty"`
+	Visibility    string    `json:"visibility"`
+	Collaborative bool      `json:"collaborative"`
+	EditorIDs     []string  `json:"editorIds"`
+	SongCount     int       `json:"songCount"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// Entries are only returned for a single playlist, not in lists
+	Entries []PlaylistEntryResponse `json:"entries,omitempty"`
+}
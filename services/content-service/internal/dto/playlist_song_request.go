@@ -0,0 +1,14 @@
+package dto
+
+type AddPlaylistSongRequest struct {
+	SongID   string `json:"songId"`
+	Position int    `json:"position,omitempty"` // 1-based, omitted appends the song
+}
+
+type MovePlaylistSongRequest struct {
+	Position int `json:"position"`
+}
+
+type AddPlaylistEditorRequest struct {
+	UserID string `json:"userId"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+// SearchHighlight is a matched field with the matching words wrapped in <mark> (HTML-escaped)
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchHit is one song, album or artist in the search results
+type SearchHit struct {
+	Type       string            `json:"type"` // song, album or artist
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Score      float64           `json:"score"`
+	Highlights []SearchHighlight `json:"highlights"`
+	Item       interface{}       `json:"item"`
+}
+
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Facets  map[string]int `json:"facets"` // matches per type, before the type filter
+	Results []SearchHit    `json:"results"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type SetLyricsRequest struct {
+	Text   string `json:"text"`
+	Format string `json:"format,omitempty"` // lrc or plain; detected from the text when empty
+}
@@ -1,15 +1,29 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"content-service/internal/model"
+)
 
 type SongResponse struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Duration     int       `json:"duration"`
-	Genre        string    `json:"genre"`
-	AlbumID      string    `json:"albumId"`
-	ArtistIDs    []string  `json:"artistIds"`
-	AudioFileURL string    `json:"audioFileUrl,omitempty"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Duration  int               `json:"duration"`
+	Genre     string            `json:"genre"`
+	AlbumID   string            `json:"albumId"`
+	ArtistIDs []string          `json:"artistIds"`
+	AudioFile *model.StorageRef `json:"audioFile,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	// Ratings as last pushed by ratings-service
+	AverageRating float64 `json:"averageRating"`
+	RatingCount   int     `json:"ratingCount"`
+	LikeCount     int     `json:"likeCount"`
+	// Format, tags and catalog mismatches of the uploaded audio file
+	Audio *model.AudioMetadata `json:"audio,omitempty"`
+	// Set when the song can be played over HLS from /songs/{id}/hls/master.m3u8
+	HLSAvailable bool `json:"hlsAvailable"`
+	// Languages with lyrics at /songs/{id}/lyrics/{language}
+	LyricsLanguages []string `json:"lyricsLanguages,omitempty"`
 }
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// TrashItemResponse is a deleted song, album or artist waiting in the trash
+type TrashItemResponse struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+	DeletedBy string    `json:"deletedBy,omitempty"`
+	// When the retention job deletes it permanently
+	PurgeAt time.Time `json:"purgeAt"`
+}
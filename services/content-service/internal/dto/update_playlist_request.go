@@ -0,0 +1,10 @@
+package dto
+
+// UpdatePlaylistRequest changes only the fields that are present
+type UpdatePlaylistRequest struct {
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	CoverURL      *string `json:"coverUrl"`
+	Visibility    *string `json:"visibility"`
+	Collaborative *bool   `json:"collaborative"`
+}
@@ -6,5 +6,5 @@ type UpdateSongRequest struct {
 	Genre        string   `json:"genre"`
 	AlbumID      string   `json:"albumId"`
 	ArtistIDs    []string `json:"artistIds"`
-	AudioFileURL string   `json:"audioFileUrl,omitempty"`
+	AudioFileURL string   `json:"audioFileUrl,omitempty"` // external URL or storage path, uploads set it themselves
 }
@@ -24,6 +24,7 @@ const (
 	EventTypeDeletedArtist EventType = "artist_deleted"
 	EventTypeDeletedAlbum  EventType = "album_deleted"
 	EventTypeDeletedSong   EventType = "song_deleted"
+	EventTypeUpdatedAlbum  EventType = "album_updated"
 )
 
 // Event payloads
@@ -32,6 +33,8 @@ type NewArtistEvent struct {
 	ArtistID string   `json:"artistId"`
 	Name     string   `json:"name"`
 	Genres   []string `json:"genres"`
+	// Restored is set when the artist is taken out of the trash rather than created
+	Restored bool `json:"restored,omitempty"`
 }
 
 type NewAlbumEvent struct {
@@ -41,6 +44,7 @@ type NewAlbumEvent struct {
 	Genre     string    `json:"genre"`
 	ArtistIDs []string  `json:"artistIds"`
 	ArtistNames []string `json:"artistNames"` // Added for better notification messages
+	Restored    bool     `json:"restored,omitempty"`
 }
 
 type NewSongEvent struct {
@@ -51,6 +55,18 @@ type NewSongEvent struct {
 	ArtistIDs   []string  `json:"artistIds"`
 	ArtistNames []string  `json:"artistNames"` // Added for better notification messages
 	AlbumID     string    `json:"albumId"`
+	Restored    bool      `json:"restored,omitempty"`
+}
+
+// UpdatedAlbumEvent announces an edited album; AddedArtistIDs are the artists it did not have before
+type UpdatedAlbumEvent struct {
+	Type           EventType `json:"type"`
+	AlbumID        string    `json:"albumId"`
+	Name           string    `json:"name"`
+	Genre          string    `json:"genre"`
+	ArtistIDs      []string  `json:"artistIds"`
+	ArtistNames    []string  `json:"artistNames"`
+	AddedArtistIDs []string  `json:"addedArtistIds"`
 }
 
 // Deletion event payloads
@@ -3,14 +3,17 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"content-service/internal/dto"
 	"content-service/internal/events"
 	"content-service/internal/logger"
 	"content-service/internal/middleware"
 	"content-service/internal/model"
+	"content-service/internal/storage"
 	"content-service/internal/store"
 )
 
@@ -25,18 +28,25 @@ func extractAlbumID(path string) string {
 type AlbumHandler struct {
 	Repo                     *store.AlbumRepository
 	ArtistRepo               *store.ArtistRepository
+	SongRepo                 *store.SongRepository
+	Revisions                *store.RevisionRepository
 	SubscriptionsServiceURL  string
 	RecommendationServiceURL string
 	Logger                   *logger.Logger
+	// Storage holds the album images
+	Storage *storage.Stores
 }
 
-func NewAlbumHandler(repo *store.AlbumRepository, artistRepo *store.ArtistRepository, subscriptionsServiceURL, recommendationServiceURL string, log *logger.Logger) *AlbumHandler {
+func NewAlbumHandler(repo *store.AlbumRepository, artistRepo *store.ArtistRepository, songRepo *store.SongRepository, revisionRepo *store.RevisionRepository, subscriptionsServiceURL, recommendationServiceURL string, log *logger.Logger, stores *storage.Stores) *AlbumHandler {
 	return &AlbumHandler{
 		Repo:                     repo,
 		ArtistRepo:               artistRepo,
+		SongRepo:                 songRepo,
+		Revisions:                revisionRepo,
 		SubscriptionsServiceURL:  subscriptionsServiceURL,
 		RecommendationServiceURL: recommendationServiceURL,
 		Logger:                   log,
+		Storage:                  stores,
 	}
 }
 
@@ -87,6 +97,8 @@ func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRevision(r.Context(), h.Revisions, model.LikeTypeAlbum, album.ID, nil, albumRevisionState(album), getAdminIDFromContext(r.Context()), 0)
+
 	// Log admin activity
 	if h.Logger != nil {
 		adminID := getAdminIDFromContext(r.Context())
@@ -98,15 +110,15 @@ func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Get artist names for the event
-	artistNames := make([]string, 0, len(album.ArtistIDs))
-	for _, artistID := range album.ArtistIDs {
-		artist, err := h.ArtistRepo.GetByID(r.Context(), artistID)
-		if err == nil && artist != nil {
-			artistNames = append(artistNames, artist.Name)
-		}
-	}
+	h.announceCreated(r.Context(), album)
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toAlbumResponse(album))
+}
+
+// announceCreated notifies the subscribers of a new album
+func (h *AlbumHandler) announceCreated(ctx context.Context, album *model.Album) {
 	// Emit event for new album (asynchronous)
 	event := events.NewAlbumEvent{
 		Type:        events.EventTypeNewAlbum,
@@ -114,13 +126,9 @@ func (h *AlbumHandler) CreateAlbum(w http.ResponseWriter, r *http.Request) {
 		Name:        album.Name,
 		Genre:       album.Genre,
 		ArtistIDs:   album.ArtistIDs,
-		ArtistNames: artistNames,
+		ArtistNames: artistNames(ctx, h.ArtistRepo, album.ArtistIDs),
 	}
-	events.EmitEvent(r.Context(), h.SubscriptionsServiceURL, event)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(toAlbumResponse(album))
+	events.EmitEvent(ctx, h.SubscriptionsServiceURL, event)
 }
 
 func (h *AlbumHandler) GetAlbum(w http.ResponseWriter, r *http.Request) {
@@ -152,9 +160,15 @@ func (h *AlbumHandler) GetAllAlbums(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	albums, err := h.Repo.GetAll(r.Context())
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	albums, next, err := h.Repo.List(r.Context(), q)
 	if err != nil {
-		http.Error(w, "failed to get albums: "+err.Error(), http.StatusInternalServerError)
+		writeListError(w, err, "albums")
 		return
 	}
 
@@ -163,6 +177,7 @@ func (h *AlbumHandler) GetAllAlbums(w http.ResponseWriter, r *http.Request) {
 		responses[i] = toAlbumResponse(album)
 	}
 
+	setNextCursor(w, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responses)
@@ -180,9 +195,16 @@ func (h *AlbumHandler) GetAlbumsByArtist(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	albums, err := h.Repo.GetByArtistID(r.Context(), artistID)
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.ArtistID = artistID
+
+	albums, next, err := h.Repo.List(r.Context(), q)
 	if err != nil {
-		http.Error(w, "failed to get albums: "+err.Error(), http.StatusInternalServerError)
+		writeListError(w, err, "albums")
 		return
 	}
 
@@ -191,6 +213,7 @@ func (h *AlbumHandler) GetAlbumsByArtist(w http.ResponseWriter, r *http.Request)
 		responses[i] = toAlbumResponse(album)
 	}
 
+	setNextCursor(w, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responses)
@@ -214,17 +237,8 @@ func (h *AlbumHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validation
-	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
-		return
-	}
-	if req.Genre == "" {
-		http.Error(w, "genre is required", http.StatusBadRequest)
-		return
-	}
-	if len(req.ArtistIDs) == 0 {
-		http.Error(w, "at least one artist ID is required", http.StatusBadRequest)
+	if err := validateAlbumUpdate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -235,6 +249,33 @@ func (h *AlbumHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.applyUpdate(r.Context(), existingAlbum, &req, 0); err != nil {
+		http.Error(w, "failed to update album: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toAlbumResponse(existingAlbum))
+}
+
+// validateAlbumUpdate checks an update of an album, also used for rollbacks to a revision
+func validateAlbumUpdate(req *dto.UpdateAlbumRequest) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.Genre == "" {
+		return errors.New("genre is required")
+	}
+	if len(req.ArtistIDs) == 0 {
+		return errors.New("at least one artist ID is required")
+	}
+	return nil
+}
+
+// applyUpdate saves the fields of a validated update to the album and records the revision;
+// restoredFrom is the revision a rollback restores, 0 for an update
+func (h *AlbumHandler) applyUpdate(ctx context.Context, existingAlbum *model.Album, req *dto.UpdateAlbumRequest, restoredFrom int) error {
 	// Store old state for logging
 	oldState := map[string]interface{}{
 		"name":        existingAlbum.Name,
@@ -242,6 +283,11 @@ func (h *AlbumHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
 		"genre":       existingAlbum.Genre,
 		"artistIDs":   existingAlbum.ArtistIDs,
 	}
+	oldRevisionState := albumRevisionState(existingAlbum)
+	oldArtists := make(map[string]bool, len(existingAlbum.ArtistIDs))
+	for _, artistID := range existingAlbum.ArtistIDs {
+		oldArtists[artistID] = true
+	}
 
 	// Update fields
 	existingAlbum.Name = req.Name
@@ -256,23 +302,58 @@ func (h *AlbumHandler) UpdateAlbum(w http.ResponseWriter, r *http.Request) {
 		"artistIDs":   existingAlbum.ArtistIDs,
 	}
 
-	if err := h.Repo.Update(r.Context(), id, existingAlbum); err != nil {
-		http.Error(w, "failed to update album: "+err.Error(), http.StatusInternalServerError)
-		return
+	if err := h.Repo.Update(ctx, existingAlbum.ID, existingAlbum); err != nil {
+		return err
 	}
 
+	adminID := getAdminIDFromContext(ctx)
+	recordRevision(ctx, h.Revisions, model.LikeTypeAlbum, existingAlbum.ID, oldRevisionState, albumRevisionState(existingAlbum), adminID, restoredFrom)
+
 	// Log admin activity and state change
 	if h.Logger != nil {
-		adminID := getAdminIDFromContext(r.Context())
-		h.Logger.LogAdminActivity(adminID, "UPDATE_ALBUM", "albums", map[string]interface{}{
-			"albumId": id,
-		})
+		if restoredFrom > 0 {
+			h.Logger.LogAdminActivity(adminID, "ROLLBACK_ALBUM", "albums", map[string]interface{}{
+				"albumId":  existingAlbum.ID,
+				"revision": restoredFrom,
+			})
+		} else {
+			h.Logger.LogAdminActivity(adminID, "UPDATE_ALBUM", "albums", map[string]interface{}{
+				"albumId": existingAlbum.ID,
+			})
+		}
 		h.Logger.LogStateChange("album", oldState, newState, adminID)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(toAlbumResponse(existingAlbum))
+	// Subscribers of artists added to the album are notified, recommendations keep the album's credits
+	addedArtistIDs := []string{}
+	for _, artistID := range existingAlbum.ArtistIDs {
+		if !oldArtists[artistID] {
+			addedArtistIDs = append(addedArtistIDs, artistID)
+		}
+	}
+	event := events.UpdatedAlbumEvent{
+		Type:           events.EventTypeUpdatedAlbum,
+		AlbumID:        existingAlbum.ID,
+		Name:           existingAlbum.Name,
+		Genre:          existingAlbum.Genre,
+		ArtistIDs:      existingAlbum.ArtistIDs,
+		ArtistNames:    artistNames(ctx, h.ArtistRepo, existingAlbum.ArtistIDs),
+		AddedArtistIDs: addedArtistIDs,
+	}
+	events.EmitEvent(ctx, h.SubscriptionsServiceURL, event)
+	events.EmitEvent(ctx, h.RecommendationServiceURL, event)
+	return nil
+}
+
+// albumRevisionState is the editable state of an album kept in its revisions, keyed like
+// UpdateAlbumRequest. The release date is cut to the millisecond MongoDB stores.
+func albumRevisionState(album *model.Album) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        album.Name,
+		"releaseDate": album.ReleaseDate.UTC().Truncate(time.Millisecond),
+		"genre":       album.Genre,
+		"artistIds":   album.ArtistIDs,
+	}
 }
 
 func (h *AlbumHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
@@ -290,7 +371,9 @@ func (h *AlbumHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
 	// Get album before deletion for logging and events
 	album, _ := h.Repo.GetByID(r.Context(), id)
 
-	if err := h.Repo.Delete(r.Context(), id); err != nil {
+	// The album goes to the trash with its songs; its image is deleted when the trash is purged
+	adminID := getAdminIDFromContext(r.Context())
+	if err := h.Repo.SoftDelete(r.Context(), id, adminID); err != nil {
 		if err.Error() == "album not found" {
 			http.Error(w, "album not found", http.StatusNotFound)
 			return
@@ -298,6 +381,10 @@ func (h *AlbumHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to delete album: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if _, err := h.SongRepo.SoftDeleteByAlbum(r.Context(), id, adminID); err != nil {
+		http.Error(w, "failed to delete the album's songs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Emit deletion event to recommendation-service (asynchronous)
 	// Use context.Background() instead of r.Context() because the HTTP request context
@@ -311,7 +398,6 @@ func (h *AlbumHandler) DeleteAlbum(w http.ResponseWriter, r *http.Request) {
 
 	// Log admin activity
 	if h.Logger != nil {
-		adminID := getAdminIDFromContext(r.Context())
 		h.Logger.LogAdminActivity(adminID, "DELETE_ALBUM", "albums", map[string]interface{}{
 			"albumId": id,
 			"name":    album.Name,
@@ -330,5 +416,7 @@ func toAlbumResponse(album *model.Album) *dto.AlbumResponse {
 		ArtistIDs:   album.ArtistIDs,
 		CreatedAt:   album.CreatedAt,
 		UpdatedAt:   album.UpdatedAt,
+		LikeCount:   album.LikeCount,
+		ImageURLs:   dto.ImageURLs("albums", album.ID, album.Image),
 	}
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	"content-service/internal/logger"
 	"content-service/internal/middleware"
 	"content-service/internal/model"
+	"content-service/internal/storage"
 	"content-service/internal/store"
 )
 
@@ -26,17 +28,26 @@ func extractArtistID(path string) string {
 
 type ArtistHandler struct {
 	Repo                     *store.ArtistRepository
+	AlbumRepo                *store.AlbumRepository
+	SongRepo                 *store.SongRepository
+	Revisions                *store.RevisionRepository
 	SubscriptionsServiceURL  string
 	RecommendationServiceURL  string
 	Logger                   *logger.Logger
+	// Storage holds the artist images
+	Storage                  *storage.Stores
 }
 
-func NewArtistHandler(repo *store.ArtistRepository, subscriptionsServiceURL, recommendationServiceURL string, log *logger.Logger) *ArtistHandler {
+func NewArtistHandler(repo *store.ArtistRepository, albumRepo *store.AlbumRepository, songRepo *store.SongRepository, revisionRepo *store.RevisionRepository, subscriptionsServiceURL, recommendationServiceURL string, log *logger.Logger, stores *storage.Stores) *ArtistHandler {
 	return &ArtistHandler{
 		Repo:                     repo,
+		AlbumRepo:                albumRepo,
+		SongRepo:                 songRepo,
+		Revisions:                revisionRepo,
 		SubscriptionsServiceURL:  subscriptionsServiceURL,
 		RecommendationServiceURL: recommendationServiceURL,
 		Logger:                   log,
+		Storage:                  stores,
 	}
 }
 
@@ -86,6 +97,8 @@ func (h *ArtistHandler) CreateArtist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRevision(r.Context(), h.Revisions, model.LikeTypeArtist, artist.ID, nil, artistRevisionState(artist), getAdminID(r.Context()), 0)
+
 	// Log admin activity
 	if h.Logger != nil {
 		adminID := getAdminID(r.Context())
@@ -96,6 +109,15 @@ func (h *ArtistHandler) CreateArtist(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	h.announceCreated(r.Context(), artist)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToArtistResponse(artist))
+}
+
+// announceCreated notifies subscribers and recommendation-service of a new artist
+func (h *ArtistHandler) announceCreated(ctx context.Context, artist *model.Artist) {
 	// Emit event for new artist (asynchronous)
 	event := events.NewArtistEvent{
 		Type:     events.EventTypeNewArtist,
@@ -103,18 +125,14 @@ func (h *ArtistHandler) CreateArtist(w http.ResponseWriter, r *http.Request) {
 		Name:     artist.Name,
 		Genres:   artist.Genres,
 	}
-	events.EmitEvent(r.Context(), h.SubscriptionsServiceURL, event)
+	events.EmitEvent(ctx, h.SubscriptionsServiceURL, event)
 	// Also emit to recommendation-service
-	events.EmitEvent(r.Context(), h.RecommendationServiceURL, map[string]interface{}{
+	events.EmitEvent(ctx, h.RecommendationServiceURL, map[string]interface{}{
 		"type":     "artist_created",
 		"artistId": artist.ID,
 		"name":     artist.Name,
 		"genres":   artist.Genres,
 	})
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(dto.ToArtistResponse(artist))
 }
 
 func (h *ArtistHandler) UpdateArtist(w http.ResponseWriter, r *http.Request) {
@@ -136,17 +154,8 @@ func (h *ArtistHandler) UpdateArtist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validation
-	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
-		return
-	}
-	if req.Biography == "" {
-		http.Error(w, "biography is required", http.StatusBadRequest)
-		return
-	}
-	if len(req.Genres) == 0 {
-		http.Error(w, "at least one genre is required", http.StatusBadRequest)
+	if err := validateArtistUpdate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -157,12 +166,40 @@ func (h *ArtistHandler) UpdateArtist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.applyUpdate(r.Context(), existingArtist, &req, 0); err != nil {
+		http.Error(w, "failed to update artist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ToArtistResponse(existingArtist))
+}
+
+// validateArtistUpdate checks an update of an artist, also used for rollbacks to a revision
+func validateArtistUpdate(req *dto.UpdateArtistRequest) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.Biography == "" {
+		return errors.New("biography is required")
+	}
+	if len(req.Genres) == 0 {
+		return errors.New("at least one genre is required")
+	}
+	return nil
+}
+
+// applyUpdate saves the fields of a validated update to the artist and records the revision;
+// restoredFrom is the revision a rollback restores, 0 for an update
+func (h *ArtistHandler) applyUpdate(ctx context.Context, existingArtist *model.Artist, req *dto.UpdateArtistRequest, restoredFrom int) error {
 	// Store old state for logging
 	oldState := map[string]interface{}{
 		"name":      existingArtist.Name,
 		"biography": existingArtist.Biography,
 		"genres":    existingArtist.Genres,
 	}
+	oldRevisionState := artistRevisionState(existingArtist)
 
 	// Update fields
 	existingArtist.Name = req.Name
@@ -175,24 +212,49 @@ func (h *ArtistHandler) UpdateArtist(w http.ResponseWriter, r *http.Request) {
 		"genres":    existingArtist.Genres,
 	}
 
-	if err := h.Repo.Update(r.Context(), id, existingArtist); err != nil {
-		http.Error(w, "failed to update artist: "+err.Error(), http.StatusInternalServerError)
-		return
+	if err := h.Repo.Update(ctx, existingArtist.ID, existingArtist); err != nil {
+		return err
 	}
 
+	adminID := getAdminID(ctx)
+	recordRevision(ctx, h.Revisions, model.LikeTypeArtist, existingArtist.ID, oldRevisionState, artistRevisionState(existingArtist), adminID, restoredFrom)
+
 	// Log admin activity and state change
 	if h.Logger != nil {
-		adminID := getAdminID(r.Context())
-		h.Logger.LogAdminActivity(adminID, "UPDATE_ARTIST", "artists", map[string]interface{}{
-			"artistId": id,
-		})
+		if restoredFrom > 0 {
+			h.Logger.LogAdminActivity(adminID, "ROLLBACK_ARTIST", "artists", map[string]interface{}{
+				"artistId": existingArtist.ID,
+				"revision": restoredFrom,
+			})
+		} else {
+			h.Logger.LogAdminActivity(adminID, "UPDATE_ARTIST", "artists", map[string]interface{}{
+				"artistId": existingArtist.ID,
+			})
+		}
 		// Log state change if there are unexpected changes
 		h.Logger.LogStateChange("artist", oldState, newState, adminID)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(dto.ToArtistResponse(existingArtist))
+	// Subscriptions keep the artist name, recommendations its genres
+	event := map[string]interface{}{
+		"type":     "artist_updated",
+		"artistId": existingArtist.ID,
+		"name":     existingArtist.Name,
+		"genres":   existingArtist.Genres,
+	}
+	events.EmitEvent(ctx, h.SubscriptionsServiceURL, event)
+	events.EmitEvent(ctx, h.RecommendationServiceURL, event)
+	return nil
+}
+
+// artistRevisionState is the editable state of an artist kept in its revisions, keyed like
+// UpdateArtistRequest
+func artistRevisionState(artist *model.Artist) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      artist.Name,
+		"biography": artist.Biography,
+		"genres":    artist.Genres,
+	}
 }
 
 func (h *ArtistHandler) GetArtist(w http.ResponseWriter, r *http.Request) {
@@ -224,9 +286,15 @@ func (h *ArtistHandler) GetAllArtists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	artists, err := h.Repo.GetAll(r.Context())
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	artists, next, err := h.Repo.List(r.Context(), q)
 	if err != nil {
-		http.Error(w, "failed to get artists: "+err.Error(), http.StatusInternalServerError)
+		writeListError(w, err, "artists")
 		return
 	}
 
@@ -235,6 +303,7 @@ func (h *ArtistHandler) GetAllArtists(w http.ResponseWriter, r *http.Request) {
 		responses[i] = dto.ToArtistResponse(artist)
 	}
 
+	setNextCursor(w, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responses)
@@ -255,7 +324,10 @@ func (h *ArtistHandler) DeleteArtist(w http.ResponseWriter, r *http.Request) {
 	// Get artist before deletion for logging and events
 	artist, _ := h.Repo.GetByID(r.Context(), id)
 
-	if err := h.Repo.Delete(r.Context(), id); err != nil {
+	// The artist goes to the trash with the albums and songs it has no other artists on, and the
+	// songs of those albums; its image is deleted when the trash is purged
+	adminID := getAdminID(r.Context())
+	if err := h.Repo.SoftDelete(r.Context(), id, adminID); err != nil {
 		if err.Error() == "artist not found" {
 			http.Error(w, "artist not found", http.StatusNotFound)
 			return
@@ -263,6 +335,16 @@ func (h *ArtistHandler) DeleteArtist(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "failed to delete artist: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	albumIDs, err := h.AlbumRepo.SoftDeleteByArtist(r.Context(), id, adminID)
+	if err != nil {
+		http.Error(w, "failed to delete the artist's albums: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	songIDs, err := h.SongRepo.SoftDeleteByArtist(r.Context(), id, albumIDs, adminID)
+	if err != nil {
+		http.Error(w, "failed to delete the artist's songs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	// Emit deletion event to recommendation-service (asynchronous)
 	// Use context.Background() instead of r.Context() because the HTTP request context
@@ -273,10 +355,16 @@ func (h *ArtistHandler) DeleteArtist(w http.ResponseWriter, r *http.Request) {
 			ArtistID: id,
 		})
 	}
+	// artist_deleted only detaches the artist's songs in the recommendation graph
+	for _, songID := range songIDs {
+		events.EmitEvent(context.Background(), h.RecommendationServiceURL, events.DeletedSongEvent{
+			Type:   events.EventTypeDeletedSong,
+			SongID: songID,
+		})
+	}
 
 	// Log admin activity
 	if h.Logger != nil {
-		adminID := getAdminID(r.Context())
 		h.Logger.LogAdminActivity(adminID, "DELETE_ARTIST", "artists", map[string]interface{}{
 			"artistId": id,
 			"name":     artist.Name,
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"content-service/internal/model"
+	"content-service/internal/storage"
+)
+
+// audioBlobKey is the content-addressed key of an audio file; the extension gives the content type
+func audioBlobKey(sum, ext string) string {
+	return fmt.Sprintf("audio/blobs/%s/%s%s", sum[:2], sum, ext)
+}
+
+// audioBlobGracePeriod is how long a blob without users is kept before its file is deleted, so that an
+// upload of the same bytes never races the deletion
+const audioBlobGracePeriod = 24 * time.Hour
+
+// audioBlobCleanupInterval is how often unreferenced blobs are collected
+const audioBlobCleanupInterval = time.Hour
+
+// storeAudioBlob stores an uploaded file by its SHA-256 in the default backend and references it for the
+// song. The file is read twice, to hash it and to stream it to the backend, and never held in memory.
+// A file that is already stored (the same bytes uploaded for another song, or again) is not written
+// a second time. The blob is referenced before its file is checked or written, so it cannot be collected
+// in between; if the file cannot be stored a reference added here is released again.
+func (h *SongHandler) storeAudioBlob(ctx context.Context, songID string, src io.ReadSeeker, ext string) (*model.StorageRef, error) {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	digest := sha256.New()
+	size, err := io.Copy(digest, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	sum := hex.EncodeToString(digest.Sum(nil))
+
+	ref := &model.StorageRef{Backend: h.Storage.DefaultBackend(), Key: audioBlobKey(sum, ext), SHA256: sum}
+	blob, added, err := h.Blobs.Reference(ctx, &model.AudioBlob{SHA256: sum, Backend: ref.Backend, Key: ref.Key, Size: size}, songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reference audio blob: %w", err)
+	}
+
+	if blob.Status == model.BlobStatusOK {
+		if store, err := h.Storage.Get(blob.Backend); err == nil {
+			if info, err := store.Stat(ctx, blob.Key); err == nil && info.Size == blob.Size {
+				log.Printf("Audio %s is already stored at %s:%s, reusing it", sum, blob.Backend, blob.Key)
+				return &model.StorageRef{Backend: blob.Backend, Key: blob.Key, SHA256: sum}, nil
+			}
+		}
+	}
+
+	// New blob, or its file is missing or damaged: write it to the default backend
+	_, err = src.Seek(0, io.SeekStart)
+	if err == nil {
+		err = h.Storage.Default().Put(ctx, ref.Key, src, size)
+	}
+	if err == nil {
+		err = h.Blobs.Save(ctx, &model.AudioBlob{SHA256: sum, Backend: ref.Backend, Key: ref.Key, Size: size})
+		if err != nil {
+			err = fmt.Errorf("failed to record audio blob: %w", err)
+		}
+	}
+	if err != nil {
+		if added {
+			if releaseErr := h.Blobs.ReleaseReference(ctx, sum, songID); releaseErr != nil {
+				log.Printf("Failed to release audio blob %s of song %s: %v", sum, songID, releaseErr)
+			}
+		}
+		return nil, err
+	}
+	return ref, nil
+}
+
+// releaseAudio is called when a song stops using an audio file (replaced or song deleted). A shared
+// blob is collected once it has had no users for the grace period; files stored before content
+// addressing belong to one song and are deleted now.
+func (h *SongHandler) releaseAudio(ctx context.Context, songID string, ref *model.StorageRef) {
+	if ref == nil || ref.Backend == storage.BackendExternal {
+		return
+	}
+	if ref.SHA256 != "" {
+		if err := h.Blobs.ReleaseReference(ctx, ref.SHA256, songID); err != nil {
+			log.Printf("Failed to release audio blob %s of song %s: %v", ref.SHA256, songID, err)
+		}
+		return
+	}
+	store, err := h.Storage.Get(ref.Backend)
+	if err != nil {
+		return
+	}
+	if err := store.Delete(ctx, ref.Key); err != nil {
+		log.Printf("Failed to delete audio %s:%s: %v", ref.Backend, ref.Key, err)
+	}
+}
+
+// ReleaseSongAudio releases the audio file of a deleted song
+func (h *SongHandler) ReleaseSongAudio(song *model.Song) {
+	h.releaseAudio(context.Background(), song.ID, song.AudioFile)
+}
+
+// StartAudioBlobCleanup deletes the files of blobs that have had no users for the grace period,
+// every audioBlobCleanupInterval until ctx is cancelled
+func StartAudioBlobCleanup(ctx context.Context, h *SongHandler) {
+	ticker := time.NewTicker(audioBlobCleanupInterval)
+	defer ticker.Stop()
+
+	h.deleteUnreferencedBlobs(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.deleteUnreferencedBlobs(ctx)
+		}
+	}
+}
+
+func (h *SongHandler) deleteUnreferencedBlobs(ctx context.Context) {
+	cutoff := time.Now().Add(-audioBlobGracePeriod)
+	blobs, err := h.Blobs.ListUnreferenced(ctx, cutoff, 100)
+	if err != nil {
+		log.Printf("Audio blob cleanup: %v", err)
+		return
+	}
+	for _, blob := range blobs {
+		// Once claimed the blob cannot be referenced, so its file is not reused while it is deleted
+		claimed, err := h.Blobs.ClaimUnreferenced(ctx, blob.SHA256, cutoff)
+		if err != nil {
+			log.Printf("Audio blob cleanup: %s: %v", blob.SHA256, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if store, err := h.Storage.Get(blob.Backend); err == nil {
+			if err := store.Delete(ctx, blob.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				// Stays claimed and is retried on the next run
+				log.Printf("Audio blob cleanup: failed to delete %s:%s: %v", blob.Backend, blob.Key, err)
+				continue
+			}
+		}
+		if err := h.Blobs.Remove(ctx, blob.SHA256); err != nil {
+			log.Printf("Audio blob cleanup: %s: %v", blob.SHA256, err)
+			continue
+		}
+		log.Printf("Deleted unreferenced audio blob %s at %s:%s", blob.SHA256, blob.Backend, blob.Key)
+	}
+}
+
+// digestHeader is the RFC 3230 Digest value of a SHA-256 in hex
+func digestHeader(sum string) string {
+	raw, err := hex.DecodeString(sum)
+	if err != nil {
+		return ""
+	}
+	return "sha-256=" + base64.StdEncoding.EncodeToString(raw)
+}
+
+// verifyingReader hashes a complete read of a blob and reports a mismatch at the end
+type verifyingReader struct {
+	io.Reader
+	hash     hash.Hash
+	expected string
+	onResult func(ok bool)
+}
+
+func newVerifyingReader(r io.Reader, expected string, onResult func(ok bool)) *verifyingReader {
+	v := &verifyingReader{hash: sha256.New(), expected: expected, onResult: onResult}
+	v.Reader = io.TeeReader(r, v.hash)
+	return v
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.Reader.Read(p)
+	if err == io.EOF && v.onResult != nil {
+		v.onResult(hex.EncodeToString(v.hash.Sum(nil)) == v.expected)
+		v.onResult = nil
+	}
+	return n, err
+}
+
+// verifyOnRead decides whether a complete read of a blob is hashed, for a sample of reads
+func (h *SongHandler) verifyOnRead() bool {
+	return h.VerifySampleRate >= 1 || h.VerifySampleRate > 0 && rand.Float64() < h.VerifySampleRate
+}
+
+// recordVerification stores the result of verifying a blob on read
+func (h *SongHandler) recordVerification(sum string) func(ok bool) {
+	return func(ok bool) {
+		status := model.BlobStatusOK
+		if !ok {
+			status = model.BlobStatusCorrupted
+			log.Printf("Integrity check failed: audio blob %s does not match its SHA-256", sum)
+		}
+		if err := h.Blobs.SetStatus(context.Background(), sum, status); err != nil {
+			log.Printf("Failed to record verification of audio blob %s: %v", sum, err)
+		}
+	}
+}
+
+// blobReport is one blob of the integrity report
+type blobReport struct {
+	*model.AudioBlob
+	Error string `json:"error,omitempty"`
+}
+
+// AudioIntegrity checks the stored audio blobs and reports the missing and corrupted ones. By default the
+// size of each file is compared; verify=full reads every file and compares its SHA-256.
+// GET /audio/integrity?verify=full
+func (h *SongHandler) AudioIntegrity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	full := r.URL.Query().Get("verify") == "full"
+
+	blobs, err := h.Blobs.List(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	missing := []blobReport{}
+	corrupted := []blobReport{}
+	ok := 0
+	for _, blob := range blobs {
+		status, reason := h.checkBlob(r.Context(), blob, full)
+		if r.Context().Err() != nil {
+			return
+		}
+		if status != blob.Status || full {
+			if err := h.Blobs.SetStatus(r.Context(), blob.SHA256, status); err != nil {
+				log.Printf("Failed to record verification of audio blob %s: %v", blob.SHA256, err)
+			}
+			blob.Status = status
+		}
+		switch status {
+		case model.BlobStatusMissing:
+			missing = append(missing, blobReport{AudioBlob: blob, Error: reason})
+		case model.BlobStatusCorrupted:
+			corrupted = append(corrupted, blobReport{AudioBlob: blob, Error: reason})
+		default:
+			ok++
+		}
+	}
+
+	unhashed, err := h.Repo.CountUnhashedAudio(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromSongContext(r.Context()), "AUDIO_INTEGRITY_CHECK", "songs", map[string]interface{}{
+			"full":      full,
+			"checked":   len(blobs),
+			"missing":   len(missing),
+			"corrupted": len(corrupted),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fullVerification": full,
+		"checked":          len(blobs),
+		"ok":               ok,
+		"missing":          missing,
+		"corrupted":        corrupted,
+		// Songs uploaded before content addressing have no hash to verify
+		"unhashedSongs": unhashed,
+	})
+}
+
+// checkBlob returns the status of a stored blob and why it is not ok
+func (h *SongHandler) checkBlob(ctx context.Context, blob *model.AudioBlob, full bool) (string, string) {
+	store, err := h.Storage.Get(blob.Backend)
+	if err != nil {
+		return model.BlobStatusMissing, err.Error()
+	}
+	info, err := store.Stat(ctx, blob.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return model.BlobStatusMissing, "file not found"
+	}
+	if err != nil {
+		// Storage unavailable: keep the last known status
+		return blob.Status, err.Error()
+	}
+	if info.Size != blob.Size {
+		return model.BlobStatusCorrupted, fmt.Sprintf("size %d, expected %d", info.Size, blob.Size)
+	}
+	if !full {
+		// A blob found corrupted on read stays so until a full verification
+		return blob.Status, ""
+	}
+
+	body, err := store.Get(ctx, blob.Key)
+	if err != nil {
+		return blob.Status, err.Error()
+	}
+	defer body.Close()
+	digest := sha256.New()
+	if _, err := io.Copy(digest, body); err != nil {
+		return blob.Status, err.Error()
+	}
+	if sum := hex.EncodeToString(digest.Sum(nil)); sum != blob.SHA256 {
+		return model.BlobStatusCorrupted, "sha256 " + sum
+	}
+	return model.BlobStatusOK, ""
+}
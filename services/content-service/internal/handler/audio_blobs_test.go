@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"content-service/internal/model"
+	"content-service/internal/storage"
+	"content-service/internal/store"
+)
+
+// newTestSongHandler returns a handler whose audio is kept in a local store in a temporary directory
+func newTestSongHandler(t *testing.T) (*SongHandler, storage.BlobStore) {
+	t.Helper()
+	local := storage.NewLocalStore(t.TempDir())
+	stores := storage.NewStores(storage.BackendLocal)
+	stores.Register(storage.BackendLocal, local)
+	return &SongHandler{Storage: stores}, local
+}
+
+func putTestFile(t *testing.T, s storage.BlobStore, key string) {
+	t.Helper()
+	if err := storage.PutBytes(context.Background(), s, key, []byte("audio")); err != nil {
+		t.Fatalf("PutBytes(%s): %v", key, err)
+	}
+}
+
+func fileExists(t *testing.T, s storage.BlobStore, key string) bool {
+	t.Helper()
+	_, err := s.Stat(context.Background(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		t.Fatalf("Stat(%s): %v", key, err)
+	}
+	return true
+}
+
+func TestSameAudio(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *model.StorageRef
+		want bool
+	}{
+		{"both nil", nil, nil, true},
+		{"one nil", &model.StorageRef{Backend: "local", Key: "songs/a.mp3"}, nil, false},
+		{
+			name: "same blob moved to another backend",
+			a:    &model.StorageRef{Backend: "local", Key: "audio/ab/abc.mp3", SHA256: "abc"},
+			b:    &model.StorageRef{Backend: "s3", Key: "audio/ab/abc.mp3", SHA256: "abc"},
+			want: true,
+		},
+		{
+			name: "different blobs",
+			a:    &model.StorageRef{Backend: "local", Key: "audio/ab/abc.mp3", SHA256: "abc"},
+			b:    &model.StorageRef{Backend: "local", Key: "audio/de/def.mp3", SHA256: "def"},
+			want: false,
+		},
+		{
+			name: "same legacy file",
+			a:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			b:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			want: true,
+		},
+		{
+			name: "different legacy files",
+			a:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			b:    &model.StorageRef{Backend: "local", Key: "songs/b.mp3"},
+			want: false,
+		},
+		{
+			name: "legacy file replaced by blob at the same location",
+			a:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			b:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3", SHA256: "abc"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameAudio(tt.a, tt.b); got != tt.want {
+				t.Errorf("sameAudio = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Legacy files are deleted right away; content-addressed blobs are released through the repository,
+// which is not set here, so a release of one would panic
+func TestDeleteReplacedAudio(t *testing.T) {
+	tests := []struct {
+		name        string
+		previous    *model.StorageRef
+		current     *model.StorageRef
+		wantDeleted bool
+	}{
+		{
+			name:     "no previous audio",
+			previous: nil,
+			current:  &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+		},
+		{
+			name:     "same legacy file",
+			previous: &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			current:  &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+		},
+		{
+			name:     "same blob after migration",
+			previous: &model.StorageRef{Backend: "local", Key: "songs/a.mp3", SHA256: "abc"},
+			current:  &model.StorageRef{Backend: "s3", Key: "audio/ab/abc.mp3", SHA256: "abc"},
+		},
+		{
+			name:        "legacy file replaced",
+			previous:    &model.StorageRef{Backend: "local", Key: "songs/a.mp3"},
+			current:     &model.StorageRef{Backend: "local", Key: "audio/de/def.mp3", SHA256: "def"},
+			wantDeleted: true,
+		},
+		{
+			name:     "external audio is never deleted",
+			previous: &model.StorageRef{Backend: storage.BackendExternal, Key: "songs/a.mp3"},
+			current:  &model.StorageRef{Backend: "local", Key: "audio/de/def.mp3", SHA256: "def"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, local := newTestSongHandler(t)
+			putTestFile(t, local, "songs/a.mp3")
+
+			h.deleteReplacedAudio("song-1", tt.previous, tt.current)
+
+			if deleted := !fileExists(t, local, "songs/a.mp3"); deleted != tt.wantDeleted {
+				t.Errorf("file deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestDeleteReplacedAudioReleasesBlob(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("release", func(mt *mtest.T) {
+		h, local := newTestSongHandler(mt.T)
+		h.Blobs = store.NewAudioBlobRepository(mt.DB)
+		putTestFile(mt.T, local, "audio/ab/abc.mp3")
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		h.deleteReplacedAudio("song-1",
+			&model.StorageRef{Backend: "local", Key: "audio/ab/abc.mp3", SHA256: "abc"},
+			&model.StorageRef{Backend: "local", Key: "audio/de/def.mp3", SHA256: "def"})
+
+		started := mt.GetStartedEvent()
+		if started == nil || started.CommandName != "update" {
+			mt.Fatalf("expected the blob reference to be released with an update, got %v", started)
+		}
+		filter := started.Command.Lookup("updates", "0", "q")
+		if id, _ := filter.Document().Lookup("_id").StringValueOK(); id != "abc" {
+			mt.Errorf("released blob = %q, want %q", id, "abc")
+		}
+		// A shared blob is only collected after the grace period
+		if !fileExists(mt.T, local, "audio/ab/abc.mp3") {
+			mt.Errorf("blob file deleted on release")
+		}
+	})
+}
+
+func TestDeleteUnreferencedBlobs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name        string
+		claimed     int32
+		wantDeleted bool
+		wantRemoved bool
+	}{
+		{name: "claimed blob is deleted", claimed: 1, wantDeleted: true, wantRemoved: true},
+		{name: "blob referenced again is kept", claimed: 0, wantDeleted: false, wantRemoved: false},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			h, local := newTestSongHandler(mt.T)
+			h.Blobs = store.NewAudioBlobRepository(mt.DB)
+			putTestFile(mt.T, local, "audio/ab/abc.mp3")
+
+			ns := mt.DB.Name() + ".audio_blobs"
+			mt.AddMockResponses(
+				mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+					{Key: "_id", Value: "abc"},
+					{Key: "backend", Value: storage.BackendLocal},
+					{Key: "key", Value: "audio/ab/abc.mp3"},
+					{Key: "refCount", Value: 0},
+				}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: tt.claimed}, bson.E{Key: "nModified", Value: tt.claimed}),
+				mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			)
+
+			h.deleteUnreferencedBlobs(context.Background())
+
+			if started := mt.GetStartedEvent(); started == nil || started.CommandName != "find" {
+				mt.Fatalf("expected unreferenced blobs to be listed, got %v", started)
+			}
+			claim := mt.GetStartedEvent()
+			if claim == nil || claim.CommandName != "update" {
+				mt.Fatalf("expected the blob to be claimed, got %v", claim)
+			}
+			// The claim re-checks that the blob still has no users
+			if _, err := claim.Command.LookupErr("updates", "0", "q", "refCount"); err != nil {
+				mt.Errorf("claim filter does not check refCount: %v", claim.Command)
+			}
+			if status, _ := claim.Command.Lookup("updates", "0", "u", "$set", "status").StringValueOK(); status != model.BlobStatusDeleting {
+				mt.Errorf("claim sets status %q, want %q", status, model.BlobStatusDeleting)
+			}
+
+			if deleted := !fileExists(mt.T, local, "audio/ab/abc.mp3"); deleted != tt.wantDeleted {
+				mt.Errorf("file deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			remove := mt.GetStartedEvent()
+			if removed := remove != nil && remove.CommandName == "delete"; removed != tt.wantRemoved {
+				mt.Errorf("blob record removed = %v, want %v", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
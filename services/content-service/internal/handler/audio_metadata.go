@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/audiometa"
+	"content-service/internal/model"
+	"content-service/internal/storage"
+)
+
+// durationTolerance is how far the catalog duration may be from the measured one before it is flagged
+const durationTolerance = 2 * time.Second
+
+// extractAudioMetadata reads format and tags of an uploaded file and stores the embedded cover.
+// Returns nil when the file cannot be parsed; the upload itself still succeeds.
+func (h *SongHandler) extractAudioMetadata(ctx context.Context, song *model.Song, data []byte) *model.AudioMetadata {
+	meta, err := audiometa.Parse(data)
+	if err != nil {
+		log.Printf("Failed to read audio metadata of song %s: %v", song.ID, err)
+		return nil
+	}
+
+	audio := &model.AudioMetadata{
+		Format:          meta.Format,
+		DurationSeconds: math.Round(meta.Duration.Seconds()*100) / 100,
+		Bitrate:         meta.Bitrate,
+		SampleRate:      meta.SampleRate,
+		Channels:        meta.Channels,
+		Title:           meta.Tags.Title,
+		Artist:          meta.Tags.Artist,
+		Album:           meta.Tags.Album,
+		TrackNumber:     meta.Tags.TrackNumber,
+		Year:            meta.Tags.Year,
+		ExtractedAt:     time.Now(),
+	}
+
+	if cover := meta.Tags.Cover; cover != nil {
+		coverRef := &model.StorageRef{Backend: h.Storage.DefaultBackend(), Key: fmt.Sprintf("covers/songs/%s%s", song.ID, cover.Extension())}
+		if err := storage.PutBytes(ctx, h.Storage.Default(), coverRef.Key, cover.Data); err != nil {
+			log.Printf("Failed to store cover of song %s: %v", song.ID, err)
+		} else {
+			audio.Cover = coverRef
+		}
+	}
+
+	audio.Mismatches = h.catalogMismatches(ctx, song, meta)
+	return audio
+}
+
+// catalogMismatches compares the tags of the file with the catalog entry of the song.
+// Only tags present in the file are compared.
+func (h *SongHandler) catalogMismatches(ctx context.Context, song *model.Song, meta *audiometa.Metadata) []model.MetadataMismatch {
+	var mismatches []model.MetadataMismatch
+	add := func(field, catalog, file string) {
+		mismatches = append(mismatches, model.MetadataMismatch{Field: field, Catalog: catalog, File: file})
+	}
+
+	tags := meta.Tags
+	if tags.Title != "" && !sameText(tags.Title, song.Name) {
+		add("title", song.Name, tags.Title)
+	}
+
+	if tags.Artist != "" && len(song.ArtistIDs) > 0 {
+		artists, err := h.ArtistRepo.GetByIDs(ctx, song.ArtistIDs)
+		if err == nil {
+			var names []string
+			matched := false
+			for _, id := range song.ArtistIDs {
+				artist, ok := artists[id]
+				if !ok {
+					continue
+				}
+				names = append(names, artist.Name)
+				// The tag often lists all artists ("A feat. B", "A & B")
+				if strings.Contains(normalizeText(tags.Artist), normalizeText(artist.Name)) {
+					matched = true
+				}
+			}
+			if len(names) > 0 && !matched {
+				add("artist", strings.Join(names, ", "), tags.Artist)
+			}
+		}
+	}
+
+	if (tags.Album != "" || tags.Year != 0) && song.AlbumID != "" {
+		if album, err := h.AlbumRepo.GetByID(ctx, song.AlbumID); err == nil {
+			if tags.Album != "" && !sameText(tags.Album, album.Name) {
+				add("album", album.Name, tags.Album)
+			}
+			if tags.Year != 0 && !album.ReleaseDate.IsZero() && album.ReleaseDate.Year() != tags.Year {
+				add("year", strconv.Itoa(album.ReleaseDate.Year()), strconv.Itoa(tags.Year))
+			}
+		}
+	}
+
+	if song.Duration > 0 && meta.Duration > 0 {
+		diff := time.Duration(song.Duration)*time.Second - meta.Duration
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > durationTolerance {
+			add("duration", strconv.Itoa(song.Duration), strconv.Itoa(int(math.Round(meta.Duration.Seconds()))))
+		}
+	}
+	return mismatches
+}
+
+// normalizeText lowercases and collapses whitespace, so tags differing only in case or spacing match
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func sameText(a, b string) bool {
+	return normalizeText(a) == normalizeText(b)
+}
+
+// GetCover serves the cover image embedded in the song's audio file
+// GET /songs/{id}/cover
+func (h *SongHandler) GetCover(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	if id == "" {
+		http.Error(w, "song ID is required", http.StatusBadRequest)
+		return
+	}
+
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+	if song.Audio == nil || song.Audio.Cover == nil {
+		http.Error(w, "song has no cover", http.StatusNotFound)
+		return
+	}
+	cover := song.Audio.Cover
+
+	store, err := h.Storage.Get(cover.Backend)
+	if err != nil {
+		http.Error(w, "cover storage not available", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := store.Get(r.Context(), cover.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "song has no cover", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to open cover %s:%s: %v", cover.Backend, cover.Key, err)
+		http.Error(w, "failed to retrieve cover", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	contentType := "image/jpeg"
+	if strings.HasSuffix(cover.Key, ".png") {
+		contentType = "image/png"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	if _, err := io.Copy(w, body); err != nil && r.Context().Err() == nil {
+		log.Printf("Serving cover %s interrupted: %v", cover.Key, err)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/storage"
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// byteRange is an inclusive range of bytes of a file
+type byteRange struct {
+	start, end int64
+}
+
+func (br byteRange) length() int64 {
+	return br.end - br.start + 1
+}
+
+// parseByteRange parses a single range of a Range header ("bytes=0-99", "bytes=100-", "bytes=-500")
+// for a file of the given size. ok is false when the header should be ignored and the whole file
+// served (missing, malformed, other units or multiple ranges, which players do not use for audio).
+func parseByteRange(header string, size int64) (br byteRange, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return byteRange{}, false, nil
+	}
+
+	if startStr == "" {
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 {
+			return byteRange{}, false, nil
+		}
+		if n == 0 || size == 0 {
+			return byteRange{}, true, errRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return byteRange{start: size - n, end: size - 1}, true, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return byteRange{}, false, nil
+	}
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, false, nil
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size {
+		return byteRange{}, true, errRangeNotSatisfiable
+	}
+	return byteRange{start: start, end: end}, true, nil
+}
+
+// isPlaybackStart reports whether a stream request starts playing the song from the beginning,
+// rather than continuing or seeking within it
+func isPlaybackStart(rangeHeader string) bool {
+	rangeHeader = strings.TrimSpace(rangeHeader)
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// audioETag identifies a version of a stored file, it changes when the file is overwritten
+func audioETag(info *storage.ObjectInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.Size, info.ModTime.UnixMilli())
+}
+
+// ifRangeMatches reports whether the If-Range precondition allows serving a range.
+// A strong ETag or the exact Last-Modified date must match, otherwise the whole file is sent.
+func ifRangeMatches(r *http.Request, etag string, modified time.Time) bool {
+	ifRange := strings.TrimSpace(r.Header.Get("If-Range"))
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return ifRange == etag
+	}
+	t, err := http.ParseTime(ifRange)
+	return err == nil && modified.Unix() == t.Unix()
+}
+
+// audioContentType returns the content type for the audio file extension
+func audioContentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return "audio/wav"
+	case ".ogg":
+		return "audio/ogg"
+	case ".m4a":
+		return "audio/mp4"
+	case ".aac":
+		return "audio/aac"
+	case ".flac":
+		return "audio/flac"
+	default:
+		return "audio/mpeg"
+	}
+}
+
+// streamStoredAudio streams a stored audio file, honoring Range and If-Range.
+// Only the requested bytes are read from the store and copied to the client as they arrive.
+// sum is the SHA-256 of content-addressed files ("" otherwise): it is the ETag and Digest, and a
+// sample of complete reads is verified against it.
+func (h *SongHandler) streamStoredAudio(w http.ResponseWriter, r *http.Request, store storage.BlobStore, key string, info *storage.ObjectInfo, sum string) {
+	size := info.Size
+	etag := audioETag(info)
+	modified := info.ModTime.Truncate(time.Second)
+	if sum != "" {
+		etag = `"` + sum + `"`
+		w.Header().Set("Digest", digestHeader(sum))
+	}
+
+	w.Header().Set("Content-Type", audioContentType(key))
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+
+	br := byteRange{start: 0, end: size - 1}
+	partial := false
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" && ifRangeMatches(r, etag, modified) {
+		parsed, ok, err := parseByteRange(rangeHeader, size)
+		if err != nil {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			http.Error(w, "requested range not satisfiable", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		if ok {
+			br, partial = parsed, true
+		}
+	}
+
+	length := br.length()
+	w.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	statusCode := http.StatusOK
+	if partial {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", br.start, br.end, size))
+		statusCode = http.StatusPartialContent
+	}
+
+	if r.Method == http.MethodHead || length == 0 {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	body, err := store.GetRange(r.Context(), key, br.start, length)
+	if err != nil {
+		log.Printf("Failed to open %s from storage: %v", key, err)
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Range")
+		http.Error(w, "failed to retrieve audio file", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	var reader io.Reader = io.LimitReader(body, length)
+	if sum != "" && length == size && h.verifyOnRead() {
+		reader = newVerifyingReader(reader, sum, h.recordVerification(sum))
+	}
+
+	w.WriteHeader(statusCode)
+	if _, err := io.Copy(w, reader); err != nil && r.Context().Err() == nil {
+		// Headers are sent, the client sees a truncated body
+		log.Printf("Streaming %s (bytes %d-%d) interrupted: %v", key, br.start, br.end, err)
+	}
+}
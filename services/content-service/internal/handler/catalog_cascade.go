@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/model"
+)
+
+// What purging an artist does with songs and albums it shares with other artists
+const (
+	SharedSongsDetach = "detach"
+	SharedSongsDelete = "delete"
+)
+
+// planAlbumDeletion backs up an album and all of its songs
+func (h *TrashHandler) planAlbumDeletion(ctx context.Context, album *model.Album) (*dto.CatalogBackup, error) {
+	songs, err := h.Songs.Repo.ListByAlbums(ctx, []string{album.ID})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.CatalogBackup{
+		Albums:         []*model.Album{album},
+		Songs:          songs,
+		DetachedAlbums: []*model.Album{},
+		DetachedSongs:  []*model.Song{},
+	}, nil
+}
+
+// planArtistDeletion backs up an artist with its albums and songs. With the detach policy albums
+// and songs that have other artists are kept without the artist, except songs of deleted albums.
+func (h *TrashHandler) planArtistDeletion(ctx context.Context, artist *model.Artist, policy string) (*dto.CatalogBackup, error) {
+	backup := &dto.CatalogBackup{
+		Artist:         artist,
+		Albums:         []*model.Album{},
+		Songs:          []*model.Song{},
+		DetachedAlbums: []*model.Album{},
+		DetachedSongs:  []*model.Song{},
+	}
+
+	albums, err := h.Albums.Repo.ListByArtist(ctx, artist.ID)
+	if err != nil {
+		return nil, err
+	}
+	deletedAlbums := map[string]bool{}
+	albumIDs := []string{}
+	for _, album := range albums {
+		if policy == SharedSongsDetach && len(album.ArtistIDs) > 1 {
+			backup.DetachedAlbums = append(backup.DetachedAlbums, album)
+			continue
+		}
+		backup.Albums = append(backup.Albums, album)
+		deletedAlbums[album.ID] = true
+		albumIDs = append(albumIDs, album.ID)
+	}
+
+	songs, err := h.Songs.Repo.ListByArtist(ctx, artist.ID)
+	if err != nil {
+		return nil, err
+	}
+	albumSongs, err := h.Songs.Repo.ListByAlbums(ctx, albumIDs)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, song := range append(songs, albumSongs...) {
+		if seen[song.ID] {
+			continue
+		}
+		seen[song.ID] = true
+		if policy == SharedSongsDetach && !deletedAlbums[song.AlbumID] && len(song.ArtistIDs) > 1 {
+			backup.DetachedSongs = append(backup.DetachedSongs, song)
+			continue
+		}
+		backup.Songs = append(backup.Songs, song)
+	}
+	return backup, nil
+}
+
+// CatalogBackup returns what deleting an album or artist removes, trash included
+// GET /catalog/internal/backup?albumId={id}
+// GET /catalog/internal/backup?artistId={id}&sharedSongs=detach|delete
+func (h *TrashHandler) CatalogBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var backup *dto.CatalogBackup
+	var err error
+	switch {
+	case query.Get("albumId") != "":
+		album, getErr := h.Albums.Repo.GetByID(r.Context(), query.Get("albumId"))
+		if getErr != nil {
+			album, getErr = h.Albums.Repo.GetDeleted(r.Context(), query.Get("albumId"))
+		}
+		if getErr != nil {
+			http.Error(w, "album not found", http.StatusNotFound)
+			return
+		}
+		backup, err = h.planAlbumDeletion(r.Context(), album)
+
+	case query.Get("artistId") != "":
+		policy := query.Get("sharedSongs")
+		if policy == "" {
+			policy = h.SharedSongs
+		}
+		if policy != SharedSongsDetach && policy != SharedSongsDelete {
+			http.Error(w, "sharedSongs must be detach or delete", http.StatusBadRequest)
+			return
+		}
+		artist, getErr := h.Artists.Repo.GetByID(r.Context(), query.Get("artistId"))
+		if getErr != nil {
+			artist, getErr = h.Artists.Repo.GetDeleted(r.Context(), query.Get("artistId"))
+		}
+		if getErr != nil {
+			http.Error(w, "artist not found", http.StatusNotFound)
+			return
+		}
+		backup, err = h.planArtistDeletion(r.Context(), artist, policy)
+
+	default:
+		http.Error(w, "albumId or artistId parameter is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to back up catalog: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(backup)
+}
+
+func decodeCatalogBackup(w http.ResponseWriter, r *http.Request) (*dto.CatalogBackup, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	var backup dto.CatalogBackup
+	if err := json.NewDecoder(r.Body).Decode(&backup); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return nil, false
+	}
+	return &backup, true
+}
+
+// DetachCatalog removes the artist from its shared albums and songs
+// POST /catalog/internal/detach with a CatalogBackup
+func (h *TrashHandler) DetachCatalog(w http.ResponseWriter, r *http.Request) {
+	backup, ok := decodeCatalogBackup(w, r)
+	if !ok {
+		return
+	}
+	if backup.Artist == nil {
+		http.Error(w, "artist is required", http.StatusBadRequest)
+		return
+	}
+	if err := h.detachCatalog(r.Context(), backup); err != nil {
+		http.Error(w, "failed to detach artist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) detachCatalog(ctx context.Context, backup *dto.CatalogBackup) error {
+	if backup.Artist == nil {
+		return nil
+	}
+	songIDs := make([]string, 0, len(backup.DetachedSongs))
+	for _, song := range backup.DetachedSongs {
+		songIDs = append(songIDs, song.ID)
+	}
+	if err := h.Songs.Repo.PullArtist(ctx, songIDs, backup.Artist.ID); err != nil {
+		return err
+	}
+	albumIDs := make([]string, 0, len(backup.DetachedAlbums))
+	for _, album := range backup.DetachedAlbums {
+		albumIDs = append(albumIDs, album.ID)
+	}
+	return h.Albums.Repo.PullArtist(ctx, albumIDs, backup.Artist.ID)
+}
+
+// DeleteCatalog permanently deletes the backed-up songs, albums and artist. Their files are kept
+// until ReleaseCatalog, so the deletion can still be undone with RestoreCatalog.
+// POST /catalog/internal/delete with a CatalogBackup
+func (h *TrashHandler) DeleteCatalog(w http.ResponseWriter, r *http.Request) {
+	backup, ok := decodeCatalogBackup(w, r)
+	if !ok {
+		return
+	}
+	if err := h.deleteCatalog(r.Context(), backup); err != nil {
+		http.Error(w, "failed to delete catalog: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) deleteCatalog(ctx context.Context, backup *dto.CatalogBackup) error {
+	songIDs := make([]string, 0, len(backup.Songs))
+	for _, song := range backup.Songs {
+		songIDs = append(songIDs, song.ID)
+	}
+	if err := h.Songs.Repo.DeleteMany(ctx, songIDs); err != nil {
+		return err
+	}
+	albumIDs := make([]string, 0, len(backup.Albums))
+	for _, album := range backup.Albums {
+		albumIDs = append(albumIDs, album.ID)
+	}
+	if err := h.Albums.Repo.DeleteMany(ctx, albumIDs); err != nil {
+		return err
+	}
+	if backup.Artist != nil {
+		if err := h.Artists.Repo.Delete(ctx, backup.Artist.ID); err != nil && err.Error() != "artist not found" {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreCatalog writes back backed-up songs, albums and artist, deleted or detached (saga compensation)
+// POST /catalog/internal/restore with a CatalogBackup
+func (h *TrashHandler) RestoreCatalog(w http.ResponseWriter, r *http.Request) {
+	backup, ok := decodeCatalogBackup(w, r)
+	if !ok {
+		return
+	}
+
+	if backup.Artist != nil {
+		if err := h.Artists.Repo.Put(r.Context(), backup.Artist); err != nil {
+			http.Error(w, "failed to restore artist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := h.Albums.Repo.Put(r.Context(), append(backup.Albums, backup.DetachedAlbums...)); err != nil {
+		http.Error(w, "failed to restore albums: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	songs := append(backup.Songs, backup.DetachedSongs...)
+	if err := h.Songs.Repo.Put(r.Context(), songs); err != nil {
+		http.Error(w, "failed to restore songs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The lyrics text of the search index is not part of the JSON backup
+	for _, song := range songs {
+		if len(song.LyricsLanguages) == 0 {
+			continue
+		}
+		if err := h.Songs.refreshLyricsIndex(r.Context(), song.ID); err != nil {
+			log.Printf("Failed to index the lyrics of restored song %s: %v", song.ID, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReleaseCatalog deletes the audio, lyrics and images of deleted songs, albums and artist. It is the
+// last step of their deletion and cannot be undone.
+// POST /catalog/internal/release with a CatalogBackup
+func (h *TrashHandler) ReleaseCatalog(w http.ResponseWriter, r *http.Request) {
+	backup, ok := decodeCatalogBackup(w, r)
+	if !ok {
+		return
+	}
+	h.releaseCatalog(backup)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) releaseCatalog(backup *dto.CatalogBackup) {
+	songIDs := make([]string, 0, len(backup.Songs))
+	for _, song := range backup.Songs {
+		h.Songs.ReleaseSongAudio(song)
+		h.Songs.DeleteSongLyrics(song.ID)
+		songIDs = append(songIDs, song.ID)
+	}
+	h.Songs.DeleteLikes(model.LikeTypeSong, songIDs...)
+	albumIDs := make([]string, 0, len(backup.Albums))
+	for _, album := range backup.Albums {
+		deleteImageFiles(context.Background(), h.Albums.Storage, album.Image, nil)
+		albumIDs = append(albumIDs, album.ID)
+	}
+	h.Songs.DeleteLikes(model.LikeTypeAlbum, albumIDs...)
+	if backup.Artist != nil {
+		deleteImageFiles(context.Background(), h.Artists.Storage, backup.Artist.Image, nil)
+		h.Songs.DeleteLikes(model.LikeTypeArtist, backup.Artist.ID)
+	}
+}
+
+// purgeCatalog deletes a backed-up album or artist permanently without saga-service: songs go
+// through PurgeSong, subscriptions to a deleted artist are dropped on a best-effort basis
+func (h *TrashHandler) purgeCatalog(ctx context.Context, backup *dto.CatalogBackup) error {
+	if err := h.detachCatalog(ctx, backup); err != nil {
+		return err
+	}
+	for _, song := range backup.Songs {
+		if err := h.Songs.PurgeSong(ctx, song); err != nil && err.Error() != "song not found" {
+			return err
+		}
+	}
+	rest := *backup
+	rest.Songs = nil
+	if err := h.deleteCatalog(ctx, &rest); err != nil {
+		return err
+	}
+	h.releaseCatalog(&rest)
+
+	if backup.Artist != nil && h.Artists.SubscriptionsServiceURL != "" {
+		go deleteArtistSubscriptions(h.Artists.SubscriptionsServiceURL, backup.Artist.ID)
+	}
+	return nil
+}
+
+func deleteArtistSubscriptions(subscriptionsServiceURL, artistID string) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/subscriptions/internal/artist?artistId=%s", subscriptionsServiceURL, artistID), nil)
+	if err != nil {
+		return
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Error calling subscriptions-service to delete subscriptions to artist %s: %v", artistID, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Failed to delete subscriptions to artist %s: status %d", artistID, resp.StatusCode)
+	}
+}
+
+// runCatalogSaga calls saga-service to orchestrate the deletion of an album or artist
+func (h *TrashHandler) runCatalogSaga(ctx context.Context, path string, body map[string]interface{}) error {
+	client := &http.Client{Timeout: 3 * time.Minute}
+
+	reqJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to create saga request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", h.Songs.SagaServiceURL+path, bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create saga request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute saga transaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var sagaResp map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&sagaResp); err == nil {
+			if errorMsg, ok := sagaResp["error"].(string); ok {
+				return fmt.Errorf("saga transaction failed: %s", errorMsg)
+			}
+		}
+		return fmt.Errorf("saga transaction failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,853 @@
+package handler
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// maxCatalogLine is the longest NDJSON record accepted
+const maxCatalogLine = 16 << 20
+
+// catalogRow is one parsed record of an import and where it came from
+type catalogRow struct {
+	file   string
+	line   int
+	artist *dto.CatalogArtistRecord
+	album  *dto.CatalogAlbumRecord
+	song   *dto.CatalogSongRecord
+}
+
+// catalogRows are the parsed records of an import by type
+type catalogRows struct {
+	artists []catalogRow
+	albums  []catalogRow
+	songs   []catalogRow
+}
+
+// Import creates and updates artists, albums and songs from an export
+// POST /catalog/import?format=ndjson|csv&type=artists|albums|songs&dryRun=true
+// The body is NDJSON, the CSV of one collection (type required) or an export archive
+// (Content-Type: application/zip) whose songs may bring their audio. A record matches an existing
+// item by ID, then by name (albums by name and artists, songs by name and album); unmatched records
+// are created under their ID. References to other items use the IDs of the import and are mapped to
+// the matched items. Invalid rows are reported and skipped; a dry run only reports what would change.
+func (h *CatalogTransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = CatalogFormatNDJSON
+	}
+	if format != CatalogFormatNDJSON && format != CatalogFormatCSV {
+		http.Error(w, "format must be ndjson or csv", http.StatusBadRequest)
+		return
+	}
+	collection := q.Get("type")
+	if collection != "" {
+		if _, ok := catalogCSVColumns[collection]; !ok {
+			http.Error(w, "type must be artists, albums or songs", http.StatusBadRequest)
+			return
+		}
+	}
+	dryRun := q.Get("dryRun") == "true"
+
+	report := &dto.CatalogImportReport{
+		DryRun: dryRun,
+		IDMap: map[string]map[string]string{
+			dto.CatalogRecordArtist: {},
+			dto.CatalogRecordAlbum:  {},
+			dto.CatalogRecordSong:   {},
+		},
+		Errors: []dto.CatalogImportError{},
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.MaxImportSize)
+	var rows *catalogRows
+	var audio map[string]*zip.File
+	var err error
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/zip") || strings.HasPrefix(contentType, "application/x-zip-compressed"):
+		var archive *os.File
+		archive, err = spoolCatalogArchive(body)
+		if archive != nil {
+			defer os.Remove(archive.Name())
+			defer archive.Close()
+		}
+		if err == nil {
+			rows, audio, err = readCatalogArchive(archive, report)
+		}
+	case format == CatalogFormatCSV:
+		if collection == "" {
+			http.Error(w, "type is required for a CSV import", http.StatusBadRequest)
+			return
+		}
+		rows = &catalogRows{}
+		err = readCatalogCSV(body, collection+".csv", collection, rows, report)
+	default:
+		rows = &catalogRows{}
+		err = readCatalogNDJSON(body, catalogNDJSONFile, rows, report)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("import is larger than %d MB", h.MaxImportSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid import: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if collection != "" {
+		rows.keepOnly(collection)
+	}
+
+	im, err := h.newCatalogImport(r.Context(), report, audio)
+	if err != nil {
+		http.Error(w, "failed to import catalog: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, row := range rows.artists {
+		im.importArtist(row)
+	}
+	for _, row := range rows.albums {
+		im.importAlbum(row)
+	}
+	for _, row := range rows.songs {
+		im.importSong(row)
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminID(r.Context()), "IMPORT_CATALOG", "catalog", map[string]interface{}{
+			"dryRun":     dryRun,
+			"artists":    report.Artists,
+			"albums":     report.Albums,
+			"songs":      report.Songs,
+			"audioFiles": report.AudioFiles,
+			"errors":     len(report.Errors),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// keepOnly drops the records of the other collections
+func (rows *catalogRows) keepOnly(collection string) {
+	if collection != TrashArtists {
+		rows.artists = nil
+	}
+	if collection != TrashAlbums {
+		rows.albums = nil
+	}
+	if collection != TrashSongs {
+		rows.songs = nil
+	}
+}
+
+// spoolCatalogArchive copies an uploaded archive to a temporary file, zip needs random access
+func spoolCatalogArchive(body io.Reader) (*os.File, error) {
+	f, err := os.CreateTemp("", "catalog-import-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, body); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// readCatalogArchive reads the data files of an export archive and indexes its audio files
+func readCatalogArchive(f *os.File, report *dto.CatalogImportReport) (*catalogRows, map[string]*zip.File, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, nil, errors.New("body is not a zip archive")
+	}
+
+	files := map[string]*zip.File{}
+	audio := map[string]*zip.File{}
+	for _, entry := range zr.File {
+		if strings.HasPrefix(entry.Name, catalogAudioDir) {
+			audio[entry.Name] = entry
+		} else {
+			files[entry.Name] = entry
+		}
+	}
+
+	rows := &catalogRows{}
+	found := false
+	if entry, ok := files[catalogNDJSONFile]; ok {
+		found = true
+		if err := readCatalogEntry(entry, func(r io.Reader) error {
+			return readCatalogNDJSON(r, entry.Name, rows, report)
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, collection := range catalogCollections {
+		entry, ok := files[collection+".csv"]
+		if !ok {
+			continue
+		}
+		found = true
+		if err := readCatalogEntry(entry, func(r io.Reader) error {
+			return readCatalogCSV(r, entry.Name, collection, rows, report)
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("archive has no %s or CSV files", catalogNDJSONFile)
+	}
+	return rows, audio, nil
+}
+
+// readCatalogEntry opens a file of the archive for read
+func readCatalogEntry(entry *zip.File, read func(io.Reader) error) error {
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return read(rc)
+}
+
+// readCatalogNDJSON parses one record per line; blank lines are skipped
+func readCatalogNDJSON(r io.Reader, file string, rows *catalogRows, report *dto.CatalogImportReport) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxCatalogLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var head struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &head); err != nil {
+			addCatalogError(report, file, line, "", "", "invalid JSON: "+err.Error())
+			continue
+		}
+
+		row := catalogRow{file: file, line: line}
+		var err error
+		switch head.Type {
+		case dto.CatalogRecordArtist:
+			row.artist = &dto.CatalogArtistRecord{}
+			if err = json.Unmarshal(data, row.artist); err == nil {
+				rows.artists = append(rows.artists, row)
+			}
+		case dto.CatalogRecordAlbum:
+			row.album = &dto.CatalogAlbumRecord{}
+			if err = json.Unmarshal(data, row.album); err == nil {
+				rows.albums = append(rows.albums, row)
+			}
+		case dto.CatalogRecordSong:
+			row.song = &dto.CatalogSongRecord{}
+			if err = json.Unmarshal(data, row.song); err == nil {
+				rows.songs = append(rows.songs, row)
+			}
+		default:
+			err = errors.New("type must be artist, album or song")
+		}
+		if err != nil {
+			addCatalogError(report, file, line, head.Type, head.ID, err.Error())
+		}
+	}
+	return scanner.Err()
+}
+
+// readCatalogCSV parses the CSV of one collection; columns are found by the header row and may be
+// in any order, only name is required
+func readCatalogCSV(r io.Reader, file, collection string, rows *catalogRows, report *dto.CatalogImportReport) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return fmt.Errorf("%s: header has no name column", file)
+	}
+	recordType := revisionEntityType(collection)
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			addCatalogError(report, file, parseErr.Line, recordType, "", parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := catalogRow{file: file, line: line}
+		switch collection {
+		case TrashArtists:
+			row.artist = &dto.CatalogArtistRecord{
+				Type:      dto.CatalogRecordArtist,
+				ID:        value("id"),
+				Name:      value("name"),
+				Biography: value("biography"),
+				Genres:    splitCatalogList(value("genres")),
+			}
+			rows.artists = append(rows.artists, row)
+		case TrashAlbums:
+			releaseDate, err := parseCatalogDate(value("releaseDate"))
+			if err != nil {
+				addCatalogError(report, file, line, recordType, value("id"), err.Error())
+				continue
+			}
+			row.album = &dto.CatalogAlbumRecord{
+				Type:        dto.CatalogRecordAlbum,
+				ID:          value("id"),
+				Name:        value("name"),
+				ReleaseDate: releaseDate,
+				Genre:       value("genre"),
+				ArtistIDs:   splitCatalogList(value("artistIds")),
+			}
+			rows.albums = append(rows.albums, row)
+		case TrashSongs:
+			duration := 0
+			if s := value("duration"); s != "" {
+				if duration, err = strconv.Atoi(s); err != nil {
+					addCatalogError(report, file, line, recordType, value("id"), "duration must be a number of seconds")
+					continue
+				}
+			}
+			row.song = &dto.CatalogSongRecord{
+				Type:         dto.CatalogRecordSong,
+				ID:           value("id"),
+				Name:         value("name"),
+				Duration:     duration,
+				Genre:        value("genre"),
+				AlbumID:      value("albumId"),
+				ArtistIDs:    splitCatalogList(value("artistIds")),
+				AudioFileURL: value("audioFileUrl"),
+				AudioFile:    value("audioFile"),
+			}
+			rows.songs = append(rows.songs, row)
+		}
+	}
+}
+
+// splitCatalogList splits a CSV list cell, dropping empty entries
+func splitCatalogList(cell string) []string {
+	items := []string{}
+	for _, item := range strings.Split(cell, catalogListSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCatalogDate reads an RFC 3339 time or a plain date; empty is the zero time
+func parseCatalogDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("releaseDate must be a date (2006-01-02) or RFC 3339 time")
+}
+
+func addCatalogError(report *dto.CatalogImportReport, file string, line int, recordType, id, message string) {
+	report.Errors = append(report.Errors, dto.CatalogImportError{
+		File:  file,
+		Line:  line,
+		Type:  recordType,
+		ID:    id,
+		Error: message,
+	})
+}
+
+// catalogImport is the state of one import: the live catalog, indexed by ID and by name, and the
+// IDs the imported records got. In a dry run the indexes are changed as if the rows were saved, so
+// later rows see earlier ones.
+type catalogImport struct {
+	h      *CatalogTransferHandler
+	ctx    context.Context
+	report *dto.CatalogImportReport
+	audio  map[string]*zip.File
+
+	artists    map[string]*model.Artist
+	artistKeys map[string]*model.Artist
+	albums     map[string]*model.Album
+	albumKeys  map[string]*model.Album
+	songs      map[string]*model.Song
+	songKeys   map[string]*model.Song
+
+	// IDs of the import mapped to the IDs here, by record type
+	imported map[string]map[string]string
+}
+
+func (h *CatalogTransferHandler) newCatalogImport(ctx context.Context, report *dto.CatalogImportReport, audio map[string]*zip.File) (*catalogImport, error) {
+	im := &catalogImport{
+		h:          h,
+		ctx:        ctx,
+		report:     report,
+		audio:      audio,
+		artists:    map[string]*model.Artist{},
+		artistKeys: map[string]*model.Artist{},
+		albums:     map[string]*model.Album{},
+		albumKeys:  map[string]*model.Album{},
+		songs:      map[string]*model.Song{},
+		songKeys:   map[string]*model.Song{},
+		imported: map[string]map[string]string{
+			dto.CatalogRecordArtist: {},
+			dto.CatalogRecordAlbum:  {},
+			dto.CatalogRecordSong:   {},
+		},
+	}
+
+	artists, err := h.Artists.Repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, artist := range artists {
+		im.indexArtist(artist)
+	}
+	albums, err := h.Albums.Repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, album := range albums {
+		im.indexAlbum(album)
+	}
+	songs, err := h.Songs.Repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, song := range songs {
+		im.indexSong(song)
+	}
+	return im, nil
+}
+
+// Name keys: artists by name, albums by name and artists, songs by name and album
+func artistKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func albumKey(name string, artistIDs []string) string {
+	ids := append([]string(nil), artistIDs...)
+	sort.Strings(ids)
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + strings.Join(ids, ",")
+}
+
+func songKey(name, albumID string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + albumID
+}
+
+func (im *catalogImport) indexArtist(artist *model.Artist) {
+	im.artists[artist.ID] = artist
+	im.artistKeys[artistKey(artist.Name)] = artist
+}
+
+func (im *catalogImport) indexAlbum(album *model.Album) {
+	im.albums[album.ID] = album
+	im.albumKeys[albumKey(album.Name, album.ArtistIDs)] = album
+}
+
+func (im *catalogImport) indexSong(song *model.Song) {
+	im.songs[song.ID] = song
+	im.songKeys[songKey(song.Name, song.AlbumID)] = song
+}
+
+// fail reports a row that was not imported
+func (im *catalogImport) fail(row catalogRow, recordType, id string, counts *dto.CatalogImportCounts, err error) {
+	counts.Failed++
+	addCatalogError(im.report, row.file, row.line, recordType, id, err.Error())
+}
+
+// claim records the ID an imported record got; a record type can use an import ID only once
+func (im *catalogImport) claim(recordType, sourceID, targetID string) error {
+	if sourceID == "" {
+		return nil
+	}
+	if _, ok := im.imported[recordType][sourceID]; ok {
+		return fmt.Errorf("duplicate %s id %s", recordType, sourceID)
+	}
+	im.imported[recordType][sourceID] = targetID
+	if sourceID != targetID {
+		im.report.IDMap[recordType][sourceID] = targetID
+	}
+	return nil
+}
+
+// resolveArtists maps artist IDs of the import to the artists here
+func (im *catalogImport) resolveArtists(ids []string) ([]string, error) {
+	resolved := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if target, ok := im.imported[dto.CatalogRecordArtist][id]; ok {
+			resolved = append(resolved, target)
+		} else if _, ok := im.artists[id]; ok {
+			resolved = append(resolved, id)
+		} else {
+			return nil, fmt.Errorf("artist %s not found", id)
+		}
+	}
+	return resolved, nil
+}
+
+// resolveAlbum maps an album ID of the import to the album here
+func (im *catalogImport) resolveAlbum(id string) (string, error) {
+	if target, ok := im.imported[dto.CatalogRecordAlbum][id]; ok {
+		return target, nil
+	}
+	if _, ok := im.albums[id]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("album %s not found", id)
+}
+
+// newImportID is the ID of a created item: the imported one, unless it belongs to an item in the trash
+func (im *catalogImport) newImportID(id string, inTrash func(context.Context, string) bool) (string, error) {
+	if id == "" {
+		return uuid.NewString(), nil
+	}
+	if inTrash(im.ctx, id) {
+		return "", fmt.Errorf("%s is in the trash, restore it first", id)
+	}
+	return id, nil
+}
+
+func (im *catalogImport) importArtist(row catalogRow) {
+	rec := row.artist
+	counts := &im.report.Artists
+	req := dto.UpdateArtistRequest{Name: rec.Name, Biography: rec.Biography, Genres: rec.Genres}
+	if req.Genres == nil {
+		req.Genres = []string{}
+	}
+	if err := validateArtistUpdate(&req); err != nil {
+		im.fail(row, dto.CatalogRecordArtist, rec.ID, counts, err)
+		return
+	}
+
+	existing := im.artists[rec.ID]
+	if existing == nil {
+		existing = im.artistKeys[artistKey(rec.Name)]
+	}
+
+	if existing != nil {
+		if err := im.claim(dto.CatalogRecordArtist, rec.ID, existing.ID); err != nil {
+			im.fail(row, dto.CatalogRecordArtist, rec.ID, counts, err)
+			return
+		}
+		updated := *existing
+		updated.Name, updated.Biography, updated.Genres = req.Name, req.Biography, req.Genres
+		if len(revisionChanges(artistRevisionState(existing), artistRevisionState(&updated))) == 0 {
+			counts.Unchanged++
+			return
+		}
+		if im.report.DryRun {
+			*existing = updated
+		} else if err := im.h.Artists.applyUpdate(im.ctx, existing, &req, 0); err != nil {
+			im.fail(row, dto.CatalogRecordArtist, rec.ID, counts, err)
+			return
+		}
+		im.indexArtist(existing)
+		counts.Updated++
+		return
+	}
+
+	id, err := im.newImportID(rec.ID, func(ctx context.Context, id string) bool {
+		_, err := im.h.Artists.Repo.GetDeleted(ctx, id)
+		return err == nil
+	})
+	if err == nil {
+		err = im.claim(dto.CatalogRecordArtist, rec.ID, id)
+	}
+	if err != nil {
+		im.fail(row, dto.CatalogRecordArtist, rec.ID, counts, err)
+		return
+	}
+	artist := &model.Artist{ID: id, Name: req.Name, Biography: req.Biography, Genres: req.Genres}
+	if !im.report.DryRun {
+		if err := im.h.Artists.Repo.Insert(im.ctx, artist); err != nil {
+			im.fail(row, dto.CatalogRecordArtist, rec.ID, counts, err)
+			return
+		}
+		recordRevision(im.ctx, im.h.Artists.Revisions, model.LikeTypeArtist, artist.ID, nil, artistRevisionState(artist), getAdminID(im.ctx), 0)
+		im.h.Artists.announceCreated(im.ctx, artist)
+	}
+	im.indexArtist(artist)
+	counts.Created++
+}
+
+func (im *catalogImport) importAlbum(row catalogRow) {
+	rec := row.album
+	counts := &im.report.Albums
+	artistIDs, err := im.resolveArtists(rec.ArtistIDs)
+	if err != nil {
+		im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+		return
+	}
+	req := dto.UpdateAlbumRequest{Name: rec.Name, ReleaseDate: rec.ReleaseDate, Genre: rec.Genre, ArtistIDs: artistIDs}
+	if err := validateAlbumUpdate(&req); err != nil {
+		im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+		return
+	}
+
+	existing := im.albums[rec.ID]
+	if existing == nil {
+		existing = im.albumKeys[albumKey(rec.Name, artistIDs)]
+	}
+
+	if existing != nil {
+		if err := im.claim(dto.CatalogRecordAlbum, rec.ID, existing.ID); err != nil {
+			im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+			return
+		}
+		updated := *existing
+		updated.Name, updated.ReleaseDate, updated.Genre, updated.ArtistIDs = req.Name, req.ReleaseDate, req.Genre, req.ArtistIDs
+		if len(revisionChanges(albumRevisionState(existing), albumRevisionState(&updated))) == 0 {
+			counts.Unchanged++
+			return
+		}
+		if im.report.DryRun {
+			*existing = updated
+		} else if err := im.h.Albums.applyUpdate(im.ctx, existing, &req, 0); err != nil {
+			im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+			return
+		}
+		im.indexAlbum(existing)
+		counts.Updated++
+		return
+	}
+
+	id, err := im.newImportID(rec.ID, func(ctx context.Context, id string) bool {
+		_, err := im.h.Albums.Repo.GetDeleted(ctx, id)
+		return err == nil
+	})
+	if err == nil {
+		err = im.claim(dto.CatalogRecordAlbum, rec.ID, id)
+	}
+	if err != nil {
+		im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+		return
+	}
+	album := &model.Album{ID: id, Name: req.Name, ReleaseDate: req.ReleaseDate, Genre: req.Genre, ArtistIDs: req.ArtistIDs}
+	if !im.report.DryRun {
+		if err := im.h.Albums.Repo.Insert(im.ctx, album); err != nil {
+			im.fail(row, dto.CatalogRecordAlbum, rec.ID, counts, err)
+			return
+		}
+		recordRevision(im.ctx, im.h.Albums.Revisions, model.LikeTypeAlbum, album.ID, nil, albumRevisionState(album), getAdminIDFromContext(im.ctx), 0)
+		im.h.Albums.announceCreated(im.ctx, album)
+	}
+	im.indexAlbum(album)
+	counts.Created++
+}
+
+func (im *catalogImport) importSong(row catalogRow) {
+	rec := row.song
+	counts := &im.report.Songs
+	albumID, err := im.resolveAlbum(rec.AlbumID)
+	if err != nil && rec.AlbumID == "" {
+		err = errors.New("albumId is required")
+	}
+	var artistIDs []string
+	if err == nil {
+		artistIDs, err = im.resolveArtists(rec.ArtistIDs)
+	}
+	if err != nil {
+		im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+		return
+	}
+	req := dto.UpdateSongRequest{
+		Name:         rec.Name,
+		Duration:     rec.Duration,
+		Genre:        rec.Genre,
+		AlbumID:      albumID,
+		ArtistIDs:    artistIDs,
+		AudioFileURL: rec.AudioFileURL,
+	}
+	if err := validateSongFields(&req); err != nil {
+		im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+		return
+	}
+	var audio *zip.File
+	if rec.AudioFile != "" {
+		if audio = im.audio[rec.AudioFile]; audio == nil {
+			im.fail(row, dto.CatalogRecordSong, rec.ID, counts, fmt.Errorf("audio file %s is not in the archive", rec.AudioFile))
+			return
+		}
+		if !audioExtensions[strings.ToLower(path.Ext(rec.AudioFile))] {
+			im.fail(row, dto.CatalogRecordSong, rec.ID, counts, fmt.Errorf("unsupported audio format %s", path.Ext(rec.AudioFile)))
+			return
+		}
+	}
+
+	song := im.songs[rec.ID]
+	if song == nil {
+		song = im.songKeys[songKey(rec.Name, albumID)]
+	}
+
+	if song != nil {
+		if err := im.claim(dto.CatalogRecordSong, rec.ID, song.ID); err != nil {
+			im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+			return
+		}
+		updated := *song
+		updated.Name, updated.Duration, updated.Genre, updated.AlbumID, updated.ArtistIDs = req.Name, req.Duration, req.Genre, req.AlbumID, req.ArtistIDs
+		newAudioURL := req.AudioFileURL != "" && !sameStorageRef(song.AudioFile, audioFileFromURL(req.AudioFileURL))
+		if !newAudioURL && len(revisionChanges(songRevisionState(song), songRevisionState(&updated))) == 0 {
+			counts.Unchanged++
+		} else {
+			if im.report.DryRun {
+				if req.AudioFileURL != "" {
+					updated.AudioFile = audioFileFromURL(req.AudioFileURL)
+				}
+				*song = updated
+			} else if err := im.h.Songs.applyUpdate(im.ctx, song, &req, 0); err != nil {
+				im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+				return
+			}
+			im.indexSong(song)
+			counts.Updated++
+		}
+	} else {
+		id, err := im.newImportID(rec.ID, func(ctx context.Context, id string) bool {
+			_, err := im.h.Songs.Repo.GetDeleted(ctx, id)
+			return err == nil
+		})
+		if err == nil {
+			err = im.claim(dto.CatalogRecordSong, rec.ID, id)
+		}
+		if err != nil {
+			im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+			return
+		}
+		song = &model.Song{
+			ID:        id,
+			Name:      req.Name,
+			Duration:  req.Duration,
+			Genre:     req.Genre,
+			AlbumID:   req.AlbumID,
+			ArtistIDs: req.ArtistIDs,
+			AudioFile: audioFileFromURL(req.AudioFileURL),
+		}
+		if !im.report.DryRun {
+			if err := im.h.Songs.Repo.Insert(im.ctx, song); err != nil {
+				im.fail(row, dto.CatalogRecordSong, rec.ID, counts, err)
+				return
+			}
+			recordRevision(im.ctx, im.h.Songs.Revisions, model.LikeTypeSong, song.ID, nil, songRevisionState(song), getAdminIDFromSongContext(im.ctx), 0)
+			im.h.Songs.announceCreated(im.ctx, song)
+		}
+		im.indexSong(song)
+		counts.Created++
+	}
+
+	if audio != nil {
+		if err := im.importAudio(song, audio); err != nil {
+			// The song itself is saved; only its audio is reported
+			addCatalogError(im.report, row.file, row.line, dto.CatalogRecordSong, rec.ID, "audio not imported: "+err.Error())
+		}
+	}
+}
+
+// importAudio stores the audio of a song from the archive, unless the song already has that file.
+// The entry is decompressed to a temporary file while it is hashed and streamed to storage from there,
+// so it is never held in memory; it is bounded like an upload and the size in the zip header is not
+// trusted alone.
+func (im *catalogImport) importAudio(song *model.Song, entry *zip.File) error {
+	if entry.UncompressedSize64 > maxResumableUploadSize {
+		return fmt.Errorf("audio file is larger than %d MB", maxResumableUploadSize>>20)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.CreateTemp("", "catalog-audio-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	digest := sha256.New()
+	written, err := io.Copy(io.MultiWriter(f, digest), io.LimitReader(rc, maxResumableUploadSize+1))
+	if err != nil {
+		return err
+	}
+	if written > maxResumableUploadSize {
+		return fmt.Errorf("audio file is larger than %d MB", maxResumableUploadSize>>20)
+	}
+
+	if song.AudioFile != nil && song.AudioFile.SHA256 == hex.EncodeToString(digest.Sum(nil)) {
+		return nil
+	}
+	if !im.report.DryRun {
+		if _, err := im.h.Songs.saveUploadedAudio(im.ctx, song, strings.ToLower(path.Ext(entry.Name)), f); err != nil {
+			return err
+		}
+	}
+	im.report.AudioFiles++
+	return nil
+}
+
+// sameStorageRef reports whether two references point at the same file
+func sameStorageRef(a, b *model.StorageRef) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Backend == b.Backend && a.Key == b.Key
+}
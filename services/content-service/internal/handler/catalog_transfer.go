@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/logger"
+	"content-service/internal/model"
+	"content-service/internal/storage"
+)
+
+// Catalog export and import formats
+const (
+	CatalogFormatNDJSON = "ndjson"
+	CatalogFormatCSV    = "csv"
+)
+
+// Files of an export archive. CSV exports have one file per collection ({collection}.csv).
+const (
+	catalogNDJSONFile   = "catalog.ndjson"
+	catalogManifestFile = "manifest.json"
+	catalogAudioDir     = "audio/"
+)
+
+// catalogListSeparator joins list fields (genres, artist IDs) in a CSV cell
+const catalogListSeparator = "|"
+
+// CSV columns of each collection
+var catalogCSVColumns = map[string][]string{
+	TrashArtists: {"id", "name", "biography", "genres"},
+	TrashAlbums:  {"id", "name", "releaseDate", "genre", "artistIds"},
+	TrashSongs:   {"id", "name", "duration", "genre", "albumId", "artistIds", "audioFileUrl", "audioFile"},
+}
+
+// catalogCollections are the exported collections, in the order an import needs them
+var catalogCollections = []string{TrashArtists, TrashAlbums, TrashSongs}
+
+// CatalogTransferHandler moves the catalog between environments: it exports artists, albums and songs
+// as NDJSON or CSV, optionally in an archive with the uploaded audio, and imports such exports.
+type CatalogTransferHandler struct {
+	Songs   *SongHandler
+	Albums  *AlbumHandler
+	Artists *ArtistHandler
+	// MaxImportSize is the largest import body accepted, in bytes
+	MaxImportSize int64
+	Logger        *logger.Logger
+}
+
+func NewCatalogTransferHandler(songs *SongHandler, albums *AlbumHandler, artists *ArtistHandler, maxImportSize int64, log *logger.Logger) *CatalogTransferHandler {
+	return &CatalogTransferHandler{
+		Songs:         songs,
+		Albums:        albums,
+		Artists:       artists,
+		MaxImportSize: maxImportSize,
+		Logger:        log,
+	}
+}
+
+// catalogExport holds the records of an export
+type catalogExport struct {
+	artists []*dto.CatalogArtistRecord
+	albums  []*dto.CatalogAlbumRecord
+	songs   []*dto.CatalogSongRecord
+	// audio of the exported songs, by song ID; only uploaded audio, external URLs are in the records
+	audio map[string]*model.StorageRef
+}
+
+// Export writes the catalog, items in the trash excluded
+// GET /catalog/export?format=ndjson|csv&type=artists|albums|songs&audio=true
+// NDJSON is one record per line with its "type". A CSV export of more than one collection, and any
+// export with audio, is a zip archive with the data files, audio/{songId}.{ext} and manifest.json.
+func (h *CatalogTransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = CatalogFormatNDJSON
+	}
+	if format != CatalogFormatNDJSON && format != CatalogFormatCSV {
+		http.Error(w, "format must be ndjson or csv", http.StatusBadRequest)
+		return
+	}
+	collections := catalogCollections
+	if collection := q.Get("type"); collection != "" {
+		if _, ok := catalogCSVColumns[collection]; !ok {
+			http.Error(w, "type must be artists, albums or songs", http.StatusBadRequest)
+			return
+		}
+		collections = []string{collection}
+	}
+	withAudio := q.Get("audio") == "true"
+
+	export, err := h.loadExport(r.Context(), collections)
+	if err != nil {
+		http.Error(w, "failed to export catalog: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stamp := time.Now().UTC().Format("20060102-150405")
+	archive := withAudio || (format == CatalogFormatCSV && len(collections) > 1)
+	switch {
+	case archive:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog-%s.zip"`, stamp))
+		w.WriteHeader(http.StatusOK)
+		if err := h.writeArchive(r.Context(), w, export, format, collections, withAudio); err != nil {
+			// The response has started; the client gets a truncated archive
+			log.Printf("Catalog export failed: %v", err)
+			return
+		}
+	case format == CatalogFormatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="catalog-%s.ndjson"`, stamp))
+		w.WriteHeader(http.StatusOK)
+		writeCatalogNDJSON(w, export)
+	default:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.csv"`, collections[0], stamp))
+		w.WriteHeader(http.StatusOK)
+		writeCatalogCSV(w, export, collections[0])
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminID(r.Context()), "EXPORT_CATALOG", "catalog", map[string]interface{}{
+			"format":  format,
+			"types":   collections,
+			"audio":   withAudio,
+			"artists": len(export.artists),
+			"albums":  len(export.albums),
+			"songs":   len(export.songs),
+		})
+	}
+}
+
+// loadExport reads the exported collections
+func (h *CatalogTransferHandler) loadExport(ctx context.Context, collections []string) (*catalogExport, error) {
+	export := &catalogExport{audio: map[string]*model.StorageRef{}}
+	for _, collection := range collections {
+		switch collection {
+		case TrashArtists:
+			artists, err := h.Artists.Repo.ListAll(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, artist := range artists {
+				export.artists = append(export.artists, &dto.CatalogArtistRecord{
+					Type:      dto.CatalogRecordArtist,
+					ID:        artist.ID,
+					Name:      artist.Name,
+					Biography: artist.Biography,
+					Genres:    artist.Genres,
+				})
+			}
+		case TrashAlbums:
+			albums, err := h.Albums.Repo.ListAll(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, album := range albums {
+				export.albums = append(export.albums, &dto.CatalogAlbumRecord{
+					Type:        dto.CatalogRecordAlbum,
+					ID:          album.ID,
+					Name:        album.Name,
+					ReleaseDate: album.ReleaseDate,
+					Genre:       album.Genre,
+					ArtistIDs:   album.ArtistIDs,
+				})
+			}
+		case TrashSongs:
+			songs, err := h.Songs.Repo.ListAll(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, song := range songs {
+				record := &dto.CatalogSongRecord{
+					Type:      dto.CatalogRecordSong,
+					ID:        song.ID,
+					Name:      song.Name,
+					Duration:  song.Duration,
+					Genre:     song.Genre,
+					AlbumID:   song.AlbumID,
+					ArtistIDs: song.ArtistIDs,
+				}
+				if song.AudioFile != nil {
+					if song.AudioFile.Backend == storage.BackendExternal {
+						record.AudioFileURL = song.AudioFile.Key
+					} else {
+						export.audio[song.ID] = song.AudioFile
+					}
+				}
+				export.songs = append(export.songs, record)
+			}
+		}
+	}
+	return export, nil
+}
+
+// writeArchive writes a zip export. Audio goes first so that the song records can name the files
+// that were written; audio that cannot be read is listed in the manifest.
+func (h *CatalogTransferHandler) writeArchive(ctx context.Context, w io.Writer, export *catalogExport, format string, collections []string, withAudio bool) error {
+	zw := zip.NewWriter(w)
+	manifest := dto.CatalogExportManifest{
+		ExportedAt:   time.Now().UTC(),
+		Format:       format,
+		Artists:      len(export.artists),
+		Albums:       len(export.albums),
+		Songs:        len(export.songs),
+		MissingAudio: []string{},
+	}
+
+	if withAudio {
+		for _, record := range export.songs {
+			ref, ok := export.audio[record.ID]
+			if !ok {
+				continue
+			}
+			name := catalogAudioDir + record.ID + path.Ext(ref.Key)
+			if err := h.writeAudio(ctx, zw, name, ref); err != nil {
+				log.Printf("Catalog export: audio of song %s not exported: %v", record.ID, err)
+				manifest.MissingAudio = append(manifest.MissingAudio, record.ID)
+				continue
+			}
+			record.AudioFile = name
+			manifest.AudioFiles++
+		}
+	}
+
+	if format == CatalogFormatNDJSON {
+		f, err := zw.Create(catalogNDJSONFile)
+		if err != nil {
+			return err
+		}
+		if err := writeCatalogNDJSON(f, export); err != nil {
+			return err
+		}
+	} else {
+		for _, collection := range collections {
+			f, err := zw.Create(collection + ".csv")
+			if err != nil {
+				return err
+			}
+			if err := writeCatalogCSV(f, export, collection); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := zw.Create(catalogManifestFile)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeAudio copies a stored audio file into the archive without compressing it again
+func (h *CatalogTransferHandler) writeAudio(ctx context.Context, zw *zip.Writer, name string, ref *model.StorageRef) error {
+	store, err := h.Songs.Storage.Get(ref.Backend)
+	if err != nil {
+		return err
+	}
+	body, err := store.Get(ctx, ref.Key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, body)
+	return err
+}
+
+// writeCatalogNDJSON writes artists, albums and songs, one JSON record per line
+func writeCatalogNDJSON(w io.Writer, export *catalogExport) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range export.artists {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	for _, record := range export.albums {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	for _, record := range export.songs {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCatalogCSV writes the records of one collection with a header row
+func writeCatalogCSV(w io.Writer, export *catalogExport, collection string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(catalogCSVColumns[collection]); err != nil {
+		return err
+	}
+	switch collection {
+	case TrashArtists:
+		for _, a := range export.artists {
+			cw.Write([]string{a.ID, a.Name, a.Biography, strings.Join(a.Genres, catalogListSeparator)})
+		}
+	case TrashAlbums:
+		for _, a := range export.albums {
+			releaseDate := ""
+			if !a.ReleaseDate.IsZero() {
+				releaseDate = a.ReleaseDate.UTC().Format(time.RFC3339)
+			}
+			cw.Write([]string{a.ID, a.Name, releaseDate, a.Genre, strings.Join(a.ArtistIDs, catalogListSeparator)})
+		}
+	case TrashSongs:
+		for _, s := range export.songs {
+			cw.Write([]string{s.ID, s.Name, strconv.Itoa(s.Duration), s.Genre, s.AlbumID,
+				strings.Join(s.ArtistIDs, catalogListSeparator), s.AudioFileURL, s.AudioFile})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
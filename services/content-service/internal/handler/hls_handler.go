@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"content-service/internal/audiometa"
+	"content-service/internal/hls"
+	"content-service/internal/logger"
+	"content-service/internal/model"
+	"content-service/internal/storage"
+)
+
+// hlsFileName matches the files a song's HLS directory can contain
+var hlsFileName = regexp.MustCompile(`^(master\.m3u8|playlist\.m3u8|segment_\d{5}\.(mp3|aac))$`)
+
+// hlsPrefix is the key prefix of a song's HLS output, next to the original audio/songs/{id}.ext
+func hlsPrefix(songID string) string {
+	return fmt.Sprintf("audio/songs/%s/hls", songID)
+}
+
+// generateHLS segments an uploaded MP3 or AAC file and stores the segments and playlists in the
+// default storage backend. Returns the number of segments; ErrUnsupportedFormat for other formats.
+func (h *SongHandler) generateHLS(ctx context.Context, song *model.Song, data []byte) (int, error) {
+	stream, err := audiometa.Frames(data)
+	if err != nil {
+		return 0, err
+	}
+	out, err := hls.Build(stream, hls.DefaultSegmentDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	// Playback falls back to progressive streaming while the segments are replaced
+	if err := h.Repo.SetHLS(ctx, song.ID, nil); err != nil {
+		return 0, err
+	}
+	if old := song.HLS; old != nil {
+		if store, err := h.Storage.Get(old.Backend); err == nil {
+			if err := storage.DeletePrefix(ctx, store, old.Key+"/"); err != nil {
+				log.Printf("Failed to delete old HLS output %s:%s: %v", old.Backend, old.Key, err)
+			}
+		}
+	}
+
+	ref := &model.StorageRef{Backend: h.Storage.DefaultBackend(), Key: hlsPrefix(song.ID)}
+	store := h.Storage.Default()
+	for _, segment := range out.Segments {
+		if err := storage.PutBytes(ctx, store, ref.Key+"/"+segment.Name, segment.Data); err != nil {
+			return 0, fmt.Errorf("store %s: %w", segment.Name, err)
+		}
+	}
+	// The master playlist goes last, it is what makes the stream playable
+	if err := storage.PutBytes(ctx, store, ref.Key+"/"+hls.MediaPlaylist, out.Media); err != nil {
+		return 0, fmt.Errorf("store %s: %w", hls.MediaPlaylist, err)
+	}
+	if err := storage.PutBytes(ctx, store, ref.Key+"/"+hls.MasterPlaylist, out.Master); err != nil {
+		return 0, fmt.Errorf("store %s: %w", hls.MasterPlaylist, err)
+	}
+
+	if err := h.Repo.SetHLS(ctx, song.ID, ref); err != nil {
+		return 0, err
+	}
+	song.HLS = ref
+	return len(out.Segments), nil
+}
+
+// generateHLSInBackground runs after an upload, so the upload response does not wait for the storage
+func (h *SongHandler) generateHLSInBackground(song *model.Song, data []byte) {
+	// The goroutine works on a copy, the request may still use the song
+	copied := *song
+	song = &copied
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		segments, err := h.generateHLS(ctx, song, data)
+		if errors.Is(err, audiometa.ErrUnsupportedFormat) {
+			log.Printf("No HLS output for song %s: format cannot be segmented", song.ID)
+			return
+		}
+		if err != nil {
+			log.Printf("HLS generation for song %s failed: %v", song.ID, err)
+			if h.Logger != nil {
+				h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to generate HLS output", map[string]interface{}{
+					"error":  err.Error(),
+					"songId": song.ID,
+				})
+			}
+			return
+		}
+		log.Printf("HLS output for song %s ready: %d segments", song.ID, segments)
+	}()
+}
+
+// RegenerateHLS (re)builds the HLS output from the uploaded audio file, e.g. for songs uploaded
+// before HLS existed
+// POST /songs/{id}/hls
+func (h *SongHandler) RegenerateHLS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+	if song.AudioFile == nil || song.AudioFile.Backend == storage.BackendExternal {
+		http.Error(w, "song has no uploaded audio file", http.StatusConflict)
+		return
+	}
+	store, err := h.Storage.Get(song.AudioFile.Backend)
+	if err != nil {
+		http.Error(w, "audio storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	body, err := store.Get(r.Context(), song.AudioFile.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "audio file not found in storage", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to retrieve audio file", http.StatusBadGateway)
+		return
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		http.Error(w, "failed to retrieve audio file", http.StatusBadGateway)
+		return
+	}
+
+	segments, err := h.generateHLS(r.Context(), song, data)
+	if errors.Is(err, audiometa.ErrUnsupportedFormat) {
+		http.Error(w, "only MP3 and AAC audio can be streamed over HLS", http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		log.Printf("HLS generation for song %s failed: %v", id, err)
+		http.Error(w, "failed to generate HLS output", http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromSongContext(r.Context()), "GENERATE_HLS", "songs", map[string]interface{}{
+			"songId":   id,
+			"segments": segments,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"songId":   id,
+		"hls":      song.HLS,
+		"segments": segments,
+	})
+}
+
+// GetHLS serves the playlists and segments of a song
+// GET /songs/{id}/hls/master.m3u8, /songs/{id}/hls/playlist.m3u8, /songs/{id}/hls/segment_00000.mp3
+func (h *SongHandler) GetHLS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	name := path.Base(r.URL.Path)
+	if id == "" || !hlsFileName.MatchString(name) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+	if song.HLS == nil {
+		http.Error(w, "song is not available over HLS", http.StatusNotFound)
+		return
+	}
+	store, err := h.Storage.Get(song.HLS.Backend)
+	if err != nil {
+		http.Error(w, "HLS storage not available", http.StatusServiceUnavailable)
+		return
+	}
+	key := song.HLS.Key + "/" + name
+
+	info, err := store.Stat(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to stat %s: %v", key, err)
+		http.Error(w, "failed to retrieve HLS file", http.StatusBadGateway)
+		return
+	}
+
+	if strings.HasSuffix(name, ".m3u8") {
+		// Loading the master playlist is the start of an HLS playback
+		if name == hls.MasterPlaylist && r.Method == http.MethodGet {
+			h.recordPlay(r, song)
+		}
+		h.serveHLSPlaylist(w, r, store, key)
+		return
+	}
+
+	// Segments are replaced only by a new upload, which the ETag reflects
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	h.streamStoredAudio(w, r, store, key, info, "")
+}
+
+func (h *SongHandler) serveHLSPlaylist(w http.ResponseWriter, r *http.Request, store storage.BlobStore, key string) {
+	body, err := store.Get(r.Context(), key)
+	if err != nil {
+		log.Printf("Failed to open %s from storage: %v", key, err)
+		http.Error(w, "failed to retrieve HLS file", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, body); err != nil && r.Context().Err() == nil {
+		log.Printf("Serving %s interrupted: %v", key, err)
+	}
+}
@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/imaging"
+	"content-service/internal/logger"
+	"content-service/internal/model"
+	"content-service/internal/storage"
+	"content-service/internal/store"
+)
+
+// imageOwner reads and stores the image of an album or artist
+type imageOwner struct {
+	get func(ctx context.Context, id string) (*model.Image, error)
+	set func(ctx context.Context, id string, image *model.Image) error
+}
+
+// ImageHandler uploads and serves album artwork and artist photos
+type ImageHandler struct {
+	AlbumRepo  *store.AlbumRepository
+	ArtistRepo *store.ArtistRepository
+	Storage    *storage.Stores
+	Logger     *logger.Logger
+}
+
+func NewImageHandler(albumRepo *store.AlbumRepository, artistRepo *store.ArtistRepository, stores *storage.Stores, log *logger.Logger) *ImageHandler {
+	return &ImageHandler{
+		AlbumRepo:  albumRepo,
+		ArtistRepo: artistRepo,
+		Storage:    stores,
+		Logger:     log,
+	}
+}
+
+func (h *ImageHandler) owner(collection string) *imageOwner {
+	switch collection {
+	case "albums":
+		return &imageOwner{
+			get: func(ctx context.Context, id string) (*model.Image, error) {
+				album, err := h.AlbumRepo.GetByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return album.Image, nil
+			},
+			set: h.AlbumRepo.SetImage,
+		}
+	case "artists":
+		return &imageOwner{
+			get: func(ctx context.Context, id string) (*model.Image, error) {
+				artist, err := h.ArtistRepo.GetByID(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return artist.Image, nil
+			},
+			set: h.ArtistRepo.SetImage,
+		}
+	}
+	return nil
+}
+
+// parseImagePath splits /{albums|artists}/{id}/image[/{size}]
+func parseImagePath(path string) (collection, id, size string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[2] != "image" {
+		return "", "", ""
+	}
+	if len(parts) == 4 {
+		size = parts[3]
+	}
+	return parts[0], parts[1], size
+}
+
+// imagePrefix is the storage directory of one version of an image
+func imagePrefix(collection, id, version string) string {
+	return fmt.Sprintf("images/%s/%s/%s/", collection, id, version)
+}
+
+// Image handles the image of an album or artist
+// POST /{albums|artists}/{id}/image - upload (multipart field "image")
+// DELETE /{albums|artists}/{id}/image - remove
+// GET /{albums|artists}/{id}/image/{size} - thumbnail
+func (h *ImageHandler) Image(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.UploadImage(w, r)
+	case http.MethodDelete:
+		h.DeleteImage(w, r)
+	case http.MethodGet, http.MethodHead:
+		h.GetImage(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// UploadImage validates an uploaded image, stores its thumbnails and replaces the previous image
+func (h *ImageHandler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, _ := parseImagePath(r.URL.Path)
+	owner := h.owner(collection)
+	if owner == nil || id == "" {
+		http.Error(w, "album or artist ID is required", http.StatusBadRequest)
+		return
+	}
+	previous, err := owner.get(r.Context(), id)
+	if err != nil {
+		http.Error(w, strings.TrimSuffix(collection, "s")+" not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, imaging.MaxFileSize+1<<20)
+	if err := r.ParseMultipartForm(imaging.MaxFileSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("image must be at most %d MB", imaging.MaxFileSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to parse multipart form: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "image file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > imaging.MaxFileSize {
+		http.Error(w, fmt.Sprintf("image must be at most %d MB", imaging.MaxFileSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "failed to read image", http.StatusBadRequest)
+		return
+	}
+
+	// The format is taken from the contents, the file name and declared type are not trusted
+	img, err := imaging.Decode(data)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, imaging.ErrUnsupportedFormat) {
+			status = http.StatusUnsupportedMediaType
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	digest := sha256.Sum256(data)
+	image := &model.Image{Version: hex.EncodeToString(digest[:8]), UploadedAt: time.Now()}
+	backend := h.Storage.DefaultBackend()
+	for _, size := range imaging.Sizes {
+		thumbnail, err := imaging.EncodeJPEG(imaging.Thumbnail(img, size))
+		if err != nil {
+			http.Error(w, "failed to encode image: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		key := fmt.Sprintf("%s%d.jpg", imagePrefix(collection, id, image.Version), size)
+		if err := storage.PutBytes(r.Context(), h.Storage.Default(), key, thumbnail); err != nil {
+			log.Printf("Failed to store image %s: %v", key, err)
+			http.Error(w, "failed to store image", http.StatusBadGateway)
+			return
+		}
+		image.Thumbnails = append(image.Thumbnails, model.Thumbnail{Size: size, StorageRef: model.StorageRef{Backend: backend, Key: key}})
+	}
+
+	if err := owner.set(r.Context(), id, image); err != nil {
+		deleteImageFiles(context.Background(), h.Storage, image, previous)
+		http.Error(w, "failed to save image: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deleteImageFiles(context.Background(), h.Storage, previous, image)
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromContext(r.Context()), "UPLOAD_IMAGE", collection, map[string]interface{}{
+			"id":      id,
+			"version": image.Version,
+			"size":    len(data),
+			"width":   img.Bounds().Dx(),
+			"height":  img.Bounds().Dy(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"image":     image,
+		"imageUrls": dto.ImageURLs(collection, id, image),
+	})
+}
+
+// DeleteImage removes the image of an album or artist
+func (h *ImageHandler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, _ := parseImagePath(r.URL.Path)
+	owner := h.owner(collection)
+	if owner == nil || id == "" {
+		http.Error(w, "album or artist ID is required", http.StatusBadRequest)
+		return
+	}
+	image, err := owner.get(r.Context(), id)
+	if err != nil {
+		http.Error(w, strings.TrimSuffix(collection, "s")+" not found", http.StatusNotFound)
+		return
+	}
+	if image == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := owner.set(r.Context(), id, nil); err != nil {
+		http.Error(w, "failed to remove image: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deleteImageFiles(context.Background(), h.Storage, image, nil)
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromContext(r.Context()), "DELETE_IMAGE", collection, map[string]interface{}{
+			"id": id,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetImage serves a thumbnail. Requested with the current version (?v=, as in the image URLs) it is
+// cached for a year; without it, only briefly, so a new upload shows up.
+func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, sizeParam := parseImagePath(r.URL.Path)
+	owner := h.owner(collection)
+	if owner == nil || id == "" {
+		http.Error(w, "album or artist ID is required", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.Atoi(sizeParam)
+	if err != nil {
+		http.Error(w, "image size must be one of "+imageSizeList(), http.StatusNotFound)
+		return
+	}
+	image, err := owner.get(r.Context(), id)
+	if err != nil {
+		http.Error(w, strings.TrimSuffix(collection, "s")+" not found", http.StatusNotFound)
+		return
+	}
+	if image == nil {
+		http.Error(w, "no image", http.StatusNotFound)
+		return
+	}
+	thumbnail := image.Thumbnail(size)
+	if thumbnail == nil {
+		http.Error(w, "image size must be one of "+imageSizeList(), http.StatusNotFound)
+		return
+	}
+
+	etag := fmt.Sprintf("\"%s-%d\"", image.Version, size)
+	w.Header().Set("ETag", etag)
+	if r.URL.Query().Get("v") == image.Version {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	if ifNoneMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	store, err := h.Storage.Get(thumbnail.Backend)
+	if err != nil {
+		http.Error(w, "image storage not available", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := store.Get(r.Context(), thumbnail.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "no image", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to open image %s:%s: %v", thumbnail.Backend, thumbnail.Key, err)
+		http.Error(w, "failed to retrieve image", http.StatusBadGateway)
+		return
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, "failed to retrieve image", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", imaging.ContentType)
+	http.ServeContent(w, r, "", image.UploadedAt, bytes.NewReader(data))
+}
+
+// ifNoneMatch reports whether an If-None-Match header lists the ETag
+func ifNoneMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func imageSizeList() string {
+	sizes := make([]string, len(imaging.Sizes))
+	for i, size := range imaging.Sizes {
+		sizes[i] = strconv.Itoa(size)
+	}
+	return strings.Join(sizes, ", ")
+}
+
+// deleteImageFiles deletes the thumbnails of a replaced image or of a deleted album or artist. Files
+// shared with keep are left alone: the same file uploaded again has the same version and keys.
+func deleteImageFiles(ctx context.Context, stores *storage.Stores, image, keep *model.Image) {
+	if image == nil {
+		return
+	}
+	for _, thumbnail := range image.Thumbnails {
+		if keep != nil && keepsThumbnail(keep, thumbnail.StorageRef) {
+			continue
+		}
+		store, err := stores.Get(thumbnail.Backend)
+		if err != nil {
+			continue
+		}
+		if err := store.Delete(ctx, thumbnail.Key); err != nil {
+			log.Printf("Failed to delete image %s:%s: %v", thumbnail.Backend, thumbnail.Key, err)
+		}
+	}
+}
+
+func keepsThumbnail(image *model.Image, ref model.StorageRef) bool {
+	for _, thumbnail := range image.Thumbnails {
+		if thumbnail.StorageRef == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,333 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"content-service/internal/dto"
+	"content-service/internal/events"
+	"content-service/internal/logger"
+	"content-service/internal/model"
+	"content-service/internal/store"
+	"shared/analytics"
+)
+
+// libraryMaxContainsIDs limits how many items one contains check may ask about
+const libraryMaxContainsIDs = 100
+
+// libraryPathTypes maps the path segment of /library/{type}/{id} to the item type
+var libraryPathTypes = map[string]string{
+	"songs":   model.LikeTypeSong,
+	"albums":  model.LikeTypeAlbum,
+	"artists": model.LikeTypeArtist,
+}
+
+type LibraryHandler struct {
+	Repo                     *store.LikeRepository
+	SongRepo                 *store.SongRepository
+	AlbumRepo                *store.AlbumRepository
+	ArtistRepo               *store.ArtistRepository
+	AnalyticsServiceURL      string
+	RecommendationServiceURL string
+	Logger                   *logger.Logger
+}
+
+func NewLibraryHandler(repo *store.LikeRepository, songRepo *store.SongRepository, albumRepo *store.AlbumRepository, artistRepo *store.ArtistRepository, analyticsServiceURL, recommendationServiceURL string, log *logger.Logger) *LibraryHandler {
+	return &LibraryHandler{
+		Repo:                     repo,
+		SongRepo:                 songRepo,
+		AlbumRepo:                albumRepo,
+		ArtistRepo:               artistRepo,
+		AnalyticsServiceURL:      analyticsServiceURL,
+		RecommendationServiceURL: recommendationServiceURL,
+		Logger:                   log,
+	}
+}
+
+// extractLibraryItem splits /library/{songs|albums|artists}/{id} into the item type and ID
+func extractLibraryItem(path string) (itemType, itemID string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "library" {
+		return "", ""
+	}
+	return libraryPathTypes[parts[1]], parts[2]
+}
+
+// Like saves a song, album or artist to the caller's library
+// PUT /library/{songs|albums|artists}/{id}
+func (h *LibraryHandler) Like(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.setLiked(w, r, true)
+}
+
+// Unlike removes a song, album or artist from the caller's library
+// DELETE /library/{songs|albums|artists}/{id}
+func (h *LibraryHandler) Unlike(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.setLiked(w, r, false)
+}
+
+// setLiked likes or unlikes the item of the request path. Both are idempotent,
+// the like count and the activity only change when the library changed.
+func (h *LibraryHandler) setLiked(w http.ResponseWriter, r *http.Request, liked bool) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	itemType, itemID := extractLibraryItem(r.URL.Path)
+	if itemType == "" || itemID == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var changed bool
+	var err error
+	if liked {
+		// Only existing items can be liked, unliking a deleted item is allowed
+		exists, existsErr := h.Repo.Exists(r.Context(), itemType, itemID)
+		if existsErr != nil {
+			http.Error(w, "failed to get "+itemType+": "+existsErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			http.Error(w, itemType+" not found", http.StatusNotFound)
+			return
+		}
+		changed, err = h.Repo.Like(r.Context(), userID, itemType, itemID)
+	} else {
+		changed, err = h.Repo.Unlike(r.Context(), userID, itemType, itemID)
+	}
+	if err != nil {
+		http.Error(w, "failed to update library: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if changed {
+		h.logLikeActivity(r.Context(), userID, itemType, itemID, liked)
+	}
+
+	count, err := h.Repo.LikeCount(r.Context(), itemType, itemID)
+	if err != nil {
+		http.Error(w, "failed to get like count: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.LikeResponse{Liked: liked, LikeCount: count})
+}
+
+// logLikeActivity sends the like to analytics-service (user projections) and
+// recommendation-service (liked songs and artists count as a preference).
+// The item is looked up again for its name and genre, a failed lookup only drops the details.
+func (h *LibraryHandler) logLikeActivity(ctx context.Context, userID, itemType, itemID string, liked bool) {
+	activity := analytics.Activity{UserID: userID}
+	recommendationEvent := map[string]interface{}{"userId": userID}
+
+	switch itemType {
+	case model.LikeTypeSong:
+		activity.Type = analytics.ActivityTypeSongUnliked
+		recommendationEvent["type"] = "song_unliked"
+		if liked {
+			activity.Type = analytics.ActivityTypeSongLiked
+			recommendationEvent["type"] = "song_liked"
+		}
+		activity.SongID = itemID
+		recommendationEvent["songId"] = itemID
+		if song, err := h.SongRepo.GetByID(ctx, itemID); err == nil {
+			activity.SongName = song.Name
+			activity.Genre = song.Genre
+			activity.AlbumID = song.AlbumID
+			if len(song.ArtistIDs) > 0 {
+				activity.ArtistID = song.ArtistIDs[0]
+			}
+		}
+	case model.LikeTypeAlbum:
+		activity.Type = analytics.ActivityTypeAlbumUnliked
+		if liked {
+			activity.Type = analytics.ActivityTypeAlbumLiked
+		}
+		activity.AlbumID = itemID
+		// recommendation-service has no album preferences
+		recommendationEvent = nil
+		if album, err := h.AlbumRepo.GetByID(ctx, itemID); err == nil {
+			activity.AlbumName = album.Name
+			activity.Genre = album.Genre
+			if len(album.ArtistIDs) > 0 {
+				activity.ArtistID = album.ArtistIDs[0]
+			}
+		}
+	case model.LikeTypeArtist:
+		activity.Type = analytics.ActivityTypeArtistUnliked
+		recommendationEvent["type"] = "artist_unliked"
+		if liked {
+			activity.Type = analytics.ActivityTypeArtistLiked
+			recommendationEvent["type"] = "artist_liked"
+		}
+		activity.ArtistID = itemID
+		recommendationEvent["artistId"] = itemID
+		if artist, err := h.ArtistRepo.GetByID(ctx, itemID); err == nil {
+			activity.ArtistName = artist.Name
+			if len(artist.Genres) > 0 {
+				activity.Genre = artist.Genres[0]
+			}
+		}
+	}
+
+	// Artist name for song and album likes, the projections count listens and likes per artist name
+	if activity.ArtistName == "" && activity.ArtistID != "" {
+		if artist, err := h.ArtistRepo.GetByID(ctx, activity.ArtistID); err == nil {
+			activity.ArtistName = artist.Name
+		}
+	}
+
+	analytics.LogActivity(h.AnalyticsServiceURL, activity)
+	if recommendationEvent != nil {
+		events.EmitEvent(context.Background(), h.RecommendationServiceURL, recommendationEvent)
+	}
+}
+
+// GetLibrary lists the caller's library, most recently added first
+// GET /library?type=song|album|artist&limit=50&cursor=...
+// Items deleted from the catalog are skipped, so a page may be shorter than the limit.
+func (h *LibraryHandler) GetLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := getUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	limit, err := optionalInt(params.Get("limit"), 1, store.MaxPageSize)
+	if err != nil {
+		http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+		return
+	}
+	itemType := strings.TrimSpace(params.Get("type"))
+
+	likes, next, err := h.Repo.List(r.Context(), userID, itemType, store.ListQuery{Limit: limit, Cursor: params.Get("cursor")})
+	if err != nil {
+		if errors.Is(err, store.ErrUnknownLikeType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeListError(w, err, "library")
+		return
+	}
+
+	items, err := h.resolveItems(r.Context(), likes)
+	if err != nil {
+		http.Error(w, "failed to get library: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setNextCursor(w, next)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// resolveItems loads the liked items in one query per type
+func (h *LibraryHandler) resolveItems(ctx context.Context, likes []*model.Like) ([]dto.LibraryItemResponse, error) {
+	ids := map[string][]string{}
+	for _, like := range likes {
+		ids[like.ItemType] = append(ids[like.ItemType], like.ItemID)
+	}
+
+	songs, err := h.SongRepo.GetByIDs(ctx, ids[model.LikeTypeSong])
+	if err != nil {
+		return nil, err
+	}
+	albums, err := h.AlbumRepo.GetByIDs(ctx, ids[model.LikeTypeAlbum])
+	if err != nil {
+		return nil, err
+	}
+	artists, err := h.ArtistRepo.GetByIDs(ctx, ids[model.LikeTypeArtist])
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.LibraryItemResponse, 0, len(likes))
+	for _, like := range likes {
+		item := dto.LibraryItemResponse{Type: like.ItemType, ID: like.ItemID, AddedAt: like.CreatedAt}
+		switch like.ItemType {
+		case model.LikeTypeSong:
+			if song, ok := songs[like.ItemID]; ok {
+				item.Song = toSongResponse(song)
+			}
+		case model.LikeTypeAlbum:
+			if album, ok := albums[like.ItemID]; ok {
+				item.Album = toAlbumResponse(album)
+			}
+		case model.LikeTypeArtist:
+			if artist, ok := artists[like.ItemID]; ok {
+				item.Artist = dto.ToArtistResponse(artist)
+			}
+		}
+		if item.Song == nil && item.Album == nil && item.Artist == nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Contains reports which of the items are in the caller's library, e.g. to show like buttons on a list
+// GET /library/contains?type=song&ids=id1,id2
+func (h *LibraryHandler) Contains(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := getUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	params := r.URL.Query()
+	var ids []string
+	for _, id := range strings.Split(params.Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		http.Error(w, "ids query parameter is required", http.StatusBadRequest)
+		return
+	}
+	if len(ids) > libraryMaxContainsIDs {
+		http.Error(w, "at most 100 ids are allowed", http.StatusBadRequest)
+		return
+	}
+
+	liked, err := h.Repo.Contains(r.Context(), userID, strings.TrimSpace(params.Get("type")), ids)
+	if err != nil {
+		if errors.Is(err, store.ErrUnknownLikeType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to check library of user %s: %v", userID, err)
+		http.Error(w, "failed to check library", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(liked)
+}
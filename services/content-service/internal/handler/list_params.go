@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"content-service/internal/store"
+	"shared/pagination"
+)
+
+// parseListQuery reads the pagination, sort and filter parameters of a list endpoint:
+// ?limit=50&cursor=...&sort=name|-name|createdAt|releaseDate|rating&genre=...&artistId=...&albumId=...
+// A leading "-" sorts descending. Which sorts are supported depends on the entity.
+func parseListQuery(r *http.Request) (store.ListQuery, error) {
+	params := r.URL.Query()
+
+	limit, err := optionalInt(params.Get("limit"), 1, store.MaxPageSize)
+	if err != nil {
+		return store.ListQuery{}, errors.New("limit must be between 1 and 200")
+	}
+
+	q := store.ListQuery{
+		Limit:    limit,
+		Cursor:   params.Get("cursor"),
+		Sort:     "name",
+		Genre:    strings.TrimSpace(params.Get("genre")),
+		ArtistID: strings.TrimSpace(params.Get("artistId")),
+		AlbumID:  strings.TrimSpace(params.Get("albumId")),
+	}
+	if sort := strings.TrimSpace(params.Get("sort")); sort != "" {
+		q.Desc = strings.HasPrefix(sort, "-")
+		q.Sort = strings.TrimPrefix(sort, "-")
+	}
+	return q, nil
+}
+
+// writeListError maps list errors to responses
+func writeListError(w http.ResponseWriter, err error, what string) {
+	switch {
+	case errors.Is(err, store.ErrUnsupportedSort):
+		http.Error(w, "unsupported sort for "+what, http.StatusBadRequest)
+	case errors.Is(err, store.ErrInvalidCursor), errors.Is(err, store.ErrCursorMismatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "failed to get "+what+": "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// setNextCursor exposes the cursor of the next page, if there is one
+func setNextCursor(w http.ResponseWriter, next string) {
+	if next != "" {
+		w.Header().Set(pagination.HeaderNextCursor, next)
+	}
+}
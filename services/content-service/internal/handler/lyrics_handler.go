@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/lyrics"
+	"content-service/internal/model"
+)
+
+// lyricsEndTolerance is how far past the song's duration a synced line may start
+const lyricsEndTolerance = 5 * time.Second
+
+// languagePattern accepts BCP 47 style tags: "sr", "en-us", "sr-latn"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+// lyricsLine is one line of the JSON lines response; plain lyrics have no offsets
+type lyricsLine struct {
+	StartMs *int64 `json:"startMs,omitempty"`
+	EndMs   *int64 `json:"endMs,omitempty"`
+	Text    string `json:"text"`
+}
+
+// lyricsSummary describes one language in the list of a song's lyrics
+type lyricsSummary struct {
+	Language  string    `json:"language"`
+	Synced    bool      `json:"synced"`
+	Lines     int       `json:"lines"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// extractLyricsLanguage returns the language of /songs/{id}/lyrics/{language}
+func extractLyricsLanguage(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 4 && parts[2] == "lyrics" {
+		return strings.ToLower(parts[3])
+	}
+	return ""
+}
+
+// LyricsRoute handles the lyrics of a song
+// GET /songs/{id}/lyrics - languages with lyrics
+// GET /songs/{id}/lyrics/{language} - lyrics as JSON lines
+// PUT /songs/{id}/lyrics/{language} - set lyrics (LRC or plain text)
+// DELETE /songs/{id}/lyrics/{language} - remove lyrics
+func (h *SongHandler) LyricsRoute(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if extractLyricsLanguage(r.URL.Path) == "" {
+			h.ListLyrics(w, r)
+			return
+		}
+		h.GetLyrics(w, r)
+	case http.MethodPut:
+		h.SetLyrics(w, r)
+	case http.MethodDelete:
+		h.DeleteLyrics(w, r)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListLyrics returns the languages the song has lyrics in
+func (h *SongHandler) ListLyrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	if id == "" {
+		http.Error(w, "song ID is required", http.StatusBadRequest)
+		return
+	}
+	if exists, err := h.Repo.Exists(r.Context(), id); err != nil || !exists {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	variants, err := h.Lyrics.ListBySong(r.Context(), id)
+	if err != nil {
+		http.Error(w, "failed to load lyrics: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	summaries := make([]lyricsSummary, len(variants))
+	for i, variant := range variants {
+		summaries[i] = lyricsSummary{
+			Language:  variant.Language,
+			Synced:    variant.Synced,
+			Lines:     len(variant.Lines),
+			UpdatedAt: variant.UpdatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// GetLyrics writes the lyrics one JSON object per line. Synced lines carry startMs and endMs (the start
+// of the next line, or the end of the song for the last one), so a player can highlight the current line.
+func (h *SongHandler) GetLyrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	language := extractLyricsLanguage(r.URL.Path)
+	if id == "" || language == "" {
+		http.Error(w, "song ID and language are required", http.StatusBadRequest)
+		return
+	}
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+	variant, err := h.Lyrics.Get(r.Context(), id, language)
+	if err != nil {
+		http.Error(w, "lyrics not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.Header().Set("Content-Language", variant.Language)
+	w.Header().Set("Last-Modified", variant.UpdatedAt.UTC().Format(http.TimeFormat))
+	encoder := json.NewEncoder(w)
+	for i, line := range variant.Lines {
+		out := lyricsLine{Text: line.Text}
+		if variant.Synced {
+			start := variant.Lines[i].StartMs
+			out.StartMs = &start
+			if i+1 < len(variant.Lines) {
+				end := variant.Lines[i+1].StartMs
+				out.EndMs = &end
+			} else if songEnd := int64(song.Duration) * 1000; songEnd > start {
+				out.EndMs = &songEnd
+			}
+		}
+		if err := encoder.Encode(out); err != nil {
+			return
+		}
+	}
+}
+
+// SetLyrics parses and stores the lyrics of a song in a language, replacing earlier ones
+func (h *SongHandler) SetLyrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	language := extractLyricsLanguage(r.URL.Path)
+	if id == "" || language == "" {
+		http.Error(w, "song ID and language are required", http.StatusBadRequest)
+		return
+	}
+	if !languagePattern.MatchString(language) {
+		http.Error(w, "language must be a language tag such as en or sr-latn", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.SetLyricsRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 2*lyrics.MaxLength)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	parsed, err := lyrics.Parse(req.Text, strings.ToLower(strings.TrimSpace(req.Format)))
+	if err != nil {
+		http.Error(w, "invalid lyrics: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if parsed.Synced && song.Duration > 0 {
+		last := parsed.Lines[len(parsed.Lines)-1].StartMs
+		if limit := int64(song.Duration)*1000 + lyricsEndTolerance.Milliseconds(); last > limit {
+			http.Error(w, fmt.Sprintf("invalid lyrics: a line starts at %s, after the end of the song (%s)",
+				formatLyricsTime(last), formatLyricsTime(int64(song.Duration)*1000)), http.StatusBadRequest)
+			return
+		}
+	}
+
+	variant := &model.Lyrics{
+		SongID:    id,
+		Language:  language,
+		Synced:    parsed.Synced,
+		Lines:     make([]model.LyricsLine, len(parsed.Lines)),
+		UpdatedAt: time.Now(),
+		UpdatedBy: getAdminIDFromSongContext(r.Context()),
+	}
+	for i, line := range parsed.Lines {
+		variant.Lines[i] = model.LyricsLine{StartMs: line.StartMs, Text: line.Text}
+	}
+	if err := h.Lyrics.Save(r.Context(), variant); err != nil {
+		http.Error(w, "failed to save lyrics: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.refreshLyricsIndex(r.Context(), id); err != nil {
+		log.Printf("Failed to update the lyrics search text of song %s: %v", id, err)
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(variant.UpdatedBy, "SET_LYRICS", "songs", map[string]interface{}{
+			"songId":   id,
+			"language": language,
+			"synced":   variant.Synced,
+			"lines":    len(variant.Lines),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lyricsSummary{
+		Language:  variant.Language,
+		Synced:    variant.Synced,
+		Lines:     len(variant.Lines),
+		UpdatedAt: variant.UpdatedAt,
+	})
+}
+
+// DeleteLyrics removes the lyrics of a song in a language
+func (h *SongHandler) DeleteLyrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	language := extractLyricsLanguage(r.URL.Path)
+	if id == "" || language == "" {
+		http.Error(w, "song ID and language are required", http.StatusBadRequest)
+		return
+	}
+	if err := h.Lyrics.Delete(r.Context(), id, language); err != nil {
+		if err.Error() == "lyrics not found" {
+			http.Error(w, "lyrics not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to delete lyrics: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.refreshLyricsIndex(r.Context(), id); err != nil && err.Error() != "song not found" {
+		log.Printf("Failed to update the lyrics search text of song %s: %v", id, err)
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromSongContext(r.Context()), "DELETE_LYRICS", "songs", map[string]interface{}{
+			"songId":   id,
+			"language": language,
+		})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// refreshLyricsIndex copies the languages and the text of all the song's lyrics to the song,
+// where the search index covers them
+func (h *SongHandler) refreshLyricsIndex(ctx context.Context, songID string) error {
+	variants, err := h.Lyrics.ListBySong(ctx, songID)
+	if err != nil {
+		return err
+	}
+	languages := make([]string, 0, len(variants))
+	var text []string
+	for _, variant := range variants {
+		languages = append(languages, variant.Language)
+		seen := map[string]bool{}
+		for _, line := range variant.Lines {
+			// A repeated chorus is indexed once
+			if line.Text != "" && !seen[line.Text] {
+				seen[line.Text] = true
+				text = append(text, line.Text)
+			}
+		}
+	}
+	return h.Repo.SetLyricsIndex(ctx, songID, languages, strings.Join(text, "\n"))
+}
+
+// DeleteSongLyrics deletes the lyrics of a deleted song
+func (h *SongHandler) DeleteSongLyrics(songID string) {
+	if err := h.Lyrics.DeleteBySong(context.Background(), songID); err != nil {
+		log.Printf("Failed to delete lyrics of song %s: %v", songID, err)
+	}
+}
+
+// formatLyricsTime writes an offset as mm:ss.xx, as in LRC files
+func formatLyricsTime(ms int64) string {
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"content-service/internal/cache"
+	"content-service/internal/model"
 	"content-service/internal/store"
 )
 
@@ -66,7 +67,7 @@ func (h *MostPlayedHandler) GetMostPlayedSongs(w http.ResponseWriter, r *http.Re
 		Genre        string   `json:"genre"`
 		AlbumID      string   `json:"albumId"`
 		ArtistIDs    []string `json:"artistIds"`
-		AudioFileURL string   `json:"audioFileUrl,omitempty"`
+		AudioFile    *model.StorageRef `json:"audioFile,omitempty"`
 		PlayCount    int      `json:"playCount"`
 	}
 
@@ -85,7 +86,7 @@ func (h *MostPlayedHandler) GetMostPlayedSongs(w http.ResponseWriter, r *http.Re
 			Genre:        song.Genre,
 			AlbumID:      song.AlbumID,
 			ArtistIDs:    song.ArtistIDs,
-			AudioFileURL: song.AudioFileURL,
+			AudioFile:    song.AudioFile,
 			PlayCount:    mp.Count,
 		})
 	}
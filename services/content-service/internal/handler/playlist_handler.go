@@ -0,0 +1,686 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"content-service/internal/dto"
+	"content-service/internal/logger"
+	"content-service/internal/middleware"
+	"content-service/internal/model"
+	"content-service/internal/store"
+)
+
+const (
+	playlistMaxNameRunes        = 100
+	playlistMaxDescriptionRunes = 500
+	playlistMaxSongs            = 1000
+	playlistMaxEditors          = 20
+	playlistPublicDefaultLimit  = 50
+	playlistPublicMaxLimit      = 200
+)
+
+var (
+	errPlaylistForbidden = errors.New("forbidden")
+	errPlaylistFull      = errors.New("playlist is full")
+	errEntryNotFound     = errors.New("playlist entry not found")
+)
+
+// ExtractPlaylistPath splits /playlists/{id}/{sub}/{subID} into its parts (also used for routing)
+func ExtractPlaylistPath(path string) (id, sub, subID string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "playlists" {
+		return "", "", ""
+	}
+	id = parts[1]
+	if len(parts) >= 3 {
+		sub = parts[2]
+	}
+	if len(parts) >= 4 {
+		subID = parts[3]
+	}
+	return id, sub, subID
+}
+
+// getUserIDFromContext extracts the caller's user ID from request context
+func getUserIDFromContext(ctx context.Context) string {
+	claims, ok := ctx.Value(middleware.UserContextKey).(*middleware.UserClaims)
+	if !ok || claims == nil {
+		return ""
+	}
+	return claims.UserID
+}
+
+type PlaylistHandler struct {
+	Repo     *store.PlaylistRepository
+	SongRepo *store.SongRepository
+	Logger   *logger.Logger
+}
+
+func NewPlaylistHandler(repo *store.PlaylistRepository, songRepo *store.SongRepository, log *logger.Logger) *PlaylistHandler {
+	return &PlaylistHandler{
+		Repo:     repo,
+		SongRepo: songRepo,
+		Logger:   log,
+	}
+}
+
+// CreatePlaylist creates a playlist owned by the caller
+// POST /playlists
+func (h *PlaylistHandler) CreatePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := getUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreatePlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	playlist := &model.Playlist{
+		OwnerID:       userID,
+		Name:          strings.TrimSpace(req.Name),
+		Description:   strings.TrimSpace(req.Description),
+		CoverURL:      strings.TrimSpace(req.CoverURL),
+		Visibility:    req.Visibility,
+		Collaborative: req.Collaborative,
+	}
+	if playlist.Visibility == "" {
+		playlist.Visibility = model.PlaylistPrivate
+	}
+	if msg := validatePlaylist(playlist); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Repo.Create(r.Context(), playlist); err != nil {
+		http.Error(w, "failed to create playlist: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toPlaylistResponse(playlist, nil))
+}
+
+// GetMyPlaylists lists the playlists the caller owns or was invited to edit
+// GET /playlists
+func (h *PlaylistHandler) GetMyPlaylists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := getUserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	playlists, err := h.Repo.ListForUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "failed to get playlists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writePlaylists(w, playlists)
+}
+
+// GetPublicPlaylists lists public playlists, optionally of one owner
+// GET /playlists/public?ownerId={id}&limit=50
+func (h *PlaylistHandler) GetPublicPlaylists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, err := optionalInt(r.URL.Query().Get("limit"), 1, playlistPublicMaxLimit)
+	if err != nil {
+		http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = playlistPublicDefaultLimit
+	}
+
+	playlists, err := h.Repo.ListPublic(r.Context(), strings.TrimSpace(r.URL.Query().Get("ownerId")), limit)
+	if err != nil {
+		http.Error(w, "failed to get playlists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writePlaylists(w, playlists)
+}
+
+// GetPlaylist returns a playlist with its songs. Private playlists are only visible to the owner
+// and the editors, for everybody else they do not exist.
+// GET /playlists/{id}
+func (h *PlaylistHandler) GetPlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := ExtractPlaylistPath(r.URL.Path)
+	if id == "" {
+		http.Error(w, "playlist ID is required", http.StatusBadRequest)
+		return
+	}
+
+	playlist, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil || !playlist.CanView(getUserIDFromContext(r.Context())) {
+		http.Error(w, "playlist not found", http.StatusNotFound)
+		return
+	}
+	h.writePlaylist(w, r, playlist, http.StatusOK)
+}
+
+// UpdatePlaylist renames the playlist or changes its description, cover, visibility or collaboration (owner only)
+// PUT /playlists/{id}
+func (h *PlaylistHandler) UpdatePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := ExtractPlaylistPath(r.URL.Path)
+	if id == "" {
+		http.Error(w, "playlist ID is required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserIDFromContext(r.Context())
+
+	var req dto.UpdatePlaylistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	var invalid string
+	playlist, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if !p.CanView(userID) {
+			return store.ErrPlaylistNotFound
+		}
+		if p.OwnerID != userID {
+			return errPlaylistForbidden
+		}
+		if req.Name != nil {
+			p.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Description != nil {
+			p.Description = strings.TrimSpace(*req.Description)
+		}
+		if req.CoverURL != nil {
+			p.CoverURL = strings.TrimSpace(*req.CoverURL)
+		}
+		if req.Visibility != nil {
+			p.Visibility = *req.Visibility
+		}
+		if req.Collaborative != nil {
+			p.Collaborative = *req.Collaborative
+		}
+		if invalid = validatePlaylist(p); invalid != "" {
+			return errors.New(invalid)
+		}
+		return nil
+	})
+	if invalid != "" {
+		http.Error(w, invalid, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	h.writePlaylist(w, r, playlist, http.StatusOK)
+}
+
+// DeletePlaylist deletes the playlist (owner only)
+// DELETE /playlists/{id}
+func (h *PlaylistHandler) DeletePlaylist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := ExtractPlaylistPath(r.URL.Path)
+	if id == "" {
+		http.Error(w, "playlist ID is required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserIDFromContext(r.Context())
+
+	playlist, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil || !playlist.CanView(userID) {
+		http.Error(w, "playlist not found", http.StatusNotFound)
+		return
+	}
+	if playlist.OwnerID != userID {
+		http.Error(w, "only the owner can delete the playlist", http.StatusForbidden)
+		return
+	}
+
+	if err := h.Repo.Delete(r.Context(), id, userID); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddSong adds a song to the playlist, at the end or at the given position (owner and editors)
+// POST /playlists/{id}/songs
+func (h *PlaylistHandler) AddSong(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := ExtractPlaylistPath(r.URL.Path)
+	userID := getUserIDFromContext(r.Context())
+
+	var req dto.AddPlaylistSongRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.SongID == "" {
+		http.Error(w, "songId is required", http.StatusBadRequest)
+		return
+	}
+	if req.Position < 0 {
+		http.Error(w, "position must be positive", http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.SongRepo.Exists(r.Context(), req.SongID)
+	if err != nil {
+		http.Error(w, "failed to check song existence", http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "song not found", http.StatusBadRequest)
+		return
+	}
+
+	entry := model.PlaylistEntry{
+		ID:      uuid.NewString(),
+		SongID:  req.SongID,
+		AddedBy: userID,
+		AddedAt: time.Now(),
+	}
+	playlist, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if err := checkPlaylistEditor(p, userID); err != nil {
+			return err
+		}
+		if len(p.Entries) >= playlistMaxSongs {
+			return errPlaylistFull
+		}
+		p.InsertEntry(entry, req.Position)
+		return nil
+	})
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	h.writePlaylist(w, r, playlist, http.StatusCreated)
+}
+
+// MoveSong moves a playlist entry to a new position (owner and editors)
+// PUT /playlists/{id}/songs/{entryId} {"position": 1}
+func (h *PlaylistHandler) MoveSong(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, entryID := ExtractPlaylistPath(r.URL.Path)
+	if entryID == "" {
+		http.Error(w, "entry ID is required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserIDFromContext(r.Context())
+
+	var req dto.MovePlaylistSongRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.Position < 1 {
+		http.Error(w, "position must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	playlist, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if err := checkPlaylistEditor(p, userID); err != nil {
+			return err
+		}
+		if !p.MoveEntry(entryID, req.Position) {
+			return errEntryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	h.writePlaylist(w, r, playlist, http.StatusOK)
+}
+
+// RemoveSong removes a playlist entry (owner and editors)
+// DELETE /playlists/{id}/songs/{entryId}
+func (h *PlaylistHandler) RemoveSong(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, entryID := ExtractPlaylistPath(r.URL.Path)
+	if entryID == "" {
+		http.Error(w, "entry ID is required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserIDFromContext(r.Context())
+
+	playlist, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if err := checkPlaylistEditor(p, userID); err != nil {
+			return err
+		}
+		if !p.RemoveEntry(entryID) {
+			return errEntryNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	h.writePlaylist(w, r, playlist, http.StatusOK)
+}
+
+// AddEditor invites a user to edit a collaborative playlist (owner only)
+// POST /playlists/{id}/editors {"userId": "..."}
+func (h *PlaylistHandler) AddEditor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := ExtractPlaylistPath(r.URL.Path)
+	userID := getUserIDFromContext(r.Context())
+
+	var req dto.AddPlaylistEditorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	editorID := strings.TrimSpace(req.UserID)
+	if editorID == "" {
+		http.Error(w, "userId is required", http.StatusBadRequest)
+		return
+	}
+
+	var invalid string
+	playlist, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if !p.CanView(userID) {
+			return store.ErrPlaylistNotFound
+		}
+		if p.OwnerID != userID {
+			return errPlaylistForbidden
+		}
+		switch {
+		case !p.Collaborative:
+			invalid = "editors can only be invited to collaborative playlists"
+		case editorID == p.OwnerID:
+			invalid = "the owner cannot be invited as an editor"
+		case len(p.EditorIDs) >= playlistMaxEditors:
+			invalid = "a playlist can have at most 20 editors"
+		}
+		if invalid != "" {
+			return errors.New(invalid)
+		}
+		for _, existing := range p.EditorIDs {
+			if existing == editorID {
+				return nil
+			}
+		}
+		p.EditorIDs = append(p.EditorIDs, editorID)
+		return nil
+	})
+	if invalid != "" {
+		http.Error(w, invalid, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+
+	h.writePlaylist(w, r, playlist, http.StatusOK)
+}
+
+// RemoveEditor removes an editor. The owner can remove anyone, an editor can only leave.
+// DELETE /playlists/{id}/editors/{userId}
+func (h *PlaylistHandler) RemoveEditor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, editorID := ExtractPlaylistPath(r.URL.Path)
+	if editorID == "" {
+		http.Error(w, "editor user ID is required", http.StatusBadRequest)
+		return
+	}
+	userID := getUserIDFromContext(r.Context())
+
+	_, err := h.Repo.Modify(r.Context(), id, func(p *model.Playlist) error {
+		if !p.CanView(userID) {
+			return store.ErrPlaylistNotFound
+		}
+		if p.OwnerID != userID && editorID != userID {
+			return errPlaylistForbidden
+		}
+		kept := make([]string, 0, len(p.EditorIDs))
+		for _, existing := range p.EditorIDs {
+			if existing != editorID {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(p.EditorIDs) {
+			return errEntryNotFound
+		}
+		p.EditorIDs = kept
+		return nil
+	})
+	if errors.Is(err, errEntryNotFound) {
+		http.Error(w, "editor not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveSongFromAll removes deleted songs from every playlist (song, album and artist deletion saga
+// step); songId is repeated for a batch of songs. The removed entries are returned so the saga can
+// restore them on compensation.
+// DELETE /playlists/internal/songs?songId={id}
+func (h *PlaylistHandler) RemoveSongFromAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	songIDs := r.URL.Query()["songId"]
+	if len(songIDs) == 0 || songIDs[0] == "" {
+		http.Error(w, "songId parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := h.Repo.RemoveSongs(r.Context(), songIDs)
+	if err != nil {
+		http.Error(w, "failed to remove song from playlists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Removed songs %v from playlists (%d entries)", songIDs, len(removed))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"songId":  songIDs[0],
+		"songIds": songIDs,
+		"removed": removed,
+	})
+}
+
+// RestoreSongEntries puts back entries removed by RemoveSongFromAll (saga compensation)
+// POST /playlists/internal/songs/restore {"removed": [...]}
+func (h *PlaylistHandler) RestoreSongEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Removed []model.RemovedPlaylistEntry `json:"removed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Repo.RestoreEntries(r.Context(), req.Removed); err != nil {
+		http.Error(w, "failed to restore playlist entries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPlaylistEditor allows changing the songs to the owner and, on collaborative playlists, the editors
+func checkPlaylistEditor(p *model.Playlist, userID string) error {
+	if !p.CanView(userID) {
+		return store.ErrPlaylistNotFound
+	}
+	if !p.IsEditor(userID) {
+		return errPlaylistForbidden
+	}
+	return nil
+}
+
+// validatePlaylist returns a message describing the first invalid field, or an empty string
+func validatePlaylist(p *model.Playlist) string {
+	switch {
+	case p.Name == "":
+		return "name is required"
+	case utf8.RuneCountInString(p.Name) > playlistMaxNameRunes:
+		return "name must be at most 100 characters"
+	case utf8.RuneCountInString(p.Description) > playlistMaxDescriptionRunes:
+		return "description must be at most 500 characters"
+	case p.Visibility != model.PlaylistPublic && p.Visibility != model.PlaylistPrivate:
+		return "visibility must be public or private"
+	case p.CoverURL != "" && !strings.HasPrefix(p.CoverURL, "https://") && !strings.HasPrefix(p.CoverURL, "http://"):
+		return "coverUrl must be an http(s) URL"
+	}
+	return ""
+}
+
+// writePlaylistError maps playlist errors to responses
+func writePlaylistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrPlaylistNotFound):
+		http.Error(w, "playlist not found", http.StatusNotFound)
+	case errors.Is(err, errPlaylistForbidden):
+		http.Error(w, "you are not allowed to change this playlist", http.StatusForbidden)
+	case errors.Is(err, errEntryNotFound):
+		http.Error(w, "playlist entry not found", http.StatusNotFound)
+	case errors.Is(err, errPlaylistFull):
+		http.Error(w, "a playlist can have at most 1000 songs", http.StatusBadRequest)
+	case errors.Is(err, store.ErrPlaylistConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "failed to update playlist: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writePlaylist writes the playlist with its entries and the details of their songs
+func (h *PlaylistHandler) writePlaylist(w http.ResponseWriter, r *http.Request, playlist *model.Playlist, status int) {
+	ids := make([]string, 0, len(playlist.Entries))
+	for _, e := range playlist.Entries {
+		ids = append(ids, e.SongID)
+	}
+	songs, err := h.SongRepo.GetByIDs(r.Context(), ids)
+	if err != nil {
+		http.Error(w, "failed to get playlist songs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(toPlaylistResponse(playlist, songs))
+}
+
+func writePlaylists(w http.ResponseWriter, playlists []*model.Playlist) {
+	responses := make([]*dto.PlaylistResponse, len(playlists))
+	for i, playlist := range playlists {
+		responses[i] = toPlaylistResponse(playlist, nil)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responses)
+}
+
+// toPlaylistResponse converts a playlist; entries are included when songs is not nil
+func toPlaylistResponse(playlist *model.Playlist, songs map[string]*model.Song) *dto.PlaylistResponse {
+	resp := &dto.PlaylistResponse{
+		ID:            playlist.ID,
+		OwnerID:       playlist.OwnerID,
+		Name:          playlist.Name,
+		Description:   playlist.Description,
+		CoverURL:      playlist.CoverURL,
+		Visibility:    playlist.Visibility,
+		Collaborative: playlist.Collaborative,
+		EditorIDs:     playlist.EditorIDs,
+		SongCount:     len(playlist.Entries),
+		Version:       playlist.Version,
+		CreatedAt:     playlist.CreatedAt,
+		UpdatedAt:     playlist.UpdatedAt,
+	}
+	if resp.EditorIDs == nil {
+		resp.EditorIDs = []string{}
+	}
+	if songs == nil {
+		return resp
+	}
+
+	resp.Entries = make([]dto.PlaylistEntryResponse, len(playlist.Entries))
+	for i, e := range playlist.Entries {
+		resp.Entries[i] = dto.PlaylistEntryResponse{
+			ID:       e.ID,
+			Position: e.Position,
+			SongID:   e.SongID,
+			AddedBy:  e.AddedBy,
+			AddedAt:  e.AddedAt,
+		}
+		if song, ok := songs[e.SongID]; ok {
+			resp.Entries[i].Song = toSongResponse(song)
+		}
+	}
+	return resp
+}
@@ -0,0 +1,345 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"content-service/internal/dto"
+	"content-service/internal/model"
+	"content-service/internal/store"
+)
+
+const (
+	defaultRevisionLimit = 20
+	maxRevisionLimit     = 100
+)
+
+// ExtractRevisionPath splits /{songs|albums|artists}/{id}/revisions/{number}/{action}; missing parts
+// are empty and number is 0 when absent or not a positive number
+func ExtractRevisionPath(path string) (collection, id string, number int, action string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[2] != "revisions" {
+		return "", "", 0, ""
+	}
+	collection, id = parts[0], parts[1]
+	if len(parts) > 3 {
+		if n, err := strconv.Atoi(parts[3]); err == nil && n > 0 {
+			number = n
+		}
+	}
+	if len(parts) > 4 {
+		action = parts[4]
+	}
+	return collection, id, number, action
+}
+
+// RevisionHandler serves the revision history of songs, albums and artists. Every create, update and
+// rollback stores a revision with the changed fields and the editable state after it; rolling back
+// applies the state of an older revision as a new update.
+type RevisionHandler struct {
+	Repo    *store.RevisionRepository
+	Songs   *SongHandler
+	Albums  *AlbumHandler
+	Artists *ArtistHandler
+}
+
+func NewRevisionHandler(repo *store.RevisionRepository, songs *SongHandler, albums *AlbumHandler, artists *ArtistHandler) *RevisionHandler {
+	return &RevisionHandler{
+		Repo:    repo,
+		Songs:   songs,
+		Albums:  albums,
+		Artists: artists,
+	}
+}
+
+// Revisions handles the history of an item
+// GET /{collection}/{id}/revisions - revisions, newest first (?before={number}&limit=)
+// GET /{collection}/{id}/revisions/{number} - one revision
+// POST /{collection}/{id}/revisions/{number}/rollback - restore the state of a revision
+func (h *RevisionHandler) Revisions(w http.ResponseWriter, r *http.Request) {
+	collection, id, number, action := ExtractRevisionPath(r.URL.Path)
+	if revisionEntityType(collection) == "" || id == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		h.ListRevisions(w, r)
+	case number > 0 && action == "" && r.Method == http.MethodGet:
+		h.GetRevision(w, r)
+	case number > 0 && action == "rollback" && r.Method == http.MethodPost:
+		h.Rollback(w, r)
+	case len(parts) == 3 || (number > 0 && (action == "" || action == "rollback")):
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	case len(parts) > 3 && number == 0:
+		http.Error(w, "revision number must be a positive integer", http.StatusBadRequest)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// ListRevisions returns the revisions of an item, newest first; the next page starts before the
+// number in the next-cursor header
+func (h *RevisionHandler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, _, _ := ExtractRevisionPath(r.URL.Path)
+	before := 0
+	if s := r.URL.Query().Get("before"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "before must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		before = n
+	}
+	limit := defaultRevisionLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxRevisionLimit {
+			http.Error(w, "limit must be between 1 and "+strconv.Itoa(maxRevisionLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	revisions, err := h.Repo.List(r.Context(), revisionEntityType(collection), id, before, int64(limit))
+	if err != nil {
+		http.Error(w, "failed to list revisions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(revisions) == limit && revisions[len(revisions)-1].Number > 1 {
+		setNextCursor(w, strconv.Itoa(revisions[len(revisions)-1].Number))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetRevision returns one revision of an item
+func (h *RevisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, number, _ := ExtractRevisionPath(r.URL.Path)
+	revision, err := h.Repo.Get(r.Context(), revisionEntityType(collection), id, number)
+	if err != nil {
+		if err.Error() == "revision not found" {
+			http.Error(w, "revision not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get revision: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revision)
+}
+
+// Rollback restores the editable fields of an item to their state in a revision. It goes through the
+// same validation and events as an update and is recorded as a new revision; the item's current
+// version is returned.
+func (h *RevisionHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, number, _ := ExtractRevisionPath(r.URL.Path)
+	revision, err := h.Repo.Get(r.Context(), revisionEntityType(collection), id, number)
+	if err != nil {
+		if err.Error() == "revision not found" {
+			http.Error(w, "revision not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to get revision: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var response interface{}
+	switch collection {
+	case TrashSongs:
+		var req dto.UpdateSongRequest
+		if err := decodeRevisionState(revision, &req); err != nil {
+			http.Error(w, "failed to read revision: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.Songs.validateUpdate(r.Context(), &req); err != nil {
+			http.Error(w, "revision cannot be restored: "+err.Error(), http.StatusConflict)
+			return
+		}
+		song, err := h.Songs.Repo.GetByID(r.Context(), id)
+		if err != nil {
+			http.Error(w, "song not found", http.StatusNotFound)
+			return
+		}
+		if err := h.Songs.applyUpdate(r.Context(), song, &req, number); err != nil {
+			http.Error(w, "failed to roll back song: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = toSongResponse(song)
+	case TrashAlbums:
+		var req dto.UpdateAlbumRequest
+		if err := decodeRevisionState(revision, &req); err != nil {
+			http.Error(w, "failed to read revision: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := validateAlbumUpdate(&req); err != nil {
+			http.Error(w, "revision cannot be restored: "+err.Error(), http.StatusConflict)
+			return
+		}
+		album, err := h.Albums.Repo.GetByID(r.Context(), id)
+		if err != nil {
+			http.Error(w, "album not found", http.StatusNotFound)
+			return
+		}
+		if err := h.Albums.applyUpdate(r.Context(), album, &req, number); err != nil {
+			http.Error(w, "failed to roll back album: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = toAlbumResponse(album)
+	case TrashArtists:
+		var req dto.UpdateArtistRequest
+		if err := decodeRevisionState(revision, &req); err != nil {
+			http.Error(w, "failed to read revision: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := validateArtistUpdate(&req); err != nil {
+			http.Error(w, "revision cannot be restored: "+err.Error(), http.StatusConflict)
+			return
+		}
+		artist, err := h.Artists.Repo.GetByID(r.Context(), id)
+		if err != nil {
+			http.Error(w, "artist not found", http.StatusNotFound)
+			return
+		}
+		if err := h.Artists.applyUpdate(r.Context(), artist, &req, number); err != nil {
+			http.Error(w, "failed to roll back artist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = dto.ToArtistResponse(artist)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// revisionEntityType maps a collection in the URL to the entity type of its revisions
+func revisionEntityType(collection string) string {
+	switch collection {
+	case TrashSongs:
+		return model.LikeTypeSong
+	case TrashAlbums:
+		return model.LikeTypeAlbum
+	case TrashArtists:
+		return model.LikeTypeArtist
+	}
+	return ""
+}
+
+// decodeRevisionState reads the state of a revision into an update request; the state is keyed
+// like the request's JSON
+func decodeRevisionState(revision *model.Revision, req interface{}) error {
+	data, err := json.Marshal(revision.State)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, req)
+}
+
+// recordRevision stores the change of an item from oldState to newState. A nil oldState records its
+// creation and restoredFrom marks a rollback. Updates that change nothing are not recorded. The first
+// recorded update of an item created before revisions were kept also stores its previous state, so
+// that the item can be rolled back to it. Failures are logged: the change itself is already saved.
+func recordRevision(ctx context.Context, repo *store.RevisionRepository, entityType, entityID string, oldState, newState map[string]interface{}, authorID string, restoredFrom int) {
+	if repo == nil {
+		return
+	}
+
+	action := model.RevisionUpdate
+	switch {
+	case oldState == nil:
+		action = model.RevisionCreate
+	case restoredFrom > 0:
+		action = model.RevisionRollback
+	}
+
+	changes := revisionChanges(oldState, newState)
+	if action != model.RevisionCreate && len(changes) == 0 {
+		return
+	}
+
+	if action != model.RevisionCreate {
+		existing, err := repo.List(ctx, entityType, entityID, 0, 1)
+		if err != nil {
+			log.Printf("Failed to read revisions of %s %s: %v", entityType, entityID, err)
+			return
+		}
+		if len(existing) == 0 {
+			initial := &model.Revision{
+				EntityType: entityType,
+				EntityID:   entityID,
+				Action:     model.RevisionInitial,
+				Changes:    revisionChanges(nil, oldState),
+				State:      oldState,
+			}
+			if err := repo.Add(ctx, initial); err != nil {
+				log.Printf("Failed to record initial revision of %s %s: %v", entityType, entityID, err)
+				return
+			}
+		}
+	}
+
+	revision := &model.Revision{
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Action:       action,
+		Changes:      changes,
+		State:        newState,
+		AuthorID:     authorID,
+		RestoredFrom: restoredFrom,
+	}
+	if err := repo.Add(ctx, revision); err != nil {
+		log.Printf("Failed to record revision of %s %s: %v", entityType, entityID, err)
+	}
+}
+
+// revisionChanges lists the fields whose JSON value differs between two states, sorted by field
+func revisionChanges(oldState, newState map[string]interface{}) []model.FieldChange {
+	fields := make([]string, 0, len(newState))
+	for field := range newState {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []model.FieldChange{}
+	for _, field := range fields {
+		var old interface{}
+		if oldState != nil {
+			old = oldState[field]
+		}
+		oldJSON, _ := json.Marshal(old)
+		newJSON, _ := json.Marshal(newState[field])
+		if oldState != nil && bytes.Equal(oldJSON, newJSON) {
+			continue
+		}
+		changes = append(changes, model.FieldChange{Field: field, Old: old, New: newState[field]})
+	}
+	return changes
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"content-service/internal/dto"
+	"content-service/internal/logger"
+	"content-service/internal/search"
+	"content-service/internal/store"
+)
+
+const (
+	searchTypeSong   = "song"
+	searchTypeAlbum  = "album"
+	searchTypeArtist = "artist"
+
+	searchDefaultLimit  = 20
+	searchMaxLimit      = 50
+	searchMaxQueryRunes = 100
+	// biographySnippetRunes is the length of the biography excerpt around the first match
+	biographySnippetRunes = 160
+)
+
+var searchTypes = []string{searchTypeSong, searchTypeAlbum, searchTypeArtist}
+
+type SearchHandler struct {
+	Repo   *store.SearchRepository
+	Logger *logger.Logger
+}
+
+func NewSearchHandler(repo *store.SearchRepository, log *logger.Logger) *SearchHandler {
+	return &SearchHandler{Repo: repo, Logger: log}
+}
+
+// Search searches songs, albums and artists by name (and artist biography, song lyrics)
+// GET /search?q=...&type=song,album,artist&genre=...&yearFrom=...&yearTo=...&artistId=...&limit=20&offset=0
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	query := strings.TrimSpace(params.Get("q"))
+	if query == "" {
+		http.Error(w, "q query parameter is required", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(query) > searchMaxQueryRunes {
+		http.Error(w, "q must be at most 100 characters", http.StatusBadRequest)
+		return
+	}
+
+	types := map[string]bool{}
+	if t := params.Get("type"); t != "" {
+		for _, name := range strings.Split(t, ",") {
+			name = strings.TrimSpace(name)
+			if name != searchTypeSong && name != searchTypeAlbum && name != searchTypeArtist {
+				http.Error(w, "type must be song, album or artist", http.StatusBadRequest)
+				return
+			}
+			types[name] = true
+		}
+	}
+
+	filter := store.SearchFilter{
+		Genre:    strings.TrimSpace(params.Get("genre")),
+		ArtistID: strings.TrimSpace(params.Get("artistId")),
+	}
+	var err error
+	if filter.YearFrom, err = optionalInt(params.Get("yearFrom"), 0, 9999); err != nil {
+		http.Error(w, "yearFrom must be a valid year", http.StatusBadRequest)
+		return
+	}
+	if filter.YearTo, err = optionalInt(params.Get("yearTo"), 0, 9999); err != nil {
+		http.Error(w, "yearTo must be a valid year", http.StatusBadRequest)
+		return
+	}
+	if filter.YearFrom > 0 && filter.YearTo > 0 && filter.YearFrom > filter.YearTo {
+		http.Error(w, "yearFrom must not be after yearTo", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := optionalInt(params.Get("limit"), 1, searchMaxLimit)
+	if err != nil {
+		http.Error(w, "limit must be between 1 and 50", http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = searchDefaultLimit
+	}
+	offset, err := optionalInt(params.Get("offset"), 0, 10000)
+	if err != nil {
+		http.Error(w, "offset must be a non-negative number", http.StatusBadRequest)
+		return
+	}
+
+	matcher := search.NewMatcher(query)
+	hits, err := h.collectHits(r, query, matcher, filter)
+	if err != nil {
+		log.Printf("Search failed for %q: %v", query, err)
+		http.Error(w, "search failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Best matches first, ties alphabetically
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return strings.ToLower(hits[i].Name) < strings.ToLower(hits[j].Name)
+	})
+
+	// Facets count every type so the client can show them next to the type filter
+	facets := map[string]int{}
+	for _, t := range searchTypes {
+		facets[t] = 0
+	}
+	filtered := make([]dto.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		facets[hit.Type]++
+		if len(types) == 0 || types[hit.Type] {
+			filtered = append(filtered, hit)
+		}
+	}
+
+	page := []dto.SearchHit{}
+	if offset < len(filtered) {
+		end := offset + limit
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		page = filtered[offset:end]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.SearchResponse{
+		Query:   query,
+		Total:   len(filtered),
+		Limit:   limit,
+		Offset:  offset,
+		Facets:  facets,
+		Results: page,
+	})
+}
+
+// collectHits searches every type and scores the matches
+func (h *SearchHandler) collectHits(r *http.Request, query string, m *search.Matcher, filter store.SearchFilter) ([]dto.SearchHit, error) {
+	ctx := r.Context()
+	var hits []dto.SearchHit
+
+	songs, songScores, err := h.Repo.SearchSongs(ctx, query, m, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, song := range songs {
+		if hit, ok := newSearchHit(m, searchTypeSong, song.ID, song.Name, "lyrics", matchingLyricsLine(m, song.LyricsText), songScores[song.ID]); ok {
+			hit.Item = toSongResponse(song)
+			hits = append(hits, hit)
+		}
+	}
+
+	albums, albumScores, err := h.Repo.SearchAlbums(ctx, query, m, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, album := range albums {
+		if hit, ok := newSearchHit(m, searchTypeAlbum, album.ID, album.Name, "", "", albumScores[album.ID]); ok {
+			hit.Item = toAlbumResponse(album)
+			hits = append(hits, hit)
+		}
+	}
+
+	artists, artistScores, err := h.Repo.SearchArtists(ctx, query, m, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, artist := range artists {
+		if hit, ok := newSearchHit(m, searchTypeArtist, artist.ID, artist.Name, "biography", artist.Biography, artistScores[artist.ID]); ok {
+			hit.Item = dto.ToArtistResponse(artist)
+			hits = append(hits, hit)
+		}
+	}
+
+	return hits, nil
+}
+
+// newSearchHit ranks a candidate and highlights its matching fields: the name and a secondary field
+// (artist biography, the matching lyrics line of a song).
+// The text index score is added to the matcher score, so whole-word matches rank above typos.
+func newSearchHit(m *search.Matcher, hitType, id, name, secondaryField, secondary string, textScore float64) (dto.SearchHit, bool) {
+	score, ok := m.Score(name, secondary)
+	if !ok && textScore == 0 {
+		return dto.SearchHit{}, false
+	}
+
+	hit := dto.SearchHit{
+		Type:       hitType,
+		ID:         id,
+		Name:       name,
+		Score:      score + textScore,
+		Highlights: []dto.SearchHighlight{},
+	}
+	if snippet, ok := m.Highlight(name, 0); ok {
+		hit.Highlights = append(hit.Highlights, dto.SearchHighlight{Field: "name", Snippet: snippet})
+	}
+	if snippet, ok := m.Highlight(secondary, biographySnippetRunes); ok {
+		hit.Highlights = append(hit.Highlights, dto.SearchHighlight{Field: secondaryField, Snippet: snippet})
+	}
+	return hit, true
+}
+
+// matchingLyricsLine returns the lyrics line that best matches the query, so a song found by a
+// remembered line shows that line
+func matchingLyricsLine(m *search.Matcher, lyrics string) string {
+	best, bestScore := "", 0.0
+	for _, line := range strings.Split(lyrics, "\n") {
+		if score, ok := m.Score(line, ""); ok && score > bestScore {
+			best, bestScore = line, score
+		}
+	}
+	return best
+}
+
+// optionalInt parses an optional query parameter; an empty value returns 0
+func optionalInt(value string, min, max int) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < min || n > max {
+		return 0, strconv.ErrRange
+	}
+	return n, nil
+}
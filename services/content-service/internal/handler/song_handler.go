@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -34,6 +34,10 @@ func extractSongID(path string) string {
 
 type SongHandler struct {
 	Repo                     *store.SongRepository
+	Blobs                    *store.AudioBlobRepository
+	Lyrics                   *store.LyricsRepository
+	Likes                    *store.LikeRepository
+	Revisions                *store.RevisionRepository
 	AlbumRepo                *store.AlbumRepository
 	ArtistRepo               *store.ArtistRepository
 	SubscriptionsServiceURL  string
@@ -42,13 +46,18 @@ type SongHandler struct {
 	AnalyticsServiceURL      string
 	SagaServiceURL           string // (2.13)
 	Logger                   *logger.Logger
-	HDFSClient               *storage.HDFSClient
+	Storage                  *storage.Stores
+	VerifySampleRate         float64 // share of complete audio reads verified against the stored hash
 	RedisCache               *cache.RedisCache // (2.12)
 }
 
-func NewSongHandler(repo *store.SongRepository, albumRepo *store.AlbumRepository, artistRepo *store.ArtistRepository, subscriptionsServiceURL, recommendationServiceURL, ratingsServiceURL, analyticsServiceURL, sagaServiceURL string, log *logger.Logger, hdfsClient *storage.HDFSClient, redisCache *cache.RedisCache) *SongHandler {
+func NewSongHandler(repo *store.SongRepository, blobRepo *store.AudioBlobRepository, lyricsRepo *store.LyricsRepository, likeRepo *store.LikeRepository, revisionRepo *store.RevisionRepository, albumRepo *store.AlbumRepository, artistRepo *store.ArtistRepository, subscriptionsServiceURL, recommendationServiceURL, ratingsServiceURL, analyticsServiceURL, sagaServiceURL string, log *logger.Logger, stores *storage.Stores, verifySampleRate float64, redisCache *cache.RedisCache) *SongHandler {
 	return &SongHandler{
 		Repo:                     repo,
+		Blobs:                    blobRepo,
+		Lyrics:                   lyricsRepo,
+		Likes:                    likeRepo,
+		Revisions:                revisionRepo,
 		AlbumRepo:                albumRepo,
 		ArtistRepo:               artistRepo,
 		SubscriptionsServiceURL:  subscriptionsServiceURL,
@@ -57,7 +66,8 @@ func NewSongHandler(repo *store.SongRepository, albumRepo *store.AlbumRepository
 		AnalyticsServiceURL:      analyticsServiceURL,
 		SagaServiceURL:           sagaServiceURL,
 		Logger:                   log,
-		HDFSClient:               hdfsClient,
+		Storage:                  stores,
+		VerifySampleRate:         verifySampleRate,
 		RedisCache:               redisCache,
 	}
 }
@@ -118,7 +128,7 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		Genre:        req.Genre,
 		AlbumID:      req.AlbumID,
 		ArtistIDs:    req.ArtistIDs,
-		AudioFileURL: req.AudioFileURL,
+		AudioFile:    audioFileFromURL(req.AudioFileURL),
 	}
 
 	if err := h.Repo.Create(r.Context(), song); err != nil {
@@ -126,6 +136,8 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordRevision(r.Context(), h.Revisions, model.LikeTypeSong, song.ID, nil, songRevisionState(song), getAdminIDFromSongContext(r.Context()), 0)
+
 	// Log admin activity
 	if h.Logger != nil {
 		adminID := getAdminIDFromSongContext(r.Context())
@@ -138,15 +150,15 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// Get artist names for the event
-	artistNames := make([]string, 0, len(song.ArtistIDs))
-	for _, artistID := range song.ArtistIDs {
-		artist, err := h.ArtistRepo.GetByID(r.Context(), artistID)
-		if err == nil && artist != nil {
-			artistNames = append(artistNames, artist.Name)
-		}
-	}
+	h.announceCreated(r.Context(), song)
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toSongResponse(song))
+}
+
+// announceCreated notifies subscribers and recommendation-service of a new song
+func (h *SongHandler) announceCreated(ctx context.Context, song *model.Song) {
 	// Emit event for new song (asynchronous)
 	event := events.NewSongEvent{
 		Type:        events.EventTypeNewSong,
@@ -154,24 +166,12 @@ func (h *SongHandler) CreateSong(w http.ResponseWriter, r *http.Request) {
 		Name:        song.Name,
 		Genre:       song.Genre,
 		ArtistIDs:   song.ArtistIDs,
-		ArtistNames: artistNames,
+		ArtistNames: artistNames(ctx, h.ArtistRepo, song.ArtistIDs),
 		AlbumID:     song.AlbumID,
 	}
-	events.EmitEvent(r.Context(), h.SubscriptionsServiceURL, event)
+	events.EmitEvent(ctx, h.SubscriptionsServiceURL, event)
 	// Also emit to recommendation-service
-	events.EmitEvent(r.Context(), h.RecommendationServiceURL, map[string]interface{}{
-		"type":      "song_created",
-		"songId":    song.ID,
-		"name":      song.Name,
-		"genre":     song.Genre,
-		"artistIds": song.ArtistIDs,
-		"albumId":   song.AlbumID,
-		"duration":  song.Duration,
-	})
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(toSongResponse(song))
+	events.EmitEvent(ctx, h.RecommendationServiceURL, songCreatedEvent(song))
 }
 
 func (h *SongHandler) GetSong(w http.ResponseWriter, r *http.Request) {
@@ -203,9 +203,15 @@ func (h *SongHandler) GetAllSongs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	songs, err := h.Repo.GetAll(r.Context())
+	q, err := parseListQuery(r)
 	if err != nil {
-		http.Error(w, "failed to get songs: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	songs, next, err := h.Repo.List(r.Context(), q)
+	if err != nil {
+		writeListError(w, err, "songs")
 		return
 	}
 
@@ -214,6 +220,7 @@ func (h *SongHandler) GetAllSongs(w http.ResponseWriter, r *http.Request) {
 		responses[i] = toSongResponse(song)
 	}
 
+	setNextCursor(w, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responses)
@@ -231,9 +238,16 @@ func (h *SongHandler) GetSongsByAlbum(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	songs, err := h.Repo.GetByAlbumID(r.Context(), albumID)
+	q, err := parseListQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.AlbumID = albumID
+
+	songs, next, err := h.Repo.List(r.Context(), q)
 	if err != nil {
-		http.Error(w, "failed to get songs: "+err.Error(), http.StatusInternalServerError)
+		writeListError(w, err, "songs")
 		return
 	}
 
@@ -242,6 +256,7 @@ func (h *SongHandler) GetSongsByAlbum(w http.ResponseWriter, r *http.Request) {
 		responses[i] = toSongResponse(song)
 	}
 
+	setNextCursor(w, next)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responses)
@@ -265,42 +280,64 @@ func (h *SongHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validation
-	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
-		return
-	}
-	if req.Duration <= 0 {
-		http.Error(w, "duration must be greater than 0", http.StatusBadRequest)
+	if err := h.validateUpdate(r.Context(), &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if req.Genre == "" {
-		http.Error(w, "genre is required", http.StatusBadRequest)
+
+	// Get existing song to preserve ID and timestamps
+	existingSong, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
 		return
 	}
-	if req.AlbumID == "" {
-		http.Error(w, "albumId is required", http.StatusBadRequest)
+
+	if err := h.applyUpdate(r.Context(), existingSong, &req, 0); err != nil {
+		http.Error(w, "failed to update song: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
-	if len(req.ArtistIDs) == 0 {
-		http.Error(w, "at least one artist ID is required", http.StatusBadRequest)
-		return
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toSongResponse(existingSong))
+}
+
+// validateUpdate checks an update of a song, also used for rollbacks to a revision
+func (h *SongHandler) validateUpdate(ctx context.Context, req *dto.UpdateSongRequest) error {
+	if err := validateSongFields(req); err != nil {
+		return err
 	}
 
 	// Check if album exists
-	_, err := h.AlbumRepo.GetByID(r.Context(), req.AlbumID)
-	if err != nil {
-		http.Error(w, "album not found", http.StatusBadRequest)
-		return
+	if _, err := h.AlbumRepo.GetByID(ctx, req.AlbumID); err != nil {
+		return errors.New("album not found")
 	}
+	return nil
+}
 
-	// Get existing song to preserve ID and timestamps
-	existingSong, err := h.Repo.GetByID(r.Context(), id)
-	if err != nil {
-		http.Error(w, "song not found", http.StatusNotFound)
-		return
+// validateSongFields checks the fields of a song update without looking up its album
+func validateSongFields(req *dto.UpdateSongRequest) error {
+	if req.Name == "" {
+		return errors.New("name is required")
+	}
+	if req.Duration <= 0 {
+		return errors.New("duration must be greater than 0")
+	}
+	if req.Genre == "" {
+		return errors.New("genre is required")
+	}
+	if req.AlbumID == "" {
+		return errors.New("albumId is required")
+	}
+	if len(req.ArtistIDs) == 0 {
+		return errors.New("at least one artist ID is required")
 	}
+	return nil
+}
 
+// applyUpdate saves the fields of a validated update to the song and records the revision;
+// restoredFrom is the revision a rollback restores, 0 for an update
+func (h *SongHandler) applyUpdate(ctx context.Context, existingSong *model.Song, req *dto.UpdateSongRequest, restoredFrom int) error {
 	// Store old state for logging
 	oldState := map[string]interface{}{
 		"name":        existingSong.Name,
@@ -308,8 +345,9 @@ func (h *SongHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 		"genre":       existingSong.Genre,
 		"albumID":     existingSong.AlbumID,
 		"artistIDs":   existingSong.ArtistIDs,
-		"audioFileURL": existingSong.AudioFileURL,
+		"audioFile":   existingSong.AudioFile,
 	}
+	oldRevisionState := songRevisionState(existingSong)
 
 	// Update fields
 	existingSong.Name = req.Name
@@ -317,7 +355,9 @@ func (h *SongHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 	existingSong.Genre = req.Genre
 	existingSong.AlbumID = req.AlbumID
 	existingSong.ArtistIDs = req.ArtistIDs
-	existingSong.AudioFileURL = req.AudioFileURL
+	if req.AudioFileURL != "" {
+		existingSong.AudioFile = audioFileFromURL(req.AudioFileURL)
+	}
 
 	newState := map[string]interface{}{
 		"name":        existingSong.Name,
@@ -325,28 +365,51 @@ func (h *SongHandler) UpdateSong(w http.ResponseWriter, r *http.Request) {
 		"genre":       existingSong.Genre,
 		"albumID":     existingSong.AlbumID,
 		"artistIDs":   existingSong.ArtistIDs,
-		"audioFileURL": existingSong.AudioFileURL,
+		"audioFile":   existingSong.AudioFile,
 	}
 
-	if err := h.Repo.Update(r.Context(), id, existingSong); err != nil {
-		http.Error(w, "failed to update song: "+err.Error(), http.StatusInternalServerError)
-		return
+	if err := h.Repo.Update(ctx, existingSong.ID, existingSong); err != nil {
+		return err
 	}
 
+	adminID := getAdminIDFromSongContext(ctx)
+	recordRevision(ctx, h.Revisions, model.LikeTypeSong, existingSong.ID, oldRevisionState, songRevisionState(existingSong), adminID, restoredFrom)
+
 	// Log admin activity and state change
 	if h.Logger != nil {
-		adminID := getAdminIDFromSongContext(r.Context())
-		h.Logger.LogAdminActivity(adminID, "UPDATE_SONG", "songs", map[string]interface{}{
-			"songId": id,
-		})
+		if restoredFrom > 0 {
+			h.Logger.LogAdminActivity(adminID, "ROLLBACK_SONG", "songs", map[string]interface{}{
+				"songId":   existingSong.ID,
+				"revision": restoredFrom,
+			})
+		} else {
+			h.Logger.LogAdminActivity(adminID, "UPDATE_SONG", "songs", map[string]interface{}{
+				"songId": existingSong.ID,
+			})
+		}
 		h.Logger.LogStateChange("song", oldState, newState, adminID)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(toSongResponse(existingSong))
+	// Recommendations link the song to its genre and artists again
+	event := songCreatedEvent(existingSong)
+	event["type"] = "song_updated"
+	events.EmitEvent(ctx, h.RecommendationServiceURL, event)
+	return nil
 }
 
+// songRevisionState is the editable state of a song kept in its revisions, keyed like UpdateSongRequest
+func songRevisionState(song *model.Song) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      song.Name,
+		"duration":  song.Duration,
+		"genre":     song.Genre,
+		"albumId":   song.AlbumID,
+		"artistIds": song.ArtistIDs,
+	}
+}
+
+// DeleteSong moves the song to the trash. Its ratings, playlist entries, lyrics and audio are kept
+// until the trash is purged.
 func (h *SongHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -362,121 +425,120 @@ func (h *SongHandler) DeleteSong(w http.ResponseWriter, r *http.Request) {
 	// Get song before deletion for logging
 	song, _ := h.Repo.GetByID(r.Context(), id)
 
-	// Use Saga pattern for distributed transaction (2.13)
-	if h.SagaServiceURL != "" {
-		// Call saga-service to orchestrate the deletion
-		client := &http.Client{Timeout: 30 * time.Second}
-		sagaURL := fmt.Sprintf("%s/sagas/delete-song", h.SagaServiceURL)
-		
-		reqBody := map[string]interface{}{
-			"songId": id,
-		}
-		reqJSON, err := json.Marshal(reqBody)
-		if err != nil {
-			log.Printf("Error marshaling saga request: %v", err)
-			http.Error(w, "failed to create saga request", http.StatusInternalServerError)
+	adminID := getAdminIDFromSongContext(r.Context())
+	if err := h.Repo.SoftDelete(r.Context(), id, adminID); err != nil {
+		if err.Error() == "song not found" {
+			http.Error(w, "song not found", http.StatusNotFound)
 			return
 		}
+		http.Error(w, "failed to delete song: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-		req, err := http.NewRequestWithContext(r.Context(), "POST", sagaURL, bytes.NewBuffer(reqJSON))
-		if err != nil {
-			log.Printf("Error creating saga request: %v", err)
-			http.Error(w, "failed to create saga request", http.StatusInternalServerError)
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
+	// Emit deletion event to recommendation-service (asynchronous)
+	events.EmitEvent(context.Background(), h.RecommendationServiceURL, events.DeletedSongEvent{
+		Type:   events.EventTypeDeletedSong,
+		SongID: id,
+	})
 
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Error calling saga-service: %v", err)
-			http.Error(w, "failed to execute saga transaction", http.StatusInternalServerError)
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			var sagaResp map[string]interface{}
-			if err := json.NewDecoder(resp.Body).Decode(&sagaResp); err == nil {
-				if errorMsg, ok := sagaResp["error"].(string); ok {
-					log.Printf("Saga transaction failed: %s", errorMsg)
-					http.Error(w, fmt.Sprintf("Saga transaction failed: %s", errorMsg), http.StatusInternalServerError)
-					return
-				}
-			}
-			http.Error(w, "saga transaction failed", http.StatusInternalServerError)
-			return
-		}
+	// Log admin activity
+	if h.Logger != nil && song != nil {
+		h.Logger.LogAdminActivity(adminID, "DELETE_SONG", "songs", map[string]interface{}{
+			"songId": id,
+			"name":   song.Name,
+		})
+	}
 
-		// Log admin activity
-		if h.Logger != nil {
-			adminID := getAdminIDFromSongContext(r.Context())
-			h.Logger.LogAdminActivity(adminID, "DELETE_SONG", "songs", map[string]interface{}{
-				"songId": id,
-				"name":   song.Name,
-				"method": "saga",
-			})
-		}
+	w.WriteHeader(http.StatusNoContent)
+}
 
-		w.WriteHeader(http.StatusNoContent)
-		return
+// PurgeSong permanently deletes a song in the trash. The saga (2.13) deletes its ratings, removes it
+// from playlists and deletes the document through /songs/internal/delete, which releases the audio.
+func (h *SongHandler) PurgeSong(ctx context.Context, song *model.Song) error {
+	if h.SagaServiceURL != "" {
+		return h.runDeletionSaga(ctx, song.ID)
 	}
 
-	// Fallback to old implementation if saga-service is not available
-	log.Printf("Saga service not configured, using direct deletion")
-	
+	// Fallback to direct deletion if saga-service is not available
+	log.Printf("Saga service not configured, purging song %s directly", song.ID)
+
 	// Delete all ratings for this song from ratings-service
 	if h.RatingsServiceURL != "" {
 		go func() {
 			client := &http.Client{Timeout: 5 * time.Second}
-			deleteURL := fmt.Sprintf("%s/delete-ratings-by-song?songId=%s", h.RatingsServiceURL, id)
+			deleteURL := fmt.Sprintf("%s/delete-ratings-by-song?songId=%s", h.RatingsServiceURL, song.ID)
 			req, err := http.NewRequest("DELETE", deleteURL, nil)
 			if err == nil {
 				resp, err := client.Do(req)
 				if err != nil {
-					log.Printf("Error calling ratings-service to delete ratings for song %s: %v", id, err)
+					log.Printf("Error calling ratings-service to delete ratings for song %s: %v", song.ID, err)
 				} else {
 					resp.Body.Close()
 					if resp.StatusCode == http.StatusOK {
-						log.Printf("Successfully deleted all ratings for song %s", id)
+						log.Printf("Successfully deleted all ratings for song %s", song.ID)
 					} else {
-						log.Printf("Failed to delete ratings for song %s: status %d", id, resp.StatusCode)
+						log.Printf("Failed to delete ratings for song %s: status %d", song.ID, resp.StatusCode)
 					}
 				}
 			}
 		}()
 	}
 
-	if err := h.Repo.Delete(r.Context(), id); err != nil {
-		if err.Error() == "song not found" {
-			http.Error(w, "song not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "failed to delete song: "+err.Error(), http.StatusInternalServerError)
-		return
+	if err := h.Repo.Delete(ctx, song.ID); err != nil {
+		return err
 	}
+	h.ReleaseSongAudio(song)
+	h.DeleteSongLyrics(song.ID)
+	h.DeleteLikes(model.LikeTypeSong, song.ID)
+	return nil
+}
 
-	// Emit deletion event to recommendation-service (asynchronous)
-	if song != nil {
-		events.EmitEvent(context.Background(), h.RecommendationServiceURL, events.DeletedSongEvent{
-			Type:   events.EventTypeDeletedSong,
-			SongID: id,
-		})
+// DeleteLikes removes permanently deleted songs, albums or artists from the libraries that saved them
+func (h *SongHandler) DeleteLikes(itemType string, itemIDs ...string) {
+	if err := h.Likes.DeleteItems(context.Background(), itemType, itemIDs); err != nil {
+		log.Printf("Failed to delete the likes of %s %v: %v", itemType, itemIDs, err)
 	}
+}
 
-	// Log admin activity
-	if h.Logger != nil {
-		adminID := getAdminIDFromSongContext(r.Context())
-		h.Logger.LogAdminActivity(adminID, "DELETE_SONG", "songs", map[string]interface{}{
-			"songId": id,
-			"name":   song.Name,
-		})
+// runDeletionSaga calls saga-service to orchestrate the deletion of a song
+func (h *SongHandler) runDeletionSaga(ctx context.Context, songID string) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	sagaURL := fmt.Sprintf("%s/sagas/delete-song", h.SagaServiceURL)
+
+	reqJSON, err := json.Marshal(map[string]interface{}{
+		"songId": songID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create saga request: %w", err)
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	req, err := http.NewRequestWithContext(ctx, "POST", sagaURL, bytes.NewBuffer(reqJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create saga request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute saga transaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var sagaResp map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&sagaResp); err == nil {
+			if errorMsg, ok := sagaResp["error"].(string); ok {
+				return fmt.Errorf("saga transaction failed: %s", errorMsg)
+			}
+		}
+		return fmt.Errorf("saga transaction failed: status %d", resp.StatusCode)
+	}
+	return nil
 }
 
+// StreamSong streams the song audio. HDFS files support Range requests (seeking) and HEAD.
 func (h *SongHandler) StreamSong(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -490,60 +552,136 @@ func (h *SongHandler) StreamSong(w http.ResponseWriter, r *http.Request) {
 	// Check if song exists
 	song, err := h.Repo.GetByID(r.Context(), id)
 	if err != nil {
-		// If song not found, try to serve from HDFS directly by ID (fallback)
-		hdfsPath := fmt.Sprintf("/audio/songs/%s.mp3", id)
-		exists, err := h.HDFSClient.FileExists(hdfsPath)
-		if err == nil && exists {
-			// File exists on HDFS, serve it directly
+		// If song not found, try to serve the default upload location directly by ID (fallback)
+		key := fmt.Sprintf("audio/songs/%s.mp3", id)
+		store := h.Storage.Default()
+		info, err := store.Stat(r.Context(), key)
+		if err == nil {
 			if h.Logger != nil {
-				h.Logger.Log(logger.LevelInfo, logger.EventStateChange, "Serving audio from HDFS (song not in DB)", map[string]interface{}{
-					"songId":   id,
-					"hdfsPath": hdfsPath,
-				})
-			}
-			audioData, err := h.HDFSClient.DownloadFile(hdfsPath)
-			if err == nil {
-				w.Header().Set("Content-Type", "audio/mpeg")
-				w.Header().Set("Content-Length", fmt.Sprintf("%d", len(audioData)))
-				w.Header().Set("Accept-Ranges", "bytes")
-				w.Header().Set("Cache-Control", "no-cache")
-				w.WriteHeader(http.StatusOK)
-				w.Write(audioData)
-				return
-			}
-			if h.Logger != nil {
-				h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to download audio from HDFS (fallback)", map[string]interface{}{
-					"error":    err.Error(),
-					"songId":   id,
-					"hdfsPath": hdfsPath,
-				})
-			}
-		} else {
-			if h.Logger != nil {
-				h.Logger.Log(logger.LevelError, logger.EventStateChange, "Song not found and HDFS file missing", map[string]interface{}{
-					"songId":   id,
-					"hdfsPath": hdfsPath,
-					"exists":   exists,
-					"error":    err,
+				h.Logger.Log(logger.LevelInfo, logger.EventStateChange, "Serving audio from storage (song not in DB)", map[string]interface{}{
+					"songId":  id,
+					"backend": h.Storage.DefaultBackend(),
+					"key":     key,
 				})
 			}
+			w.Header().Set("Cache-Control", "no-cache")
+			h.streamStoredAudio(w, r, store, key, info, "")
+			return
+		}
+		if h.Logger != nil {
+			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Song not found and audio file missing", map[string]interface{}{
+				"songId": id,
+				"key":    key,
+				"error":  err.Error(),
+			})
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{
-			"error": "Song not found and audio file not available on HDFS",
+			"error": "Song not found and audio file not available",
 			"songId": id,
 		})
 		return
 	}
 
+	if song.AudioFile == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "No audio file available for this song",
+			"song":  song.Name,
+		})
+		return
+	}
+
+	// A play is counted once per playback: not for HEAD or for the range requests a player sends while seeking
+	startsPlayback := r.Method == http.MethodGet && isPlaybackStart(r.Header.Get("Range"))
+	if startsPlayback {
+		h.recordPlay(r, song)
+	}
+
+	// Audio hosted elsewhere
+	if song.AudioFile.Backend == storage.BackendExternal {
+		http.Redirect(w, r, song.AudioFile.Key, http.StatusTemporaryRedirect)
+		return
+	}
+
+	store, err := h.Storage.Get(song.AudioFile.Backend)
+	if err != nil {
+		log.Printf("Cannot stream song %s: %v", id, err)
+		http.Error(w, "audio storage not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// The object info gives the size and version (ETag) and tells if the file exists
+	info, err := store.Stat(r.Context(), song.AudioFile.Key)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Failed to stat %s:%s: %v", song.AudioFile.Backend, song.AudioFile.Key, err)
+		http.Error(w, "failed to retrieve audio file", http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Audio file not found in storage", map[string]interface{}{
+				"error":   err.Error(),
+				"songId":  id,
+				"backend": song.AudioFile.Backend,
+				"key":     song.AudioFile.Key,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": "Audio file not found in storage",
+			"song":  song.Name,
+		})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	h.streamStoredAudio(w, r, store, song.AudioFile.Key, info, song.AudioFile.SHA256)
+}
+
+// audioFileFromURL turns the audioFileUrl of create/update requests (an external URL or a path
+// in one of the storage backends) into a storage reference
+func audioFileFromURL(url string) *model.StorageRef {
+	backend, key := storage.ParseLegacyURL(url)
+	if backend == "" {
+		return nil
+	}
+	return &model.StorageRef{Backend: backend, Key: key}
+}
+
+// deleteReplacedAudio releases the previous audio file after an upload replaced it
+func (h *SongHandler) deleteReplacedAudio(songID string, previous, current *model.StorageRef) {
+	if previous == nil || sameAudio(previous, current) {
+		return
+	}
+	h.releaseAudio(context.Background(), songID, previous)
+}
+
+// sameAudio reports whether two references are the same audio. Content-addressed references compare by
+// SHA-256, since a blob moved to another backend or key keeps its hash; older references by location.
+func sameAudio(a, b *model.StorageRef) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.SHA256 != "" && b.SHA256 != "" {
+		return a.SHA256 == b.SHA256
+	}
+	return *a == *b
+}
+
+// recordPlay logs the song_played activity of the signed in user and counts the play (1.15, 2.12).
+// Called once per playback, by the progressive stream and by the HLS master playlist.
+func (h *SongHandler) recordPlay(r *http.Request, song *model.Song) {
 	// Log activity if user is authenticated (1.15)
 	// Try to get userID from JWT token (optional auth)
 	claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.UserClaims)
 	if ok && claims != nil && claims.UserID != "" {
 		// Deduplication: Check if we already logged this activity recently (within 5 minutes)
 		// This prevents multiple log entries for the same song play (e.g., from preload, range requests, etc.)
-		activityKey := fmt.Sprintf("activity:%s:%s", claims.UserID, id)
+		activityKey := fmt.Sprintf("activity:%s:%s", claims.UserID, song.ID)
 		shouldLog := true
 		
 		if h.RedisCache != nil {
@@ -552,7 +690,7 @@ func (h *SongHandler) StreamSong(w http.ResponseWriter, r *http.Request) {
 			if err == nil && exists > 0 {
 				// Activity already logged recently, skip
 				shouldLog = false
-				log.Printf("Skipping duplicate activity log for user %s, song %s (deduplication)", claims.UserID, id)
+				log.Printf("Skipping duplicate activity log for user %s, song %s (deduplication)", claims.UserID, song.ID)
 			}
 		}
 		
@@ -574,7 +712,7 @@ func (h *SongHandler) StreamSong(w http.ResponseWriter, r *http.Request) {
 			activity := analytics.Activity{
 				UserID:   claims.UserID,
 				Type:     analytics.ActivityTypeSongPlayed,
-				SongID:   id,
+				SongID:   song.ID,
 				SongName: song.Name,
 				Genre:    song.Genre,
 			}
@@ -600,124 +738,11 @@ func (h *SongHandler) StreamSong(w http.ResponseWriter, r *http.Request) {
 	// Increment play count in Redis cache (2.12)
 	if h.RedisCache != nil {
 		ctx := r.Context()
-		if err := h.RedisCache.IncrementPlayCount(ctx, id); err != nil {
-			log.Printf("Failed to increment play count for song %s: %v", id, err)
+		if err := h.RedisCache.IncrementPlayCount(ctx, song.ID); err != nil {
+			log.Printf("Failed to increment play count for song %s: %v", song.ID, err)
 			// Don't fail the request if cache update fails
 		}
 	}
-
-	// Set headers for audio streaming
-	w.Header().Set("Accept-Ranges", "bytes")
-	w.Header().Set("Cache-Control", "no-cache")
-
-	if song.AudioFileURL != "" {
-		// If song has an external URL, redirect to it
-		if strings.HasPrefix(song.AudioFileURL, "http://") || strings.HasPrefix(song.AudioFileURL, "https://") {
-			http.Redirect(w, r, song.AudioFileURL, http.StatusTemporaryRedirect)
-			return
-		}
-
-		// Check if it's an HDFS path (starts with /audio/ or hdfs://)
-		if strings.HasPrefix(song.AudioFileURL, "/audio/") || strings.HasPrefix(song.AudioFileURL, "hdfs://") {
-			// Download from HDFS (2.11)
-			hdfsPath := song.AudioFileURL
-			if strings.HasPrefix(hdfsPath, "hdfs://") {
-				// Extract path from hdfs://namenode:port/path
-				parts := strings.SplitN(hdfsPath, "/", 4)
-				if len(parts) >= 4 {
-					hdfsPath = "/" + parts[3]
-				}
-			}
-
-			// Check if file exists in HDFS before trying to download
-			exists, err := h.HDFSClient.FileExists(hdfsPath)
-			if err != nil || !exists {
-				if h.Logger != nil {
-					h.Logger.Log(logger.LevelError, logger.EventStateChange, "Audio file not found in HDFS", map[string]interface{}{
-						"error":    err,
-						"songId":   id,
-						"hdfsPath": hdfsPath,
-						"exists":   exists,
-					})
-				}
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusNotFound)
-				json.NewEncoder(w).Encode(map[string]string{
-					"error": "Audio file not found in HDFS",
-					"song":  song.Name,
-					"path":  hdfsPath,
-				})
-				return
-			}
-
-			audioData, err := h.HDFSClient.DownloadFile(hdfsPath)
-			if err != nil {
-				if h.Logger != nil {
-					h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to download audio from HDFS", map[string]interface{}{
-						"error":    err.Error(),
-						"songId":   id,
-						"hdfsPath": hdfsPath,
-					})
-				}
-				http.Error(w, "failed to retrieve audio file: "+err.Error(), http.StatusInternalServerError)
-				return
-			}
-
-			// Set content type based on file extension
-			ext := strings.ToLower(filepath.Ext(hdfsPath))
-			contentType := "audio/mpeg" // default
-			switch ext {
-			case ".mp3":
-				contentType = "audio/mpeg"
-			case ".wav":
-				contentType = "audio/wav"
-			case ".ogg":
-				contentType = "audio/ogg"
-			case ".m4a":
-				contentType = "audio/mp4"
-			case ".flac":
-				contentType = "audio/flac"
-			}
-			w.Header().Set("Content-Type", contentType)
-			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(audioData)))
-
-			// Stream the audio data
-			w.WriteHeader(http.StatusOK)
-			w.Write(audioData)
-			return
-		}
-
-		// Check if it's a path to frontend/public/music files
-		if strings.HasPrefix(song.AudioFileURL, "/music/") || strings.HasPrefix(song.AudioFileURL, "music/") {
-			// Try to serve from /app/music directory (mounted from frontend/public/music)
-			fileName := strings.TrimPrefix(song.AudioFileURL, "/music/")
-			fileName = strings.TrimPrefix(fileName, "music/")
-			musicPath := filepath.Join("/app/music", fileName)
-			
-			// Check if file exists
-			if _, err := os.Stat(musicPath); err == nil {
-				http.ServeFile(w, r, musicPath)
-				return
-			}
-			
-			// Fallback: redirect to frontend public folder
-			frontendURL := fmt.Sprintf("http://localhost:3000/music/%s", fileName)
-			http.Redirect(w, r, frontendURL, http.StatusTemporaryRedirect)
-			return
-		}
-
-		// Fallback to local file (for backward compatibility)
-		http.ServeFile(w, r, song.AudioFileURL)
-		return
-	}
-
-	// If no audio file is specified, return a placeholder or error
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusNotFound)
-	json.NewEncoder(w).Encode(map[string]string{
-		"error": "No audio file available for this song",
-		"song":  song.Name,
-	})
 }
 
 // UploadAudio uploads an audio file to HDFS (2.11)
@@ -780,99 +805,190 @@ func (h *SongHandler) UploadAudio(w http.ResponseWriter, r *http.Request) {
 	// Validate file extension
 	ext := strings.ToLower(filepath.Ext(header.Filename))
 	log.Printf("File extension: %s", ext)
-	allowedExts := map[string]bool{
-		".mp3":  true,
-		".wav":  true,
-		".ogg":  true,
-		".m4a":  true,
-		".flac": true,
-	}
-	if !allowedExts[ext] {
+	if !audioExtensions[ext] {
 		log.Printf("File type not allowed: %s", ext)
 		http.Error(w, "file type not allowed. Allowed: mp3, wav, ogg, m4a, flac", http.StatusBadRequest)
 		return
 	}
 
-	// Read file data
-	log.Printf("Reading file data...")
-	fileData, err := io.ReadAll(file)
+	// The file is streamed to storage, it is not read into memory here
+	audioFile, err := h.saveUploadedAudio(r.Context(), song, ext, file)
 	if err != nil {
-		log.Printf("Error reading file: %v", err)
-		http.Error(w, "failed to read file: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("File data read: %d bytes", len(fileData))
-
-	// Upload to HDFS (2.11)
-	hdfsPath := fmt.Sprintf("/audio/songs/%s%s", songID, ext)
-	log.Printf("Uploading to HDFS: %s", hdfsPath)
-	
-	// Delay to ensure HDFS is ready (especially for newly created songs)
-	// Check if this is a newly created song (no existing audioFileUrl or not HDFS path)
-	isNewSong := song.AudioFileURL == "" || (!strings.HasPrefix(song.AudioFileURL, "/audio/") && !strings.HasPrefix(song.AudioFileURL, "hdfs://"))
-	if isNewSong {
-		log.Printf("New song or non-HDFS path detected, waiting longer for HDFS to be ready...")
-		time.Sleep(2 * time.Second) // Wait 2 seconds for new songs
-	} else {
-		time.Sleep(1 * time.Second) // Wait 1 second for existing HDFS songs (to avoid connection issues)
+		http.Error(w, "failed to upload file to storage: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
-	
-	err = h.HDFSClient.UploadData(fileData, hdfsPath)
+
+	// Log admin activity
+	if h.Logger != nil {
+		adminID := getAdminIDFromSongContext(r.Context())
+		h.Logger.LogAdminActivity(adminID, "UPLOAD_AUDIO", "songs", map[string]interface{}{
+			"songId":    songID,
+			"audioFile": audioFile,
+			"fileName":  header.Filename,
+			"fileSize":  header.Size,
+		})
+	}
+
+	// Return success response
+	log.Printf("Returning success response")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":   "Audio file uploaded successfully",
+		"songId":    songID,
+		"audioFile": audioFile,
+		"fileName":  header.Filename,
+		"fileSize":  header.Size,
+		"duration":  song.Duration,
+		"audio":     song.Audio,
+	})
+	log.Printf("Upload completed successfully")
+}
+
+// audioExtensions are the audio formats that can be uploaded
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".wav":  true,
+	".ogg":  true,
+	".m4a":  true,
+	".flac": true,
+}
+
+// maxAnalyzedAudioSize bounds the files whose metadata is read and whose HLS output is built, both need the
+// whole file in memory. Larger files are stored and streamed progressively without them.
+const maxAnalyzedAudioSize = 100 << 20
+
+// saveUploadedAudio stores an uploaded audio file in the default storage backend, reads its metadata
+// and points the song at it; used by the multipart upload and by finished resumable uploads.
+// Fails only when the file cannot be stored.
+func (h *SongHandler) saveUploadedAudio(ctx context.Context, song *model.Song, ext string, src io.ReadSeeker) (*model.StorageRef, error) {
+	// Upload to the default storage backend (2.11), stored once by content hash
+	backend := h.Storage.DefaultBackend()
+	log.Printf("Uploading to %s", backend)
+
+	if backend == storage.BackendHDFS {
+		// Delay to ensure HDFS is ready (especially for newly created songs)
+		if song.AudioFile == nil || song.AudioFile.Backend != storage.BackendHDFS {
+			log.Printf("New song or non-HDFS file detected, waiting longer for HDFS to be ready...")
+			time.Sleep(2 * time.Second) // Wait 2 seconds for new songs
+		} else {
+			time.Sleep(1 * time.Second) // Wait 1 second for existing HDFS songs (to avoid connection issues)
+		}
+	}
+
+	// The song references the blob before it points at it, so the blob is never deleted under it
+	audioFile, err := h.storeAudioBlob(ctx, song.ID, src, ext)
 	if err != nil {
-		log.Printf("HDFS upload failed: %v", err)
+		log.Printf("Upload to %s failed: %v", backend, err)
 		if h.Logger != nil {
-			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to upload audio to HDFS", map[string]interface{}{
-				"error":    err.Error(),
-				"songId":   songID,
-				"hdfsPath": hdfsPath,
+			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to upload audio to storage", map[string]interface{}{
+				"error":   err.Error(),
+				"songId":  song.ID,
+				"backend": backend,
 			})
 		}
-		http.Error(w, "failed to upload file to HDFS: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
+	log.Printf("Upload to %s successful: %s", audioFile.Backend, audioFile.Key)
 
-	log.Printf("HDFS upload successful")
+	// Read format, duration and tags from the file; the measured duration replaces the entered one
+	data := h.readForAnalysis(song.ID, src)
+	var audio *model.AudioMetadata
+	if data != nil {
+		audio = h.extractAudioMetadata(ctx, song, data)
+	}
+	if audio != nil && audio.DurationSeconds > 0 {
+		song.Duration = int(audio.DurationSeconds + 0.5)
+	}
 
-	// Update song with HDFS path
-	song.AudioFileURL = hdfsPath
-	log.Printf("Updating song with HDFS path...")
-	err = h.Repo.Update(r.Context(), songID, song)
+	// Update song with the storage reference
+	previous := song.AudioFile
+	song.AudioFile = audioFile
+	song.Audio = audio
+	log.Printf("Updating song with storage reference...")
+	err = h.Repo.UpdateAudio(ctx, song.ID, audioFile, song.Duration, audio)
 	if err != nil {
 		log.Printf("Error updating song: %v", err)
 		if h.Logger != nil {
-			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to update song with HDFS path", map[string]interface{}{
+			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to update song with storage reference", map[string]interface{}{
 				"error":  err.Error(),
-				"songId": songID,
+				"songId": song.ID,
 			})
 		}
-		// Don't fail the request, file is already uploaded
+		// Don't fail the request, file is already uploaded; the song keeps pointing at its previous file
+		h.deleteReplacedAudio(song.ID, audioFile, previous)
 	} else {
 		log.Printf("Song updated successfully")
+		h.deleteReplacedAudio(song.ID, previous, audioFile)
+		// Segment MP3/AAC uploads for HLS playback
+		if data != nil {
+			h.generateHLSInBackground(song, data)
+		}
 	}
 
-	// Log admin activity
-	if h.Logger != nil {
-		adminID := getAdminIDFromSongContext(r.Context())
-		h.Logger.LogAdminActivity(adminID, "UPLOAD_AUDIO", "songs", map[string]interface{}{
-			"songId":   songID,
-			"hdfsPath": hdfsPath,
-			"fileName": header.Filename,
-			"fileSize": len(fileData),
-		})
+	return audioFile, nil
+}
+
+// readForAnalysis reads an uploaded file into memory for metadata and HLS, or returns nil when it is larger
+// than maxAnalyzedAudioSize or cannot be read
+func (h *SongHandler) readForAnalysis(songID string, src io.ReadSeeker) []byte {
+	size, err := src.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = src.Seek(0, io.SeekStart)
 	}
+	if err != nil {
+		log.Printf("Failed to read audio of song %s for metadata: %v", songID, err)
+		return nil
+	}
+	if size > maxAnalyzedAudioSize {
+		log.Printf("Audio of song %s is %d MB, metadata and HLS output are skipped", songID, size>>20)
+		return nil
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		log.Printf("Failed to read audio of song %s for metadata: %v", songID, err)
+		return nil
+	}
+	return data
+}
 
-	// Return success response
-	log.Printf("Returning success response")
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message":  "Audio file uploaded successfully",
-		"songId":   songID,
-		"hdfsPath": hdfsPath,
-		"fileName": header.Filename,
-		"fileSize": len(fileData),
-	})
-	log.Printf("Upload completed successfully")
+// UpdateRating stores the average rating and count of a song as computed by ratings-service
+// PUT /songs/internal/rating?songId={id}
+func (h *SongHandler) UpdateRating(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	songID := r.URL.Query().Get("songId")
+	if songID == "" {
+		http.Error(w, "songId parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		AverageRating float64 `json:"averageRating"`
+		RatingCount   int     `json:"ratingCount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if req.AverageRating < 0 || req.AverageRating > 5 || req.RatingCount < 0 {
+		http.Error(w, "invalid rating", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Repo.UpdateRating(r.Context(), songID, req.AverageRating, req.RatingCount); err != nil {
+		if err.Error() == "song not found" {
+			http.Error(w, "song not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to update rating: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func toSongResponse(song *model.Song) *dto.SongResponse {
@@ -883,8 +999,16 @@ func toSongResponse(song *model.Song) *dto.SongResponse {
 		Genre:        song.Genre,
 		AlbumID:      song.AlbumID,
 		ArtistIDs:    song.ArtistIDs,
-		AudioFileURL: song.AudioFileURL,
+		AudioFile:    song.AudioFile,
 		CreatedAt:    song.CreatedAt,
 		UpdatedAt:    song.UpdatedAt,
+
+		AverageRating: song.AverageRating,
+		RatingCount:   song.RatingCount,
+		LikeCount:     song.LikeCount,
+		Audio:         song.Audio,
+		HLSAvailable:  song.HLS != nil,
+
+		LyricsLanguages: song.LyricsLanguages,
 	}
 }
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"content-service/internal/dto"
+	"content-service/internal/model"
+	"content-service/internal/storage"
+)
+
+// MigrateStorage moves the uploaded audio file of a song, its HLS output and cover to another
+// storage backend. The files are copied and the references switched before the old files are deleted.
+// POST /songs/{id}/storage
+func (h *SongHandler) MigrateStorage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req dto.MigrateStorageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	target, err := h.Storage.Get(req.Backend)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := extractSongID(r.URL.Path)
+	song, err := h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	var audioFile, hls, cover *model.StorageRef
+	var moved []*model.StorageRef // old references, deleted once the song points to the copies
+	if ref := song.AudioFile; ref != nil && ref.Backend != storage.BackendExternal && ref.Backend != req.Backend {
+		if err := h.copyStored(r.Context(), ref, target, false); err != nil {
+			log.Printf("Storage migration of song %s failed: %v", id, err)
+			http.Error(w, "failed to copy audio file", http.StatusBadGateway)
+			return
+		}
+		audioFile = &model.StorageRef{Backend: req.Backend, Key: ref.Key, SHA256: ref.SHA256}
+		moved = append(moved, ref)
+	}
+	if ref := song.HLS; ref != nil && ref.Backend != req.Backend {
+		if err := h.copyStored(r.Context(), ref, target, true); err != nil {
+			log.Printf("Storage migration of song %s failed: %v", id, err)
+			http.Error(w, "failed to copy HLS output", http.StatusBadGateway)
+			return
+		}
+		hls = &model.StorageRef{Backend: req.Backend, Key: ref.Key}
+		moved = append(moved, ref)
+	}
+	if song.Audio != nil && song.Audio.Cover != nil && song.Audio.Cover.Backend != req.Backend {
+		ref := song.Audio.Cover
+		if err := h.copyStored(r.Context(), ref, target, false); err != nil {
+			log.Printf("Storage migration of song %s failed: %v", id, err)
+			http.Error(w, "failed to copy cover", http.StatusBadGateway)
+			return
+		}
+		cover = &model.StorageRef{Backend: req.Backend, Key: ref.Key}
+		moved = append(moved, ref)
+	}
+
+	if len(moved) > 0 {
+		// A content-addressed file is shared, every song using it moves with it
+		if audioFile != nil && audioFile.SHA256 != "" {
+			if err := h.Blobs.SetLocation(r.Context(), audioFile.SHA256, audioFile.Backend, audioFile.Key); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := h.Repo.MoveAudioBlob(r.Context(), audioFile.SHA256, audioFile); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			audioFile = nil
+		}
+		if err := h.Repo.SetStorageRefs(r.Context(), id, audioFile, hls, cover); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The HLS reference is a prefix, the others single objects
+		for _, ref := range moved {
+			store, err := h.Storage.Get(ref.Backend)
+			if err != nil {
+				continue
+			}
+			if ref == song.HLS {
+				err = storage.DeletePrefix(r.Context(), store, ref.Key+"/")
+			} else {
+				err = store.Delete(r.Context(), ref.Key)
+			}
+			if err != nil {
+				log.Printf("Failed to delete migrated file %s:%s: %v", ref.Backend, ref.Key, err)
+			}
+		}
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminIDFromSongContext(r.Context()), "MIGRATE_STORAGE", "songs", map[string]interface{}{
+			"songId":  id,
+			"backend": req.Backend,
+			"files":   len(moved),
+		})
+	}
+
+	song, err = h.Repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toSongResponse(song))
+}
+
+// copyStored copies a referenced file to the same key in another store; for a prefix reference
+// (HLS output) every object under it is copied
+func (h *SongHandler) copyStored(ctx context.Context, ref *model.StorageRef, target storage.BlobStore, prefix bool) error {
+	source, err := h.Storage.Get(ref.Backend)
+	if err != nil {
+		return err
+	}
+	if !prefix {
+		return storage.Copy(ctx, source, target, ref.Key)
+	}
+	objects, err := source.List(ctx, ref.Key+"/")
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err := storage.Copy(ctx, source, target, object.Key); err != nil {
+			return fmt.Errorf("copy %s: %w", object.Key, err)
+		}
+	}
+	return nil
+}
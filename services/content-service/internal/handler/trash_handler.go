@@ -0,0 +1,519 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"content-service/internal/dto"
+	"content-service/internal/events"
+	"content-service/internal/logger"
+	"content-service/internal/model"
+	"content-service/internal/store"
+)
+
+// Catalog collections with a trash, as they appear in /trash/{collection}
+const (
+	TrashSongs   = "songs"
+	TrashAlbums  = "albums"
+	TrashArtists = "artists"
+)
+
+// trashPurgeInterval is how often the retention job looks for expired trash
+const trashPurgeInterval = time.Hour
+
+// ExtractTrashPath splits /trash/{collection}/{id}/{action}; missing parts are empty
+func ExtractTrashPath(path string) (collection, id, action string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "trash" {
+		return "", "", ""
+	}
+	collection = parts[1]
+	if len(parts) > 2 {
+		id = parts[2]
+	}
+	if len(parts) > 3 {
+		action = parts[3]
+	}
+	return collection, id, action
+}
+
+// TrashHandler lists, restores and purges deleted songs, albums and artists. Deleting keeps the
+// document with deletedAt set; the retention job purges it after Retention. Purging an album or
+// artist cascades to its songs through saga-service; SharedSongs is the default policy for songs and
+// albums an artist shares with other artists.
+type TrashHandler struct {
+	Songs       *SongHandler
+	Albums      *AlbumHandler
+	Artists     *ArtistHandler
+	Retention   time.Duration
+	SharedSongs string
+	Logger      *logger.Logger
+}
+
+func NewTrashHandler(songs *SongHandler, albums *AlbumHandler, artists *ArtistHandler, retention time.Duration, sharedSongs string, log *logger.Logger) *TrashHandler {
+	return &TrashHandler{
+		Songs:       songs,
+		Albums:      albums,
+		Artists:     artists,
+		Retention:   retention,
+		SharedSongs: sharedSongs,
+		Logger:      log,
+	}
+}
+
+// Trash handles the trash of a collection
+// GET /trash/{collection} - deleted items, most recently deleted first
+// POST /trash/{collection}/{id}/restore - take an item out of the trash
+// DELETE /trash/{collection}/{id} - delete an item permanently; albums and artists with their songs
+// (?sharedSongs=detach|delete for artists)
+func (h *TrashHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	collection, id, action := ExtractTrashPath(r.URL.Path)
+	if collection != TrashSongs && collection != TrashAlbums && collection != TrashArtists {
+		http.Error(w, "collection must be songs, albums or artists", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		h.ListTrash(w, r)
+	case id != "" && action == "restore" && r.Method == http.MethodPost:
+		h.Restore(w, r)
+	case id != "" && action == "" && r.Method == http.MethodDelete:
+		h.Purge(w, r)
+	case id == "" || action == "" || action == "restore":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// ListTrash returns the deleted items of a collection with the time they will be purged
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, _, _ := ExtractTrashPath(r.URL.Path)
+	items := []*dto.TrashItemResponse{}
+	switch collection {
+	case TrashSongs:
+		songs, err := h.Songs.Repo.ListDeleted(r.Context(), time.Time{}, store.TrashListLimit)
+		if err != nil {
+			http.Error(w, "failed to list trash: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, song := range songs {
+			items = append(items, h.trashItem(model.LikeTypeSong, song.ID, song.Name, song.DeletedAt, song.DeletedBy))
+		}
+	case TrashAlbums:
+		albums, err := h.Albums.Repo.ListDeleted(r.Context(), time.Time{}, store.TrashListLimit)
+		if err != nil {
+			http.Error(w, "failed to list trash: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, album := range albums {
+			items = append(items, h.trashItem(model.LikeTypeAlbum, album.ID, album.Name, album.DeletedAt, album.DeletedBy))
+		}
+	case TrashArtists:
+		artists, err := h.Artists.Repo.ListDeleted(r.Context(), time.Time{}, store.TrashListLimit)
+		if err != nil {
+			http.Error(w, "failed to list trash: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, artist := range artists {
+			items = append(items, h.trashItem(model.LikeTypeArtist, artist.ID, artist.Name, artist.DeletedAt, artist.DeletedBy))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(items)
+}
+
+func (h *TrashHandler) trashItem(itemType, id, name string, deletedAt *time.Time, deletedBy string) *dto.TrashItemResponse {
+	item := &dto.TrashItemResponse{Type: itemType, ID: id, Name: name, DeletedBy: deletedBy}
+	if deletedAt != nil {
+		item.DeletedAt = *deletedAt
+		item.PurgeAt = deletedAt.Add(h.Retention)
+	}
+	return item
+}
+
+// Restore takes an item out of the trash and announces it again: subscriptions-service gets the
+// creation event marked as restored, recommendation-service gets back the nodes removed on deletion
+func (h *TrashHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	collection, id, _ := ExtractTrashPath(r.URL.Path)
+	adminID := getAdminID(r.Context())
+	var response interface{}
+	var name string
+
+	switch collection {
+	case TrashSongs:
+		song, err := h.Songs.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "song")
+			return
+		}
+		// A song needs its album, as when it is created
+		if song.TrashedWith != "" {
+			http.Error(w, "the song was deleted with its album or artist, restore that instead", http.StatusConflict)
+			return
+		}
+		if _, err := h.Albums.Repo.GetDeleted(r.Context(), song.AlbumID); err == nil {
+			http.Error(w, "the song's album is in the trash, restore the album first", http.StatusConflict)
+			return
+		}
+		if _, err := h.Albums.Repo.GetByID(r.Context(), song.AlbumID); err != nil {
+			if err.Error() == "album not found" {
+				http.Error(w, "the song's album has been deleted permanently, the song cannot be restored", http.StatusConflict)
+				return
+			}
+			http.Error(w, "failed to get the song's album: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := h.Songs.Repo.Restore(r.Context(), id); err != nil {
+			writeTrashError(w, err, "song")
+			return
+		}
+		song.DeletedAt, song.DeletedBy = nil, ""
+		h.Songs.emitSongRestored(r.Context(), song)
+		response, name = toSongResponse(song), song.Name
+
+	case TrashAlbums:
+		album, err := h.Albums.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "album")
+			return
+		}
+		if album.TrashedWith != "" {
+			http.Error(w, "the album was deleted with its artist, restore the artist instead", http.StatusConflict)
+			return
+		}
+		if err := h.Albums.Repo.Restore(r.Context(), id); err != nil {
+			writeTrashError(w, err, "album")
+			return
+		}
+		// The songs deleted with the album come back with it
+		if _, err := h.Songs.Repo.RestoreTrashedWith(r.Context(), id); err != nil {
+			http.Error(w, "failed to restore the album's songs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		album.DeletedAt, album.DeletedBy = nil, ""
+		h.emitAlbumRestored(r.Context(), album)
+		response, name = toAlbumResponse(album), album.Name
+
+	case TrashArtists:
+		artist, err := h.Artists.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "artist")
+			return
+		}
+		if err := h.Artists.Repo.Restore(r.Context(), id); err != nil {
+			writeTrashError(w, err, "artist")
+			return
+		}
+		// The albums and songs deleted with the artist come back with it
+		if _, err := h.Albums.Repo.RestoreTrashedWith(r.Context(), id); err != nil {
+			http.Error(w, "failed to restore the artist's albums: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		songs, err := h.Songs.Repo.RestoreTrashedWith(r.Context(), id)
+		if err != nil {
+			http.Error(w, "failed to restore the artist's songs: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		artist.DeletedAt, artist.DeletedBy = nil, ""
+		h.emitArtistRestored(artist, songs)
+		response, name = dto.ToArtistResponse(artist), artist.Name
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(adminID, "RESTORE_"+strings.ToUpper(strings.TrimSuffix(collection, "s")), collection, map[string]interface{}{
+			"id":   id,
+			"name": name,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Purge permanently deletes an item in the trash without waiting for the retention period
+func (h *TrashHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	policy := r.URL.Query().Get("sharedSongs")
+	if policy == "" {
+		policy = h.SharedSongs
+	}
+	if policy != SharedSongsDetach && policy != SharedSongsDelete {
+		http.Error(w, "sharedSongs must be detach or delete", http.StatusBadRequest)
+		return
+	}
+
+	collection, id, _ := ExtractTrashPath(r.URL.Path)
+	var name string
+	switch collection {
+	case TrashSongs:
+		song, err := h.Songs.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "song")
+			return
+		}
+		if err := h.Songs.PurgeSong(r.Context(), song); err != nil {
+			http.Error(w, "failed to purge song: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name = song.Name
+
+	case TrashAlbums:
+		album, err := h.Albums.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "album")
+			return
+		}
+		if err := h.purgeAlbum(r.Context(), album); err != nil {
+			http.Error(w, "failed to purge album: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name = album.Name
+
+	case TrashArtists:
+		artist, err := h.Artists.Repo.GetDeleted(r.Context(), id)
+		if err != nil {
+			writeTrashError(w, err, "artist")
+			return
+		}
+		if err := h.purgeArtist(r.Context(), artist, policy); err != nil {
+			http.Error(w, "failed to purge artist: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		name = artist.Name
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(getAdminID(r.Context()), "PURGE_"+strings.ToUpper(strings.TrimSuffix(collection, "s")), collection, map[string]interface{}{
+			"id":   id,
+			"name": name,
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTrashError reports an item that is not in the trash as 404
+func writeTrashError(w http.ResponseWriter, err error, noun string) {
+	if err.Error() == noun+" not found" {
+		http.Error(w, noun+" not found in the trash", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "failed to access the trash: "+err.Error(), http.StatusInternalServerError)
+}
+
+// purgeAlbum deletes an album with its songs and their ratings, playlist entries and files
+func (h *TrashHandler) purgeAlbum(ctx context.Context, album *model.Album) error {
+	if h.Songs.SagaServiceURL != "" {
+		return h.runCatalogSaga(ctx, "/sagas/delete-album", map[string]interface{}{
+			"albumId": album.ID,
+		})
+	}
+
+	log.Printf("Saga service not configured, purging album %s directly", album.ID)
+	backup, err := h.planAlbumDeletion(ctx, album)
+	if err != nil {
+		return err
+	}
+	return h.purgeCatalog(ctx, backup)
+}
+
+// purgeArtist deletes an artist with its albums, songs and subscriptions; policy decides what
+// happens to albums and songs shared with other artists
+func (h *TrashHandler) purgeArtist(ctx context.Context, artist *model.Artist, policy string) error {
+	if h.Songs.SagaServiceURL != "" {
+		return h.runCatalogSaga(ctx, "/sagas/delete-artist", map[string]interface{}{
+			"artistId":    artist.ID,
+			"sharedSongs": policy,
+		})
+	}
+
+	log.Printf("Saga service not configured, purging artist %s directly", artist.ID)
+	backup, err := h.planArtistDeletion(ctx, artist, policy)
+	if err != nil {
+		return err
+	}
+	return h.purgeCatalog(ctx, backup)
+}
+
+// emitSongRestored announces a restored song; recommendation-service gets the song_created event of
+// CreateSong, as song_deleted removed the song from its graph
+func (h *SongHandler) emitSongRestored(ctx context.Context, song *model.Song) {
+	events.EmitEvent(context.Background(), h.SubscriptionsServiceURL, events.NewSongEvent{
+		Type:        events.EventTypeNewSong,
+		SongID:      song.ID,
+		Name:        song.Name,
+		Genre:       song.Genre,
+		ArtistIDs:   song.ArtistIDs,
+		ArtistNames: artistNames(ctx, h.ArtistRepo, song.ArtistIDs),
+		AlbumID:     song.AlbumID,
+		Restored:    true,
+	})
+	events.EmitEvent(context.Background(), h.RecommendationServiceURL, songCreatedEvent(song))
+}
+
+// emitAlbumRestored announces a restored album. album_deleted removed the album's songs from the
+// recommendation graph, so each of them is sent to recommendation-service again.
+func (h *TrashHandler) emitAlbumRestored(ctx context.Context, album *model.Album) {
+	events.EmitEvent(context.Background(), h.Albums.SubscriptionsServiceURL, events.NewAlbumEvent{
+		Type:        events.EventTypeNewAlbum,
+		AlbumID:     album.ID,
+		Name:        album.Name,
+		Genre:       album.Genre,
+		ArtistIDs:   album.ArtistIDs,
+		ArtistNames: artistNames(ctx, h.Albums.ArtistRepo, album.ArtistIDs),
+		Restored:    true,
+	})
+
+	q := store.ListQuery{AlbumID: album.ID, Sort: "createdAt", Limit: store.MaxPageSize}
+	for {
+		songs, next, err := h.Songs.Repo.List(ctx, q)
+		if err != nil {
+			log.Printf("Failed to list the songs of restored album %s: %v", album.ID, err)
+			return
+		}
+		for _, song := range songs {
+			events.EmitEvent(context.Background(), h.Albums.RecommendationServiceURL, songCreatedEvent(song))
+		}
+		if next == "" {
+			return
+		}
+		q.Cursor = next
+	}
+}
+
+// emitArtistRestored announces a restored artist with the events of CreateArtist, and the songs
+// restored with it to recommendation-service
+func (h *TrashHandler) emitArtistRestored(artist *model.Artist, songs []*model.Song) {
+	events.EmitEvent(context.Background(), h.Artists.SubscriptionsServiceURL, events.NewArtistEvent{
+		Type:     events.EventTypeNewArtist,
+		ArtistID: artist.ID,
+		Name:     artist.Name,
+		Genres:   artist.Genres,
+		Restored: true,
+	})
+	events.EmitEvent(context.Background(), h.Artists.RecommendationServiceURL, map[string]interface{}{
+		"type":     "artist_created",
+		"artistId": artist.ID,
+		"name":     artist.Name,
+		"genres":   artist.Genres,
+	})
+	for _, song := range songs {
+		events.EmitEvent(context.Background(), h.Artists.RecommendationServiceURL, songCreatedEvent(song))
+	}
+}
+
+// songCreatedEvent is the song_created event of recommendation-service
+func songCreatedEvent(song *model.Song) map[string]interface{} {
+	return map[string]interface{}{
+		"type":      "song_created",
+		"songId":    song.ID,
+		"name":      song.Name,
+		"genre":     song.Genre,
+		"artistIds": song.ArtistIDs,
+		"albumId":   song.AlbumID,
+		"duration":  song.Duration,
+	}
+}
+
+// artistNames returns the names of the artists that exist, for notification messages
+func artistNames(ctx context.Context, repo *store.ArtistRepository, ids []string) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if artist, err := repo.GetByID(ctx, id); err == nil {
+			names = append(names, artist.Name)
+		}
+	}
+	return names
+}
+
+// StartTrashPurge permanently deletes items that have been in the trash longer than the retention
+// period, once at start and then every hour, until ctx is cancelled
+func StartTrashPurge(ctx context.Context, h *TrashHandler) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	h.purgeExpired(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.purgeExpired(ctx)
+		}
+	}
+}
+
+func (h *TrashHandler) purgeExpired(ctx context.Context) {
+	cutoff := time.Now().Add(-h.Retention)
+	purged := 0
+
+	songs, err := h.Songs.Repo.ListDeleted(ctx, cutoff, store.TrashListLimit)
+	if err != nil {
+		log.Printf("Trash purge: %v", err)
+	}
+	for _, song := range songs {
+		// Songs deleted with their album or artist are purged with it
+		if song.TrashedWith != "" {
+			continue
+		}
+		if err := h.Songs.PurgeSong(ctx, song); err != nil {
+			log.Printf("Trash purge: song %s: %v", song.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	albums, err := h.Albums.Repo.ListDeleted(ctx, cutoff, store.TrashListLimit)
+	if err != nil {
+		log.Printf("Trash purge: %v", err)
+	}
+	for _, album := range albums {
+		if album.TrashedWith != "" {
+			continue
+		}
+		if err := h.purgeAlbum(ctx, album); err != nil {
+			log.Printf("Trash purge: album %s: %v", album.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	artists, err := h.Artists.Repo.ListDeleted(ctx, cutoff, store.TrashListLimit)
+	if err != nil {
+		log.Printf("Trash purge: %v", err)
+	}
+	for _, artist := range artists {
+		if err := h.purgeArtist(ctx, artist, h.SharedSongs); err != nil {
+			log.Printf("Trash purge: artist %s: %v", artist.ID, err)
+			continue
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		log.Printf("Trash purge: permanently deleted %d items deleted before %s", purged, cutoff.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"content-service/internal/upload"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,expiration,checksum,termination"
+	// maxResumableUploadSize bounds an upload; the completed file is streamed from disk to storage
+	maxResumableUploadSize = 500 << 20
+	// statusChecksumMismatch is the status tus defines for a chunk or file with the wrong checksum
+	statusChecksumMismatch = 460
+)
+
+// UploadHandler implements resumable audio uploads with the tus 1.0 protocol: POST /uploads creates an
+// upload for a song, PATCH /uploads/{id} appends chunks at Upload-Offset, HEAD /uploads/{id} returns the
+// offset to resume from and DELETE /uploads/{id} abandons it. When the last chunk arrives the file is
+// verified and stored like a multipart upload.
+type UploadHandler struct {
+	Songs   *SongHandler
+	Uploads *upload.Store
+}
+
+func NewUploadHandler(songs *SongHandler, uploads *upload.Store) *UploadHandler {
+	return &UploadHandler{
+		Songs:   songs,
+		Uploads: uploads,
+	}
+}
+
+// tusRequest sets the protocol headers and rejects requests of other protocol versions
+func tusRequest(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Method == http.MethodOptions {
+		return true
+	}
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		http.Error(w, "unsupported tus version", http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func setUploadExpires(w http.ResponseWriter, info *upload.Info) {
+	w.Header().Set("Upload-Expires", info.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// Options describes the server's tus support
+// OPTIONS /uploads
+func (h *UploadHandler) Options(w http.ResponseWriter, r *http.Request) {
+	tusRequest(w, r)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.Itoa(maxResumableUploadSize))
+	w.Header().Set("Tus-Checksum-Algorithm", upload.ChecksumAlgorithms)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseUploadMetadata decodes the Upload-Metadata header: comma separated "key base64(value)" pairs
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.New("invalid Upload-Metadata value for " + key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// CreateUpload starts an upload. Upload-Metadata carries songId and filename, and optionally checksum
+// ("sha256 <base64 digest>") of the whole file, which is verified before the file is stored.
+// POST /uploads
+func (h *UploadHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	if !tusRequest(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		http.Error(w, "Upload-Length is required", http.StatusBadRequest)
+		return
+	}
+	if length > maxResumableUploadSize {
+		http.Error(w, "upload exceeds Tus-Max-Size", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	metadata, err := parseUploadMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	songID, fileName := metadata["songId"], metadata["filename"]
+	if songID == "" || fileName == "" {
+		http.Error(w, "songId and filename metadata are required", http.StatusBadRequest)
+		return
+	}
+	if !audioExtensions[strings.ToLower(filepath.Ext(fileName))] {
+		http.Error(w, "file type not allowed. Allowed: mp3, wav, ogg, m4a, flac", http.StatusBadRequest)
+		return
+	}
+	if checksum := metadata["checksum"]; checksum != "" {
+		if _, err := upload.ParseChecksum(checksum); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if exists, err := h.Songs.Repo.Exists(r.Context(), songID); err != nil || !exists {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	info := &upload.Info{
+		SongID:    songID,
+		FileName:  fileName,
+		Length:    length,
+		Checksum:  metadata["checksum"],
+		CreatedBy: getAdminIDFromSongContext(r.Context()),
+	}
+	if err := h.Uploads.Create(info); err != nil {
+		log.Printf("Failed to create upload for song %s: %v", songID, err)
+		http.Error(w, "failed to create upload", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Resumable upload %s created for song %s: %s, %d bytes", info.ID, songID, fileName, length)
+
+	w.Header().Set("Location", "/uploads/"+info.ID)
+	setUploadExpires(w, info)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// uploadRequest loads the upload named by the path; it writes the error response when there is none
+func (h *UploadHandler) uploadRequest(w http.ResponseWriter, r *http.Request) (*upload.Info, bool) {
+	info, err := h.Uploads.Get(strings.TrimPrefix(r.URL.Path, "/uploads/"))
+	if err != nil {
+		if errors.Is(err, upload.ErrNotFound) {
+			http.Error(w, "upload not found", http.StatusNotFound)
+			return nil, false
+		}
+		log.Printf("Failed to load upload: %v", err)
+		http.Error(w, "failed to load upload", http.StatusInternalServerError)
+		return nil, false
+	}
+	return info, true
+}
+
+// GetUpload returns the offset to resume from
+// HEAD /uploads/{id}
+func (h *UploadHandler) GetUpload(w http.ResponseWriter, r *http.Request) {
+	if !tusRequest(w, r) {
+		return
+	}
+	info, ok := h.uploadRequest(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(info.Length, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	setUploadExpires(w, info)
+	w.WriteHeader(http.StatusOK)
+}
+
+// PatchUpload appends a chunk; the chunk that completes the upload also stores the file. An empty PATCH
+// at the full offset retries storing a complete upload whose storing failed.
+// PATCH /uploads/{id}
+func (h *UploadHandler) PatchUpload(w http.ResponseWriter, r *http.Request) {
+	if !tusRequest(w, r) {
+		return
+	}
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		http.Error(w, "Content-Type must be application/offset+octet-stream", http.StatusUnsupportedMediaType)
+		return
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Upload-Offset is required", http.StatusBadRequest)
+		return
+	}
+	var checksum *upload.Checksum
+	if header := r.Header.Get("Upload-Checksum"); header != "" {
+		if checksum, err = upload.ParseChecksum(header); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	info, ok := h.uploadRequest(w, r)
+	if !ok {
+		return
+	}
+	unlock := h.Uploads.Lock(info.ID)
+	defer unlock()
+	// Reload under the lock, a concurrent request may have moved the offset
+	if info, ok = h.uploadRequest(w, r); !ok {
+		return
+	}
+
+	if !info.Complete() || offset != info.Offset {
+		err := h.Uploads.Append(info, offset, r.Body, checksum)
+		switch {
+		case errors.Is(err, upload.ErrOffsetMismatch):
+			http.Error(w, "Upload-Offset does not match the upload", http.StatusConflict)
+			return
+		case errors.Is(err, upload.ErrTooLarge):
+			http.Error(w, "chunk exceeds Upload-Length", http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, upload.ErrChecksumMismatch):
+			http.Error(w, "chunk checksum mismatch", statusChecksumMismatch)
+			return
+		case err != nil:
+			// Received bytes are kept (see Append), the client resumes from the offset HEAD returns
+			log.Printf("Upload %s interrupted at offset %d: %v", info.ID, info.Offset, err)
+			http.Error(w, "failed to receive chunk", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if info.Complete() {
+		if status, message := h.completeUpload(r.Context(), info); status != 0 {
+			http.Error(w, message, status)
+			return
+		}
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	setUploadExpires(w, info)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// completeUpload verifies a complete upload and stores it as the song's audio file. Returns the error
+// status and message, or 0 when the file was stored and the upload deleted. An upload that cannot be
+// verified is deleted as well, it has to be sent again.
+func (h *UploadHandler) completeUpload(ctx context.Context, info *upload.Info) (int, string) {
+	// The spooled file is streamed from disk, never read into memory as a whole
+	file, err := h.Uploads.Open(info.ID)
+	if err != nil {
+		log.Printf("Failed to open upload %s: %v", info.ID, err)
+		return http.StatusInternalServerError, "failed to read upload"
+	}
+	discard := func() {
+		file.Close()
+		h.deleteUpload(info.ID)
+	}
+
+	if info.Checksum != "" {
+		checksum, err := upload.ParseChecksum(info.Checksum)
+		if err != nil {
+			discard()
+			return http.StatusBadRequest, err.Error()
+		}
+		ok, err := checksum.Verify(file)
+		if err != nil {
+			file.Close()
+			log.Printf("Failed to read upload %s: %v", info.ID, err)
+			return http.StatusInternalServerError, "failed to read upload"
+		}
+		if !ok {
+			log.Printf("Upload %s of song %s failed checksum verification", info.ID, info.SongID)
+			discard()
+			return statusChecksumMismatch, "file checksum mismatch, the upload has to be restarted"
+		}
+	}
+
+	song, err := h.Songs.Repo.GetByID(ctx, info.SongID)
+	if err != nil {
+		discard()
+		return http.StatusNotFound, "song not found"
+	}
+	audioFile, err := h.Songs.saveUploadedAudio(ctx, song, strings.ToLower(filepath.Ext(info.FileName)), file)
+	if err != nil {
+		file.Close()
+		return http.StatusInternalServerError, "failed to upload file to storage: " + err.Error()
+	}
+	discard()
+
+	if h.Songs.Logger != nil {
+		h.Songs.Logger.LogAdminActivity(getAdminIDFromSongContext(ctx), "UPLOAD_AUDIO", "songs", map[string]interface{}{
+			"songId":    song.ID,
+			"audioFile": audioFile,
+			"fileName":  info.FileName,
+			"fileSize":  info.Length,
+			"uploadId":  info.ID,
+		})
+	}
+	log.Printf("Resumable upload %s of song %s completed", info.ID, song.ID)
+	return 0, ""
+}
+
+func (h *UploadHandler) deleteUpload(id string) {
+	if err := h.Uploads.Delete(id); err != nil {
+		log.Printf("Failed to delete upload %s: %v", id, err)
+	}
+}
+
+// TerminateUpload abandons an upload and deletes the received bytes
+// DELETE /uploads/{id}
+func (h *UploadHandler) TerminateUpload(w http.ResponseWriter, r *http.Request) {
+	if !tusRequest(w, r) {
+		return
+	}
+	info, ok := h.uploadRequest(w, r)
+	if !ok {
+		return
+	}
+	unlock := h.Uploads.Lock(info.ID)
+	defer unlock()
+	if err := h.Uploads.Delete(info.ID); err != nil {
+		log.Printf("Failed to delete upload %s: %v", info.ID, err)
+		http.Error(w, "failed to delete upload", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StartUploadExpiry starts a background worker that deletes uploads without progress before their expiry
+func StartUploadExpiry(ctx context.Context, uploads *upload.Store) {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	expireUploads(uploads)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expireUploads(uploads)
+		}
+	}
+}
+
+func expireUploads(uploads *upload.Store) {
+	expired, err := uploads.ExpireStale(time.Now())
+	if err != nil {
+		log.Printf("Upload expiry: %v", err)
+	} else if expired > 0 {
+		log.Printf("Upload expiry: deleted %d stale uploads", expired)
+	}
+}
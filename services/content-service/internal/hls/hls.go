@@ -0,0 +1,164 @@
+// Package hls cuts MP3 and AAC audio into HLS packed audio segments at frame boundaries and writes
+// the playlists for them. The audio is not re-encoded, so each song has a single rendition.
+package hls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"content-service/internal/audiometa"
+)
+
+// DefaultSegmentDuration is short enough for a fast start and few enough requests for a whole song
+const DefaultSegmentDuration = 6 * time.Second
+
+// File names within the HLS directory of a song
+const (
+	MasterPlaylist = "master.m3u8"
+	MediaPlaylist  = "playlist.m3u8"
+)
+
+// Segment is one media segment of the stream
+type Segment struct {
+	Name     string
+	Duration time.Duration
+	Data     []byte
+}
+
+// Output is everything that is stored for a song: the segments and both playlists
+type Output struct {
+	Segments []Segment
+	Master   []byte
+	Media    []byte
+}
+
+// Build segments the audio frames into segments of about target duration and writes the playlists
+func Build(stream *audiometa.FrameStream, target time.Duration) (*Output, error) {
+	if len(stream.Frames) == 0 || stream.SampleRate <= 0 {
+		return nil, fmt.Errorf("no audio frames to segment")
+	}
+	targetSamples := int64(target.Seconds() * float64(stream.SampleRate))
+	ext := SegmentExtension(stream.Codec)
+
+	out := &Output{}
+	var (
+		buf          bytes.Buffer
+		startSample  int64 // first sample of the current segment
+		totalSamples int64
+	)
+	flush := func() {
+		samples := totalSamples - startSample
+		data := append(timestampTag(startSample, stream.SampleRate), buf.Bytes()...)
+		out.Segments = append(out.Segments, Segment{
+			Name:     fmt.Sprintf("segment_%05d%s", len(out.Segments), ext),
+			Duration: time.Duration(float64(samples) / float64(stream.SampleRate) * float64(time.Second)),
+			Data:     data,
+		})
+		buf.Reset()
+		startSample = totalSamples
+	}
+
+	for _, frame := range stream.Frames {
+		buf.Write(frame.Data)
+		totalSamples += int64(frame.Samples)
+		if totalSamples-startSample >= targetSamples {
+			flush()
+		}
+	}
+	if buf.Len() > 0 {
+		flush()
+	}
+
+	out.Media = mediaPlaylist(out.Segments)
+	out.Master = masterPlaylist(out.Segments, codecs(stream))
+	return out, nil
+}
+
+// SegmentExtension returns the file extension of packed audio segments of the codec
+func SegmentExtension(codec string) string {
+	if codec == "aac" {
+		return ".aac"
+	}
+	return ".mp3"
+}
+
+// codecs returns the RFC 6381 codec string for the CODECS attribute
+func codecs(stream *audiometa.FrameStream) string {
+	if stream.Codec == "aac" {
+		return fmt.Sprintf("mp4a.40.%d", stream.ObjectType)
+	}
+	return "mp4a.40.34"
+}
+
+// timestampTag is the ID3 tag every packed audio segment starts with. Its PRIV frame carries the
+// 33 bit MPEG-2 timestamp (90 kHz) of the first sample, so players can align the segments.
+func timestampTag(startSample int64, sampleRate int) []byte {
+	const owner = "com.apple.streaming.transportStreamTimestamp\x00"
+	pts := uint64(startSample*90000/int64(sampleRate)) & (1<<33 - 1)
+
+	frameSize := len(owner) + 8
+	tag := make([]byte, 0, 10+10+frameSize)
+	tag = append(tag, 'I', 'D', '3', 4, 0, 0)
+	tag = appendSynchsafe(tag, 10+frameSize)
+	tag = append(tag, 'P', 'R', 'I', 'V')
+	tag = appendSynchsafe(tag, frameSize)
+	tag = append(tag, 0, 0)
+	tag = append(tag, owner...)
+	return binary.BigEndian.AppendUint64(tag, pts)
+}
+
+func appendSynchsafe(b []byte, n int) []byte {
+	return append(b, byte(n>>21&0x7F), byte(n>>14&0x7F), byte(n>>7&0x7F), byte(n&0x7F))
+}
+
+func mediaPlaylist(segments []Segment) []byte {
+	var longest time.Duration
+	for _, s := range segments {
+		if s.Duration > longest {
+			longest = s.Duration
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	// The target duration must not be exceeded by any segment duration rounded to whole seconds
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", max(1, int(math.Round(longest.Seconds()))))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n")
+	b.WriteString("#EXT-X-PLAYLIST-TYPE:VOD\n")
+	for _, s := range segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", s.Duration.Seconds(), s.Name)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return []byte(b.String())
+}
+
+func masterPlaylist(segments []Segment, codecs string) []byte {
+	// BANDWIDTH is the peak segment bitrate, AVERAGE-BANDWIDTH the bitrate of the whole stream
+	var peak, totalBytes int64
+	var total time.Duration
+	for _, s := range segments {
+		totalBytes += int64(len(s.Data))
+		total += s.Duration
+		if s.Duration > 0 {
+			if bps := int64(float64(len(s.Data)*8) / s.Duration.Seconds()); bps > peak {
+				peak = bps
+			}
+		}
+	}
+	average := peak
+	if total > 0 {
+		average = int64(float64(totalBytes*8) / total.Seconds())
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d,CODECS=\"%s\"\n", peak, average, codecs)
+	b.WriteString(MediaPlaylist + "\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,107 @@
+// Package imaging validates uploaded album and artist images and scales them to square thumbnails.
+// JPEG, PNG, GIF and WebP are accepted; thumbnails are written as JPEG since there is no pure Go
+// WebP encoder.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Sizes are the thumbnail edges in pixels: list rows, cards and detail pages
+var Sizes = []int{64, 300, 640}
+
+// MaxFileSize limits an uploaded image
+const MaxFileSize = 10 << 20
+
+// maxPixels guards against small files that decode to huge images
+const maxPixels = 40_000_000
+
+// ContentType is the content type of the thumbnails
+const ContentType = "image/jpeg"
+
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format, use JPEG, PNG, GIF or WebP")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+// Sniff returns the format of an image from its magic number, regardless of the file name or the
+// declared content type
+func Sniff(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg", nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png", nil
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif", nil
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return "webp", nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Decode checks the format and dimensions of an uploaded image before decoding it
+func Decode(data []byte) (image.Image, error) {
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s image: %w", format, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("invalid %s image: empty", format)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s image: %w", format, err)
+	}
+	return img, nil
+}
+
+// Thumbnail crops the centre square of the image and scales it to size pixels. Smaller images are
+// not scaled up. Transparent areas become white, as JPEG has no alpha channel.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+	if side < size {
+		size = side
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+	return dst
+}
+
+// EncodeJPEG encodes a thumbnail
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
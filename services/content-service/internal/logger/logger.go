@@ -35,6 +35,7 @@ const (
 	EventExpiredToken         EventType = "EXPIRED_TOKEN"
 	EventAdminActivity        EventType = "ADMIN_ACTIVITY"
 	EventTLSFailure           EventType = "TLS_FAILURE"
+	EventImpersonation        EventType = "IMPERSONATION"
 )
 
 // Logger is a structured logger with file rotation and security features
@@ -0,0 +1,224 @@
+// Package lyrics parses song lyrics: plain text or time-synced LRC ("[mm:ss.xx]line").
+package lyrics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of one lyrics variant
+const (
+	MaxLength = 64 << 10
+	MaxLines  = 2000
+)
+
+// Formats accepted when lyrics are set
+const (
+	FormatLRC   = "lrc"
+	FormatPlain = "plain"
+)
+
+// Line is one line of lyrics; StartMs is its offset from the start of the song, for synced lyrics
+type Line struct {
+	StartMs int64
+	Text    string
+}
+
+// Lyrics are parsed lyrics; synced lines are sorted by start
+type Lyrics struct {
+	Synced bool
+	Lines  []Line
+}
+
+// Error is a problem with one line of the lyrics
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+var (
+	// [mm:ss], [mm:ss.x], [mm:ss.xx] or [mm:ss.xxx]; some editors write [mm:ss:xx]
+	timestampPattern = regexp.MustCompile(`^(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	// ID tags such as [ar:Artist], [ti:Title], [offset:+250]
+	idTagPattern = regexp.MustCompile(`^([a-zA-Z#]+):(.*)$`)
+	// Word timing of enhanced LRC: <mm:ss.xx>
+	wordTimePattern = regexp.MustCompile(`<\d{1,3}:\d{1,2}(?:[.:]\d{1,3})?>`)
+)
+
+// Parse parses lyrics in the given format; an empty format is LRC when a line starts with a timestamp
+func Parse(text, format string) (*Lyrics, error) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("lyrics are empty")
+	}
+	if len(text) > MaxLength {
+		return nil, fmt.Errorf("lyrics must be at most %d KB", MaxLength>>10)
+	}
+	if !utf8.ValidString(text) {
+		return nil, fmt.Errorf("lyrics must be UTF-8 text")
+	}
+
+	if format == "" {
+		format = FormatPlain
+		if looksLikeLRC(text) {
+			format = FormatLRC
+		}
+	}
+	var (
+		lyrics *Lyrics
+		err    error
+	)
+	switch format {
+	case FormatLRC:
+		lyrics, err = parseLRC(text)
+	case FormatPlain:
+		lyrics = parsePlain(text)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s", FormatLRC, FormatPlain)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(lyrics.Lines) > MaxLines {
+		return nil, fmt.Errorf("lyrics must have at most %d lines", MaxLines)
+	}
+	return lyrics, nil
+}
+
+func looksLikeLRC(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+		if end := strings.IndexByte(line, ']'); end > 0 && timestampPattern.MatchString(line[1:end]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePlain keeps the lines as written, without leading and trailing blank lines
+func parsePlain(text string) *Lyrics {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	lyrics := &Lyrics{Lines: make([]Line, len(lines))}
+	for i, line := range lines {
+		lyrics.Lines[i] = Line{Text: strings.TrimRightFunc(line, isSpace)}
+	}
+	return lyrics
+}
+
+// parseLRC reads timed lines. A line may have several timestamps (a repeated chorus); ID tags are
+// skipped except [offset:], which shifts every line (a positive offset shows lines earlier).
+func parseLRC(text string) (*Lyrics, error) {
+	lyrics := &Lyrics{Synced: true}
+	var offset int64
+	for n, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lineNo := n + 1
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			return nil, &Error{Line: lineNo, Msg: "missing timestamp"}
+		}
+
+		var starts []int64
+		for strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, &Error{Line: lineNo, Msg: "unclosed ["}
+			}
+			tag := line[1:end]
+			if start, ok, err := parseTimestamp(tag); ok {
+				if err != nil {
+					return nil, &Error{Line: lineNo, Msg: err.Error()}
+				}
+				starts = append(starts, start)
+				line = line[end+1:]
+				continue
+			}
+			if len(starts) > 0 {
+				break // a bracket in the lyric text
+			}
+			match := idTagPattern.FindStringSubmatch(tag)
+			if match == nil {
+				return nil, &Error{Line: lineNo, Msg: fmt.Sprintf("invalid timestamp [%s]", tag)}
+			}
+			if strings.EqualFold(match[1], "offset") {
+				value, err := strconv.ParseInt(strings.TrimSpace(match[2]), 10, 64)
+				if err != nil {
+					return nil, &Error{Line: lineNo, Msg: fmt.Sprintf("invalid offset %q", match[2])}
+				}
+				offset = value
+			}
+			line = line[end+1:]
+		}
+		if len(starts) == 0 {
+			if strings.TrimSpace(line) != "" {
+				return nil, &Error{Line: lineNo, Msg: "missing timestamp"}
+			}
+			continue // an ID tag line
+		}
+
+		lineText := strings.TrimSpace(wordTimePattern.ReplaceAllString(line, ""))
+		for _, start := range starts {
+			lyrics.Lines = append(lyrics.Lines, Line{StartMs: start, Text: lineText})
+		}
+	}
+	if len(lyrics.Lines) == 0 {
+		return nil, fmt.Errorf("no timed lines, use the plain format for lyrics without timestamps")
+	}
+
+	for i := range lyrics.Lines {
+		lyrics.Lines[i].StartMs -= offset
+		if lyrics.Lines[i].StartMs < 0 {
+			lyrics.Lines[i].StartMs = 0
+		}
+	}
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool {
+		return lyrics.Lines[i].StartMs < lyrics.Lines[j].StartMs
+	})
+	return lyrics, nil
+}
+
+// parseTimestamp parses the content of [mm:ss.xx]; ok is false when it is not a timestamp at all
+func parseTimestamp(tag string) (ms int64, ok bool, err error) {
+	match := timestampPattern.FindStringSubmatch(tag)
+	if match == nil {
+		return 0, false, nil
+	}
+	minutes, _ := strconv.ParseInt(match[1], 10, 64)
+	seconds, _ := strconv.ParseInt(match[2], 10, 64)
+	if seconds >= 60 {
+		return 0, true, fmt.Errorf("invalid timestamp [%s], seconds must be below 60", tag)
+	}
+	ms = (minutes*60 + seconds) * 1000
+	if fraction := match[3]; fraction != "" {
+		value, _ := strconv.ParseInt(fraction, 10, 64)
+		// .x are tenths, .xx hundredths and .xxx milliseconds
+		for i := len(fraction); i < 3; i++ {
+			value *= 10
+		}
+		ms += value
+	}
+	return ms, true, nil
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r'
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 
 	"content-service/config"
+	"shared/authz"
 )
 
 type contextKey string
@@ -19,6 +20,11 @@ type UserClaims struct {
 	UserID   string `json:"userId"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// Permissions resolved from the role by users-service at login
+	Permissions []string `json:"permissions"`
+	// Set only on impersonation tokens: the admin acting as UserID, and whether writes are allowed
+	ImpersonatorID     string `json:"impersonatorId,omitempty"`
+	ImpersonationWrite bool   `json:"impersonationWrite,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -56,27 +62,32 @@ func JWTAuth(cfg *config.Config) func(http.HandlerFunc) http.HandlerFunc {
 				return
 			}
 
+			// Read-only impersonation sessions are blocked here too, not only by the API gateway,
+			// since the service port can be reached directly
+			if claims.ImpersonatorID != "" && !claims.ImpersonationWrite && !isReadOnlyMethod(r.Method) {
+				http.Error(w, "forbidden: impersonation session is read-only", http.StatusForbidden)
+				return
+			}
+
 			// Add user claims to context
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
+			ctx = authz.WithPrincipal(ctx, &authz.Principal{
+				UserID:         claims.UserID,
+				Role:           claims.Role,
+				Permissions:    claims.Permissions,
+				ImpersonatorID: claims.ImpersonatorID,
+			})
 			next(w, r.WithContext(ctx))
 		}
 	}
 }
 
-// AdminOnly checks if the user has admin role
-func AdminOnly(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		claims, ok := r.Context().Value(UserContextKey).(*UserClaims)
-		if !ok || claims == nil {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
-			return
-		}
-
-		if claims.Role != "ADMIN" {
-			http.Error(w, "forbidden: admin access required", http.StatusForbidden)
-			return
-		}
+// isReadOnlyMethod reports whether the method does not modify state
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
 
-		next(w, r)
-	}
+// RequirePermission checks if the user's token grants the permission (must run after JWTAuth)
+func RequirePermission(permission string) func(http.HandlerFunc) http.HandlerFunc {
+	return authz.RequirePermission(permission, nil)
 }
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"content-service/config"
+)
+
+const testJWTSecret = "test-secret"
+
+func signTestToken(t *testing.T, claims *UserClaims) string {
+	t.Helper()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return token
+}
+
+func TestJWTAuthImpersonation(t *testing.T) {
+	cfg := &config.Config{JWTSecret: testJWTSecret}
+
+	readOnly := signTestToken(t, &UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "admin"})
+	writable := signTestToken(t, &UserClaims{UserID: "listener", Role: "USER", ImpersonatorID: "admin", ImpersonationWrite: true})
+	regular := signTestToken(t, &UserClaims{UserID: "listener", Role: "USER"})
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+	}{
+		{"read-only impersonation may read", http.MethodGet, readOnly, http.StatusOK},
+		{"read-only impersonation may not post", http.MethodPost, readOnly, http.StatusForbidden},
+		{"read-only impersonation may not put", http.MethodPut, readOnly, http.StatusForbidden},
+		{"read-only impersonation may not delete", http.MethodDelete, readOnly, http.StatusForbidden},
+		{"write impersonation may post", http.MethodPost, writable, http.StatusOK},
+		{"regular token may post", http.MethodPost, regular, http.StatusOK},
+		{"invalid token", http.MethodGet, "not-a-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := JWTAuth(cfg)(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/ratings", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
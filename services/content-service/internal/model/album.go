@@ -10,4 +10,14 @@ type Album struct {
 	ArtistIDs []string  `json:"artistIds" bson:"artistIds"`
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	// Number of users that saved the album to their library
+	LikeCount int `json:"likeCount" bson:"likeCount"`
+	// Album artwork, set by POST /albums/{id}/image
+	Image *Image `json:"image,omitempty" bson:"image,omitempty"`
+	// Set while the album is in the trash; trashed albums are hidden from every read and purged
+	// after the retention period
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	// ID of the artist the album went to the trash with; it comes back when the artist is restored
+	TrashedWith string `json:"trashedWith,omitempty" bson:"trashedWith,omitempty"`
 }
@@ -9,4 +9,12 @@ type Artist struct {
 	Genres    []string  `json:"genres" bson:"genres"`
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	// Number of users that saved the artist to their library
+	LikeCount int `json:"likeCount" bson:"likeCount"`
+	// Artist photo, set by POST /artists/{id}/image
+	Image *Image `json:"image,omitempty" bson:"image,omitempty"`
+	// Set while the artist is in the trash; trashed artists are hidden from every read and purged
+	// after the retention period
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
 }
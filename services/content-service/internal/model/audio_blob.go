@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Integrity status of an audio blob
+const (
+	BlobStatusOK        = "ok"
+	BlobStatusCorrupted = "corrupted"
+	BlobStatusMissing   = "missing"
+	// BlobStatusDeleting marks an unreferenced blob whose file is being deleted
+	BlobStatusDeleting = "deleting"
+)
+
+// AudioBlob is an uploaded audio file stored once by its SHA-256 and shared by the songs that reference it.
+// It is deleted from storage once no song has referenced it for a grace period.
+type AudioBlob struct {
+	SHA256   string   `json:"sha256" bson:"_id"`
+	Backend  string   `json:"backend" bson:"backend"`
+	Key      string   `json:"key" bson:"key"`
+	Size     int64    `json:"size" bson:"size"`
+	SongIDs  []string `json:"songIds" bson:"songIds"`
+	RefCount int      `json:"refCount" bson:"refCount"`
+	// Status is the result of the last verification (on read or by the integrity check)
+	Status     string     `json:"status" bson:"status"`
+	VerifiedAt *time.Time `json:"verifiedAt,omitempty" bson:"verifiedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+	// ReleasedAt is when the last song released the blob
+	ReleasedAt *time.Time `json:"releasedAt,omitempty" bson:"releasedAt,omitempty"`
+}
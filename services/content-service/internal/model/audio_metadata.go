@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// AudioMetadata is read from the uploaded audio file: its technical properties and embedded tags
+type AudioMetadata struct {
+	Format          string  `json:"format" bson:"format"`
+	DurationSeconds float64 `json:"durationSeconds" bson:"durationSeconds"`
+	Bitrate         int     `json:"bitrate" bson:"bitrate"` // kbit/s
+	SampleRate      int     `json:"sampleRate" bson:"sampleRate"`
+	Channels        int     `json:"channels" bson:"channels"`
+	// Tags embedded in the file (ID3, Vorbis comment, iTunes or RIFF INFO)
+	Title       string      `json:"title,omitempty" bson:"title,omitempty"`
+	Artist      string      `json:"artist,omitempty" bson:"artist,omitempty"`
+	Album       string      `json:"album,omitempty" bson:"album,omitempty"`
+	TrackNumber int         `json:"trackNumber,omitempty" bson:"trackNumber,omitempty"`
+	Year        int         `json:"year,omitempty" bson:"year,omitempty"`
+	Cover       *StorageRef `json:"cover,omitempty" bson:"cover,omitempty"` // the embedded cover image
+	// Tags that disagree with the catalog, for admins to review
+	Mismatches  []MetadataMismatch `json:"mismatches,omitempty" bson:"mismatches,omitempty"`
+	ExtractedAt time.Time          `json:"extractedAt" bson:"extractedAt"`
+}
+
+// MetadataMismatch is a field whose value in the file differs from the catalog
+type MetadataMismatch struct {
+	Field   string `json:"field" bson:"field"`
+	Catalog string `json:"catalog" bson:"catalog"`
+	File    string `json:"file" bson:"file"`
+}
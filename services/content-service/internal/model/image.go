@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Image is the uploaded image of an album or artist, stored as square JPEG thumbnails
+type Image struct {
+	// Version is derived from the SHA-256 of the uploaded file; it is part of the thumbnail keys,
+	// URLs and ETags, so a new image never reuses a cached one
+	Version    string      `json:"version" bson:"version"`
+	Thumbnails []Thumbnail `json:"thumbnails" bson:"thumbnails"`
+	UploadedAt time.Time   `json:"uploadedAt" bson:"uploadedAt"`
+}
+
+// Thumbnail is one size of an image; Size is the requested edge, smaller originals are not scaled up
+type Thumbnail struct {
+	Size       int `json:"size" bson:"size"`
+	StorageRef `bson:",inline"`
+}
+
+// Thumbnail returns the stored thumbnail of a size
+func (i *Image) Thumbnail(size int) *Thumbnail {
+	for n := range i.Thumbnails {
+		if i.Thumbnails[n].Size == size {
+			return &i.Thumbnails[n]
+		}
+	}
+	return nil
+}
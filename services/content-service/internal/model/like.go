@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Library item types
+const (
+	LikeTypeSong   = "song"
+	LikeTypeAlbum  = "album"
+	LikeTypeArtist = "artist"
+)
+
+// Like is a song, album or artist saved to a user's library
+type Like struct {
+	ID        string    `json:"id" bson:"_id"` // userId:itemType:itemId, one like per user and item
+	UserID    string    `json:"userId" bson:"userId"`
+	ItemType  string    `json:"itemType" bson:"itemType"`
+	ItemID    string    `json:"itemId" bson:"itemId"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// LikeID returns the ID of the user's like of an item
+func LikeID(userID, itemType, itemID string) string {
+	return userID + ":" + itemType + ":" + itemID
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Lyrics are the lyrics of a song in one language, plain or time-synced
+type Lyrics struct {
+	ID        string       `json:"-" bson:"_id"` // {songId}:{language}
+	SongID    string       `json:"songId" bson:"songId"`
+	Language  string       `json:"language" bson:"language"` // BCP 47 tag, e.g. "sr" or "en-us"
+	Synced    bool         `json:"synced" bson:"synced"`
+	Lines     []LyricsLine `json:"lines" bson:"lines"`
+	UpdatedAt time.Time    `json:"updatedAt" bson:"updatedAt"`
+	UpdatedBy string       `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+}
+
+// LyricsLine is one line; StartMs is the offset from the start of the song and is 0 for plain lyrics
+type LyricsLine struct {
+	StartMs int64  `json:"startMs" bson:"startMs"`
+	Text    string `json:"text" bson:"text"`
+}
@@ -0,0 +1,157 @@
+package model
+
+import "time"
+
+const (
+	PlaylistPublic  = "public"
+	PlaylistPrivate = "private"
+)
+
+type Playlist struct {
+	ID          string `json:"id" bson:"_id"`
+	OwnerID     string `json:"ownerId" bson:"ownerId"`
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+	CoverURL    string `json:"coverUrl" bson:"coverUrl,omitempty"`
+	Visibility  string `json:"visibility" bson:"visibility"` // public or private
+	// Collaborative playlists can be edited by the invited editors, not only by the owner
+	Collaborative bool            `json:"collaborative" bson:"collaborative"`
+	EditorIDs     []string        `json:"editorIds" bson:"editorIds"`
+	Entries       []PlaylistEntry `json:"entries" bson:"entries"`
+	// Version is incremented on every change, updates are rejected when it moved in between
+	Version   int       `json:"version" bson:"version"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// PlaylistEntry is one song in a playlist. The entry ID stays the same when the song is moved,
+// so the same song can appear twice and be moved or removed unambiguously.
+type PlaylistEntry struct {
+	ID       string    `json:"id" bson:"id"`
+	SongID   string    `json:"songId" bson:"songId"`
+	Position int       `json:"position" bson:"position"` // 1-based, always contiguous
+	AddedBy  string    `json:"addedBy" bson:"addedBy"`
+	AddedAt  time.Time `json:"addedAt" bson:"addedAt"`
+}
+
+// IsEditor reports whether the user may change the songs of the playlist
+func (p *Playlist) IsEditor(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if p.OwnerID == userID {
+		return true
+	}
+	if !p.Collaborative {
+		return false
+	}
+	for _, id := range p.EditorIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMember reports whether the user owns the playlist or was invited to it
+func (p *Playlist) IsMember(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if p.OwnerID == userID {
+		return true
+	}
+	for _, id := range p.EditorIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CanView reports whether the user may see the playlist; private playlists are visible to members only
+func (p *Playlist) CanView(userID string) bool {
+	return p.Visibility == PlaylistPublic || p.IsMember(userID)
+}
+
+// InsertEntry inserts the entry at the 1-based position; 0 or a position past the end appends it
+func (p *Playlist) InsertEntry(entry PlaylistEntry, position int) {
+	index := len(p.Entries)
+	if position > 0 && position <= len(p.Entries) {
+		index = position - 1
+	}
+	p.Entries = append(p.Entries, PlaylistEntry{})
+	copy(p.Entries[index+1:], p.Entries[index:])
+	p.Entries[index] = entry
+	p.renumber()
+}
+
+// MoveEntry moves the entry to the 1-based position, a position past the end moves it last
+func (p *Playlist) MoveEntry(entryID string, position int) bool {
+	index := p.entryIndex(entryID)
+	if index < 0 {
+		return false
+	}
+	entry := p.Entries[index]
+	p.Entries = append(p.Entries[:index], p.Entries[index+1:]...)
+	p.InsertEntry(entry, position)
+	return true
+}
+
+// RemoveEntry removes the entry and closes the gap in the positions
+func (p *Playlist) RemoveEntry(entryID string) bool {
+	index := p.entryIndex(entryID)
+	if index < 0 {
+		return false
+	}
+	p.Entries = append(p.Entries[:index], p.Entries[index+1:]...)
+	p.renumber()
+	return true
+}
+
+// RemoveSongs removes every entry of the songs and returns the removed entries in position order
+func (p *Playlist) RemoveSongs(songIDs []string) []PlaylistEntry {
+	songs := make(map[string]bool, len(songIDs))
+	for _, id := range songIDs {
+		songs[id] = true
+	}
+	var removed []PlaylistEntry
+	kept := make([]PlaylistEntry, 0, len(p.Entries))
+	for _, e := range p.Entries {
+		if songs[e.SongID] {
+			removed = append(removed, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	p.Entries = kept
+	p.renumber()
+	return removed
+}
+
+// HasEntry reports whether the playlist contains the entry
+func (p *Playlist) HasEntry(entryID string) bool {
+	return p.entryIndex(entryID) >= 0
+}
+
+func (p *Playlist) entryIndex(entryID string) int {
+	for i, e := range p.Entries {
+		if e.ID == entryID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Playlist) renumber() {
+	for i := range p.Entries {
+		p.Entries[i].Position = i + 1
+	}
+}
+
+// RemovedPlaylistEntry records a song entry removed from a playlist when the song was deleted,
+// so the deletion saga can put it back if a later step fails
+type RemovedPlaylistEntry struct {
+	PlaylistID string        `json:"playlistId"`
+	Entry      PlaylistEntry `json:"entry"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Revision actions
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionRollback = "rollback"
+	// RevisionInitial is the state of an item created before revisions were kept, recorded with its
+	// first update
+	RevisionInitial = "initial"
+)
+
+// Revision is one change of a song, album or artist. Revisions are never modified; a rollback is a
+// new revision that restores the state of an older one.
+type Revision struct {
+	ID string `json:"id" bson:"_id"`
+	// EntityType is song, album or artist (the LikeType constants)
+	EntityType string `json:"entityType" bson:"entityType"`
+	EntityID   string `json:"entityId" bson:"entityId"`
+	// Number counts the revisions of one entity from 1
+	Number  int           `json:"number" bson:"number"`
+	Action  string        `json:"action" bson:"action"`
+	Changes []FieldChange `json:"changes" bson:"changes"`
+	// State holds the editable fields after the change, keyed as in the update request; a rollback to
+	// the revision restores it
+	State     map[string]interface{} `json:"state" bson:"state"`
+	AuthorID  string                 `json:"authorId" bson:"authorId"`
+	CreatedAt time.Time              `json:"createdAt" bson:"createdAt"`
+	// RestoredFrom is the revision whose state a rollback restored
+	RestoredFrom int `json:"restoredFrom,omitempty" bson:"restoredFrom,omitempty"`
+}
+
+// FieldChange is the old and new value of one changed field; Old is nil in the creation revision
+type FieldChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
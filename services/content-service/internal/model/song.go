@@ -3,13 +3,31 @@ package model
 import "time"
 
 type Song struct {
-	ID           string    `json:"id" bson:"_id"`
-	Name         string    `json:"name" bson:"name"`
-	Duration     int       `json:"duration" bson:"duration"` // duration in seconds
-	Genre        string    `json:"genre" bson:"genre"`
-	AlbumID      string    `json:"albumId" bson:"albumId"`
-	ArtistIDs    []string  `json:"artistIds" bson:"artistIds"`
-	AudioFileURL string    `json:"audioFileUrl" bson:"audioFileUrl,omitempty"` // path or URL to audio file
-	CreatedAt    time.Time `json:"createdAt" bson:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt" bson:"updatedAt"`
+	ID        string      `json:"id" bson:"_id"`
+	Name      string      `json:"name" bson:"name"`
+	Duration  int         `json:"duration" bson:"duration"` // duration in seconds
+	Genre     string      `json:"genre" bson:"genre"`
+	AlbumID   string      `json:"albumId" bson:"albumId"`
+	ArtistIDs []string    `json:"artistIds" bson:"artistIds"`
+	AudioFile *StorageRef `json:"audioFile,omitempty" bson:"audioFile,omitempty"` // uploaded or external audio
+	CreatedAt time.Time   `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt" bson:"updatedAt"`
+	// Copy of the ratings-service average, kept for sorting by rating
+	AverageRating float64 `json:"averageRating" bson:"averageRating"`
+	RatingCount   int     `json:"ratingCount" bson:"ratingCount"`
+	// Number of users that saved the song to their library
+	LikeCount int `json:"likeCount" bson:"likeCount"`
+	// Read from the uploaded audio file, nil until audio is uploaded
+	Audio *AudioMetadata `json:"audio,omitempty" bson:"audio,omitempty"`
+	// Key prefix of the HLS playlists and segments, set once they are generated
+	HLS *StorageRef `json:"hls,omitempty" bson:"hls,omitempty"`
+	// Languages with lyrics, and the text of all of them for the search index
+	LyricsLanguages []string `json:"lyricsLanguages,omitempty" bson:"lyricsLanguages,omitempty"`
+	LyricsText      string   `json:"-" bson:"lyricsText,omitempty"`
+	// Set while the song is in the trash; trashed songs are hidden from every read and purged
+	// after the retention period
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	// ID of the album or artist the song went to the trash with; it comes back when that is restored
+	TrashedWith string `json:"trashedWith,omitempty" bson:"trashedWith,omitempty"`
 }
@@ -0,0 +1,10 @@
+package model
+
+// StorageRef locates a stored file: the storage backend (hdfs, local, s3) and the key within it.
+// For audio hosted elsewhere the backend is "external" and the key is its URL.
+type StorageRef struct {
+	Backend string `json:"backend" bson:"backend"`
+	Key     string `json:"key" bson:"key"`
+	// SHA256 is set for content-addressed audio (an AudioBlob); hex encoded
+	SHA256 string `json:"sha256,omitempty" bson:"sha256,omitempty"`
+}
@@ -0,0 +1,287 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Matcher scores catalog names against a search query.
+// MongoDB text indexes only match whole words, so prefix ("metal" -> "metallica")
+// and typo ("metalica" -> "metallica") matching is done here on the candidates.
+
+const maxQueryTokens = 8
+
+// Word match kinds, from weakest to strongest
+const (
+	matchNone = iota
+	matchFuzzy
+	matchPrefix
+	matchExact
+)
+
+// diacritics maps Serbian Latin letters to ASCII so "Đorđe" matches "djordje"
+var diacritics = map[rune]string{
+	'č': "c", 'ć': "c", 'š': "s", 'ž': "z", 'đ': "dj",
+}
+
+type Matcher struct {
+	query  string
+	tokens []string
+}
+
+func NewMatcher(query string) *Matcher {
+	return &Matcher{query: Fold(query), tokens: Tokens(query)}
+}
+
+// Tokens returns the distinct folded words of the query
+func (m *Matcher) Tokens() []string {
+	return m.tokens
+}
+
+// Fold lowercases the text and strips Serbian diacritics
+func Fold(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if repl, ok := diacritics[r]; ok {
+			b.WriteString(repl)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Tokens splits text into distinct folded words
+func Tokens(s string) []string {
+	seen := map[string]bool{}
+	var tokens []string
+	for _, w := range strings.FieldsFunc(Fold(s), isSeparator) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		tokens = append(tokens, w)
+		if len(tokens) == maxQueryTokens {
+			break
+		}
+	}
+	return tokens
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// maxEdits is the number of typos tolerated for a token
+func maxEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matchWord compares one folded query token with one folded word of the text
+func matchWord(token, word string) int {
+	if token == word {
+		return matchExact
+	}
+	if strings.HasPrefix(word, token) {
+		return matchPrefix
+	}
+	edits := maxEdits(token)
+	// Short words are not typo-matched, "band" must not match "and"
+	if edits == 0 || len([]rune(word)) < 4 {
+		return matchNone
+	}
+	if levenshtein(token, word) <= edits {
+		return matchFuzzy
+	}
+	// Typo in a prefix: compare with the beginning of a longer word
+	if wr := []rune(word); len(wr) > len([]rune(token)) && levenshtein(token, string(wr[:len([]rune(token))])) <= edits {
+		return matchFuzzy
+	}
+	return matchNone
+}
+
+// bestMatch returns the strongest match of the token among the words
+func bestMatch(token string, words []string) int {
+	best := matchNone
+	for _, w := range words {
+		if kind := matchWord(token, w); kind > best {
+			best = kind
+			if best == matchExact {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// Matches reports whether every query token matches a word of the text
+func (m *Matcher) Matches(text string) bool {
+	if len(m.tokens) == 0 {
+		return false
+	}
+	words := Tokens(text)
+	for _, t := range m.tokens {
+		if bestMatch(t, words) == matchNone {
+			return false
+		}
+	}
+	return true
+}
+
+// Score ranks a candidate by its name and, with a lower weight, a secondary text (e.g. biography).
+// Exact and whole-name matches rank above prefix matches, which rank above typo matches.
+// ok is false when no query token matches the candidate at all.
+func (m *Matcher) Score(name, secondary string) (score float64, ok bool) {
+	nameWords := strings.FieldsFunc(Fold(name), isSeparator)
+	secondaryWords := strings.FieldsFunc(Fold(secondary), isSeparator)
+
+	matchedInName := 0
+	for _, t := range m.tokens {
+		switch bestMatch(t, nameWords) {
+		case matchExact:
+			score += 4
+			matchedInName++
+		case matchPrefix:
+			score += 3
+			matchedInName++
+		case matchFuzzy:
+			score += 1.5
+			matchedInName++
+		default:
+			if kind := bestMatch(t, secondaryWords); kind != matchNone {
+				score += 0.25 * float64(kind)
+				ok = true
+			}
+		}
+	}
+	if matchedInName > 0 {
+		ok = true
+	}
+	if len(m.tokens) > 0 && matchedInName == len(m.tokens) {
+		score += 2
+	}
+
+	folded := strings.Join(nameWords, " ")
+	query := strings.Join(strings.FieldsFunc(m.query, isSeparator), " ")
+	switch {
+	case query == "":
+	case folded == query:
+		score += 10
+	case strings.HasPrefix(folded, query):
+		score += 4
+	}
+	return score, ok
+}
+
+// Highlight returns the HTML-escaped text with matched words wrapped in <mark>.
+// With maxRunes > 0 only a window around the first match is returned.
+// ok is false when nothing in the text matches.
+func (m *Matcher) Highlight(text string, maxRunes int) (snippet string, ok bool) {
+	runes := []rune(text)
+
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < len(runes); {
+		if isSeparator(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && !isSeparator(runes[j]) {
+			j++
+		}
+		word := Fold(string(runes[i:j]))
+		for _, t := range m.tokens {
+			if matchWord(t, word) != matchNone {
+				spans = append(spans, span{i, j})
+				break
+			}
+		}
+		i = j
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		from = spans[0].start - maxRunes/4
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxRunes
+		if to > len(runes) {
+			to = len(runes)
+			from = to - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := s.start, s.end
+		if start < from {
+			start = from
+		}
+		if end > to {
+			end = to
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[start:end])))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
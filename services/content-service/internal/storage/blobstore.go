@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Names of the storage backends, as stored in song storage references
+const (
+	BackendHDFS  = "hdfs"
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	// BackendExternal references audio hosted elsewhere by URL; it has no BlobStore
+	BackendExternal = "external"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobStore stores audio, covers and HLS segments under slash separated keys
+// such as "audio/songs/{id}.mp3"
+type BlobStore interface {
+	// Put streams size bytes from body into the object, replacing it if it exists
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// GetRange reads length bytes from offset; length 0 reads to the end
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// Stores holds the configured backends; new files are written to the default one, existing files
+// are read from the backend their reference names
+type Stores struct {
+	defaultBackend string
+	backends       map[string]BlobStore
+}
+
+func NewStores(defaultBackend string) *Stores {
+	return &Stores{defaultBackend: defaultBackend, backends: make(map[string]BlobStore)}
+}
+
+func (s *Stores) Register(backend string, store BlobStore) {
+	s.backends[backend] = store
+}
+
+// DefaultBackend is the backend new uploads go to
+func (s *Stores) DefaultBackend() string {
+	return s.defaultBackend
+}
+
+// Default returns the store new uploads go to
+func (s *Stores) Default() BlobStore {
+	return s.backends[s.defaultBackend]
+}
+
+// Get returns the store of a backend
+func (s *Stores) Get(backend string) (BlobStore, error) {
+	store, ok := s.backends[backend]
+	if !ok {
+		return nil, fmt.Errorf("storage backend %q is not configured", backend)
+	}
+	return store, nil
+}
+
+// PutBytes stores a small object held in memory (covers, thumbnails, HLS segments and playlists)
+func PutBytes(ctx context.Context, store BlobStore, key string, data []byte) error {
+	return store.Put(ctx, key, bytes.NewReader(data), int64(len(data)))
+}
+
+// CleanKey normalises a key and rejects keys that escape the store (e.g. "../")
+func CleanKey(key string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+key), "/")
+	if cleaned == "" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return cleaned, nil
+}
+
+// DeletePrefix deletes every object under the prefix (e.g. the HLS directory of a song)
+func DeletePrefix(ctx context.Context, store BlobStore, prefix string) error {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err := store.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy streams an object between stores, used to migrate audio to another backend
+func Copy(ctx context.Context, from BlobStore, to BlobStore, key string) error {
+	info, err := from.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	body, err := from.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return to.Put(ctx, key, body, info.Size)
+}
+
+// ParseLegacyURL maps the audioFileUrl values songs used to store to a backend and key:
+// "/audio/..." and "hdfs://namenode:9000/audio/..." are HDFS paths, "/music/..." and other paths
+// were files on the content-service disk (now the local backend), http(s) URLs are external.
+func ParseLegacyURL(url string) (backend, key string) {
+	switch {
+	case url == "":
+		return "", ""
+	case strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://"):
+		return BackendExternal, url
+	case strings.HasPrefix(url, "hdfs://"):
+		// hdfs://namenode:port/path
+		parts := strings.SplitN(url, "/", 4)
+		if len(parts) < 4 {
+			return "", ""
+		}
+		return BackendHDFS, parts[3]
+	case strings.HasPrefix(url, "/audio/"):
+		return BackendHDFS, strings.TrimPrefix(url, "/")
+	default:
+		// "/music/..." and other paths were files on the content-service disk
+		return BackendLocal, strings.TrimPrefix(url, "/")
+	}
+}
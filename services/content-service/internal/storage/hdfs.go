@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 type HDFSClient struct {
 	baseURL    string
 	httpClient *http.Client
+	// streamClient has no overall timeout, streamed reads are bounded by the request context
+	streamClient *http.Client
 }
 
 // NewHDFSClient creates a new HDFS client
@@ -43,6 +46,9 @@ func NewHDFSClient(namenodeURL string) *HDFSClient {
 			Timeout:   600 * time.Second, // Increased timeout for large file uploads (10 minutes)
 			Transport: transport,
 		},
+		streamClient: &http.Client{
+			Transport: transport,
+		},
 	}
 }
 
@@ -138,6 +144,12 @@ func (c *HDFSClient) UploadFile(localPath, hdfsPath string) error {
 
 // UploadData uploads data from memory to HDFS
 func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
+	return c.UploadReader(bytes.NewReader(data), int64(len(data)), hdfsPath)
+}
+
+// UploadReader streams size bytes from body to HDFS. A failed attempt is retried from the start of body
+// when body can seek back (files, data in memory), otherwise only while none of it was sent.
+func (c *HDFSClient) UploadReader(body io.Reader, size int64, hdfsPath string) error {
 	// Create HDFS directory if it doesn't exist
 	dir := filepath.Dir(hdfsPath)
 	if dir != "." && dir != "/" {
@@ -155,7 +167,28 @@ func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
 
 	// Retry logic for upload (HDFS can be flaky)
 	maxRetries := 5
+	// The transport closes a request body, body belongs to the caller
+	reqBody := io.Reader(http.NoBody)
+	if size > 0 {
+		reqBody = io.NopCloser(body)
+	}
+
 	var lastErr error
+	sent := false
+	// rewind prepares body for another attempt after some of it may have been sent
+	rewind := func() error {
+		if !sent {
+			return nil
+		}
+		seeker, ok := body.(io.Seeker)
+		if !ok {
+			return fmt.Errorf("upload failed and cannot be retried: %w", lastErr)
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("upload failed and cannot be retried: %w", lastErr)
+		}
+		return nil
+	}
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		if attempt > 0 {
 			// Wait before retry (exponential backoff with longer delays)
@@ -193,7 +226,7 @@ func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
 			// Step 2: Upload file data to redirect URL
 			// Use streaming approach with explicit Content-Length (HDFS requires it)
 			uploadCtx, uploadCancel := context.WithTimeout(context.Background(), 600*time.Second) // Increased to 10 minutes for large files
-			req, err = http.NewRequestWithContext(uploadCtx, "PUT", redirectURL, bytes.NewReader(data))
+			req, err = http.NewRequestWithContext(uploadCtx, "PUT", redirectURL, reqBody)
 			if err != nil {
 				uploadCancel()
 				lastErr = fmt.Errorf("failed to create upload request: %w", err)
@@ -201,10 +234,15 @@ func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
 			}
 			
 			// Set Content-Length explicitly (required by HDFS WebHDFS API)
-			req.ContentLength = int64(len(data))
+			req.ContentLength = size
 			req.Header.Set("Content-Type", "application/octet-stream")
 			req.Header.Set("Connection", "keep-alive") // Keep connection alive
 
+			if err := rewind(); err != nil {
+				uploadCancel()
+				return err
+			}
+			sent = true
 			resp, err = c.httpClient.Do(req)
 			uploadCancel()
 			if err != nil {
@@ -225,7 +263,7 @@ func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
 
 		// If no redirect, try direct upload
 		uploadCtx, uploadCancel := context.WithTimeout(context.Background(), 600*time.Second) // Increased to 10 minutes for large files
-		req, err = http.NewRequestWithContext(uploadCtx, "PUT", createURL, bytes.NewReader(data))
+		req, err = http.NewRequestWithContext(uploadCtx, "PUT", createURL, reqBody)
 		if err != nil {
 			uploadCancel()
 			lastErr = fmt.Errorf("failed to create upload request: %w", err)
@@ -233,10 +271,15 @@ func (c *HDFSClient) UploadData(data []byte, hdfsPath string) error {
 		}
 		
 		// Set Content-Length explicitly (required by HDFS WebHDFS API)
-		req.ContentLength = int64(len(data))
+		req.ContentLength = size
 		req.Header.Set("Content-Type", "application/octet-stream")
 		req.Header.Set("Connection", "keep-alive") // Keep connection alive
 
+		if err := rewind(); err != nil {
+			uploadCancel()
+			return err
+		}
+		sent = true
 		resp, err = c.httpClient.Do(req)
 		uploadCancel()
 		if err != nil {
@@ -399,8 +442,18 @@ func (c *HDFSClient) Mkdir(hdfsPath string, createParent bool) error {
 	return nil
 }
 
+// ErrFileNotFound is returned when the HDFS path does not exist
+var ErrFileNotFound = errors.New("file not found in HDFS")
+
+// FileStatus is the WebHDFS status of a file
+type FileStatus struct {
+	Length           int64  `json:"length"`
+	ModificationTime int64  `json:"modificationTime"` // unix milliseconds
+	Type             string `json:"type"`             // FILE or DIRECTORY
+}
+
 // GetFileStatus returns file status information
-func (c *HDFSClient) GetFileStatus(hdfsPath string) (map[string]interface{}, error) {
+func (c *HDFSClient) GetFileStatus(hdfsPath string) (*FileStatus, error) {
 	statusURL := fmt.Sprintf("%s/webhdfs/v1%s?op=GETFILESTATUS&user.name=root", c.baseURL, hdfsPath)
 	req, err := http.NewRequest("GET", statusURL, nil)
 	if err != nil {
@@ -413,17 +466,52 @@ func (c *HDFSClient) GetFileStatus(hdfsPath string) (map[string]interface{}, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrFileNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("failed to get file status: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var result struct {
-		FileStatus map[string]interface{} `json:"FileStatus"`
+		FileStatus FileStatus `json:"FileStatus"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return result.FileStatus, nil
+	return &result.FileStatus, nil
+}
+
+// OpenRange opens length bytes of the file starting at offset for streaming; length <= 0 reads to the end.
+// The namenode redirects the OPEN to a datanode, which is followed automatically.
+// Only the requested bytes are transferred and nothing is buffered, the caller must close the reader.
+// The read is bounded by ctx instead of the client timeout, so long and slow streams are not cut off.
+func (c *HDFSClient) OpenRange(ctx context.Context, hdfsPath string, offset, length int64) (io.ReadCloser, error) {
+	openURL := fmt.Sprintf("%s/webhdfs/v1%s?op=OPEN&user.name=root&offset=%d", c.baseURL, hdfsPath, offset)
+	if length > 0 {
+		openURL += fmt.Sprintf("&length=%d", length)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", openURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file in HDFS: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to open file: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return resp.Body, nil
 }
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HDFSStore is the BlobStore on HDFS; a key is the HDFS path without the leading slash
+type HDFSStore struct {
+	client *HDFSClient
+}
+
+func NewHDFSStore(client *HDFSClient) *HDFSStore {
+	return &HDFSStore{client: client}
+}
+
+func hdfsPath(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return "/" + key, nil
+}
+
+func hdfsError(err error) error {
+	if errors.Is(err, ErrFileNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *HDFSStore) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	p, err := hdfsPath(key)
+	if err != nil {
+		return err
+	}
+	return s.client.UploadReader(body, size, p)
+}
+
+func (s *HDFSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.GetRange(ctx, key, 0, 0)
+}
+
+func (s *HDFSStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	p, err := hdfsPath(key)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.client.OpenRange(ctx, p, offset, length)
+	return body, hdfsError(err)
+}
+
+func (s *HDFSStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	p, err := hdfsPath(key)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.client.GetFileStatus(p)
+	if err != nil {
+		return nil, hdfsError(err)
+	}
+	if status.Type == "DIRECTORY" {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Key: key, Size: status.Length, ModTime: time.UnixMilli(status.ModificationTime).UTC()}, nil
+}
+
+func (s *HDFSStore) Delete(ctx context.Context, key string) error {
+	p, err := hdfsPath(key)
+	if err != nil {
+		return err
+	}
+	// WebHDFS answers a DELETE of a missing path with 200 and {"boolean": false}
+	return s.client.DeleteFile(p)
+}
+
+func (s *HDFSStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	// List the deepest directory that contains the prefix and filter by the rest
+	dir := ""
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir = prefix[:i]
+	}
+
+	var objects []ObjectInfo
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := s.client.ListStatus("/" + dir)
+		if errors.Is(err, ErrFileNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			key := entry.PathSuffix
+			if dir != "" {
+				key = dir + "/" + entry.PathSuffix
+			}
+			if entry.Type == "DIRECTORY" {
+				if strings.HasPrefix(key+"/", prefix) || strings.HasPrefix(prefix, key+"/") {
+					if err := walk(key); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if strings.HasPrefix(key, prefix) {
+				objects = append(objects, ObjectInfo{Key: key, Size: entry.Length, ModTime: time.UnixMilli(entry.ModificationTime).UTC()})
+			}
+		}
+		return nil
+	}
+	if err := walk(dir); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// DirEntry is one entry of a WebHDFS LISTSTATUS response
+type DirEntry struct {
+	FileStatus
+	PathSuffix string `json:"pathSuffix"`
+}
+
+// ListStatus lists the entries of an HDFS directory
+func (c *HDFSClient) ListStatus(hdfsPath string) ([]DirEntry, error) {
+	listURL := fmt.Sprintf("%s/webhdfs/v1%s?op=LISTSTATUS&user.name=root", c.baseURL, hdfsPath)
+	req, err := http.NewRequest("GET", listURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to list directory: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		FileStatuses struct {
+			FileStatus []DirEntry `json:"FileStatus"`
+		} `json:"FileStatuses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.FileStatuses.FileStatus, nil
+}
@@ -0,0 +1,310 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible store (AWS S3, MinIO, ...)
+type S3Config struct {
+	Endpoint  string // e.g. http://minio:9000
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store is the BlobStore on an S3-compatible object store. Requests use path-style URLs
+// (endpoint/bucket/key), which MinIO and AWS both accept, and are signed with AWS Signature V4.
+type S3Store struct {
+	cfg    S3Config
+	client *http.Client
+}
+
+func NewS3Store(cfg S3Config) *S3Store {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	// No overall timeout: streamed reads are bounded by the request context
+	return &S3Store{cfg: cfg, client: &http.Client{}}
+}
+
+// emptySHA256 is the payload hash of requests without a body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// unsignedPayload replaces the payload hash of uploads, so an object is streamed without hashing it first
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+func (s *S3Store) objectURL(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.cfg.Endpoint + "/" + s.cfg.Bucket + "/" + s3Escape(key, false), nil
+}
+
+// do signs and sends a request without a body; the caller closes the response body
+func (s *S3Store) do(ctx context.Context, method, rawURL string, header http.Header) (*http.Response, error) {
+	return s.send(ctx, method, rawURL, nil, 0, emptySHA256, header)
+}
+
+// send signs and sends a request streaming size bytes from body. payloadHash is the SHA-256 of the body,
+// or unsignedPayload for a body that is streamed without reading it twice.
+func (s *S3Store) send(ctx context.Context, method, rawURL string, body io.Reader, size int64, payloadHash string, header http.Header) (*http.Response, error) {
+	// The transport closes a request body, body belongs to the caller
+	reqBody := io.Reader(http.NoBody)
+	if body != nil && size > 0 {
+		reqBody = io.NopCloser(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	signV4(req, payloadHash, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s failed: %w", method, err)
+	}
+	return resp, nil
+}
+
+// s3Error turns an unexpected response into an error and closes its body
+func s3Error(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 request failed: status %d, body: %s", resp.StatusCode, string(body))
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.send(ctx, http.MethodPut, objectURL, body, size, unsignedPayload, http.Header{"Content-Type": {"application/octet-stream"}})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.GetRange(ctx, key, 0, 0)
+}
+
+func (s *S3Store) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if length > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := s.do(ctx, http.MethodGet, objectURL, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, s3Error(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+	resp.Body.Close()
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &ObjectInfo{Key: key, Size: size, ModTime: modified.UTC()}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, objectURL, nil)
+	if err != nil {
+		return err
+	}
+	// S3 answers 204 whether or not the object existed
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		if err := s3Error(resp); err != ErrNotFound {
+			return err
+		}
+		return nil
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		listURL := s.cfg.Endpoint + "/" + s.cfg.Bucket + "?" + s3Query(query)
+		resp, err := s.do(ctx, http.MethodGet, listURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, s3Error(resp)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode S3 list response: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			objects = append(objects, ObjectInfo{Key: c.Key, Size: c.Size, ModTime: c.LastModified.UTC()})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// s3Escape URI-encodes everything except the unreserved characters (and "/" in paths), as SigV4 requires
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Query encodes query parameters sorted by name, the canonical form used for signing
+func s3Query(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// signV4 adds the AWS Signature Version 4 Authorization header. The host, the Range header and
+// all x-amz-* headers are signed.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		s3Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
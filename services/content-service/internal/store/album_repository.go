@@ -33,7 +33,7 @@ func (r *AlbumRepository) Create(ctx context.Context, album *model.Album) error
 
 func (r *AlbumRepository) GetByID(ctx context.Context, id string) (*model.Album, error) {
 	var album model.Album
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&album)
+	err := r.collection.FindOne(ctx, notDeleted(bson.M{"_id": id})).Decode(&album)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("album not found")
@@ -43,34 +43,72 @@ func (r *AlbumRepository) GetByID(ctx context.Context, id string) (*model.Album,
 	return &album, nil
 }
 
-func (r *AlbumRepository) GetByArtistID(ctx context.Context, artistID string) ([]*model.Album, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"artistIds": artistID})
+// GetByIDs returns the albums with the given IDs that exist, keyed by ID
+func (r *AlbumRepository) GetByIDs(ctx context.Context, ids []string) (map[string]*model.Album, error) {
+	albums := map[string]*model.Album{}
+	if len(ids) == 0 {
+		return albums, nil
+	}
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": ids}}))
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var albums []*model.Album
-	if err = cursor.All(ctx, &albums); err != nil {
-		return nil, err
+	for cursor.Next(ctx) {
+		var album model.Album
+		if err := cursor.Decode(&album); err != nil {
+			return nil, err
+		}
+		albums[album.ID] = &album
 	}
+	return albums, cursor.Err()
+}
 
-	return albums, nil
+
+// albumSortFields maps API sort names to album fields
+var albumSortFields = map[string]string{
+	"name":        "name",
+	"createdAt":   "createdAt",
+	"releaseDate": "releaseDate",
 }
 
-func (r *AlbumRepository) GetAll(ctx context.Context) ([]*model.Album, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+// EnsureIndexes creates the indexes used by the album list and the trash
+func (r *AlbumRepository) EnsureIndexes(ctx context.Context) error {
+	if err := listIndexes(ctx, r.collection, []string{"name", "createdAt", "releaseDate"}, []string{"genre", "artistIds"}); err != nil {
+		return err
 	}
-	defer cursor.Close(ctx)
+	return trashIndex(ctx, r.collection)
+}
 
-	var albums []*model.Album
-	if err = cursor.All(ctx, &albums); err != nil {
-		return nil, err
+// List returns one page of albums filtered by genre and artist, and the cursor of the next page
+func (r *AlbumRepository) List(ctx context.Context, q ListQuery) ([]*model.Album, string, error) {
+	field, ok := albumSortFields[q.Sort]
+	if !ok {
+		return nil, "", ErrUnsupportedSort
 	}
 
-	return albums, nil
+	filter := notDeleted(bson.M{})
+	if q.Genre != "" {
+		filter["genre"] = q.Genre
+	}
+	if q.ArtistID != "" {
+		filter["artistIds"] = q.ArtistID
+	}
+
+	docs, next, err := listPage(ctx, r.collection, filter, q, field)
+	if err != nil {
+		return nil, "", err
+	}
+	albums := make([]*model.Album, 0, len(docs))
+	for _, doc := range docs {
+		var album model.Album
+		if err := bson.Unmarshal(doc, &album); err != nil {
+			return nil, "", err
+		}
+		albums = append(albums, &album)
+	}
+	return albums, next, nil
 }
 
 func (r *AlbumRepository) Update(ctx context.Context, id string, album *model.Album) error {
@@ -86,6 +124,22 @@ func (r *AlbumRepository) Update(ctx context.Context, id string, album *model.Al
 		},
 	}
 
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("album not found")
+	}
+	return nil
+}
+
+// SetImage stores the uploaded image of the album; nil removes it
+func (r *AlbumRepository) SetImage(ctx context.Context, id string, image *model.Image) error {
+	update := bson.M{"$set": bson.M{"image": image, "updatedAt": time.Now()}}
+	if image == nil {
+		update = bson.M{"$unset": bson.M{"image": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	}
 	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 	if err != nil {
 		return err
@@ -105,4 +159,93 @@ func (r *AlbumRepository) Delete(ctx context.Context, id string) error {
 		return errors.New("album not found")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SoftDelete moves the album to the trash, recording who deleted it
+func (r *AlbumRepository) SoftDelete(ctx context.Context, id, deletedBy string) error {
+	return moveToTrash(ctx, r.collection, id, deletedBy, errors.New("album not found"))
+}
+
+// Restore takes the album out of the trash
+func (r *AlbumRepository) Restore(ctx context.Context, id string) error {
+	return restoreFromTrash(ctx, r.collection, id, errors.New("album not found"))
+}
+
+// SoftDeleteByArtist moves the albums an artist has no other artists on to the trash with it and
+// returns their IDs
+func (r *AlbumRepository) SoftDeleteByArtist(ctx context.Context, artistID, deletedBy string) ([]string, error) {
+	return moveToTrashWith(ctx, r.collection, bson.M{"artistIds": bson.A{artistID}}, artistID, deletedBy)
+}
+
+// RestoreTrashedWith takes the albums that went to the trash with an artist out of it
+func (r *AlbumRepository) RestoreTrashedWith(ctx context.Context, artistID string) ([]*model.Album, error) {
+	albums := []*model.Album{}
+	if err := restoreTrashedWith(ctx, r.collection, artistID, &albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+// GetDeleted returns a album in the trash
+func (r *AlbumRepository) GetDeleted(ctx context.Context, id string) (*model.Album, error) {
+	var album model.Album
+	if err := findOneTrashed(ctx, r.collection, id, errors.New("album not found"), &album); err != nil {
+		return nil, err
+	}
+	return &album, nil
+}
+
+// ListDeleted returns the albums in the trash, most recently deleted first; a non-zero before keeps
+// those deleted before it
+func (r *AlbumRepository) ListDeleted(ctx context.Context, before time.Time, limit int64) ([]*model.Album, error) {
+	var albums []*model.Album
+	if err := findTrashed(ctx, r.collection, before, limit, &albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+// ListByArtist returns all albums of an artist, trash included
+func (r *AlbumRepository) ListByArtist(ctx context.Context, artistID string) ([]*model.Album, error) {
+	albums := []*model.Album{}
+	if err := findAll(ctx, r.collection, bson.M{"artistIds": artistID}, nil, &albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+// DeleteMany permanently deletes albums, trash included
+func (r *AlbumRepository) DeleteMany(ctx context.Context, ids []string) error {
+	return deleteByIDs(ctx, r.collection, ids)
+}
+
+// PullArtist removes an artist from albums that keep their other artists
+func (r *AlbumRepository) PullArtist(ctx context.Context, ids []string, artistID string) error {
+	return pullArtist(ctx, r.collection, ids, artistID)
+}
+
+// Put writes back backed-up albums with their original IDs
+func (r *AlbumRepository) Put(ctx context.Context, albums []*model.Album) error {
+	ids := make([]string, len(albums))
+	docs := make([]interface{}, len(albums))
+	for i, album := range albums {
+		ids[i], docs[i] = album.ID, album
+	}
+	return putAll(ctx, r.collection, ids, docs)
+}
+
+// ListAll returns every album that is not in the trash, for the catalog export
+func (r *AlbumRepository) ListAll(ctx context.Context) ([]*model.Album, error) {
+	albums := []*model.Album{}
+	if err := findAllLive(ctx, r.collection, &albums); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
+
+// Insert stores an imported album under the ID it already has
+func (r *AlbumRepository) Insert(ctx context.Context, album *model.Album) error {
+	now := time.Now()
+	album.CreatedAt = now
+	album.UpdatedAt = now
+	return insertWithID(ctx, r.collection, album)
+}
@@ -33,7 +33,7 @@ func (r *ArtistRepository) Create(ctx context.Context, artist *model.Artist) err
 
 func (r *ArtistRepository) GetByID(ctx context.Context, id string) (*model.Artist, error) {
 	var artist model.Artist
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&artist)
+	err := r.collection.FindOne(ctx, notDeleted(bson.M{"_id": id})).Decode(&artist)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("artist not found")
@@ -43,6 +43,28 @@ func (r *ArtistRepository) GetByID(ctx context.Context, id string) (*model.Artis
 	return &artist, nil
 }
 
+// GetByIDs returns the artists with the given IDs that exist, keyed by ID
+func (r *ArtistRepository) GetByIDs(ctx context.Context, ids []string) (map[string]*model.Artist, error) {
+	artists := map[string]*model.Artist{}
+	if len(ids) == 0 {
+		return artists, nil
+	}
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var artist model.Artist
+		if err := cursor.Decode(&artist); err != nil {
+			return nil, err
+		}
+		artists[artist.ID] = &artist
+	}
+	return artists, cursor.Err()
+}
+
 func (r *ArtistRepository) Update(ctx context.Context, id string, artist *model.Artist) error {
 	artist.UpdatedAt = time.Now()
 	
@@ -55,7 +77,7 @@ func (r *ArtistRepository) Update(ctx context.Context, id string, artist *model.
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
 	if err != nil {
 		return err
 	}
@@ -65,19 +87,61 @@ func (r *ArtistRepository) Update(ctx context.Context, id string, artist *model.
 	return nil
 }
 
-func (r *ArtistRepository) GetAll(ctx context.Context) ([]*model.Artist, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, err
+// artistSortFields maps API sort names to artist fields
+var artistSortFields = map[string]string{
+	"name":      "name",
+	"createdAt": "createdAt",
+}
+
+// EnsureIndexes creates the indexes used by the artist list and the trash
+func (r *ArtistRepository) EnsureIndexes(ctx context.Context) error {
+	if err := listIndexes(ctx, r.collection, []string{"name", "createdAt"}, []string{"genres"}); err != nil {
+		return err
 	}
-	defer cursor.Close(ctx)
+	return trashIndex(ctx, r.collection)
+}
 
-	var artists []*model.Artist
-	if err = cursor.All(ctx, &artists); err != nil {
-		return nil, err
+// List returns one page of artists filtered by genre, and the cursor of the next page
+func (r *ArtistRepository) List(ctx context.Context, q ListQuery) ([]*model.Artist, string, error) {
+	field, ok := artistSortFields[q.Sort]
+	if !ok {
+		return nil, "", ErrUnsupportedSort
 	}
 
-	return artists, nil
+	filter := notDeleted(bson.M{})
+	if q.Genre != "" {
+		filter["genres"] = q.Genre
+	}
+
+	docs, next, err := listPage(ctx, r.collection, filter, q, field)
+	if err != nil {
+		return nil, "", err
+	}
+	artists := make([]*model.Artist, 0, len(docs))
+	for _, doc := range docs {
+		var artist model.Artist
+		if err := bson.Unmarshal(doc, &artist); err != nil {
+			return nil, "", err
+		}
+		artists = append(artists, &artist)
+	}
+	return artists, next, nil
+}
+
+// SetImage stores the uploaded image of the artist; nil removes it
+func (r *ArtistRepository) SetImage(ctx context.Context, id string, image *model.Image) error {
+	update := bson.M{"$set": bson.M{"image": image, "updatedAt": time.Now()}}
+	if image == nil {
+		update = bson.M{"$unset": bson.M{"image": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("artist not found")
+	}
+	return nil
 }
 
 func (r *ArtistRepository) Delete(ctx context.Context, id string) error {
@@ -89,4 +153,54 @@ func (r *ArtistRepository) Delete(ctx context.Context, id string) error {
 		return errors.New("artist not found")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SoftDelete moves the artist to the trash, recording who deleted it
+func (r *ArtistRepository) SoftDelete(ctx context.Context, id, deletedBy string) error {
+	return moveToTrash(ctx, r.collection, id, deletedBy, errors.New("artist not found"))
+}
+
+// Restore takes the artist out of the trash
+func (r *ArtistRepository) Restore(ctx context.Context, id string) error {
+	return restoreFromTrash(ctx, r.collection, id, errors.New("artist not found"))
+}
+
+// GetDeleted returns a artist in the trash
+func (r *ArtistRepository) GetDeleted(ctx context.Context, id string) (*model.Artist, error) {
+	var artist model.Artist
+	if err := findOneTrashed(ctx, r.collection, id, errors.New("artist not found"), &artist); err != nil {
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// ListDeleted returns the artists in the trash, most recently deleted first; a non-zero before keeps
+// those deleted before it
+func (r *ArtistRepository) ListDeleted(ctx context.Context, before time.Time, limit int64) ([]*model.Artist, error) {
+	var artists []*model.Artist
+	if err := findTrashed(ctx, r.collection, before, limit, &artists); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+// Put writes back a backed-up artist with its original ID
+func (r *ArtistRepository) Put(ctx context.Context, artist *model.Artist) error {
+	return putAll(ctx, r.collection, []string{artist.ID}, []interface{}{artist})
+}
+
+// ListAll returns every artist that is not in the trash, for the catalog export
+func (r *ArtistRepository) ListAll(ctx context.Context) ([]*model.Artist, error) {
+	artists := []*model.Artist{}
+	if err := findAllLive(ctx, r.collection, &artists); err != nil {
+		return nil, err
+	}
+	return artists, nil
+}
+
+// Insert stores an imported artist under the ID it already has
+func (r *ArtistRepository) Insert(ctx context.Context, artist *model.Artist) error {
+	now := time.Now()
+	artist.CreatedAt = now
+	artist.UpdatedAt = now
+	return insertWithID(ctx, r.collection, artist)
+}
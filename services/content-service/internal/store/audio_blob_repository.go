@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+)
+
+// AudioBlobRepository tracks the content-addressed audio files and the songs referencing them
+type AudioBlobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAudioBlobRepository(db *mongo.Database) *AudioBlobRepository {
+	return &AudioBlobRepository{
+		collection: db.Collection("audio_blobs"),
+	}
+}
+
+func (r *AudioBlobRepository) Get(ctx context.Context, sha256 string) (*model.AudioBlob, error) {
+	var blob model.AudioBlob
+	err := r.collection.FindOne(ctx, bson.M{"_id": sha256}).Decode(&blob)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("audio blob not found")
+		}
+		return nil, err
+	}
+	return &blob, nil
+}
+
+// Save records where the file of a referenced blob was stored; the blob keeps its references and
+// becomes ok again
+func (r *AudioBlobRepository) Save(ctx context.Context, blob *model.AudioBlob) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": blob.SHA256}, bson.M{
+		"$set": bson.M{
+			"backend":    blob.Backend,
+			"key":        blob.Key,
+			"size":       blob.Size,
+			"status":     model.BlobStatusOK,
+			"verifiedAt": now,
+		},
+		"$setOnInsert": bson.M{"songIds": []string{}, "refCount": 0, "createdAt": now},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// ErrBlobCollecting is returned when a blob cannot be referenced because its file is being deleted
+var ErrBlobCollecting = errors.New("audio blob is being deleted, try again")
+
+// Reference counts a song as a user of a blob, recording the blob when it is new. Finding and
+// referencing the blob is one atomic update, so the blob cannot be collected in between; referencing
+// the same song twice counts it once. The stored blob is returned with whether the song was added.
+func (r *AudioBlobRepository) Reference(ctx context.Context, blob *model.AudioBlob, songID string) (*model.AudioBlob, bool, error) {
+	now := time.Now()
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"backend":    bson.M{"$ifNull": bson.A{"$backend", blob.Backend}},
+			"key":        bson.M{"$ifNull": bson.A{"$key", blob.Key}},
+			"size":       bson.M{"$ifNull": bson.A{"$size", blob.Size}},
+			"status":     bson.M{"$ifNull": bson.A{"$status", model.BlobStatusOK}},
+			"createdAt":  bson.M{"$ifNull": bson.A{"$createdAt", now}},
+			"songIds":    bson.M{"$setUnion": bson.A{bson.M{"$ifNull": bson.A{"$songIds", bson.A{}}}, bson.A{songID}}},
+			"releasedAt": "$$REMOVE",
+		}}},
+		{{Key: "$set", Value: bson.M{"refCount": bson.M{"$size": "$songIds"}}}},
+	}
+	// A blob being deleted does not match, and the upsert then fails on its ID
+	filter := bson.M{"_id": blob.SHA256, "status": bson.M{"$ne": model.BlobStatusDeleting}}
+
+	var before model.AudioBlob
+	err := r.collection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		created := *blob
+		created.SongIDs = []string{songID}
+		created.RefCount = 1
+		created.Status = model.BlobStatusOK
+		created.CreatedAt = now
+		return &created, true, nil
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, false, ErrBlobCollecting
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	added := true
+	for _, id := range before.SongIDs {
+		if id == songID {
+			added = false
+		}
+	}
+	return &before, added, nil
+}
+
+// ReleaseReference removes a song from the blob's users. The blob is not deleted here: when the last
+// user leaves it records the time, and DeleteUnreferenced collects it after a grace period.
+func (r *AudioBlobRepository) ReleaseReference(ctx context.Context, sha256, songID string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": sha256, "songIds": songID}, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"songIds": bson.M{"$setDifference": bson.A{"$songIds", bson.A{songID}}}}}},
+		{{Key: "$set", Value: bson.M{"refCount": bson.M{"$size": "$songIds"}}}},
+		{{Key: "$set", Value: bson.M{"releasedAt": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$refCount", 0}}, time.Now(), "$$REMOVE",
+		}}}}},
+	})
+	return err
+}
+
+// unreferencedSince matches blobs without users since before cutoff; blobs that never got a user
+// count from their creation
+func unreferencedSince(cutoff time.Time) bson.M {
+	return bson.M{
+		"refCount": bson.M{"$lte": 0},
+		"$or": bson.A{
+			bson.M{"releasedAt": bson.M{"$lt": cutoff}},
+			bson.M{"releasedAt": bson.M{"$exists": false}, "createdAt": bson.M{"$lt": cutoff}},
+		},
+	}
+}
+
+// ListUnreferenced returns blobs that have had no users since before cutoff
+func (r *AudioBlobRepository) ListUnreferenced(ctx context.Context, cutoff time.Time, limit int64) ([]*model.AudioBlob, error) {
+	cursor, err := r.collection.Find(ctx, unreferencedSince(cutoff), options.Find().SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blobs []*model.AudioBlob
+	if err := cursor.All(ctx, &blobs); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// ClaimUnreferenced marks a blob that still has no users as being deleted, after which it cannot be
+// referenced; false when it was referenced again in the meantime
+func (r *AudioBlobRepository) ClaimUnreferenced(ctx context.Context, sha256 string, cutoff time.Time) (bool, error) {
+	filter := unreferencedSince(cutoff)
+	filter["_id"] = sha256
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": model.BlobStatusDeleting}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Remove deletes the record of a claimed blob once its file is deleted
+func (r *AudioBlobRepository) Remove(ctx context.Context, sha256 string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": sha256, "status": model.BlobStatusDeleting})
+	return err
+}
+
+// SetLocation records that a blob was moved to another backend
+func (r *AudioBlobRepository) SetLocation(ctx context.Context, sha256, backend, key string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": sha256}, bson.M{
+		"$set": bson.M{"backend": backend, "key": key},
+	})
+	return err
+}
+
+// SetStatus stores the result of a verification
+func (r *AudioBlobRepository) SetStatus(ctx context.Context, sha256, status string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": sha256, "status": bson.M{"$ne": model.BlobStatusDeleting}}, bson.M{
+		"$set": bson.M{"status": status, "verifiedAt": time.Now()},
+	})
+	return err
+}
+
+// List returns all blobs except those being deleted, for the integrity check
+func (r *AudioBlobRepository) List(ctx context.Context) ([]*model.AudioBlob, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"status": bson.M{"$ne": model.BlobStatusDeleting}}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blobs []*model.AudioBlob
+	for cursor.Next(ctx) {
+		var blob model.AudioBlob
+		if err := cursor.Decode(&blob); err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, &blob)
+	}
+	return blobs, cursor.Err()
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Helpers of the album and artist deletion saga. They see documents in the trash as well: albums and
+// artists are deleted permanently when they are purged from it.
+
+// deleteByIDs permanently deletes the documents with the given IDs
+func deleteByIDs(ctx context.Context, coll *mongo.Collection, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// pullArtist removes an artist from the artistIds of the documents with the given IDs
+func pullArtist(ctx context.Context, coll *mongo.Collection, ids []string, artistID string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := coll.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{
+		"$pull": bson.M{"artistIds": artistID},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	})
+	return err
+}
+
+// putAll writes documents back as they were backed up, replacing the current version or inserting
+// them again; docs[i] has the ID ids[i]
+func putAll(ctx context.Context, coll *mongo.Collection, ids []string, docs []interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for i, doc := range docs {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": ids[i]}).
+			SetReplacement(doc).
+			SetUpsert(true))
+	}
+	_, err := coll.BulkWrite(ctx, models)
+	return err
+}
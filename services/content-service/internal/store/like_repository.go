@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+)
+
+var ErrUnknownLikeType = errors.New("type must be song, album or artist")
+
+type LikeRepository struct {
+	collection *mongo.Collection
+	// Catalog collections whose likeCount is kept in sync, by item type
+	targets map[string]*mongo.Collection
+}
+
+func NewLikeRepository(db *mongo.Database) *LikeRepository {
+	return &LikeRepository{
+		collection: db.Collection("likes"),
+		targets: map[string]*mongo.Collection{
+			model.LikeTypeSong:   db.Collection("songs"),
+			model.LikeTypeAlbum:  db.Collection("albums"),
+			model.LikeTypeArtist: db.Collection("artists"),
+		},
+	}
+}
+
+// EnsureIndexes creates the indexes for listing a library by date added (all items or one type).
+// They use the list collation, otherwise the paginated queries could not use them. The item index
+// finds the likes of deleted items.
+func (r *LikeRepository) EnsureIndexes(ctx context.Context) error {
+	opts := options.Index().SetCollation(listCollation)
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}, Options: opts},
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "itemType", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}, Options: opts},
+		{Keys: bson.D{{Key: "itemType", Value: 1}, {Key: "itemId", Value: 1}}},
+	})
+	return err
+}
+
+// DeleteItems removes permanently deleted items of a type from every library
+func (r *LikeRepository) DeleteItems(ctx context.Context, itemType string, itemIDs []string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	_, err := r.collection.DeleteMany(ctx, bson.M{"itemType": itemType, "itemId": bson.M{"$in": itemIDs}})
+	return err
+}
+
+// Like saves the item to the user's library and increments its like count.
+// It returns false if the item was already liked.
+func (r *LikeRepository) Like(ctx context.Context, userID, itemType, itemID string) (bool, error) {
+	target, ok := r.targets[itemType]
+	if !ok {
+		return false, ErrUnknownLikeType
+	}
+
+	like := &model.Like{
+		ID:        model.LikeID(userID, itemType, itemID),
+		UserID:    userID,
+		ItemType:  itemType,
+		ItemID:    itemID,
+		CreatedAt: time.Now(),
+	}
+	if _, err := r.collection.InsertOne(ctx, like); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	_, err := target.UpdateOne(ctx, bson.M{"_id": itemID}, bson.M{"$inc": bson.M{"likeCount": 1}})
+	return true, err
+}
+
+// Unlike removes the item from the user's library and decrements its like count.
+// It returns false if the item was not liked.
+func (r *LikeRepository) Unlike(ctx context.Context, userID, itemType, itemID string) (bool, error) {
+	target, ok := r.targets[itemType]
+	if !ok {
+		return false, ErrUnknownLikeType
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": model.LikeID(userID, itemType, itemID)})
+	if err != nil {
+		return false, err
+	}
+	if result.DeletedCount == 0 {
+		return false, nil
+	}
+
+	_, err = target.UpdateOne(ctx,
+		bson.M{"_id": itemID, "likeCount": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"likeCount": -1}})
+	return true, err
+}
+
+// LikeCount returns the current like count of the item
+func (r *LikeRepository) LikeCount(ctx context.Context, itemType, itemID string) (int, error) {
+	target, ok := r.targets[itemType]
+	if !ok {
+		return 0, ErrUnknownLikeType
+	}
+	var doc struct {
+		LikeCount int `bson:"likeCount"`
+	}
+	err := target.FindOne(ctx, bson.M{"_id": itemID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	return doc.LikeCount, err
+}
+
+// Exists reports whether the catalog item exists and is not in the trash
+func (r *LikeRepository) Exists(ctx context.Context, itemType, itemID string) (bool, error) {
+	target, ok := r.targets[itemType]
+	if !ok {
+		return false, ErrUnknownLikeType
+	}
+	count, err := target.CountDocuments(ctx, notDeleted(bson.M{"_id": itemID}))
+	return count > 0, err
+}
+
+// Contains returns which of the items the user has liked
+func (r *LikeRepository) Contains(ctx context.Context, userID, itemType string, itemIDs []string) (map[string]bool, error) {
+	if _, ok := r.targets[itemType]; !ok {
+		return nil, ErrUnknownLikeType
+	}
+	ids := make([]string, len(itemIDs))
+	for i, itemID := range itemIDs {
+		ids[i] = model.LikeID(userID, itemType, itemID)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var likes []model.Like
+	if err := cursor.All(ctx, &likes); err != nil {
+		return nil, err
+	}
+
+	liked := make(map[string]bool, len(itemIDs))
+	for _, itemID := range itemIDs {
+		liked[itemID] = false
+	}
+	for _, like := range likes {
+		liked[like.ItemID] = true
+	}
+	return liked, nil
+}
+
+// List returns one page of the user's library, most recently added first.
+// itemType may be empty to list all types.
+func (r *LikeRepository) List(ctx context.Context, userID, itemType string, q ListQuery) ([]*model.Like, string, error) {
+	filter := bson.M{"userId": userID}
+	if itemType != "" {
+		if _, ok := r.targets[itemType]; !ok {
+			return nil, "", ErrUnknownLikeType
+		}
+		filter["itemType"] = itemType
+	}
+
+	q.Sort = "addedAt"
+	q.Desc = true
+	q.Scope = userID + "\x00" + itemType
+	docs, next, err := listPage(ctx, r.collection, filter, q, "createdAt")
+	if err != nil {
+		return nil, "", err
+	}
+	likes := make([]*model.Like, 0, len(docs))
+	for _, doc := range docs {
+		var like model.Like
+		if err := bson.Unmarshal(doc, &like); err != nil {
+			return nil, "", err
+		}
+		likes = append(likes, &like)
+	}
+	return likes, next, nil
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+)
+
+// LyricsRepository stores the lyrics of songs, one document per song and language
+type LyricsRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLyricsRepository(db *mongo.Database) *LyricsRepository {
+	return &LyricsRepository{
+		collection: db.Collection("lyrics"),
+	}
+}
+
+func lyricsID(songID, language string) string {
+	return songID + ":" + language
+}
+
+// EnsureIndexes creates the index used to list the lyrics of a song
+func (r *LyricsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "songId", Value: 1}, {Key: "language", Value: 1}}})
+	return err
+}
+
+// Save creates or replaces the lyrics of a song in a language
+func (r *LyricsRepository) Save(ctx context.Context, lyrics *model.Lyrics) error {
+	lyrics.ID = lyricsID(lyrics.SongID, lyrics.Language)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": lyrics.ID}, lyrics, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *LyricsRepository) Get(ctx context.Context, songID, language string) (*model.Lyrics, error) {
+	var lyrics model.Lyrics
+	err := r.collection.FindOne(ctx, bson.M{"_id": lyricsID(songID, language)}).Decode(&lyrics)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("lyrics not found")
+		}
+		return nil, err
+	}
+	return &lyrics, nil
+}
+
+// ListBySong returns every language of a song's lyrics, ordered by language
+func (r *LyricsRepository) ListBySong(ctx context.Context, songID string) ([]*model.Lyrics, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"songId": songID}, options.Find().SetSort(bson.D{{Key: "language", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	lyrics := []*model.Lyrics{}
+	if err := cursor.All(ctx, &lyrics); err != nil {
+		return nil, err
+	}
+	return lyrics, nil
+}
+
+func (r *LyricsRepository) Delete(ctx context.Context, songID, language string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": lyricsID(songID, language)})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("lyrics not found")
+	}
+	return nil
+}
+
+// DeleteBySong deletes the lyrics of a deleted song
+func (r *LyricsRepository) DeleteBySong(ctx context.Context, songID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"songId": songID})
+	return err
+}
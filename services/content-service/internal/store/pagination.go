@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+var (
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrCursorMismatch  = errors.New("cursor was issued for a different sort or filter")
+	ErrUnsupportedSort = errors.New("unsupported sort")
+)
+
+// listCollation makes name sorting case-insensitive; list queries and their indexes must use the same collation
+var listCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// ListQuery describes one page of a catalog list.
+// Sort is the API sort name (e.g. "name", "createdAt"), the repository maps it to a document field.
+type ListQuery struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	Desc   bool
+
+	Genre    string
+	ArtistID string
+	AlbumID  string
+	// Scope identifies filters set by the server rather than the client (e.g. whose library is listed)
+	Scope string
+}
+
+// pageCursor is the position after the last returned document, encoded as opaque base64 JSON
+type pageCursor struct {
+	Sort    string      `json:"s"`
+	Desc    bool        `json:"d,omitempty"`
+	Filters string      `json:"f"`
+	Kind    string      `json:"k"` // s = string, t = time (unix ms), n = number, z = null
+	Value   interface{} `json:"v,omitempty"`
+	ID      string      `json:"id"`
+}
+
+// filterKey identifies the filters so a cursor cannot be reused with different ones
+func (q ListQuery) filterKey() string {
+	key := q.Genre + "\x00" + q.ArtistID + "\x00" + q.AlbumID
+	if q.Scope != "" {
+		key += "\x00" + q.Scope
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:6])
+}
+
+func (q ListQuery) pageSize() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultPageSize
+	case q.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return q.Limit
+	}
+}
+
+func encodeCursor(q ListQuery, field string, last bson.Raw) (string, error) {
+	c := pageCursor{Sort: q.Sort, Desc: q.Desc, Filters: q.filterKey()}
+
+	id, ok := last.Lookup("_id").StringValueOK()
+	if !ok {
+		return "", ErrInvalidCursor
+	}
+	c.ID = id
+
+	value, err := last.LookupErr(field)
+	switch {
+	case err != nil || value.Type == bsontype.Null:
+		c.Kind = "z"
+	case value.Type == bsontype.String:
+		c.Kind, c.Value = "s", value.StringValue()
+	case value.Type == bsontype.DateTime:
+		c.Kind, c.Value = "t", value.DateTime()
+	case value.Type == bsontype.Double:
+		c.Kind, c.Value = "n", value.Double()
+	case value.Type == bsontype.Int32:
+		c.Kind, c.Value = "n", float64(value.Int32())
+	case value.Type == bsontype.Int64:
+		c.Kind, c.Value = "n", float64(value.Int64())
+	default:
+		return "", ErrInvalidCursor
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// afterCursor returns the filter selecting documents after the cursor position (keyset pagination)
+func afterCursor(q ListQuery, field string) (bson.M, error) {
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != q.Sort || c.Desc != q.Desc || c.Filters != q.filterKey() {
+		return nil, ErrCursorMismatch
+	}
+
+	var value interface{}
+	switch c.Kind {
+	case "s":
+		s, ok := c.Value.(string)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		value = s
+	case "t":
+		ms, ok := c.Value.(float64)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		value = primitive.NewDateTimeFromTime(time.UnixMilli(int64(ms)))
+	case "n":
+		n, ok := c.Value.(float64)
+		if !ok {
+			return nil, ErrInvalidCursor
+		}
+		value = n
+	case "z":
+		value = nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+
+	cmp := "$gt"
+	if q.Desc {
+		cmp = "$lt"
+	}
+	sameValue := bson.M{field: value, "_id": bson.M{cmp: c.ID}}
+
+	// Nulls sort first: after a null, ascending continues with all non-null values
+	if value == nil {
+		if q.Desc {
+			return sameValue, nil
+		}
+		return bson.M{"$or": bson.A{sameValue, bson.M{field: bson.M{"$ne": nil}}}}, nil
+	}
+	return bson.M{"$or": bson.A{bson.M{field: bson.M{cmp: value}}, sameValue}}, nil
+}
+
+// listPage runs a keyset-paginated query sorted by field and _id.
+// It returns the raw documents of the page and the cursor for the next page.
+func listPage(ctx context.Context, coll *mongo.Collection, filter bson.M, q ListQuery, field string) ([]bson.Raw, string, error) {
+	if q.Cursor != "" {
+		after, err := afterCursor(q, field)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(filter) == 0 {
+			filter = after
+		} else {
+			filter = bson.M{"$and": bson.A{filter, after}}
+		}
+	}
+
+	direction := 1
+	if q.Desc {
+		direction = -1
+	}
+	size := q.pageSize()
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(size + 1)).
+		SetCollation(listCollation)
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(docs) <= size {
+		return docs, "", nil
+	}
+	docs = docs[:size]
+	next, err := encodeCursor(q, field, docs[size-1])
+	if err != nil {
+		return nil, "", err
+	}
+	return docs, next, nil
+}
+
+// listIndexes creates the sort indexes ({field: 1, _id: 1}) and single field filter indexes for a list endpoint
+func listIndexes(ctx context.Context, coll *mongo.Collection, sortFields []string, filterFields []string) error {
+	var models []mongo.IndexModel
+	for _, f := range sortFields {
+		models = append(models, mongo.IndexModel{
+			Keys:    bson.D{{Key: f, Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetCollation(listCollation),
+		})
+	}
+	for _, f := range filterFields {
+		models = append(models, mongo.IndexModel{
+			Keys:    bson.D{{Key: f, Value: 1}},
+			Options: options.Index().SetCollation(listCollation),
+		})
+	}
+	_, err := coll.Indexes().CreateMany(ctx, models)
+	return err
+}
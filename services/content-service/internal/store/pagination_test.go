@@ -0,0 +1,181 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rawDoc(t *testing.T, doc bson.D) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+	return bson.Raw(data)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	releasedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query ListQuery
+		field string
+		last  bson.D
+		want  bson.M
+	}{
+		{
+			name:  "string ascending",
+			query: ListQuery{Sort: "name"},
+			field: "name",
+			last:  bson.D{{Key: "_id", Value: "a1"}, {Key: "name", Value: "Abbey Road"}},
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": bson.M{"$gt": "Abbey Road"}},
+				bson.M{"name": "Abbey Road", "_id": bson.M{"$gt": "a1"}},
+			}},
+		},
+		{
+			name:  "string descending",
+			query: ListQuery{Sort: "name", Desc: true},
+			field: "name",
+			last:  bson.D{{Key: "_id", Value: "a1"}, {Key: "name", Value: "Abbey Road"}},
+			want: bson.M{"$or": bson.A{
+				bson.M{"name": bson.M{"$lt": "Abbey Road"}},
+				bson.M{"name": "Abbey Road", "_id": bson.M{"$lt": "a1"}},
+			}},
+		},
+		{
+			name:  "time",
+			query: ListQuery{Sort: "releaseDate"},
+			field: "releaseDate",
+			last:  bson.D{{Key: "_id", Value: "a2"}, {Key: "releaseDate", Value: releasedAt}},
+			want: bson.M{"$or": bson.A{
+				bson.M{"releaseDate": bson.M{"$gt": primitive.NewDateTimeFromTime(releasedAt)}},
+				bson.M{"releaseDate": primitive.NewDateTimeFromTime(releasedAt), "_id": bson.M{"$gt": "a2"}},
+			}},
+		},
+		{
+			name:  "number",
+			query: ListQuery{Sort: "rating", Desc: true},
+			field: "averageRating",
+			last:  bson.D{{Key: "_id", Value: "s1"}, {Key: "averageRating", Value: int32(4)}},
+			want: bson.M{"$or": bson.A{
+				bson.M{"averageRating": bson.M{"$lt": float64(4)}},
+				bson.M{"averageRating": float64(4), "_id": bson.M{"$lt": "s1"}},
+			}},
+		},
+		{
+			name:  "missing field ascending continues with non-null values",
+			query: ListQuery{Sort: "releaseDate"},
+			field: "releaseDate",
+			last:  bson.D{{Key: "_id", Value: "a3"}},
+			want: bson.M{"$or": bson.A{
+				bson.M{"releaseDate": nil, "_id": bson.M{"$gt": "a3"}},
+				bson.M{"releaseDate": bson.M{"$ne": nil}},
+			}},
+		},
+		{
+			name:  "null descending stays within nulls",
+			query: ListQuery{Sort: "releaseDate", Desc: true},
+			field: "releaseDate",
+			last:  bson.D{{Key: "_id", Value: "a3"}, {Key: "releaseDate", Value: nil}},
+			want:  bson.M{"releaseDate": nil, "_id": bson.M{"$lt": "a3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := encodeCursor(tt.query, tt.field, rawDoc(t, tt.last))
+			if err != nil {
+				t.Fatalf("encodeCursor: %v", err)
+			}
+
+			q := tt.query
+			q.Cursor = cursor
+			got, err := afterCursor(q, tt.field)
+			if err != nil {
+				t.Fatalf("afterCursor: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("afterCursor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCursorRejectsUnsupportedDocuments(t *testing.T) {
+	tests := []struct {
+		name string
+		last bson.D
+	}{
+		{"missing id", bson.D{{Key: "name", Value: "Abbey Road"}}},
+		{"non-string id", bson.D{{Key: "_id", Value: int32(1)}, {Key: "name", Value: "Abbey Road"}}},
+		{"unsupported value type", bson.D{{Key: "_id", Value: "a1"}, {Key: "name", Value: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encodeCursor(ListQuery{Sort: "name"}, "name", rawDoc(t, tt.last)); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("encodeCursor error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestAfterCursorRejectsInvalidCursors(t *testing.T) {
+	issued := ListQuery{Sort: "name", Genre: "rock", Scope: "user-1"}
+	cursor, err := encodeCursor(issued, "name", rawDoc(t, bson.D{{Key: "_id", Value: "a1"}, {Key: "name", Value: "Abbey Road"}}))
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		query   ListQuery
+		wantErr error
+	}{
+		{"not base64", ListQuery{Sort: "name", Cursor: "!!!"}, ErrInvalidCursor},
+		{"not json", ListQuery{Sort: "name", Cursor: encode("cursor")}, ErrInvalidCursor},
+		{"missing id", ListQuery{Sort: "name", Cursor: encode(`{"s":"name","k":"s","v":"x"}`)}, ErrInvalidCursor},
+		{"unknown kind", ListQuery{Sort: "name", Cursor: encode(`{"s":"name","f":"` + ListQuery{Sort: "name"}.filterKey() + `","k":"x","id":"a1"}`)}, ErrInvalidCursor},
+		{"value of wrong kind", ListQuery{Sort: "name", Cursor: encode(`{"s":"name","f":"` + ListQuery{Sort: "name"}.filterKey() + `","k":"t","v":"x","id":"a1"}`)}, ErrInvalidCursor},
+		{"different sort", ListQuery{Sort: "createdAt", Genre: "rock", Scope: "user-1", Cursor: cursor}, ErrCursorMismatch},
+		{"different direction", ListQuery{Sort: "name", Desc: true, Genre: "rock", Scope: "user-1", Cursor: cursor}, ErrCursorMismatch},
+		{"different filter", ListQuery{Sort: "name", Genre: "jazz", Scope: "user-1", Cursor: cursor}, ErrCursorMismatch},
+		{"different scope", ListQuery{Sort: "name", Genre: "rock", Scope: "user-2", Cursor: cursor}, ErrCursorMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := afterCursor(tt.query, "name"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("afterCursor error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, DefaultPageSize},
+		{-1, DefaultPageSize},
+		{10, 10},
+		{MaxPageSize, MaxPageSize},
+		{MaxPageSize + 1, MaxPageSize},
+	}
+
+	for _, tt := range tests {
+		if got := (ListQuery{Limit: tt.limit}).pageSize(); got != tt.want {
+			t.Errorf("pageSize(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
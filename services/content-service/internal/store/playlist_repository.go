@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+)
+
+var (
+	ErrPlaylistNotFound = errors.New("playlist not found")
+	// ErrPlaylistConflict is returned when the playlist kept changing while an update was retried
+	ErrPlaylistConflict = errors.New("playlist was modified concurrently, try again")
+)
+
+// modifyRetries is how often a read-modify-write is retried after a concurrent change
+const modifyRetries = 5
+
+type PlaylistRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPlaylistRepository(db *mongo.Database) *PlaylistRepository {
+	return &PlaylistRepository{
+		collection: db.Collection("playlists"),
+	}
+}
+
+// EnsureIndexes creates the indexes for listing a user's playlists, public playlists,
+// and finding the playlists that contain a song
+func (r *PlaylistRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "ownerId", Value: 1}, {Key: "updatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "editorIds", Value: 1}}},
+		{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "updatedAt", Value: -1}}},
+		{Keys: bson.D{{Key: "entries.songId", Value: 1}}},
+	})
+	return err
+}
+
+func (r *PlaylistRepository) Create(ctx context.Context, playlist *model.Playlist) error {
+	playlist.ID = uuid.NewString()
+	now := time.Now()
+	playlist.CreatedAt = now
+	playlist.UpdatedAt = now
+	playlist.Version = 1
+	if playlist.EditorIDs == nil {
+		playlist.EditorIDs = []string{}
+	}
+	if playlist.Entries == nil {
+		playlist.Entries = []model.PlaylistEntry{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, playlist)
+	return err
+}
+
+func (r *PlaylistRepository) GetByID(ctx context.Context, id string) (*model.Playlist, error) {
+	var playlist model.Playlist
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&playlist)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrPlaylistNotFound
+		}
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// ListForUser returns the playlists the user owns or was invited to edit, most recently changed first
+func (r *PlaylistRepository) ListForUser(ctx context.Context, userID string) ([]*model.Playlist, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"ownerId": userID},
+		bson.M{"editorIds": userID},
+	}}
+	return r.find(ctx, filter, 0)
+}
+
+// ListPublic returns public playlists, optionally of one owner, most recently changed first
+func (r *PlaylistRepository) ListPublic(ctx context.Context, ownerID string, limit int) ([]*model.Playlist, error) {
+	filter := bson.M{"visibility": model.PlaylistPublic}
+	if ownerID != "" {
+		filter["ownerId"] = ownerID
+	}
+	return r.find(ctx, filter, limit)
+}
+
+func (r *PlaylistRepository) find(ctx context.Context, filter bson.M, limit int) ([]*model.Playlist, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	playlists := []*model.Playlist{}
+	if err := cursor.All(ctx, &playlists); err != nil {
+		return nil, err
+	}
+	return playlists, nil
+}
+
+// Modify applies change to the current playlist and stores the result.
+// The update only succeeds if nobody changed the playlist in between, otherwise it is retried
+// on a fresh copy, so concurrent edits (e.g. two collaborators adding songs) are never lost.
+// An error returned by change aborts the update and is returned as is.
+func (r *PlaylistRepository) Modify(ctx context.Context, id string, change func(*model.Playlist) error) (*model.Playlist, error) {
+	for attempt := 0; attempt < modifyRetries; attempt++ {
+		playlist, err := r.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := change(playlist); err != nil {
+			return nil, err
+		}
+
+		version := playlist.Version
+		playlist.Version++
+		playlist.UpdatedAt = time.Now()
+		result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id, "version": version}, playlist)
+		if err != nil {
+			return nil, err
+		}
+		if result.MatchedCount == 1 {
+			return playlist, nil
+		}
+	}
+	return nil, ErrPlaylistConflict
+}
+
+// Delete deletes the playlist if it belongs to the owner
+func (r *PlaylistRepository) Delete(ctx context.Context, id, ownerID string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "ownerId": ownerID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrPlaylistNotFound
+	}
+	return nil
+}
+
+// RemoveSongs removes every entry of the songs from all playlists (song, album and artist deletion
+// sagas) and returns the removed entries so they can be restored
+func (r *PlaylistRepository) RemoveSongs(ctx context.Context, songIDs []string) ([]model.RemovedPlaylistEntry, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"entries.songId": bson.M{"$in": songIDs}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var ids []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &ids); err != nil {
+		return nil, err
+	}
+
+	removed := []model.RemovedPlaylistEntry{}
+	for _, doc := range ids {
+		var fromPlaylist []model.PlaylistEntry
+		_, err := r.Modify(ctx, doc.ID, func(p *model.Playlist) error {
+			fromPlaylist = p.RemoveSongs(songIDs)
+			return nil
+		})
+		if err == ErrPlaylistNotFound {
+			continue
+		}
+		if err != nil {
+			// Leave the playlists as they were, the saga does not compensate a failed step
+			if restoreErr := r.RestoreEntries(ctx, removed); restoreErr != nil {
+				return nil, errors.Join(err, restoreErr)
+			}
+			return nil, err
+		}
+		for _, e := range fromPlaylist {
+			removed = append(removed, model.RemovedPlaylistEntry{PlaylistID: doc.ID, Entry: e})
+		}
+	}
+	return removed, nil
+}
+
+// RestoreEntries puts removed entries back at their original positions.
+// Entries that are already present and playlists deleted in the meantime are skipped.
+func (r *PlaylistRepository) RestoreEntries(ctx context.Context, entries []model.RemovedPlaylistEntry) error {
+	byPlaylist := map[string][]model.PlaylistEntry{}
+	var order []string
+	for _, e := range entries {
+		if _, ok := byPlaylist[e.PlaylistID]; !ok {
+			order = append(order, e.PlaylistID)
+		}
+		byPlaylist[e.PlaylistID] = append(byPlaylist[e.PlaylistID], e.Entry)
+	}
+
+	for _, playlistID := range order {
+		_, err := r.Modify(ctx, playlistID, func(p *model.Playlist) error {
+			// Removed entries are in position order, inserting them in that order restores the positions
+			for _, entry := range byPlaylist[playlistID] {
+				if !p.HasEntry(entry.ID) {
+					p.InsertEntry(entry, entry.Position)
+				}
+			}
+			return nil
+		})
+		if err != nil && err != ErrPlaylistNotFound {
+			return err
+		}
+	}
+	return nil
+}
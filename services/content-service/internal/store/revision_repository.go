@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+)
+
+// revisionInsertAttempts bounds the retries when concurrent edits take the same revision number
+const revisionInsertAttempts = 5
+
+// RevisionRepository stores the revision history of songs, albums and artists. It only inserts:
+// revisions are immutable.
+type RevisionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRevisionRepository(db *mongo.Database) *RevisionRepository {
+	return &RevisionRepository{
+		collection: db.Collection("revisions"),
+	}
+}
+
+// EnsureIndexes creates the unique index that numbers the revisions of an entity
+func (r *RevisionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "entityType", Value: 1}, {Key: "entityId", Value: 1}, {Key: "number", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Add stores a revision as the next one of its entity, setting its ID, number and time
+func (r *RevisionRepository) Add(ctx context.Context, revision *model.Revision) error {
+	var err error
+	for attempt := 0; attempt < revisionInsertAttempts; attempt++ {
+		var last model.Revision
+		number := 1
+		findErr := r.collection.FindOne(ctx,
+			bson.M{"entityType": revision.EntityType, "entityId": revision.EntityID},
+			options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}}).SetProjection(bson.M{"number": 1}),
+		).Decode(&last)
+		if findErr == nil {
+			number = last.Number + 1
+		} else if !errors.Is(findErr, mongo.ErrNoDocuments) {
+			return findErr
+		}
+
+		revision.ID = uuid.NewString()
+		revision.Number = number
+		revision.CreatedAt = time.Now()
+		_, err = r.collection.InsertOne(ctx, revision)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// List returns the revisions of an entity, newest first; a non-zero before keeps the revisions
+// numbered below it
+func (r *RevisionRepository) List(ctx context.Context, entityType, entityID string, before int, limit int64) ([]*model.Revision, error) {
+	filter := bson.M{"entityType": entityType, "entityId": entityID}
+	if before > 0 {
+		filter["number"] = bson.M{"$lt": before}
+	}
+	revisions := []*model.Revision{}
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}}).SetLimit(limit)
+	if err := findAll(ctx, r.collection, filter, opts, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// Get returns one revision of an entity
+func (r *RevisionRepository) Get(ctx context.Context, entityType, entityID string, number int) (*model.Revision, error) {
+	var revision model.Revision
+	err := r.collection.FindOne(ctx, bson.M{"entityType": entityType, "entityId": entityID, "number": number}).Decode(&revision)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("revision not found")
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,301 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"content-service/internal/model"
+	"content-service/internal/search"
+)
+
+const (
+	// searchCandidateLimit caps the candidates taken from each query per collection
+	searchCandidateLimit = 200
+	// fuzzyScanLimit caps the names scanned for typo matching when the index finds too little
+	fuzzyScanLimit = 5000
+	// fuzzyThreshold is the number of indexed matches below which typo matching kicks in
+	fuzzyThreshold = 20
+)
+
+// SearchFilter narrows catalog search results
+type SearchFilter struct {
+	Genre    string
+	YearFrom int // album release year, inclusive (0 = unbounded)
+	YearTo   int
+	ArtistID string
+}
+
+func (f SearchFilter) hasYears() bool {
+	return f.YearFrom > 0 || f.YearTo > 0
+}
+
+// SearchRepository runs catalog search over songs, albums and artists
+type SearchRepository struct {
+	songs   *mongo.Collection
+	albums  *mongo.Collection
+	artists *mongo.Collection
+}
+
+func NewSearchRepository(db *mongo.Database) *SearchRepository {
+	return &SearchRepository{
+		songs:   db.Collection("songs"),
+		albums:  db.Collection("albums"),
+		artists: db.Collection("artists"),
+	}
+}
+
+// EnsureIndexes creates the text indexes used by search.
+// Language "none" disables stemming and stop words, names are matched as written.
+func (r *SearchRepository) EnsureIndexes(ctx context.Context) error {
+	textIndex := func(name string, keys bson.D, weights bson.M) mongo.IndexModel {
+		opts := options.Index().SetName(name).SetDefaultLanguage("none")
+		if weights != nil {
+			opts.SetWeights(weights)
+		}
+		return mongo.IndexModel{Keys: keys, Options: opts}
+	}
+
+	// Lyrics let users find a song by a line; a name match weighs much more
+	songsIndex := textIndex("songs_text",
+		bson.D{{Key: "name", Value: "text"}, {Key: "lyricsText", Value: "text"}},
+		bson.M{"name": 10, "lyricsText": 1})
+	if err := ensureTextIndex(ctx, r.songs, songsIndex); err != nil {
+		return err
+	}
+	if _, err := r.albums.Indexes().CreateOne(ctx, textIndex("albums_text", bson.D{{Key: "name", Value: "text"}}, nil)); err != nil {
+		return err
+	}
+	_, err := r.artists.Indexes().CreateOne(ctx, textIndex("artists_text",
+		bson.D{{Key: "name", Value: "text"}, {Key: "biography", Value: "text"}},
+		bson.M{"name": 10, "biography": 2}))
+	return err
+}
+
+// ensureTextIndex creates a text index. A collection has at most one text index, so one created with
+// other fields or weights (by an older version) is dropped and created again.
+func ensureTextIndex(ctx context.Context, coll *mongo.Collection, index mongo.IndexModel) error {
+	_, err := coll.Indexes().CreateOne(ctx, index)
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) || (cmdErr.Name != "IndexOptionsConflict" && cmdErr.Name != "IndexKeySpecsConflict") {
+		return err
+	}
+	if _, err := coll.Indexes().DropOne(ctx, *index.Options.Name); err != nil {
+		return err
+	}
+	_, err = coll.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// SearchSongs returns matching songs and the text index score per song ID
+func (r *SearchRepository) SearchSongs(ctx context.Context, query string, m *search.Matcher, f SearchFilter) ([]*model.Song, map[string]float64, error) {
+	filter := bson.M{}
+	if f.Genre != "" {
+		filter["genre"] = equalFold(f.Genre)
+	}
+	if f.ArtistID != "" {
+		filter["artistIds"] = f.ArtistID
+	}
+	if f.hasYears() {
+		albumIDs, err := r.albums.Distinct(ctx, "_id", notDeleted(releaseYearFilter(f)))
+		if err != nil {
+			return nil, nil, err
+		}
+		filter["albumId"] = bson.M{"$in": albumIDs}
+	}
+
+	scores, err := r.candidates(ctx, r.songs, query, m, filter)
+	if err != nil || len(scores) == 0 {
+		return nil, scores, err
+	}
+
+	var songs []*model.Song
+	if err := r.findByIDs(ctx, r.songs, scores, &songs); err != nil {
+		return nil, nil, err
+	}
+	return songs, scores, nil
+}
+
+// SearchAlbums returns matching albums and the text index score per album ID
+func (r *SearchRepository) SearchAlbums(ctx context.Context, query string, m *search.Matcher, f SearchFilter) ([]*model.Album, map[string]float64, error) {
+	filter := bson.M{}
+	if f.Genre != "" {
+		filter["genre"] = equalFold(f.Genre)
+	}
+	if f.ArtistID != "" {
+		filter["artistIds"] = f.ArtistID
+	}
+	if f.hasYears() {
+		filter["releaseDate"] = releaseYearFilter(f)["releaseDate"]
+	}
+
+	scores, err := r.candidates(ctx, r.albums, query, m, filter)
+	if err != nil || len(scores) == 0 {
+		return nil, scores, err
+	}
+
+	var albums []*model.Album
+	if err := r.findByIDs(ctx, r.albums, scores, &albums); err != nil {
+		return nil, nil, err
+	}
+	return albums, scores, nil
+}
+
+// SearchArtists returns matching artists and the text index score per artist ID.
+// The year filter keeps artists with at least one album released in the range.
+func (r *SearchRepository) SearchArtists(ctx context.Context, query string, m *search.Matcher, f SearchFilter) ([]*model.Artist, map[string]float64, error) {
+	filter := bson.M{}
+	if f.Genre != "" {
+		filter["genres"] = equalFold(f.Genre)
+	}
+	if f.hasYears() {
+		artistIDs, err := r.albums.Distinct(ctx, "artistIds", notDeleted(releaseYearFilter(f)))
+		if err != nil {
+			return nil, nil, err
+		}
+		filter["_id"] = bson.M{"$in": artistIDs}
+	}
+	if f.ArtistID != "" {
+		if ids, ok := filter["_id"]; ok {
+			filter["$and"] = bson.A{bson.M{"_id": ids}, bson.M{"_id": f.ArtistID}}
+			delete(filter, "_id")
+		} else {
+			filter["_id"] = f.ArtistID
+		}
+	}
+
+	scores, err := r.candidates(ctx, r.artists, query, m, filter)
+	if err != nil || len(scores) == 0 {
+		return nil, scores, err
+	}
+
+	var artists []*model.Artist
+	if err := r.findByIDs(ctx, r.artists, scores, &artists); err != nil {
+		return nil, nil, err
+	}
+	return artists, scores, nil
+}
+
+// candidates collects matching document IDs from the text index, a word-prefix match on the name
+// and, when those find too little, a typo-tolerant scan of the names.
+// The returned map holds the text index score (0 for prefix and typo matches).
+func (r *SearchRepository) candidates(ctx context.Context, coll *mongo.Collection, query string, m *search.Matcher, filter bson.M) (map[string]float64, error) {
+	scores := map[string]float64{}
+	filter = notDeleted(copyFilter(filter))
+	tokens := m.Tokens()
+	if len(tokens) == 0 {
+		return scores, nil
+	}
+
+	// 1. Whole words through the text index, ranked by text score
+	textFilter := copyFilter(filter)
+	textFilter["$text"] = bson.M{"$search": query}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1, "score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(searchCandidateLimit)
+	var textHits []struct {
+		ID    string  `bson:"_id"`
+		Score float64 `bson:"score"`
+	}
+	if err := findAll(ctx, coll, textFilter, opts, &textHits); err != nil {
+		return nil, err
+	}
+	for _, h := range textHits {
+		scores[h.ID] = h.Score
+	}
+
+	// 2. Every token as the beginning of a word in the name
+	prefixFilter := copyFilter(filter)
+	var prefixes bson.A
+	for _, t := range tokens {
+		prefixes = append(prefixes, bson.M{"name": bson.M{"$regex": `(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(t), "$options": "i"}})
+	}
+	if and, ok := prefixFilter["$and"].(bson.A); ok {
+		prefixes = append(and, prefixes...)
+	}
+	prefixFilter["$and"] = prefixes
+	if err := r.collectNames(ctx, coll, prefixFilter, searchCandidateLimit, nil, scores); err != nil {
+		return nil, err
+	}
+
+	// 3. Typos: scan the names and match them in Go
+	if len(scores) < fuzzyThreshold {
+		if err := r.collectNames(ctx, coll, filter, fuzzyScanLimit, m, scores); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// collectNames adds the IDs of documents matching the filter (and the matcher, if given) to scores
+func (r *SearchRepository) collectNames(ctx context.Context, coll *mongo.Collection, filter bson.M, limit int64, m *search.Matcher, scores map[string]float64) error {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "name": 1}).SetLimit(limit)
+	var docs []struct {
+		ID   string `bson:"_id"`
+		Name string `bson:"name"`
+	}
+	if err := findAll(ctx, coll, filter, opts, &docs); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		if _, seen := scores[d.ID]; seen {
+			continue
+		}
+		if m == nil || m.Matches(d.Name) {
+			scores[d.ID] = 0
+		}
+	}
+	return nil
+}
+
+func (r *SearchRepository) findByIDs(ctx context.Context, coll *mongo.Collection, scores map[string]float64, results interface{}) error {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	return findAll(ctx, coll, notDeleted(bson.M{"_id": bson.M{"$in": ids}}), nil, results)
+}
+
+func findAll(ctx context.Context, coll *mongo.Collection, filter bson.M, opts *options.FindOptions, results interface{}) error {
+	var findOpts []*options.FindOptions
+	if opts != nil {
+		findOpts = append(findOpts, opts)
+	}
+	cursor, err := coll.Find(ctx, filter, findOpts...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, results)
+}
+
+func copyFilter(filter bson.M) bson.M {
+	c := make(bson.M, len(filter)+1)
+	for k, v := range filter {
+		c[k] = v
+	}
+	return c
+}
+
+// equalFold matches a string (or an array element) case-insensitively
+func equalFold(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
+
+// releaseYearFilter matches albums released within the filter's year range
+func releaseYearFilter(f SearchFilter) bson.M {
+	date := bson.M{}
+	if f.YearFrom > 0 {
+		date["$gte"] = time.Date(f.YearFrom, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if f.YearTo > 0 {
+		date["$lt"] = time.Date(f.YearTo+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return bson.M{"releaseDate": date}
+}
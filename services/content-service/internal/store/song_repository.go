@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"content-service/internal/model"
+	"content-service/internal/storage"
 )
 
 type SongRepository struct {
@@ -34,7 +36,7 @@ func (r *SongRepository) Create(ctx context.Context, song *model.Song) error {
 
 func (r *SongRepository) GetByID(ctx context.Context, id string) (*model.Song, error) {
 	var song model.Song
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&song)
+	err := r.collection.FindOne(ctx, notDeleted(bson.M{"_id": id})).Decode(&song)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, errors.New("song not found")
@@ -44,38 +46,186 @@ func (r *SongRepository) GetByID(ctx context.Context, id string) (*model.Song, e
 	return &song, nil
 }
 
-func (r *SongRepository) GetByAlbumID(ctx context.Context, albumID string) ([]*model.Song, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"albumId": albumID})
+// GetByIDs returns the songs with the given IDs that exist, keyed by ID
+func (r *SongRepository) GetByIDs(ctx context.Context, ids []string) (map[string]*model.Song, error) {
+	songs := map[string]*model.Song{}
+	if len(ids) == 0 {
+		return songs, nil
+	}
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": ids}}))
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var songs []*model.Song
-	if err = cursor.All(ctx, &songs); err != nil {
-		return nil, err
+	for cursor.Next(ctx) {
+		var song model.Song
+		if err := cursor.Decode(&song); err != nil {
+			return nil, err
+		}
+		songs[song.ID] = &song
 	}
+	return songs, cursor.Err()
+}
 
-	return songs, nil
+// songSortFields maps API sort names to song fields
+var songSortFields = map[string]string{
+	"name":      "name",
+	"createdAt": "createdAt",
+	"rating":    "averageRating",
 }
 
-func (r *SongRepository) GetAll(ctx context.Context) ([]*model.Song, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+// EnsureIndexes creates the indexes used by the song list and the trash, and sets a zero rating on songs created before ratings were stored
+func (r *SongRepository) EnsureIndexes(ctx context.Context) error {
+	if err := listIndexes(ctx, r.collection, []string{"name", "createdAt", "averageRating"}, []string{"genre", "albumId", "artistIds"}); err != nil {
+		return err
+	}
+	if err := trashIndex(ctx, r.collection); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"averageRating": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"averageRating": 0.0, "ratingCount": 0}})
+	return err
+}
+
+// List returns one page of songs filtered by genre, artist and album, and the cursor of the next page
+func (r *SongRepository) List(ctx context.Context, q ListQuery) ([]*model.Song, string, error) {
+	field, ok := songSortFields[q.Sort]
+	if !ok {
+		return nil, "", ErrUnsupportedSort
+	}
+
+	filter := notDeleted(bson.M{})
+	if q.Genre != "" {
+		filter["genre"] = q.Genre
+	}
+	if q.ArtistID != "" {
+		filter["artistIds"] = q.ArtistID
+	}
+	if q.AlbumID != "" {
+		filter["albumId"] = q.AlbumID
+	}
+
+	docs, next, err := listPage(ctx, r.collection, filter, q, field)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer cursor.Close(ctx)
+	songs := make([]*model.Song, 0, len(docs))
+	for _, doc := range docs {
+		var song model.Song
+		if err := bson.Unmarshal(doc, &song); err != nil {
+			return nil, "", err
+		}
+		songs = append(songs, &song)
+	}
+	return songs, next, nil
+}
 
-	var songs []*model.Song
-	if err = cursor.All(ctx, &songs); err != nil {
-		return nil, err
+// UpdateRating stores the average rating pushed by ratings-service (used for sorting by rating)
+func (r *SongRepository) UpdateRating(ctx context.Context, id string, average float64, count int) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"averageRating": average, "ratingCount": count},
+	})
+	if err != nil {
+		return err
 	}
+	if result.MatchedCount == 0 {
+		return errors.New("song not found")
+	}
+	return nil
+}
 
-	return songs, nil
+// UpdateAudio stores the reference to the uploaded audio file with the metadata read from it
+func (r *SongRepository) UpdateAudio(ctx context.Context, id string, audioFile *model.StorageRef, duration int, audio *model.AudioMetadata) error {
+	set := bson.M{"audioFile": audioFile, "updatedAt": time.Now()}
+	if audio != nil {
+		set["audio"] = audio
+		set["duration"] = duration
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("song not found")
+	}
+	return nil
+}
+
+// SetHLS records where the song's HLS output is stored; nil removes it
+func (r *SongRepository) SetHLS(ctx context.Context, id string, hls *model.StorageRef) error {
+	update := bson.M{"$set": bson.M{"hls": hls}}
+	if hls == nil {
+		update = bson.M{"$unset": bson.M{"hls": ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("song not found")
+	}
+	return nil
+}
+
+// SetLyricsIndex stores the languages with lyrics and their text, which the search index covers
+func (r *SongRepository) SetLyricsIndex(ctx context.Context, id string, languages []string, text string) error {
+	update := bson.M{"$set": bson.M{"lyricsLanguages": languages, "lyricsText": text}}
+	if len(languages) == 0 {
+		update = bson.M{"$unset": bson.M{"lyricsLanguages": "", "lyricsText": ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("song not found")
+	}
+	return nil
+}
+
+// SetStorageRefs points the song's files at their copies in another backend; nil references are kept
+func (r *SongRepository) SetStorageRefs(ctx context.Context, id string, audioFile, hls, cover *model.StorageRef) error {
+	set := bson.M{"updatedAt": time.Now()}
+	if audioFile != nil {
+		set["audioFile"] = audioFile
+	}
+	if hls != nil {
+		set["hls"] = hls
+	}
+	if cover != nil {
+		set["audio.cover"] = cover
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("song not found")
+	}
+	return nil
+}
+
+// MoveAudioBlob points every song using a content-addressed audio file at its new location
+func (r *SongRepository) MoveAudioBlob(ctx context.Context, sha256 string, audioFile *model.StorageRef) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"audioFile.sha256": sha256}, bson.M{
+		"$set": bson.M{"audioFile": audioFile},
+	})
+	return err
+}
+
+// CountUnhashedAudio counts the songs whose uploaded audio predates content-addressed storage
+func (r *SongRepository) CountUnhashedAudio(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{
+		"audioFile":         bson.M{"$exists": true},
+		"audioFile.backend": bson.M{"$ne": storage.BackendExternal},
+		"audioFile.sha256":  bson.M{"$exists": false},
+	})
 }
 
 func (r *SongRepository) Exists(ctx context.Context, id string) (bool, error) {
-	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id})
+	count, err := r.collection.CountDocuments(ctx, notDeleted(bson.M{"_id": id}))
 	if err != nil {
 		return false, err
 	}
@@ -92,12 +242,12 @@ func (r *SongRepository) Update(ctx context.Context, id string, song *model.Song
 			"genre":        song.Genre,
 			"albumId":      song.AlbumID,
 			"artistIds":    song.ArtistIDs,
-			"audioFileUrl": song.AudioFileURL,
+			"audioFile":    song.AudioFile,
 			"updatedAt":    song.UpdatedAt,
 		},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
 	if err != nil {
 		return err
 	}
@@ -116,4 +266,166 @@ func (r *SongRepository) Delete(ctx context.Context, id string) error {
 		return errors.New("song not found")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SoftDelete moves the song to the trash, recording who deleted it
+func (r *SongRepository) SoftDelete(ctx context.Context, id, deletedBy string) error {
+	return moveToTrash(ctx, r.collection, id, deletedBy, errors.New("song not found"))
+}
+
+// Restore takes the song out of the trash
+func (r *SongRepository) Restore(ctx context.Context, id string) error {
+	return restoreFromTrash(ctx, r.collection, id, errors.New("song not found"))
+}
+
+// SoftDeleteByAlbum moves the songs of an album to the trash with it and returns their IDs
+func (r *SongRepository) SoftDeleteByAlbum(ctx context.Context, albumID, deletedBy string) ([]string, error) {
+	return moveToTrashWith(ctx, r.collection, bson.M{"albumId": albumID}, albumID, deletedBy)
+}
+
+// SoftDeleteByArtist moves to the trash with an artist the songs of its albums that went with it and
+// the songs it has no other artists on, and returns their IDs
+func (r *SongRepository) SoftDeleteByArtist(ctx context.Context, artistID string, albumIDs []string, deletedBy string) ([]string, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"albumId": bson.M{"$in": albumIDs}},
+		bson.M{"artistIds": bson.A{artistID}},
+	}}
+	return moveToTrashWith(ctx, r.collection, filter, artistID, deletedBy)
+}
+
+// RestoreTrashedWith takes the songs that went to the trash with an album or artist out of it
+func (r *SongRepository) RestoreTrashedWith(ctx context.Context, parentID string) ([]*model.Song, error) {
+	songs := []*model.Song{}
+	if err := restoreTrashedWith(ctx, r.collection, parentID, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// GetDeleted returns a song in the trash
+func (r *SongRepository) GetDeleted(ctx context.Context, id string) (*model.Song, error) {
+	var song model.Song
+	if err := findOneTrashed(ctx, r.collection, id, errors.New("song not found"), &song); err != nil {
+		return nil, err
+	}
+	return &song, nil
+}
+
+// ListDeleted returns the songs in the trash, most recently deleted first; a non-zero before keeps
+// those deleted before it
+func (r *SongRepository) ListDeleted(ctx context.Context, before time.Time, limit int64) ([]*model.Song, error) {
+	var songs []*model.Song
+	if err := findTrashed(ctx, r.collection, before, limit, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+// MigrateAudioFileURLs replaces the audioFileUrl paths of songs stored before storage references
+// (and the HDFS paths of their HLS output and cover) with references. Songs already migrated are
+// not matched, so it is safe to run on every start.
+func (r *SongRepository) MigrateAudioFileURLs(ctx context.Context) (int, error) {
+	filter := bson.M{"$or": []bson.M{
+		{"audioFileUrl": bson.M{"$exists": true}},
+		{"hlsPath": bson.M{"$exists": true}},
+		{"audio.coverPath": bson.M{"$exists": true}},
+	}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	migrated := 0
+	for cursor.Next(ctx) {
+		var legacy struct {
+			ID           string `bson:"_id"`
+			AudioFileURL string `bson:"audioFileUrl"`
+			HLSPath      string `bson:"hlsPath"`
+			Audio        *struct {
+				CoverPath string `bson:"coverPath"`
+			} `bson:"audio"`
+		}
+		if err := cursor.Decode(&legacy); err != nil {
+			return migrated, err
+		}
+
+		set := bson.M{}
+		if backend, key := storage.ParseLegacyURL(legacy.AudioFileURL); backend != "" {
+			set["audioFile"] = &model.StorageRef{Backend: backend, Key: key}
+		}
+		// HLS output and covers were only ever written to HDFS
+		if legacy.HLSPath != "" {
+			set["hls"] = &model.StorageRef{Backend: storage.BackendHDFS, Key: strings.TrimPrefix(legacy.HLSPath, "/")}
+		}
+		if legacy.Audio != nil && legacy.Audio.CoverPath != "" {
+			set["audio.cover"] = &model.StorageRef{Backend: storage.BackendHDFS, Key: strings.TrimPrefix(legacy.Audio.CoverPath, "/")}
+		}
+		update := bson.M{"$unset": bson.M{"audioFileUrl": "", "hlsPath": "", "audio.coverPath": ""}}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": legacy.ID}, update); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, cursor.Err()
+}
+
+// ListByAlbums returns all songs of the albums, trash included
+func (r *SongRepository) ListByAlbums(ctx context.Context, albumIDs []string) ([]*model.Song, error) {
+	songs := []*model.Song{}
+	if len(albumIDs) == 0 {
+		return songs, nil
+	}
+	if err := findAll(ctx, r.collection, bson.M{"albumId": bson.M{"$in": albumIDs}}, nil, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// ListByArtist returns all songs of an artist, trash included
+func (r *SongRepository) ListByArtist(ctx context.Context, artistID string) ([]*model.Song, error) {
+	songs := []*model.Song{}
+	if err := findAll(ctx, r.collection, bson.M{"artistIds": artistID}, nil, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// DeleteMany permanently deletes songs, trash included
+func (r *SongRepository) DeleteMany(ctx context.Context, ids []string) error {
+	return deleteByIDs(ctx, r.collection, ids)
+}
+
+// PullArtist removes an artist from songs that keep their other artists
+func (r *SongRepository) PullArtist(ctx context.Context, ids []string, artistID string) error {
+	return pullArtist(ctx, r.collection, ids, artistID)
+}
+
+// Put writes back backed-up songs with their original IDs
+func (r *SongRepository) Put(ctx context.Context, songs []*model.Song) error {
+	ids := make([]string, len(songs))
+	docs := make([]interface{}, len(songs))
+	for i, song := range songs {
+		ids[i], docs[i] = song.ID, song
+	}
+	return putAll(ctx, r.collection, ids, docs)
+}
+
+// ListAll returns every song that is not in the trash, for the catalog export
+func (r *SongRepository) ListAll(ctx context.Context) ([]*model.Song, error) {
+	songs := []*model.Song{}
+	if err := findAllLive(ctx, r.collection, &songs); err != nil {
+		return nil, err
+	}
+	return songs, nil
+}
+
+// Insert stores an imported song under the ID it already has
+func (r *SongRepository) Insert(ctx context.Context, song *model.Song) error {
+	now := time.Now()
+	song.CreatedAt = now
+	song.UpdatedAt = now
+	return insertWithID(ctx, r.collection, song)
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Helpers of the catalog import and export. Items in the trash are neither exported nor matched by
+// an import.
+
+// findAllLive reads every document that is not in the trash, ordered by ID
+func findAllLive(ctx context.Context, coll *mongo.Collection, results interface{}) error {
+	return findAll(ctx, coll, notDeleted(bson.M{}), options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}), results)
+}
+
+// insertWithID inserts an imported document under the ID it already has; a duplicate key error means
+// the ID is taken, possibly by a document in the trash
+func insertWithID(ctx context.Context, coll *mongo.Collection, doc interface{}) error {
+	_, err := coll.InsertOne(ctx, doc)
+	return err
+}
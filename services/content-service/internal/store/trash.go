@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TrashListLimit caps the trash listing of one collection
+const TrashListLimit = 500
+
+// notDeleted adds the condition that hides trashed documents to a filter and returns it
+func notDeleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// inTrash matches trashed documents
+func inTrash(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": true}
+	return filter
+}
+
+// trashIndex indexes deletedAt for the trash listing and the retention job, and trashedWith for
+// restoring the items deleted with an album or artist; documents outside the trash have neither and
+// are left out of the sparse indexes
+func trashIndex(ctx context.Context, coll *mongo.Collection) error {
+	_, err := coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "deletedAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "trashedWith", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	return err
+}
+
+// moveToTrash marks a document as deleted; notFound is returned when it does not exist or is
+// already in the trash
+func moveToTrash(ctx context.Context, coll *mongo.Collection, id, deletedBy string, notFound error) error {
+	result, err := coll.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), bson.M{
+		"$set": bson.M{"deletedAt": time.Now(), "deletedBy": deletedBy},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// moveToTrashWith marks the documents matching filter as deleted together with their album or
+// artist parentID, so that restoring the parent brings them back, and returns their IDs. Documents
+// already in the trash keep their own mark.
+func moveToTrashWith(ctx context.Context, coll *mongo.Collection, filter bson.M, parentID, deletedBy string) ([]string, error) {
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := findAll(ctx, coll, notDeleted(filter), options.Find().SetProjection(bson.M{"_id": 1}), &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	_, err := coll.UpdateMany(ctx, notDeleted(bson.M{"_id": bson.M{"$in": ids}}), bson.M{
+		"$set": bson.M{"deletedAt": time.Now(), "deletedBy": deletedBy, "trashedWith": parentID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// restoreFromTrash clears the deletion mark; notFound is returned when the document is not in the trash
+func restoreFromTrash(ctx context.Context, coll *mongo.Collection, id string, notFound error) error {
+	result, err := coll.UpdateOne(ctx, inTrash(bson.M{"_id": id}), bson.M{
+		"$unset": bson.M{"deletedAt": "", "deletedBy": "", "trashedWith": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// restoreTrashedWith takes the documents that went to the trash with parentID out of it and decodes
+// them into results
+func restoreTrashedWith(ctx context.Context, coll *mongo.Collection, parentID string, results interface{}) error {
+	filter := inTrash(bson.M{"trashedWith": parentID})
+	if err := findAll(ctx, coll, filter, nil, results); err != nil {
+		return err
+	}
+	_, err := coll.UpdateMany(ctx, filter, bson.M{
+		"$unset": bson.M{"deletedAt": "", "deletedBy": "", "trashedWith": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	})
+	return err
+}
+
+// findTrashed decodes trashed documents into results, most recently deleted first. A non-zero
+// before keeps the documents deleted before it; a zero limit means no limit.
+func findTrashed(ctx context.Context, coll *mongo.Collection, before time.Time, limit int64, results interface{}) error {
+	filter := inTrash(bson.M{})
+	if !before.IsZero() {
+		filter["deletedAt"] = bson.M{"$lt": before}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	return findAll(ctx, coll, filter, opts, results)
+}
+
+// findOneTrashed decodes a trashed document
+func findOneTrashed(ctx context.Context, coll *mongo.Collection, id string, notFound error, result interface{}) error {
+	err := coll.FindOne(ctx, inTrash(bson.M{"_id": id})).Decode(result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound
+	}
+	return err
+}
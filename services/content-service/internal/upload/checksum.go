@@ -0,0 +1,75 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// ChecksumAlgorithms are the algorithms accepted in Upload-Checksum (tus checksum extension)
+const ChecksumAlgorithms = "sha1,sha256,md5"
+
+// Checksum is an expected digest, written as "<algorithm> <base64 digest>"
+type Checksum struct {
+	Algorithm string
+	Digest    []byte
+}
+
+// ParseChecksum parses the value of an Upload-Checksum header or the checksum upload metadata
+func ParseChecksum(value string) (*Checksum, error) {
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return nil, fmt.Errorf("checksum must be \"<algorithm> <base64 digest>\"")
+	}
+	algorithm = strings.ToLower(algorithm)
+	h := newHash(algorithm)
+	if h == nil {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", algorithm)
+	}
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(digest) != h.Size() {
+		return nil, fmt.Errorf("invalid %s digest", algorithm)
+	}
+	return &Checksum{Algorithm: algorithm, Digest: digest}, nil
+}
+
+func newHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// Verify reads r to the end and reports whether its digest matches
+func (c *Checksum) Verify(r io.Reader) (bool, error) {
+	h := newHashWriter(c)
+	if _, err := io.Copy(h, r); err != nil {
+		return false, err
+	}
+	return h.matches(), nil
+}
+
+// hashWriter computes the digest of the bytes written to it
+type hashWriter struct {
+	hash.Hash
+	expected []byte
+}
+
+func newHashWriter(c *Checksum) *hashWriter {
+	return &hashWriter{Hash: newHash(c.Algorithm), expected: c.Digest}
+}
+
+func (h *hashWriter) matches() bool {
+	return bytes.Equal(h.Sum(nil), h.expected)
+}
@@ -0,0 +1,252 @@
+package upload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound         = errors.New("upload not found")
+	ErrOffsetMismatch   = errors.New("upload offset does not match")
+	ErrTooLarge         = errors.New("chunk exceeds the upload length")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// Info describes a resumable upload; it is saved as {id}.json next to the bytes received so far in {id}.bin
+type Info struct {
+	ID       string `json:"id"`
+	SongID   string `json:"songId"`
+	FileName string `json:"fileName"`
+	Length   int64  `json:"length"`
+	Offset   int64  `json:"offset"`
+	// Checksum of the whole file ("<algorithm> <base64 digest>"), verified when the upload completes
+	Checksum  string    `json:"checksum,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt moves forward with every chunk; uploads without progress until then are deleted
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Complete reports whether all bytes have been received
+func (i *Info) Complete() bool {
+	return i.Offset == i.Length
+}
+
+// uploadID matches the IDs the store creates, so request paths cannot point outside the directory
+var uploadID = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Store keeps partial uploads on the local disk until they are complete and moved to the audio storage
+type Store struct {
+	dir string
+	ttl time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewStore(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	return &Store{dir: dir, ttl: ttl, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+func (s *Store) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *Store) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Lock serializes the requests of one upload (chunks, completion, termination); call the returned func to unlock
+func (s *Store) Lock(id string) func() {
+	s.mu.Lock()
+	lock, ok := s.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[id] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// Create starts an upload; ID, offset and timestamps are set by the store
+func (s *Store) Create(info *Info) error {
+	now := time.Now()
+	info.ID = uuid.NewString()
+	info.Offset = 0
+	info.CreatedAt = now
+	info.ExpiresAt = now.Add(s.ttl)
+
+	file, err := os.Create(s.dataPath(info.ID))
+	if err != nil {
+		return fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+	return s.save(info)
+}
+
+// Get returns an upload; one past its expiry is not found even before the expiry worker deletes it
+func (s *Store) Get(id string) (*Info, error) {
+	info, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(info.ExpiresAt) {
+		return nil, ErrNotFound
+	}
+	return info, nil
+}
+
+// load reads the info of an upload, expired or not
+func (s *Store) load(id string) (*Info, error) {
+	if !uploadID.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode upload %s: %w", id, err)
+	}
+	return &info, nil
+}
+
+// save writes the info through a temporary file so a crash never leaves a truncated one
+func (s *Store) save(info *Info) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp := s.infoPath(info.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save upload %s: %w", info.ID, err)
+	}
+	return os.Rename(tmp, s.infoPath(info.ID))
+}
+
+// Append writes a chunk at offset, which must be the current offset of the upload. With a checksum the
+// chunk is kept only if it matches; without one, the bytes received before a dropped connection are kept
+// so the client can resume after them. The caller holds the lock of the upload.
+func (s *Store) Append(info *Info, offset int64, chunk io.Reader, checksum *Checksum) error {
+	if offset != info.Offset {
+		return ErrOffsetMismatch
+	}
+
+	file, err := os.OpenFile(s.dataPath(info.ID), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var hash *hashWriter
+	var w io.Writer = file
+	if checksum != nil {
+		hash = newHashWriter(checksum)
+		w = io.MultiWriter(file, hash)
+	}
+
+	// One byte more than remains tells a chunk that is too long
+	remaining := info.Length - offset
+	written, copyErr := io.Copy(w, io.LimitReader(chunk, remaining+1))
+
+	discard := func(err error) error {
+		if truncErr := file.Truncate(offset); truncErr != nil {
+			log.Printf("Failed to discard chunk of upload %s: %v", info.ID, truncErr)
+		}
+		return err
+	}
+	if written > remaining {
+		return discard(ErrTooLarge)
+	}
+	if checksum != nil {
+		if copyErr != nil {
+			return discard(copyErr)
+		}
+		if !hash.matches() {
+			return discard(ErrChecksumMismatch)
+		}
+	}
+
+	info.Offset += written
+	info.ExpiresAt = time.Now().Add(s.ttl)
+	if err := s.save(info); err != nil {
+		return err
+	}
+	return copyErr
+}
+
+// Open returns the received bytes of an upload
+func (s *Store) Open(id string) (*os.File, error) {
+	if !uploadID.MatchString(id) {
+		return nil, ErrNotFound
+	}
+	return os.Open(s.dataPath(id))
+}
+
+// Delete removes an upload and its data
+func (s *Store) Delete(id string) error {
+	if !uploadID.MatchString(id) {
+		return ErrNotFound
+	}
+	if err := os.Remove(s.infoPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(s.dataPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.locks, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// ExpireStale deletes the uploads that made no progress before their expiry and returns how many
+func (s *Store) ExpireStale(now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !uploadID.MatchString(id) {
+			continue
+		}
+		unlock := s.Lock(id)
+		info, err := s.load(id)
+		if err == nil && now.After(info.ExpiresAt) {
+			if err = s.Delete(id); err == nil {
+				expired++
+			}
+		}
+		unlock()
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.Printf("Failed to expire upload %s: %v", id, err)
+		}
+	}
+	return expired, nil
+}
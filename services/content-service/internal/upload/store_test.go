@@ -0,0 +1,97 @@
+package upload
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStoreGetExpiry(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare returns the ID to look up
+		prepare func(t *testing.T, s *Store) string
+		wantErr error
+	}{
+		{
+			name: "active upload",
+			prepare: func(t *testing.T, s *Store) string {
+				return createUpload(t, s, time.Hour).ID
+			},
+			wantErr: nil,
+		},
+		{
+			name: "expired upload not yet deleted",
+			prepare: func(t *testing.T, s *Store) string {
+				return createUpload(t, s, -time.Minute).ID
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "unknown upload",
+			prepare: func(t *testing.T, s *Store) string {
+				return "00000000-0000-0000-0000-000000000000"
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "path outside the store",
+			prepare: func(t *testing.T, s *Store) string {
+				return "../../etc/passwd"
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			if _, err := s.Get(tt.prepare(t, s)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStoreExpireStale(t *testing.T) {
+	s := newTestStore(t)
+	active := createUpload(t, s, time.Hour)
+	expired := createUpload(t, s, -time.Minute)
+
+	count, err := s.ExpireStale(time.Now())
+	if err != nil {
+		t.Fatalf("ExpireStale: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expired %d uploads, want 1", count)
+	}
+	if _, err := s.load(expired.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired upload still stored: %v", err)
+	}
+	if _, err := s.Get(active.ID); err != nil {
+		t.Errorf("active upload: %v", err)
+	}
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := NewStore(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	return s
+}
+
+// createUpload creates an upload that expires after ttl (negative for one already expired)
+func createUpload(t *testing.T, s *Store, ttl time.Duration) *Info {
+	t.Helper()
+	info := &Info{SongID: "song-1", FileName: "song.mp3", Length: 10}
+	if err := s.Create(info); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	info.ExpiresAt = time.Now().Add(ttl)
+	if err := s.save(info); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	return info
+}
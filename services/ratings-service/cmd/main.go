@@ -387,6 +387,75 @@ func main() {
 		}()
 	}
 
+	// pushSongRating sends the song's average rating to content-service, which keeps a copy for sorting by rating
+	pushSongRating := func(songID string, average float64, count int) error {
+		body, err := json.Marshal(map[string]interface{}{
+			"averageRating": average,
+			"ratingCount":   count,
+		})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodPut, cfg.ContentServiceURL+"/songs/internal/rating?songId="+url.QueryEscape(songID), bytes.NewBuffer(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Internal-Key", cfg.InternalAPIKey)
+
+		resp, err := clientHTTP.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("content-service returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	// syncSongRating recomputes the song's average after a rating change and pushes it asynchronously
+	syncSongRating := func(songID string) {
+		go func() {
+			syncCtx, syncCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer syncCancel()
+
+			err := RetryWithExponentialBackoff(syncCtx, DefaultRetryConfig(), func() error {
+				avg, count, err := ratingStore.GetAverageRating(syncCtx, songID)
+				if err != nil {
+					return err
+				}
+				return pushSongRating(songID, avg, count)
+			})
+			if err != nil {
+				log.Printf("Failed to sync average rating of song %s to content-service: %v", songID, err)
+			}
+		}()
+	}
+
+	// Push all averages once at startup so content-service has ratings given before it stored them
+	go func() {
+		time.Sleep(10 * time.Second)
+
+		syncCtx, syncCancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer syncCancel()
+
+		summaries, err := ratingStore.GetAllAverageRatings(syncCtx)
+		if err != nil {
+			log.Printf("Failed to load average ratings for sync: %v", err)
+			return
+		}
+		failed := 0
+		for _, s := range summaries {
+			if err := pushSongRating(s.SongID, s.Average, s.Count); err != nil {
+				failed++
+			}
+		}
+		log.Printf("Synced average ratings of %d songs to content-service (%d failed)", len(summaries)-failed, failed)
+	}()
+
 	// Health check
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		// Add CORS headers
@@ -582,6 +651,7 @@ func main() {
 			log.Printf("Updated rating for song %s by user %s", songID, userID)
 			// Emit event to recommendation-service
 			emitRatingEvent(cfg.RecommendationServiceURL, userID, songID, ratingValue, "rating_updated")
+			syncSongRating(songID)
 			// Log activity (1.15)
 			analytics.LogActivity(cfg.AnalyticsServiceURL, analytics.Activity{
 				UserID: userID,
@@ -611,6 +681,7 @@ func main() {
 			log.Printf("Created rating for song %s by user %s", songID, userID)
 			// Emit event to recommendation-service
 			emitRatingEvent(cfg.RecommendationServiceURL, userID, songID, ratingValue, "rating_created")
+			syncSongRating(songID)
 			// Log activity (1.15)
 			analytics.LogActivity(cfg.AnalyticsServiceURL, analytics.Activity{
 				UserID: userID,
@@ -675,6 +746,7 @@ func main() {
 		log.Printf("Deleted rating for song %s by user %s", songID, userID)
 		// Emit event to recommendation-service for rating deletion
 		emitRatingEvent(cfg.RecommendationServiceURL, userID, songID, 0, "rating_deleted")
+		syncSongRating(songID)
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("Rating deleted successfully"))
 	})
@@ -827,7 +899,8 @@ func main() {
 		json.NewEncoder(w).Encode(response)
 	})
 
-	// Delete all ratings for a song endpoint (called when song is deleted)
+	// Delete all ratings for a song endpoint (called when song is deleted); the album and artist
+	// deletion saga repeats songId for a batch of songs
 	mux.HandleFunc("/delete-ratings-by-song", func(w http.ResponseWriter, r *http.Request) {
 		// Add CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -845,17 +918,17 @@ func main() {
 			return
 		}
 
-		songID := r.URL.Query().Get("songId")
-		if songID == "" {
+		songIDs := r.URL.Query()["songId"]
+		if len(songIDs) == 0 || songIDs[0] == "" {
 			http.Error(w, "songId parameter is required", http.StatusBadRequest)
 			return
 		}
 
-		// Delete all ratings for this song
+		// Delete all ratings for these songs
 		ratingCtx, ratingCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer ratingCancel()
 
-		err := ratingStore.DeleteBySong(ratingCtx, songID)
+		err := ratingStore.DeleteBySongs(ratingCtx, songIDs)
 		if err != nil {
 			log.Printf("Error deleting ratings for song: %v", err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -863,11 +936,68 @@ func main() {
 			return
 		}
 
-		log.Printf("Deleted all ratings for song %s", songID)
+		log.Printf("Deleted all ratings for songs %v", songIDs)
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("All ratings deleted successfully"))
 	})
 
+	// Get all ratings of a song endpoint (saga backup before the song is deleted); songId may be
+	// repeated for a batch of songs
+	mux.HandleFunc("/ratings-by-song", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		songIDs := r.URL.Query()["songId"]
+		if len(songIDs) == 0 || songIDs[0] == "" {
+			http.Error(w, "songId parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		ratingCtx, ratingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer ratingCancel()
+
+		ratings, err := ratingStore.GetBySongs(ratingCtx, songIDs)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Error getting ratings"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ratings)
+	})
+
+	// Restore deleted ratings endpoint (saga compensation)
+	// POST /restore-ratings {"ratings": [...]}
+	mux.HandleFunc("/restore-ratings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Ratings []*model.Rating `json:"ratings"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		ratingCtx, ratingCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer ratingCancel()
+
+		if err := ratingStore.Restore(ratingCtx, req.Ratings); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("Error restoring ratings"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
 	// Get all ratings for a user endpoint (for sync purposes)
 	mux.HandleFunc("/ratings-by-user", func(w http.ResponseWriter, r *http.Request) {
 		// Add CORS headers
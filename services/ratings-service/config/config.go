@@ -7,6 +7,7 @@ type Config struct {
 	ContentServiceURL      string
 	RecommendationServiceURL string
 	AnalyticsServiceURL    string
+	InternalAPIKey         string // Sent as X-Internal-Key on calls to internal endpoints of content-service
 }
 
 func Load() *Config {
@@ -30,10 +31,16 @@ func Load() *Config {
 		analyticsURL = "http://analytics-service:8007"
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match content-service
+	}
+
 	return &Config{
 		Port:                    port,
 		ContentServiceURL:       contentURL,
 		RecommendationServiceURL: recommendationURL,
 		AnalyticsServiceURL:     analyticsURL,
+		InternalAPIKey:          internalAPIKey,
 	}
 }
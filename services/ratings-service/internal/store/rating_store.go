@@ -113,6 +113,37 @@ func (rs *RatingStore) GetAverageRating(ctx context.Context, songID string) (flo
 	return 0, 0, nil
 }
 
+// SongRatingSummary is the average rating and rating count of one song
+type SongRatingSummary struct {
+	SongID  string  `bson:"_id"`
+	Average float64 `bson:"avg"`
+	Count   int     `bson:"count"`
+}
+
+// GetAllAverageRatings returns the average rating and count of every rated song
+func (rs *RatingStore) GetAllAverageRatings(ctx context.Context) ([]SongRatingSummary, error) {
+	pipeline := []bson.M{
+		{"$group": bson.M{
+			"_id":   "$songId",
+			"avg":   bson.M{"$avg": "$rating"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := rs.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("Error getting average ratings: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var summaries []SongRatingSummary
+	if err := cursor.All(ctx, &summaries); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
 func (rs *RatingStore) DeleteBySongAndUser(ctx context.Context, songID, userID string) error {
 	filter := bson.M{
 		"songId": songID,
@@ -134,19 +165,61 @@ func (rs *RatingStore) DeleteBySongAndUser(ctx context.Context, songID, userID s
 	return nil
 }
 
-// DeleteBySong deletes all ratings for a specific song
-func (rs *RatingStore) DeleteBySong(ctx context.Context, songID string) error {
+// DeleteBySongs deletes all ratings of the songs
+func (rs *RatingStore) DeleteBySongs(ctx context.Context, songIDs []string) error {
 	filter := bson.M{
-		"songId": songID,
+		"songId": bson.M{"$in": songIDs},
 	}
 
 	result, err := rs.collection.DeleteMany(ctx, filter)
 	if err != nil {
-		log.Printf("Error deleting ratings for song: %v", err)
+		log.Printf("Error deleting ratings for songs: %v", err)
+		return err
+	}
+
+	log.Printf("Deleted %d ratings of %d songs", result.DeletedCount, len(songIDs))
+	return nil
+}
+
+// GetBySongs returns all ratings of the songs
+func (rs *RatingStore) GetBySongs(ctx context.Context, songIDs []string) ([]*model.Rating, error) {
+	cursor, err := rs.collection.Find(ctx, bson.M{"songId": bson.M{"$in": songIDs}})
+	if err != nil {
+		log.Printf("Error getting ratings for songs: %v", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ratings := []*model.Rating{}
+	if err = cursor.All(ctx, &ratings); err != nil {
+		log.Printf("Error decoding ratings: %v", err)
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// Restore puts back deleted ratings with their original IDs and timestamps; ratings that
+// still exist are overwritten
+func (rs *RatingStore) Restore(ctx context.Context, ratings []*model.Rating) error {
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ratings))
+	for _, rating := range ratings {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": rating.ID}).
+			SetReplacement(rating).
+			SetUpsert(true))
+	}
+
+	if _, err := rs.collection.BulkWrite(ctx, models); err != nil {
+		log.Printf("Error restoring ratings: %v", err)
 		return err
 	}
 
-	log.Printf("Deleted %d ratings for songId: %s", result.DeletedCount, songID)
+	log.Printf("Restored %d ratings", len(ratings))
 	return nil
 }
 
@@ -283,14 +283,24 @@ func main() {
 				handleSubscriptionDeleted(eventCtx, event, neo4jStore)
 			case "song_created":
 				handleSongCreated(eventCtx, event, neo4jStore)
+			case "song_updated":
+				handleSongUpdated(eventCtx, event, neo4jStore)
 			case "song_deleted":
 				handleSongDeleted(eventCtx, event, neo4jStore)
+			case "songs_deleted":
+				handleSongsDeleted(eventCtx, event, neo4jStore)
 			case "artist_created":
 				handleArtistCreated(eventCtx, event, neo4jStore)
+			case "artist_updated":
+				handleArtistUpdated(eventCtx, event, neo4jStore)
 			case "artist_deleted":
 				handleArtistDeleted(eventCtx, event, neo4jStore)
+			case "album_updated":
+				handleAlbumUpdated(eventCtx, event, neo4jStore)
 			case "album_deleted":
 				handleAlbumDeleted(eventCtx, event, neo4jStore)
+			case "song_liked", "song_unliked", "artist_liked", "artist_unliked":
+				handleLikeEvent(eventCtx, eventType, event, neo4jStore)
 			default:
 				log.Printf("Unknown event type: %s", eventType)
 			}
@@ -453,6 +463,36 @@ func handleArtistCreated(ctx context.Context, event map[string]interface{}, stor
 	log.Printf("Artist created: %s", artistName)
 }
 
+// handleSongUpdated replaces the genre and artist links of an edited song
+func handleSongUpdated(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
+	songID, _ := event["songId"].(string)
+	if songID == "" {
+		log.Printf("Invalid song_updated event: missing songId")
+		return
+	}
+
+	if err := store.ClearSongLinks(ctx, songID); err != nil {
+		log.Printf("Error clearing song links: %v", err)
+		return
+	}
+	handleSongCreated(ctx, event, store)
+}
+
+// handleArtistUpdated replaces the genres of an edited artist
+func handleArtistUpdated(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
+	artistID, _ := event["artistId"].(string)
+	if artistID == "" {
+		log.Printf("Invalid artist_updated event: missing artistId")
+		return
+	}
+
+	if err := store.ClearArtistGenres(ctx, artistID); err != nil {
+		log.Printf("Error clearing artist genres: %v", err)
+		return
+	}
+	handleArtistCreated(ctx, event, store)
+}
+
 func handleSongDeleted(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
 	songID, _ := event["songId"].(string)
 
@@ -469,6 +509,29 @@ func handleSongDeleted(ctx context.Context, event map[string]interface{}, store
 	log.Printf("Song deleted: %s", songID)
 }
 
+// handleSongsDeleted removes a batch of songs, sent by the album and artist deletion saga
+func handleSongsDeleted(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
+	ids, _ := event["songIds"].([]interface{})
+	songIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if songID, ok := id.(string); ok && songID != "" {
+			songIDs = append(songIDs, songID)
+		}
+	}
+
+	if len(songIDs) == 0 {
+		log.Printf("Invalid songs_deleted event: missing songIds")
+		return
+	}
+
+	if err := store.DeleteSongs(ctx, songIDs); err != nil {
+		log.Printf("Error deleting songs: %v", err)
+		return
+	}
+
+	log.Printf("Songs deleted: %d", len(songIDs))
+}
+
 func handleArtistDeleted(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
 	artistID, _ := event["artistId"].(string)
 
@@ -485,6 +548,33 @@ func handleArtistDeleted(ctx context.Context, event map[string]interface{}, stor
 	log.Printf("Artist deleted: %s", artistID)
 }
 
+// handleAlbumUpdated replaces the genre and artist links of an edited album
+func handleAlbumUpdated(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
+	albumID, _ := event["albumId"].(string)
+	name, _ := event["name"].(string)
+	genre, _ := event["genre"].(string)
+	artistIDsInterface, _ := event["artistIds"].([]interface{})
+
+	if albumID == "" || genre == "" {
+		log.Printf("Invalid album_updated event: missing albumId or genre")
+		return
+	}
+
+	artistIDs := make([]string, 0, len(artistIDsInterface))
+	for _, id := range artistIDsInterface {
+		if idStr, ok := id.(string); ok {
+			artistIDs = append(artistIDs, idStr)
+		}
+	}
+
+	if err := store.UpdateAlbum(ctx, albumID, name, genre, artistIDs); err != nil {
+		log.Printf("Error updating album: %v", err)
+		return
+	}
+
+	log.Printf("Album updated: %s", albumID)
+}
+
 func handleAlbumDeleted(ctx context.Context, event map[string]interface{}, store *store.Neo4jStore) {
 	albumID, _ := event["albumId"].(string)
 
@@ -517,3 +607,32 @@ func handleRatingDeleted(ctx context.Context, event map[string]interface{}, stor
 
 	log.Printf("Rating deleted: user %s, song %s", userID, songID)
 }
+
+func handleLikeEvent(ctx context.Context, eventType string, event map[string]interface{}, store *store.Neo4jStore) {
+	userID, _ := event["userId"].(string)
+	itemType, itemKey := "song", "songId"
+	if strings.HasPrefix(eventType, "artist_") {
+		itemType, itemKey = "artist", "artistId"
+	}
+	itemID, _ := event[itemKey].(string)
+
+	if userID == "" || itemID == "" {
+		log.Printf("Invalid %s event: missing userId or %s", eventType, itemKey)
+		return
+	}
+
+	liked := strings.HasSuffix(eventType, "_liked")
+	if liked {
+		// Ensure user exists
+		if err := store.AddOrUpdateUser(ctx, userID); err != nil {
+			log.Printf("Error adding user: %v", err)
+		}
+	}
+
+	if err := store.SetLike(ctx, userID, itemType, itemID, liked); err != nil {
+		log.Printf("Error updating like: %v", err)
+		return
+	}
+
+	log.Printf("Like event processed: %s, user %s, %s %s", eventType, userID, itemType, itemID)
+}
@@ -7,6 +7,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"shared/pagination"
 )
 
 // SyncFromMongoDB syncs data from MongoDB databases to Neo4j graph
@@ -38,15 +40,14 @@ func (s *Neo4jStore) SyncFromMongoDB(ctx context.Context, contentServiceURL, rat
 func (s *Neo4jStore) syncContentData(ctx context.Context, contentServiceURL string) error {
 	client := &http.Client{Timeout: 30 * time.Second}
 
-	// Get all artists
-	resp, err := client.Get(contentServiceURL + "/artists")
+	// Get all artists (the list is paginated, FetchAll follows the cursors)
+	body, err := pagination.FetchAll(ctx, client, contentServiceURL+"/artists")
 	if err != nil {
 		return fmt.Errorf("failed to fetch artists: %w", err)
 	}
-	defer resp.Body.Close()
 
 	var artists []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&artists); err != nil {
+	if err := json.Unmarshal(body, &artists); err != nil {
 		return fmt.Errorf("failed to decode artists: %w", err)
 	}
 
@@ -70,14 +71,13 @@ func (s *Neo4jStore) syncContentData(ctx context.Context, contentServiceURL stri
 	}
 
 	// Get all songs
-	resp, err = client.Get(contentServiceURL + "/songs")
+	body, err = pagination.FetchAll(ctx, client, contentServiceURL+"/songs")
 	if err != nil {
 		return fmt.Errorf("failed to fetch songs: %w", err)
 	}
-	defer resp.Body.Close()
 
 	var songs []map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&songs); err != nil {
+	if err := json.Unmarshal(body, &songs); err != nil {
 		return fmt.Errorf("failed to decode songs: %w", err)
 	}
 
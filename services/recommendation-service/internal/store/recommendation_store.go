@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -77,6 +78,40 @@ func (s *Neo4jStore) AddOrUpdateSong(ctx context.Context, songID, songName, genr
 	return err
 }
 
+// ClearSongLinks removes the genre and artist relationships of a song so that an edited song can be
+// connected again by AddOrUpdateSong
+func (s *Neo4jStore) ClearSongLinks(ctx context.Context, songID string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (s:Song {id: $songID})-[r:BELONGS_TO|PERFORMED_BY]->()
+		DELETE r
+	`
+
+	_, err := session.Run(ctx, query, map[string]interface{}{
+		"songID": songID,
+	})
+	return err
+}
+
+// ClearArtistGenres removes the genre relationships of an artist so that an edited artist can be
+// connected again by AddOrUpdateArtist
+func (s *Neo4jStore) ClearArtistGenres(ctx context.Context, artistID string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (a:Artist {id: $artistID})-[r:PERFORMS_IN]->()
+		DELETE r
+	`
+
+	_, err := session.Run(ctx, query, map[string]interface{}{
+		"artistID": artistID,
+	})
+	return err
+}
+
 // AddRating creates or updates a RATED relationship between user and song
 func (s *Neo4jStore) AddRating(ctx context.Context, userID, songID string, rating int) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -242,7 +277,50 @@ func (s *Neo4jStore) GetSubscribedGenreSongs(ctx context.Context, userID string)
 	return recommendations, nil
 }
 
-// GetTopRatedSongFromUnsubscribedGenre returns the song with most 5-star ratings
+// likeQueries are the queries creating and removing a LIKED relationship, by liked node type
+var likeQueries = map[string][2]string{
+	"song": {`
+		MATCH (u:User {id: $userID})
+		MATCH (n:Song {id: $itemID})
+		MERGE (u)-[r:LIKED]->(n)
+		SET r.createdAt = datetime()
+	`, `
+		MATCH (u:User {id: $userID})-[r:LIKED]->(n:Song {id: $itemID})
+		DELETE r
+	`},
+	"artist": {`
+		MATCH (u:User {id: $userID})
+		MATCH (n:Artist {id: $itemID})
+		MERGE (u)-[r:LIKED]->(n)
+		SET r.createdAt = datetime()
+	`, `
+		MATCH (u:User {id: $userID})-[r:LIKED]->(n:Artist {id: $itemID})
+		DELETE r
+	`},
+}
+
+// SetLike creates or removes a LIKED relationship between user and song or artist (library)
+func (s *Neo4jStore) SetLike(ctx context.Context, userID, itemType, itemID string, liked bool) error {
+	queries, ok := likeQueries[itemType]
+	if !ok {
+		return fmt.Errorf("unsupported like type %q", itemType)
+	}
+	query := queries[1]
+	if liked {
+		query = queries[0]
+	}
+
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	_, err := session.Run(ctx, query, map[string]interface{}{
+		"userID": userID,
+		"itemID": itemID,
+	})
+	return err
+}
+
+// GetTopRatedSongFromUnsubscribedGenre returns the song with most 5-star ratings and likes
 // from a genre the user is not subscribed to
 func (s *Neo4jStore) GetTopRatedSongFromUnsubscribedGenre(ctx context.Context, userID string) (*model.SongRecommendation, error) {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -254,10 +332,11 @@ func (s *Neo4jStore) GetTopRatedSongFromUnsubscribedGenre(ctx context.Context, u
 		MATCH (s:Song)-[:BELONGS_TO]->(g:Genre)
 		WHERE (size(subscribedGenres) = 0 OR NOT toLower(g.name) IN subscribedGenres)
 		OPTIONAL MATCH (otherUser:User)-[r:RATED {rating: 5}]->(s)
+		OPTIONAL MATCH (likedBy:User)-[l:LIKED]->(s)
 		OPTIONAL MATCH (s)-[:PERFORMED_BY]->(a:Artist)
-		WITH s, g, collect(DISTINCT a.id) AS artistIds, count(r) AS fiveStarCount
-		WITH DISTINCT s, g, artistIds, fiveStarCount
-		ORDER BY fiveStarCount DESC, rand()
+		WITH s, g, collect(DISTINCT a.id) AS artistIds, count(DISTINCT r) + count(DISTINCT l) AS popularity
+		WITH DISTINCT s, g, artistIds, popularity
+		ORDER BY popularity DESC, rand()
 		LIMIT 1
 		RETURN s.id AS songId, s.name AS name, g.name AS genre, 
 		       s.albumId AS albumId, s.duration AS duration, artistIds
@@ -338,6 +417,23 @@ func (s *Neo4jStore) DeleteSong(ctx context.Context, songID string) error {
 	return err
 }
 
+// DeleteSongs removes songs and all their relationships
+func (s *Neo4jStore) DeleteSongs(ctx context.Context, songIDs []string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (s:Song)
+		WHERE s.id IN $songIDs
+		DETACH DELETE s
+	`
+
+	_, err := session.Run(ctx, query, map[string]interface{}{
+		"songIDs": songIDs,
+	})
+	return err
+}
+
 // DeleteArtist removes an artist and all its relationships
 func (s *Neo4jStore) DeleteArtist(ctx context.Context, artistID string) error {
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
@@ -354,13 +450,45 @@ func (s *Neo4jStore) DeleteArtist(ctx context.Context, artistID string) error {
 	return err
 }
 
-// DeleteAlbum removes an album (note: albums are not stored as nodes, but we can clean up related songs if needed)
+// UpdateAlbum keeps the name, genre and artists of an edited album; the album node is created by
+// its first update, songs refer to albums by albumId
+func (s *Neo4jStore) UpdateAlbum(ctx context.Context, albumID, name, genre string, artistIDs []string) error {
+	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	query := `
+		MERGE (al:Album {id: $albumID})
+		SET al.name = $name, al.updatedAt = datetime()
+		WITH al
+		OPTIONAL MATCH (al)-[r:BELONGS_TO|RELEASED_BY]->()
+		DELETE r
+		WITH DISTINCT al
+		MERGE (g:Genre {name: $genre})
+		MERGE (al)-[:BELONGS_TO]->(g)
+		WITH al
+		UNWIND $artistIDs AS artistID
+		MERGE (a:Artist {id: artistID})
+		MERGE (al)-[:RELEASED_BY]->(a)
+	`
+
+	_, err := session.Run(ctx, query, map[string]interface{}{
+		"albumID":   albumID,
+		"name":      name,
+		"genre":     genre,
+		"artistIDs": artistIDs,
+	})
+	return err
+}
+
+// DeleteAlbum removes an album with all songs from that album
 func (s *Neo4jStore) DeleteAlbum(ctx context.Context, albumID string) error {
-	// Albums are not stored as nodes in Neo4j, but we can delete all songs from that album
 	session := s.driver.NewSession(ctx, neo4j.SessionConfig{})
 	defer session.Close(ctx)
 
 	query := `
+		OPTIONAL MATCH (al:Album {id: $albumID})
+		DETACH DELETE al
+		WITH count(*) AS albums
 		MATCH (s:Song {albumId: $albumID})
 		DETACH DELETE s
 	`
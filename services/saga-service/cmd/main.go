@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"saga-service/config"
+	"saga-service/internal/model"
 	"saga-service/internal/orchestrator"
 	"saga-service/internal/store"
 )
@@ -34,6 +35,7 @@ func main() {
 	// Initialize store and orchestrator
 	sagaStore := store.NewSagaStore(db)
 	songDeletionSaga := orchestrator.NewSongDeletionSaga(sagaStore, cfg)
+	catalogDeletionSaga := orchestrator.NewCatalogDeletionSaga(sagaStore, cfg)
 
 	mux := http.NewServeMux()
 
@@ -85,6 +87,72 @@ func main() {
 		json.NewEncoder(w).Encode(saga)
 	})
 
+	// Start saga transaction for album deletion: the album, its songs and everything that refers to them
+	mux.HandleFunc("/sagas/delete-album", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			AlbumID string `json:"albumId"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		if req.AlbumID == "" {
+			http.Error(w, "albumId is required", http.StatusBadRequest)
+			return
+		}
+
+		sagaCtx, sagaCancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer sagaCancel()
+
+		saga, err := catalogDeletionSaga.ExecuteAlbum(sagaCtx, req.AlbumID)
+		writeSagaResult(w, saga, err)
+	})
+
+	// Start saga transaction for artist deletion: the artist, its albums, songs and subscriptions.
+	// sharedSongs "detach" (default) keeps albums and songs shared with other artists without the
+	// artist, "delete" deletes them as well.
+	mux.HandleFunc("/sagas/delete-artist", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			ArtistID    string `json:"artistId"`
+			SharedSongs string `json:"sharedSongs"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		if req.ArtistID == "" {
+			http.Error(w, "artistId is required", http.StatusBadRequest)
+			return
+		}
+		if req.SharedSongs == "" {
+			req.SharedSongs = model.SharedSongsDetach
+		}
+		if req.SharedSongs != model.SharedSongsDetach && req.SharedSongs != model.SharedSongsDelete {
+			http.Error(w, "sharedSongs must be detach or delete", http.StatusBadRequest)
+			return
+		}
+
+		sagaCtx, sagaCancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer sagaCancel()
+
+		saga, err := catalogDeletionSaga.ExecuteArtist(sagaCtx, req.ArtistID, req.SharedSongs)
+		writeSagaResult(w, saga, err)
+	})
+
 	// Get saga transaction status
 	mux.HandleFunc("/sagas/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -119,3 +187,19 @@ func main() {
 	log.Printf("Saga service running on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, mux))
 }
+
+// writeSagaResult writes a finished saga, with the error when it failed and was compensated
+func writeSagaResult(w http.ResponseWriter, saga *model.SagaTransaction, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		log.Printf("Saga execution failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+			"saga":  saga,
+		})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(saga)
+}
@@ -7,8 +7,10 @@ type Config struct {
 	ContentServiceURL       string
 	RatingsServiceURL       string
 	RecommendationServiceURL string
+	SubscriptionsServiceURL  string
 	MongoDBURI              string
 	MongoDBDatabase         string
+	InternalAPIKey          string // Sent as X-Internal-Key on calls to internal endpoints of the services
 }
 
 func Load() *Config {
@@ -32,6 +34,11 @@ func Load() *Config {
 		recommendationServiceURL = "http://recommendation-service:8006"
 	}
 
+	subscriptionsServiceURL := os.Getenv("SUBSCRIPTIONS_SERVICE_URL")
+	if subscriptionsServiceURL == "" {
+		subscriptionsServiceURL = "http://subscriptions-service:8004"
+	}
+
 	mongoURI := os.Getenv("MONGODB_URI")
 	if mongoURI == "" {
 		mongoURI = "mongodb://localhost:27017"
@@ -42,12 +49,19 @@ func Load() *Config {
 		mongoDB = "saga_db"
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match content-service and subscriptions-service
+	}
+
 	return &Config{
 		Port:                     port,
 		ContentServiceURL:        contentServiceURL,
 		RatingsServiceURL:        ratingsServiceURL,
 		RecommendationServiceURL: recommendationServiceURL,
+		SubscriptionsServiceURL:  subscriptionsServiceURL,
 		MongoDBURI:               mongoURI,
 		MongoDBDatabase:          mongoDB,
+		InternalAPIKey:           internalAPIKey,
 	}
 }
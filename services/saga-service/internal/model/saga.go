@@ -33,6 +33,10 @@ type SagaTransaction struct {
 	Status      SagaStatus             `bson:"status" json:"status"`
 	SongID      string                 `bson:"songId" json:"songId"`
 	SongData    map[string]interface{} `bson:"songData,omitempty" json:"songData,omitempty"` // Backup of song data
+	AlbumID     string                 `bson:"albumId,omitempty" json:"albumId,omitempty"`
+	ArtistID    string                 `bson:"artistId,omitempty" json:"artistId,omitempty"`
+	SharedSongs string                 `bson:"sharedSongs,omitempty" json:"sharedSongs,omitempty"` // Policy for songs shared with other artists
+	Catalog     *CatalogBackup         `bson:"catalog,omitempty" json:"catalog,omitempty"`         // Backup of album/artist deletion
 	Steps       []SagaStep             `bson:"steps" json:"steps"`
 	CreatedAt   time.Time              `bson:"createdAt" json:"createdAt"`
 	UpdatedAt   time.Time              `bson:"updatedAt" json:"updatedAt"`
@@ -50,18 +54,52 @@ type SagaStep struct {
 	Data           map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"` // Step-specific data
 }
 
+// CatalogBackup is the content-service backup of an album or artist deletion: what is deleted and,
+// for shared albums and songs that only lose the artist, how they were before
+type CatalogBackup struct {
+	Artist         map[string]interface{}   `bson:"artist,omitempty" json:"artist,omitempty"`
+	Albums         []map[string]interface{} `bson:"albums" json:"albums"`
+	Songs          []map[string]interface{} `bson:"songs" json:"songs"`
+	DetachedAlbums []map[string]interface{} `bson:"detachedAlbums" json:"detachedAlbums"`
+	DetachedSongs  []map[string]interface{} `bson:"detachedSongs" json:"detachedSongs"`
+}
+
+// Saga types
+const (
+	SagaTypeDeleteSong   = "DELETE_SONG"
+	SagaTypeDeleteAlbum  = "DELETE_ALBUM"
+	SagaTypeDeleteArtist = "DELETE_ARTIST"
+)
+
+// Policies for songs and albums an artist shares with other artists
+const (
+	SharedSongsDetach = "detach"
+	SharedSongsDelete = "delete"
+)
+
 // Step names for song deletion saga
 const (
-	StepBackupSong      = "BACKUP_SONG"
-	StepDeleteRatings   = "DELETE_RATINGS"
-	StepDeleteFromNeo4j = "DELETE_FROM_NEO4J"
-	StepDeleteFromHDFS  = "DELETE_FROM_HDFS"
-	StepDeleteFromMongo = "DELETE_FROM_MONGO"
+	StepBackupSong          = "BACKUP_SONG"
+	StepDeleteRatings       = "DELETE_RATINGS"
+	StepDeleteFromNeo4j     = "DELETE_FROM_NEO4J"
+	StepDeleteFromHDFS      = "DELETE_FROM_HDFS"
+	StepRemoveFromPlaylists = "REMOVE_FROM_PLAYLISTS"
+	StepDeleteFromMongo     = "DELETE_FROM_MONGO"
+)
+
+// Step names of album and artist deletion sagas, besides the song deletion steps they fan out
+// over the songs (DELETE_RATINGS, DELETE_FROM_NEO4J, REMOVE_FROM_PLAYLISTS, DELETE_FROM_MONGO,
+// DELETE_FROM_HDFS)
+const (
+	StepBackupCatalog       = "BACKUP_CATALOG"
+	StepDetachSharedSongs   = "DETACH_SHARED_SONGS"
+	StepDeleteSubscriptions = "DELETE_SUBSCRIPTIONS"
 )
 
 // Compensating step names
 const (
-	CompensateRestoreToNeo4j = "RESTORE_TO_NEO4J"
-	CompensateRestoreToHDFS  = "RESTORE_TO_HDFS"
-	CompensateRestoreToMongo = "RESTORE_TO_MONGO"
+	CompensateRestoreToNeo4j     = "RESTORE_TO_NEO4J"
+	CompensateRestoreToHDFS      = "RESTORE_TO_HDFS"
+	CompensateRestoreToMongo     = "RESTORE_TO_MONGO"
+	CompensateRestoreToPlaylists = "RESTORE_TO_PLAYLISTS"
 )
@@ -0,0 +1,497 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"saga-service/config"
+	"saga-service/internal/model"
+	"saga-service/internal/store"
+)
+
+// songBatchSize is how many songs one call of the song deletion steps covers
+const songBatchSize = 100
+
+// compensationTimeout bounds the compensation of a failed saga. It has its own context: the saga
+// may have failed because its context ran out.
+const compensationTimeout = 5 * time.Minute
+
+// CatalogDeletionSaga deletes an album or artist together with its songs. The song deletion steps
+// fan out over every song of the backup; shared songs and albums of an artist are detached instead
+// when the saga runs with the detach policy. Files are released last, once nothing can fail anymore,
+// so every earlier step can be compensated from the backup.
+type CatalogDeletionSaga struct {
+	store  *store.SagaStore
+	config *config.Config
+}
+
+func NewCatalogDeletionSaga(store *store.SagaStore, cfg *config.Config) *CatalogDeletionSaga {
+	return &CatalogDeletionSaga{
+		store:  store,
+		config: cfg,
+	}
+}
+
+// ExecuteAlbum runs the saga transaction for album deletion
+func (s *CatalogDeletionSaga) ExecuteAlbum(ctx context.Context, albumID string) (*model.SagaTransaction, error) {
+	saga := &model.SagaTransaction{
+		ID:      fmt.Sprintf("saga_album_%s_%d", albumID, time.Now().Unix()),
+		Type:    model.SagaTypeDeleteAlbum,
+		Status:  model.SagaStatusPending,
+		AlbumID: albumID,
+		Steps: []model.SagaStep{
+			{Name: model.StepBackupCatalog, Status: model.StepStatusPending, Order: 1},
+			{Name: model.StepDeleteRatings, Status: model.StepStatusPending, Order: 2},
+			{Name: model.StepDeleteFromNeo4j, Status: model.StepStatusPending, Order: 3},
+			{Name: model.StepRemoveFromPlaylists, Status: model.StepStatusPending, Order: 4},
+			{Name: model.StepDeleteFromMongo, Status: model.StepStatusPending, Order: 5},
+			{Name: model.StepDeleteFromHDFS, Status: model.StepStatusPending, Order: 6},
+		},
+	}
+	return s.execute(ctx, saga)
+}
+
+// ExecuteArtist runs the saga transaction for artist deletion; sharedSongs is the policy for songs
+// and albums the artist shares with other artists
+func (s *CatalogDeletionSaga) ExecuteArtist(ctx context.Context, artistID, sharedSongs string) (*model.SagaTransaction, error) {
+	saga := &model.SagaTransaction{
+		ID:          fmt.Sprintf("saga_artist_%s_%d", artistID, time.Now().Unix()),
+		Type:        model.SagaTypeDeleteArtist,
+		Status:      model.SagaStatusPending,
+		ArtistID:    artistID,
+		SharedSongs: sharedSongs,
+		Steps: []model.SagaStep{
+			{Name: model.StepBackupCatalog, Status: model.StepStatusPending, Order: 1},
+			{Name: model.StepDeleteRatings, Status: model.StepStatusPending, Order: 2},
+			{Name: model.StepDeleteFromNeo4j, Status: model.StepStatusPending, Order: 3},
+			{Name: model.StepRemoveFromPlaylists, Status: model.StepStatusPending, Order: 4},
+			{Name: model.StepDeleteSubscriptions, Status: model.StepStatusPending, Order: 5},
+			{Name: model.StepDetachSharedSongs, Status: model.StepStatusPending, Order: 6},
+			{Name: model.StepDeleteFromMongo, Status: model.StepStatusPending, Order: 7},
+			{Name: model.StepDeleteFromHDFS, Status: model.StepStatusPending, Order: 8},
+		},
+	}
+	return s.execute(ctx, saga)
+}
+
+func (s *CatalogDeletionSaga) execute(ctx context.Context, saga *model.SagaTransaction) (*model.SagaTransaction, error) {
+	if err := s.store.CreateTransaction(ctx, saga); err != nil {
+		return nil, fmt.Errorf("failed to create saga transaction: %w", err)
+	}
+
+	saga.Status = model.SagaStatusInProgress
+	s.store.UpdateTransaction(ctx, saga)
+
+	for i := range saga.Steps {
+		step := &saga.Steps[i]
+		log.Printf("Executing step %d: %s for saga %s", step.Order, step.Name, saga.ID)
+
+		err := s.executeStep(ctx, saga, step)
+		if err != nil {
+			log.Printf("Step %s failed: %v", step.Name, err)
+			compensateCtx, cancel := context.WithTimeout(context.Background(), compensationTimeout)
+			defer cancel()
+
+			step.Status = model.StepStatusFailed
+			step.Error = err.Error()
+			s.store.UpdateStepStatus(compensateCtx, saga.ID, step.Name, step.Status, err.Error())
+
+			saga.Status = model.SagaStatusCompensating
+			s.store.UpdateTransaction(compensateCtx, saga)
+			s.compensate(compensateCtx, saga, i)
+			saga.Status = model.SagaStatusCompensated
+			saga.Error = fmt.Sprintf("Step %s failed: %v", step.Name, err)
+			s.store.UpdateTransaction(compensateCtx, saga)
+			return saga, fmt.Errorf("saga failed at step %s: %w", step.Name, err)
+		}
+
+		step.Status = model.StepStatusCompleted
+		s.store.UpdateStepStatus(ctx, saga.ID, step.Name, step.Status, "")
+		log.Printf("Step %s completed successfully", step.Name)
+	}
+
+	saga.Status = model.SagaStatusCompleted
+	s.store.UpdateTransaction(ctx, saga)
+	log.Printf("Saga transaction %s completed successfully", saga.ID)
+
+	return saga, nil
+}
+
+func (s *CatalogDeletionSaga) executeStep(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	switch step.Name {
+	case model.StepBackupCatalog:
+		return s.backupCatalog(ctx, saga)
+	case model.StepDeleteRatings:
+		return s.deleteRatings(ctx, saga, step)
+	case model.StepDeleteFromNeo4j:
+		return s.deleteFromNeo4j(ctx, saga)
+	case model.StepRemoveFromPlaylists:
+		return s.removeFromPlaylists(ctx, saga, step)
+	case model.StepDeleteSubscriptions:
+		return s.deleteSubscriptions(ctx, saga, step)
+	case model.StepDetachSharedSongs:
+		return s.detachSharedSongs(ctx, saga)
+	case model.StepDeleteFromMongo:
+		return s.deleteFromMongo(ctx, saga)
+	case model.StepDeleteFromHDFS:
+		return s.deleteFromHDFS(ctx, saga)
+	default:
+		return fmt.Errorf("unknown step: %s", step.Name)
+	}
+}
+
+// backupCatalog backs up the album or artist with its albums and songs, trash included, and splits
+// off the shared ones that are only detached
+func (s *CatalogDeletionSaga) backupCatalog(ctx context.Context, saga *model.SagaTransaction) error {
+	query := url.Values{}
+	if saga.Type == model.SagaTypeDeleteAlbum {
+		query.Set("albumId", saga.AlbumID)
+	} else {
+		query.Set("artistId", saga.ArtistID)
+		query.Set("sharedSongs", saga.SharedSongs)
+	}
+
+	var backup model.CatalogBackup
+	backupURL := fmt.Sprintf("%s/catalog/internal/backup?%s", s.config.ContentServiceURL, query.Encode())
+	if err := callService(ctx, s.config.InternalAPIKey, 10*time.Second, http.MethodGet, backupURL, nil, &backup); err != nil {
+		return fmt.Errorf("failed to back up catalog: %w", err)
+	}
+
+	saga.Catalog = &backup
+	s.store.UpdateTransaction(ctx, saga)
+	log.Printf("Saga %s backed up %d albums and %d songs (%d albums and %d songs to detach)",
+		saga.ID, len(backup.Albums), len(backup.Songs), len(backup.DetachedAlbums), len(backup.DetachedSongs))
+	return nil
+}
+
+// deleteRatings deletes the ratings of every deleted song, keeping them in the step data first
+func (s *CatalogDeletionSaga) deleteRatings(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	ratings := []interface{}{}
+	step.Data = map[string]interface{}{"ratings": ratings}
+
+	for _, batch := range songBatches(saga.Catalog.Songs) {
+		query := songQuery(batch)
+
+		var songRatings []interface{}
+		getURL := fmt.Sprintf("%s/ratings-by-song?%s", s.config.RatingsServiceURL, query)
+		if err := callService(ctx, s.config.InternalAPIKey, 10*time.Second, http.MethodGet, getURL, nil, &songRatings); err != nil {
+			return fmt.Errorf("failed to back up ratings of %d songs: %w", len(batch), err)
+		}
+		ratings = append(ratings, songRatings...)
+		step.Data["ratings"] = ratings
+		s.store.UpdateTransaction(ctx, saga)
+
+		deleteURL := fmt.Sprintf("%s/delete-ratings-by-song?%s", s.config.RatingsServiceURL, query)
+		if err := callService(ctx, s.config.InternalAPIKey, 10*time.Second, http.MethodDelete, deleteURL, nil, nil); err != nil {
+			return fmt.Errorf("failed to delete ratings of %d songs: %w", len(batch), err)
+		}
+	}
+
+	log.Printf("Deleted %d ratings of %d songs", len(ratings), len(saga.Catalog.Songs))
+	return nil
+}
+
+// deleteFromNeo4j removes the deleted songs and the album or artist from the recommendation graph
+func (s *CatalogDeletionSaga) deleteFromNeo4j(ctx context.Context, saga *model.SagaTransaction) error {
+	for _, batch := range songBatches(saga.Catalog.Songs) {
+		if err := s.sendEvent(ctx, map[string]interface{}{"type": "songs_deleted", "songIds": batch}); err != nil {
+			return fmt.Errorf("failed to delete %d songs from Neo4j: %w", len(batch), err)
+		}
+	}
+	for _, album := range saga.Catalog.Albums {
+		if err := s.sendEvent(ctx, map[string]interface{}{"type": "album_deleted", "albumId": docID(album)}); err != nil {
+			return fmt.Errorf("failed to delete album %s from Neo4j: %w", docID(album), err)
+		}
+	}
+	if saga.Catalog.Artist != nil {
+		if err := s.sendEvent(ctx, map[string]interface{}{"type": "artist_deleted", "artistId": docID(saga.Catalog.Artist)}); err != nil {
+			return fmt.Errorf("failed to delete artist from Neo4j: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeFromPlaylists removes every deleted song from all playlists, keeping the removed entries
+func (s *CatalogDeletionSaga) removeFromPlaylists(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	removed := []interface{}{}
+	step.Data = map[string]interface{}{"removed": removed}
+
+	for _, batch := range songBatches(saga.Catalog.Songs) {
+		var result struct {
+			Removed []interface{} `json:"removed"`
+		}
+		removeURL := fmt.Sprintf("%s/playlists/internal/songs?%s", s.config.ContentServiceURL, songQuery(batch))
+		if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodDelete, removeURL, nil, &result); err != nil {
+			return fmt.Errorf("failed to remove %d songs from playlists: %w", len(batch), err)
+		}
+		removed = append(removed, result.Removed...)
+		step.Data["removed"] = removed
+		s.store.UpdateTransaction(ctx, saga)
+	}
+
+	log.Printf("Removed %d playlist entries of %d songs", len(removed), len(saga.Catalog.Songs))
+	return nil
+}
+
+// deleteSubscriptions deletes the subscriptions to the artist, keeping them in the step data
+func (s *CatalogDeletionSaga) deleteSubscriptions(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	var result struct {
+		Removed []interface{} `json:"removed"`
+	}
+	deleteURL := fmt.Sprintf("%s/subscriptions/internal/artist?artistId=%s", s.config.SubscriptionsServiceURL, url.QueryEscape(saga.ArtistID))
+	if err := callService(ctx, s.config.InternalAPIKey, 10*time.Second, http.MethodDelete, deleteURL, nil, &result); err != nil {
+		return fmt.Errorf("failed to delete subscriptions: %w", err)
+	}
+
+	step.Data = map[string]interface{}{"removed": result.Removed}
+	s.store.UpdateTransaction(ctx, saga)
+	log.Printf("Deleted %d subscriptions to artist %s", len(result.Removed), saga.ArtistID)
+	return nil
+}
+
+// detachSharedSongs removes the artist from the albums and songs it shares with other artists
+func (s *CatalogDeletionSaga) detachSharedSongs(ctx context.Context, saga *model.SagaTransaction) error {
+	if len(saga.Catalog.DetachedAlbums) == 0 && len(saga.Catalog.DetachedSongs) == 0 {
+		log.Printf("Artist %s has no shared albums or songs to detach", saga.ArtistID)
+		return nil
+	}
+
+	detachURL := fmt.Sprintf("%s/catalog/internal/detach", s.config.ContentServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, detachURL, saga.Catalog, nil); err != nil {
+		return fmt.Errorf("failed to detach shared songs: %w", err)
+	}
+
+	log.Printf("Artist %s detached from %d albums and %d songs", saga.ArtistID, len(saga.Catalog.DetachedAlbums), len(saga.Catalog.DetachedSongs))
+	return nil
+}
+
+// deleteFromMongo deletes the songs, albums and artist; their files are kept until the last step
+func (s *CatalogDeletionSaga) deleteFromMongo(ctx context.Context, saga *model.SagaTransaction) error {
+	deleteURL := fmt.Sprintf("%s/catalog/internal/delete", s.config.ContentServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, deleteURL, saga.Catalog, nil); err != nil {
+		return fmt.Errorf("failed to delete from MongoDB: %w", err)
+	}
+
+	log.Printf("Saga %s deleted %d albums and %d songs from MongoDB", saga.ID, len(saga.Catalog.Albums), len(saga.Catalog.Songs))
+	return nil
+}
+
+// deleteFromHDFS releases the audio files, lyrics and images of everything deleted. It is the last
+// step and is not compensated: uploaded audio may be shared with other songs, so content-service
+// only deletes files nothing else refers to.
+func (s *CatalogDeletionSaga) deleteFromHDFS(ctx context.Context, saga *model.SagaTransaction) error {
+	releaseURL := fmt.Sprintf("%s/catalog/internal/release", s.config.ContentServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 60*time.Second, http.MethodPost, releaseURL, saga.Catalog, nil); err != nil {
+		return fmt.Errorf("failed to release files: %w", err)
+	}
+	return nil
+}
+
+// compensate undoes the failed step and the completed steps before it, in reverse order. The
+// failed step is included because the steps over many songs keep what they did so far.
+func (s *CatalogDeletionSaga) compensate(ctx context.Context, saga *model.SagaTransaction, failedStepIndex int) {
+	log.Printf("Starting compensation for saga %s (failed at step %d)", saga.ID, failedStepIndex)
+
+	for i := failedStepIndex; i >= 0; i-- {
+		step := &saga.Steps[i]
+		if step.Status != model.StepStatusCompleted && step.Status != model.StepStatusFailed {
+			continue
+		}
+
+		log.Printf("Compensating step: %s", step.Name)
+		if err := s.compensateStep(ctx, saga, step); err != nil {
+			log.Printf("Compensation failed for step %s: %v", step.Name, err)
+			continue
+		}
+		step.Status = model.StepStatusCompensated
+		s.store.UpdateStepStatus(ctx, saga.ID, step.Name, step.Status, "")
+		log.Printf("Step %s compensated successfully", step.Name)
+	}
+}
+
+func (s *CatalogDeletionSaga) compensateStep(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	switch step.Name {
+	case model.StepDeleteRatings:
+		return s.restoreRatings(ctx, step)
+	case model.StepDeleteFromNeo4j:
+		return s.restoreToNeo4j(ctx, saga)
+	case model.StepRemoveFromPlaylists:
+		return s.restoreToPlaylists(ctx, step)
+	case model.StepDeleteSubscriptions:
+		return s.restoreSubscriptions(ctx, step)
+	case model.StepDetachSharedSongs:
+		return s.restoreToMongo(ctx, &model.CatalogBackup{
+			DetachedAlbums: saga.Catalog.DetachedAlbums,
+			DetachedSongs:  saga.Catalog.DetachedSongs,
+		})
+	case model.StepDeleteFromMongo:
+		return s.restoreToMongo(ctx, &model.CatalogBackup{
+			Artist: saga.Catalog.Artist,
+			Albums: saga.Catalog.Albums,
+			Songs:  saga.Catalog.Songs,
+		})
+	case model.StepBackupCatalog, model.StepDeleteFromHDFS:
+		// Nothing to undo
+		return nil
+	default:
+		return fmt.Errorf("unknown step for compensation: %s", step.Name)
+	}
+}
+
+// restoreRatings puts back the deleted ratings
+func (s *CatalogDeletionSaga) restoreRatings(ctx context.Context, step *model.SagaStep) error {
+	ratings, ok := step.Data["ratings"]
+	if !ok {
+		return nil
+	}
+	restoreURL := fmt.Sprintf("%s/restore-ratings", s.config.RatingsServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, restoreURL, map[string]interface{}{"ratings": ratings}, nil); err != nil {
+		return fmt.Errorf("failed to restore ratings: %w", err)
+	}
+	return nil
+}
+
+// restoreToNeo4j sends recommendation-service the creation events of everything that was in its
+// graph: items in the trash were already removed from it when they were deleted
+func (s *CatalogDeletionSaga) restoreToNeo4j(ctx context.Context, saga *model.SagaTransaction) error {
+	artist := saga.Catalog.Artist
+	if artist != nil && !inTrash(artist) {
+		if err := s.sendEvent(ctx, map[string]interface{}{
+			"type":     "artist_created",
+			"artistId": docID(artist),
+			"name":     artist["name"],
+			"genres":   artist["genres"],
+		}); err != nil {
+			return fmt.Errorf("failed to restore artist to Neo4j: %w", err)
+		}
+	}
+
+	trashedAlbums := map[string]bool{}
+	for _, album := range saga.Catalog.Albums {
+		if inTrash(album) {
+			trashedAlbums[docID(album)] = true
+		}
+	}
+	restored := map[string]bool{}
+	for _, song := range append(append([]map[string]interface{}{}, saga.Catalog.Songs...), saga.Catalog.DetachedSongs...) {
+		albumID, _ := song["albumId"].(string)
+		if inTrash(song) || trashedAlbums[albumID] {
+			continue
+		}
+		if err := s.sendEvent(ctx, map[string]interface{}{
+			"type":      "song_created",
+			"songId":    docID(song),
+			"name":      song["name"],
+			"genre":     song["genre"],
+			"artistIds": song["artistIds"],
+			"albumId":   albumID,
+			"duration":  song["duration"],
+		}); err != nil {
+			return fmt.Errorf("failed to restore song %s to Neo4j: %w", docID(song), err)
+		}
+		restored[docID(song)] = true
+	}
+
+	// The ratings of restored songs were removed from the graph with them
+	for i := range saga.Steps {
+		if saga.Steps[i].Name != model.StepDeleteRatings {
+			continue
+		}
+		ratings, _ := saga.Steps[i].Data["ratings"].([]interface{})
+		for _, r := range ratings {
+			rating, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			songID, _ := rating["songId"].(string)
+			if !restored[songID] {
+				continue
+			}
+			if err := s.sendEvent(ctx, map[string]interface{}{
+				"type":   "rating_created",
+				"userId": rating["userId"],
+				"songId": songID,
+				"rating": rating["rating"],
+			}); err != nil {
+				return fmt.Errorf("failed to restore rating to Neo4j: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// restoreToPlaylists puts the removed playlist entries back
+func (s *CatalogDeletionSaga) restoreToPlaylists(ctx context.Context, step *model.SagaStep) error {
+	removed, ok := step.Data["removed"]
+	if !ok {
+		return nil
+	}
+	restoreURL := fmt.Sprintf("%s/playlists/internal/songs/restore", s.config.ContentServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, restoreURL, map[string]interface{}{"removed": removed}, nil); err != nil {
+		return fmt.Errorf("failed to restore playlist entries: %w", err)
+	}
+	return nil
+}
+
+// restoreSubscriptions puts back the deleted subscriptions to the artist
+func (s *CatalogDeletionSaga) restoreSubscriptions(ctx context.Context, step *model.SagaStep) error {
+	removed, ok := step.Data["removed"]
+	if !ok {
+		return nil
+	}
+	restoreURL := fmt.Sprintf("%s/subscriptions/internal/restore", s.config.SubscriptionsServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, restoreURL, map[string]interface{}{"subscriptions": removed}, nil); err != nil {
+		return fmt.Errorf("failed to restore subscriptions: %w", err)
+	}
+	return nil
+}
+
+// restoreToMongo writes backed-up songs, albums and artist back as they were
+func (s *CatalogDeletionSaga) restoreToMongo(ctx context.Context, backup *model.CatalogBackup) error {
+	restoreURL := fmt.Sprintf("%s/catalog/internal/restore", s.config.ContentServiceURL)
+	if err := callService(ctx, s.config.InternalAPIKey, 30*time.Second, http.MethodPost, restoreURL, backup, nil); err != nil {
+		return fmt.Errorf("failed to restore to MongoDB: %w", err)
+	}
+	return nil
+}
+
+// sendEvent posts an event to recommendation-service
+func (s *CatalogDeletionSaga) sendEvent(ctx context.Context, event map[string]interface{}) error {
+	eventsURL := fmt.Sprintf("%s/events", s.config.RecommendationServiceURL)
+	return callService(ctx, s.config.InternalAPIKey, 10*time.Second, http.MethodPost, eventsURL, event, nil)
+}
+
+// songBatches splits the IDs of backed-up songs into batches of songBatchSize
+func songBatches(songs []map[string]interface{}) [][]string {
+	var batches [][]string
+	for start := 0; start < len(songs); start += songBatchSize {
+		end := min(start+songBatchSize, len(songs))
+		batch := make([]string, 0, end-start)
+		for _, song := range songs[start:end] {
+			batch = append(batch, docID(song))
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// songQuery is the songId query of a batch of songs
+func songQuery(songIDs []string) string {
+	return url.Values{"songId": songIDs}.Encode()
+}
+
+// docID is the ID of a backed-up document
+func docID(doc map[string]interface{}) string {
+	id, _ := doc["id"].(string)
+	return id
+}
+
+// inTrash reports whether a backed-up document was in the trash
+func inTrash(doc map[string]interface{}) bool {
+	deletedAt, ok := doc["deletedAt"]
+	return ok && deletedAt != nil
+}
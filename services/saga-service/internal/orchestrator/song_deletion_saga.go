@@ -31,7 +31,7 @@ func (s *SongDeletionSaga) Execute(ctx context.Context, songID string) (*model.S
 	// Create saga transaction
 	saga := &model.SagaTransaction{
 		ID:     fmt.Sprintf("saga_%s_%d", songID, time.Now().Unix()),
-		Type:   "DELETE_SONG",
+		Type:   model.SagaTypeDeleteSong,
 		Status: model.SagaStatusPending,
 		SongID: songID,
 		Steps: []model.SagaStep{
@@ -39,7 +39,8 @@ func (s *SongDeletionSaga) Execute(ctx context.Context, songID string) (*model.S
 			{Name: model.StepDeleteRatings, Status: model.StepStatusPending, Order: 2},
 			{Name: model.StepDeleteFromNeo4j, Status: model.StepStatusPending, Order: 3},
 			{Name: model.StepDeleteFromHDFS, Status: model.StepStatusPending, Order: 4},
-			{Name: model.StepDeleteFromMongo, Status: model.StepStatusPending, Order: 5},
+			{Name: model.StepRemoveFromPlaylists, Status: model.StepStatusPending, Order: 5},
+			{Name: model.StepDeleteFromMongo, Status: model.StepStatusPending, Order: 6},
 		},
 	}
 
@@ -103,6 +104,8 @@ func (s *SongDeletionSaga) executeStep(ctx context.Context, saga *model.SagaTran
 		return s.deleteFromNeo4j(ctx, saga.SongID)
 	case model.StepDeleteFromHDFS:
 		return s.deleteFromHDFS(ctx, saga)
+	case model.StepRemoveFromPlaylists:
+		return s.removeFromPlaylists(ctx, saga, step)
 	case model.StepDeleteFromMongo:
 		return s.deleteFromMongo(ctx, saga.SongID)
 	default:
@@ -110,15 +113,17 @@ func (s *SongDeletionSaga) executeStep(ctx context.Context, saga *model.SagaTran
 	}
 }
 
-// backupSong backs up song data before deletion
+// backupSong backs up song data before deletion. Songs are purged from the trash, where the public
+// song endpoint no longer finds them, so the internal one is used.
 func (s *SongDeletionSaga) backupSong(ctx context.Context, saga *model.SagaTransaction) error {
 	client := &http.Client{Timeout: 5 * time.Second}
-	url := fmt.Sprintf("%s/songs/%s", s.config.ContentServiceURL, saga.SongID)
+	url := fmt.Sprintf("%s/songs/internal/backup?songId=%s", s.config.ContentServiceURL, saga.SongID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("X-Internal-Key", s.config.InternalAPIKey)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -201,23 +206,51 @@ func (s *SongDeletionSaga) deleteFromNeo4j(ctx context.Context, songID string) e
 	return nil
 }
 
-// deleteFromHDFS deletes the audio file from HDFS (if exists)
+// deleteFromHDFS checks the song's audio file. The file itself is released by content-service when the
+// song is deleted from MongoDB: uploads are stored by content hash and may be shared with other songs.
 func (s *SongDeletionSaga) deleteFromHDFS(ctx context.Context, saga *model.SagaTransaction) error {
-	// Check if song has audio file
-	audioFileURL, ok := saga.SongData["audioFileUrl"].(string)
-	if !ok || audioFileURL == "" {
+	audioFile, ok := saga.SongData["audioFile"]
+	if !ok || audioFile == nil {
 		log.Printf("Song %s has no audio file, skipping HDFS deletion", saga.SongID)
 		return nil // Not an error if there's no file
 	}
 
-	// If it's an HDFS path, we would delete it here
-	// For now, we'll just log it (HDFS deletion can be implemented later)
-	if audioFileURL != "" {
-		log.Printf("Would delete audio file from HDFS: %s (not implemented yet)", audioFileURL)
-		// In a real implementation, we would call HDFS API to delete the file
-		// For now, we'll just succeed (as HDFS deletion is optional)
+	log.Printf("Audio file of song %s (%v) is released when the song is deleted", saga.SongID, audioFile)
+	return nil
+}
+
+// removeFromPlaylists removes the song from all playlists using internal endpoint.
+// The removed entries are kept in the step data so compensation can put them back.
+func (s *SongDeletionSaga) removeFromPlaylists(ctx context.Context, saga *model.SagaTransaction, step *model.SagaStep) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	url := fmt.Sprintf("%s/playlists/internal/songs?songId=%s", s.config.ContentServiceURL, saga.SongID)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Internal-Key", s.config.InternalAPIKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to remove song from playlists: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to remove song from playlists: status %d", resp.StatusCode)
 	}
 
+	var result struct {
+		Removed []map[string]interface{} `json:"removed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode removed playlist entries: %w", err)
+	}
+
+	step.Data = map[string]interface{}{"removed": result.Removed}
+	s.store.UpdateTransaction(ctx, saga)
+	log.Printf("Song %s removed from playlists (%d entries)", saga.SongID, len(result.Removed))
 	return nil
 }
 
@@ -230,6 +263,7 @@ func (s *SongDeletionSaga) deleteFromMongo(ctx context.Context, songID string) e
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("X-Internal-Key", s.config.InternalAPIKey)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -281,6 +315,9 @@ func (s *SongDeletionSaga) compensateStep(ctx context.Context, saga *model.SagaT
 		// Restore audio file to HDFS (if it was deleted)
 		log.Printf("Compensating: Would restore audio file for song %s to HDFS (not implemented)", saga.SongID)
 		return nil
+	case model.StepRemoveFromPlaylists:
+		// Put the song back into the playlists at its old positions
+		return s.restoreToPlaylists(ctx, step)
 	case model.StepDeleteFromMongo:
 		// Restore song to MongoDB
 		return s.restoreToMongo(ctx, saga)
@@ -325,3 +362,39 @@ func (s *SongDeletionSaga) restoreToMongo(ctx context.Context, saga *model.SagaT
 	log.Printf("Song %s restored to MongoDB successfully", saga.SongID)
 	return nil
 }
+
+// restoreToPlaylists puts the removed playlist entries back
+func (s *SongDeletionSaga) restoreToPlaylists(ctx context.Context, step *model.SagaStep) error {
+	removed, ok := step.Data["removed"]
+	if !ok {
+		return nil // Nothing was removed
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	url := fmt.Sprintf("%s/playlists/internal/songs/restore", s.config.ContentServiceURL)
+
+	body, err := json.Marshal(map[string]interface{}{"removed": removed})
+	if err != nil {
+		return fmt.Errorf("failed to marshal playlist entries: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Internal-Key", s.config.InternalAPIKey)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to restore playlist entries: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to restore playlist entries: status %d", resp.StatusCode)
+	}
+
+	log.Printf("Playlist entries restored successfully")
+	return nil
+}
@@ -20,6 +20,13 @@ const (
 	ActivityTypeGenreUnsubscribed ActivityType = "GENRE_UNSUBSCRIBED"
 	ActivityTypeArtistSubscribed  ActivityType = "ARTIST_SUBSCRIBED"
 	ActivityTypeArtistUnsubscribed ActivityType = "ARTIST_UNSUBSCRIBED"
+	// Library (likes)
+	ActivityTypeSongLiked     ActivityType = "SONG_LIKED"
+	ActivityTypeSongUnliked   ActivityType = "SONG_UNLIKED"
+	ActivityTypeAlbumLiked    ActivityType = "ALBUM_LIKED"
+	ActivityTypeAlbumUnliked  ActivityType = "ALBUM_UNLIKED"
+	ActivityTypeArtistLiked   ActivityType = "ARTIST_LIKED"
+	ActivityTypeArtistUnliked ActivityType = "ARTIST_UNLIKED"
 )
 
 // Activity represents a user activity to be logged
@@ -32,6 +39,8 @@ type Activity struct {
 	Genre      string       `json:"genre,omitempty"`
 	ArtistID   string       `json:"artistId,omitempty"`
 	ArtistName string       `json:"artistName,omitempty"`
+	AlbumID    string       `json:"albumId,omitempty"`
+	AlbumName  string       `json:"albumName,omitempty"`
 }
 
 // LogActivity logs a user activity to analytics service asynchronously
@@ -0,0 +1,179 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+)
+
+// Named permissions checked by the services. Roles are mapped to permissions in users-service
+// and the resolved list is embedded in the JWT ("permissions" claim).
+const (
+	CatalogArtistWrite = "catalog.artist.write"
+	CatalogAlbumWrite  = "catalog.album.write"
+	CatalogSongWrite   = "catalog.song.write"
+	SagasRun           = "sagas.run"
+	UsersManage        = "users.manage"
+	AuditRead          = "audit.read"
+	MailManage         = "mail.manage"
+	RolesManage        = "roles.manage"
+	// Impersonation ("view as user"); write access during impersonation is granted separately
+	UsersImpersonate      = "users.impersonate"
+	UsersImpersonateWrite = "users.impersonate.write"
+
+	// Listener permissions (rating, subscribing, personal activity)
+	RatingsWrite       = "ratings.write"
+	SubscriptionsWrite = "subscriptions.write"
+	ActivityRead       = "activity.read"
+)
+
+// AllPermissions lists every known permission
+var AllPermissions = []string{
+	CatalogArtistWrite,
+	CatalogAlbumWrite,
+	CatalogSongWrite,
+	SagasRun,
+	UsersManage,
+	AuditRead,
+	MailManage,
+	RolesManage,
+	UsersImpersonate,
+	UsersImpersonateWrite,
+	RatingsWrite,
+	SubscriptionsWrite,
+	ActivityRead,
+}
+
+// DefaultRolePermissions is the built-in mapping used to seed the roles collection.
+// It is also the fallback for tokens issued before permissions were embedded.
+var DefaultRolePermissions = map[string][]string{
+	"ADMIN": {
+		CatalogArtistWrite,
+		CatalogAlbumWrite,
+		CatalogSongWrite,
+		SagasRun,
+		UsersManage,
+		AuditRead,
+		MailManage,
+		RolesManage,
+		UsersImpersonate,
+	},
+	"USER": {
+		RatingsWrite,
+		SubscriptionsWrite,
+		ActivityRead,
+	},
+}
+
+// SelfPermissions only let the principal act on their own data (ratings, subscriptions, activity
+// history); holding them as another user grants no access to anyone else's data
+var SelfPermissions = []string{
+	RatingsWrite,
+	SubscriptionsWrite,
+	ActivityRead,
+}
+
+// IsSelfPermission reports whether the permission is one of SelfPermissions
+func IsSelfPermission(permission string) bool {
+	for _, p := range SelfPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsKnownPermission reports whether the permission is defined
+func IsKnownPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is the authenticated caller as seen by the authorization middleware
+type Principal struct {
+	UserID      string
+	Role        string
+	Permissions []string
+	// ImpersonatorID is set when an admin acts as UserID through an impersonation token
+	ImpersonatorID string
+}
+
+// Has reports whether the principal was granted the permission
+func (p *Principal) Has(permission string) bool {
+	if p == nil {
+		return false
+	}
+	permissions := p.Permissions
+	if permissions == nil {
+		permissions = DefaultRolePermissions[p.Role]
+	}
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Request headers the API gateway sets, from the verified token, on requests of an impersonation session
+const (
+	HeaderImpersonatorID   = "X-Impersonator-ID"
+	HeaderImpersonatedUser = "X-Impersonated-User"
+)
+
+// ImpersonationFrom returns the impersonating admin and the impersonated user forwarded by the API gateway
+func ImpersonationFrom(r *http.Request) (impersonatorID, userID string, ok bool) {
+	impersonatorID = r.Header.Get(HeaderImpersonatorID)
+	return impersonatorID, r.Header.Get(HeaderImpersonatedUser), impersonatorID != ""
+}
+
+type contextKey struct{}
+
+// WithPrincipal stores the principal in the context (called by the JWT middleware)
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored by WithPrincipal
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// DeniedFunc writes the response when authorization fails.
+// status is http.StatusUnauthorized when no principal is present, otherwise http.StatusForbidden.
+type DeniedFunc func(w http.ResponseWriter, r *http.Request, p *Principal, status int, reason string)
+
+// DefaultDenied writes a plain text error
+func DefaultDenied(w http.ResponseWriter, r *http.Request, p *Principal, status int, reason string) {
+	if status == http.StatusUnauthorized {
+		http.Error(w, "unauthorized", status)
+		return
+	}
+	http.Error(w, "forbidden: "+reason, status)
+}
+
+// RequirePermission allows the request only if the authenticated principal has the permission.
+// It must run after a middleware that called WithPrincipal.
+func RequirePermission(permission string, denied DeniedFunc) func(http.HandlerFunc) http.HandlerFunc {
+	if denied == nil {
+		denied = DefaultDenied
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFrom(r.Context())
+			if !ok {
+				denied(w, r, nil, http.StatusUnauthorized, "missing user claims in context")
+				return
+			}
+			if !p.Has(permission) {
+				denied(w, r, p, http.StatusForbidden, permission+" permission required")
+				return
+			}
+			next(w, r)
+		}
+	}
+}
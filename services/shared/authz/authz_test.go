@@ -0,0 +1,138 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPrincipalHas(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *Principal
+		permission string
+		want       bool
+	}{
+		{
+			name:       "nil principal",
+			principal:  nil,
+			permission: CatalogSongWrite,
+			want:       false,
+		},
+		{
+			name:       "explicit permission granted",
+			principal:  &Principal{UserID: "u1", Role: "USER", Permissions: []string{CatalogSongWrite}},
+			permission: CatalogSongWrite,
+			want:       true,
+		},
+		{
+			name:       "explicit permissions override the role",
+			principal:  &Principal{UserID: "u1", Role: "ADMIN", Permissions: []string{RatingsWrite}},
+			permission: CatalogSongWrite,
+			want:       false,
+		},
+		{
+			name:       "empty permission list grants nothing",
+			principal:  &Principal{UserID: "u1", Role: "ADMIN", Permissions: []string{}},
+			permission: CatalogSongWrite,
+			want:       false,
+		},
+		{
+			name:       "role fallback for tokens without permissions",
+			principal:  &Principal{UserID: "u1", Role: "ADMIN"},
+			permission: UsersImpersonate,
+			want:       true,
+		},
+		{
+			name:       "role fallback does not grant write impersonation",
+			principal:  &Principal{UserID: "u1", Role: "ADMIN"},
+			permission: UsersImpersonateWrite,
+			want:       false,
+		},
+		{
+			name:       "role fallback for listener",
+			principal:  &Principal{UserID: "u1", Role: "USER"},
+			permission: CatalogArtistWrite,
+			want:       false,
+		},
+		{
+			name:       "unknown role",
+			principal:  &Principal{UserID: "u1", Role: "GUEST"},
+			permission: RatingsWrite,
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.Has(tt.permission); got != tt.want {
+				t.Errorf("Has(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSelfPermission(t *testing.T) {
+	tests := []struct {
+		permission string
+		want       bool
+	}{
+		{RatingsWrite, true},
+		{SubscriptionsWrite, true},
+		{ActivityRead, true},
+		{CatalogSongWrite, false},
+		{UsersImpersonate, false},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			if got := IsSelfPermission(tt.permission); got != tt.want {
+				t.Errorf("IsSelfPermission(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		principal  *Principal
+		wantStatus int
+	}{
+		{
+			name:       "missing principal",
+			principal:  nil,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "permission missing",
+			principal:  &Principal{UserID: "u1", Role: "USER"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "permission granted",
+			principal:  &Principal{UserID: "u1", Permissions: []string{SagasRun}},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequirePermission(SagasRun, nil)(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/sagas", nil)
+			if tt.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tt.principal))
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package authz
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+// HeaderInternalKey carries the shared INTERNAL_API_KEY on service-to-service calls to internal endpoints
+const HeaderInternalKey = "X-Internal-Key"
+
+// RequireInternalKey protects an internal endpoint: only callers presenting the shared key in
+// X-Internal-Key (API gateway, saga-service, other services) are allowed. An empty key refuses everyone.
+func RequireInternalKey(key string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		presented := r.Header.Get(HeaderInternalKey)
+		if key == "" || presented == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(key)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireInternalKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		presented  string
+		wantStatus int
+	}{
+		{
+			name:       "matching key",
+			key:        "secret",
+			presented:  "secret",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing header",
+			key:        "secret",
+			presented:  "",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong key",
+			key:        "secret",
+			presented:  "guess",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unconfigured key refuses everyone",
+			key:        "",
+			presented:  "",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RequireInternalKey(tt.key, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/catalog/internal/backup", nil)
+			if tt.presented != "" {
+				req.Header.Set(HeaderInternalKey, tt.presented)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
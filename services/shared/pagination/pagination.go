@@ -0,0 +1,69 @@
+package pagination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Catalog list endpoints return a JSON array with at most `limit` items.
+// When there are more, the opaque cursor of the next page is returned in this header
+// and is passed back as the `cursor` query parameter.
+const HeaderNextCursor = "X-Next-Cursor"
+
+// MaxPageSize is the largest page the list endpoints return
+const MaxPageSize = 200
+
+// maxPages guards against a cursor loop
+const maxPages = 1000
+
+// FetchAll follows the cursors of a list endpoint and returns all items as one JSON array,
+// so callers that need the whole list can decode it as before.
+// listURL may already contain query parameters (filters, sort).
+func FetchAll(ctx context.Context, client *http.Client, listURL string) ([]byte, error) {
+	u, err := url.Parse(listURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("limit", fmt.Sprint(MaxPageSize))
+
+	var items []json.RawMessage
+	for page := 0; page < maxPages; page++ {
+		u.RawQuery = query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var pageItems []json.RawMessage
+		if resp.StatusCode != http.StatusOK {
+			detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s returned %d: %s", u.Path, resp.StatusCode, detail)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&pageItems)
+		next := resp.Header.Get(HeaderNextCursor)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, pageItems...)
+		if next == "" {
+			if items == nil {
+				items = []json.RawMessage{}
+			}
+			return json.Marshal(items)
+		}
+		query.Set("cursor", next)
+	}
+	return nil, fmt.Errorf("%s: too many pages", u.Path)
+}
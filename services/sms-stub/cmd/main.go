@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Local stand-in for the SMS provider API used by users-service.
+// POST /messages accepts {"from","to","message"} and GET /messages?to= lists what was "sent",
+// so OTP and phone verification codes can be read during development and tests.
+// Both require "Authorization: Bearer <SMS_API_KEY>", the listing holds live login codes.
+
+type message struct {
+	ID        int       `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const maxMessages = 500
+
+type store struct {
+	mu       sync.Mutex
+	messages []message
+	nextID   int
+}
+
+func (s *store) add(m message) message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	m.ID = s.nextID
+	m.CreatedAt = time.Now()
+	s.messages = append(s.messages, m)
+	if len(s.messages) > maxMessages {
+		s.messages = s.messages[len(s.messages)-maxMessages:]
+	}
+	return m
+}
+
+func (s *store) list(to string) []message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := []message{}
+	// Newest first
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if to == "" || s.messages[i].To == to {
+			result = append(result, s.messages[i])
+		}
+	}
+	return result
+}
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8090"
+	}
+	apiKey := os.Getenv("SMS_API_KEY")
+	if apiKey == "" {
+		log.Fatal("SMS_API_KEY is required")
+	}
+	// SMS_STUB_FAIL=true makes every send fail, to exercise the email fallback
+	failAll := os.Getenv("SMS_STUB_FAIL") == "true"
+
+	s := &store{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "healthy", "service": "sms-stub"})
+	})
+
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+apiKey)) != 1 {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			if failAll {
+				http.Error(w, "provider unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			var m message
+			if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+				http.Error(w, "invalid JSON body", http.StatusBadRequest)
+				return
+			}
+			if !strings.HasPrefix(m.To, "+") || m.Message == "" {
+				http.Error(w, "to (E.164) and message are required", http.StatusBadRequest)
+				return
+			}
+			m = s.add(m)
+			log.Printf("[SMS] #%d to %s from %s: %s", m.ID, m.To, m.From, m.Message)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": m.ID, "status": "queued"})
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(s.list(r.URL.Query().Get("to")))
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	log.Println("SMS stub running on port", port)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
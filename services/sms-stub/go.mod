@@ -0,0 +1,3 @@
+module sms-stub
+
+go 1.21
@@ -18,6 +18,7 @@ import (
 	"subscriptions-service/internal/model"
 	"subscriptions-service/internal/store"
 	"shared/analytics"
+	"shared/authz"
 	"shared/tracing"
 )
 
@@ -295,6 +296,8 @@ func handleNewArtistEvent(ctx context.Context, event map[string]interface{}, rep
 	artistID, _ := event["artistId"].(string)
 	artistName, _ := event["name"].(string)
 	genres, _ := event["genres"].([]interface{})
+	// Set when the artist was restored from the trash of content-service
+	restored, _ := event["restored"].(bool)
 
 	if artistID == "" {
 		log.Printf("Invalid new_artist event: missing artistId")
@@ -321,6 +324,9 @@ func handleNewArtistEvent(ctx context.Context, event map[string]interface{}, rep
 
 		for _, sub := range subscriptions {
 			message := fmt.Sprintf("New artist '%s' in genre %s has been added", artistName, genre)
+			if restored {
+				message = fmt.Sprintf("Artist '%s' in genre %s is available again", artistName, genre)
+			}
 			createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_artist", message, artistID)
 		}
 	}
@@ -328,6 +334,25 @@ func handleNewArtistEvent(ctx context.Context, event map[string]interface{}, rep
 	log.Printf("Processed new_artist event for artist %s", artistID)
 }
 
+// handleArtistUpdatedEvent keeps the denormalized artist name of subscriptions in sync after an edit
+func handleArtistUpdatedEvent(ctx context.Context, event map[string]interface{}, repo *store.SubscriptionRepository) {
+	artistID, _ := event["artistId"].(string)
+	artistName, _ := event["name"].(string)
+
+	if artistID == "" || artistName == "" {
+		log.Printf("Invalid artist_updated event: missing artistId or name")
+		return
+	}
+
+	updated, err := repo.UpdateArtistNameByArtist(ctx, artistID, artistName)
+	if err != nil {
+		log.Printf("Error updating artist name in subscriptions for artist %s: %v", artistID, err)
+		return
+	}
+
+	log.Printf("Processed artist_updated event for artist %s (%d subscriptions updated)", artistID, updated)
+}
+
 // handleNewAlbumEvent processes new album events
 func handleNewAlbumEvent(ctx context.Context, event map[string]interface{}, repo *store.SubscriptionRepository, cfg *config.Config) {
 	albumID, _ := event["albumId"].(string)
@@ -335,6 +360,8 @@ func handleNewAlbumEvent(ctx context.Context, event map[string]interface{}, repo
 	genre, _ := event["genre"].(string)
 	artistIDs, _ := event["artistIds"].([]interface{})
 	artistNamesInterface, _ := event["artistNames"].([]interface{})
+	// Set when the album was restored from the trash of content-service
+	restored, _ := event["restored"].(bool)
 
 	if albumID == "" {
 		log.Printf("Invalid new_album event: missing albumId")
@@ -380,6 +407,9 @@ func handleNewAlbumEvent(ctx context.Context, event map[string]interface{}, repo
 		for _, sub := range subscriptions {
 			if !notifiedUsers[sub.UserID] {
 				message := fmt.Sprintf("New album '%s' by %s has been released", albumName, artistNamesStr)
+				if restored {
+					message = fmt.Sprintf("Album '%s' by %s is available again", albumName, artistNamesStr)
+				}
 				createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_album", message, albumID)
 				notifiedUsers[sub.UserID] = true
 			}
@@ -400,6 +430,9 @@ func handleNewAlbumEvent(ctx context.Context, event map[string]interface{}, repo
 				// Only notify if user hasn't been notified already (to avoid duplicates)
 				if !notifiedUsers[sub.UserID] {
 					message := fmt.Sprintf("New album '%s' in genre %s has been released", albumName, genre)
+					if restored {
+						message = fmt.Sprintf("Album '%s' in genre %s is available again", albumName, genre)
+					}
 					log.Printf("[GENRE] Creating genre notification for user %s: %s", sub.UserID, message)
 					createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_album", message, albumID)
 					notifiedUsers[sub.UserID] = true
@@ -415,6 +448,66 @@ func handleNewAlbumEvent(ctx context.Context, event map[string]interface{}, repo
 	log.Printf("Processed new_album event for album %s", albumID)
 }
 
+// handleAlbumUpdatedEvent notifies the subscribers of artists added to an edited album, as
+// new_album does for the artists of a new one
+func handleAlbumUpdatedEvent(ctx context.Context, event map[string]interface{}, repo *store.SubscriptionRepository, cfg *config.Config) {
+	albumID, _ := event["albumId"].(string)
+	albumName, _ := event["name"].(string)
+	addedArtistIDs, _ := event["addedArtistIds"].([]interface{})
+	artistNamesInterface, _ := event["artistNames"].([]interface{})
+
+	if albumID == "" {
+		log.Printf("Invalid album_updated event: missing albumId")
+		return
+	}
+
+	// Convert artist names to string slice
+	artistNames := make([]string, 0, len(artistNamesInterface))
+	for _, nameInterface := range artistNamesInterface {
+		if name, ok := nameInterface.(string); ok {
+			artistNames = append(artistNames, name)
+		}
+	}
+
+	// Format artist names for message
+	artistNamesStr := "artist"
+	if len(artistNames) > 0 {
+		if len(artistNames) == 1 {
+			artistNamesStr = artistNames[0]
+		} else if len(artistNames) == 2 {
+			artistNamesStr = artistNames[0] + " and " + artistNames[1]
+		} else {
+			artistNamesStr = artistNames[0] + " and others"
+		}
+	}
+
+	// Track notified users to avoid duplicate notifications
+	notifiedUsers := make(map[string]bool)
+
+	for _, artistIDInterface := range addedArtistIDs {
+		artistID, ok := artistIDInterface.(string)
+		if !ok {
+			continue
+		}
+
+		subscriptions, err := repo.GetByArtistID(ctx, artistID)
+		if err != nil {
+			log.Printf("Error getting subscriptions for artist %s: %v", artistID, err)
+			continue
+		}
+
+		for _, sub := range subscriptions {
+			if !notifiedUsers[sub.UserID] {
+				message := fmt.Sprintf("Album '%s' by %s has been added", albumName, artistNamesStr)
+				createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_album", message, albumID)
+				notifiedUsers[sub.UserID] = true
+			}
+		}
+	}
+
+	log.Printf("Processed album_updated event for album %s (%d users notified)", albumID, len(notifiedUsers))
+}
+
 // handleNewSongEvent processes new song events
 func handleNewSongEvent(ctx context.Context, event map[string]interface{}, repo *store.SubscriptionRepository, cfg *config.Config) {
 	// Log entire event for debugging
@@ -426,6 +519,8 @@ func handleNewSongEvent(ctx context.Context, event map[string]interface{}, repo
 	genre, _ := event["genre"].(string)
 	artistIDs, _ := event["artistIds"].([]interface{})
 	artistNamesInterface, _ := event["artistNames"].([]interface{})
+	// Set when the song was restored from the trash of content-service
+	restored, _ := event["restored"].(bool)
 
 	log.Printf("[DEBUG] Processing new_song event - SongID: %s, Name: %s, Genre: '%s' (empty: %v)", songID, songName, genre, genre == "")
 
@@ -473,6 +568,9 @@ func handleNewSongEvent(ctx context.Context, event map[string]interface{}, repo
 		for _, sub := range subscriptions {
 			if !notifiedUsers[sub.UserID] {
 				message := fmt.Sprintf("New song '%s' by %s has been added", songName, artistNamesStr)
+				if restored {
+					message = fmt.Sprintf("Song '%s' by %s is available again", songName, artistNamesStr)
+				}
 				createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_song", message, songID)
 				notifiedUsers[sub.UserID] = true
 			}
@@ -496,6 +594,9 @@ func handleNewSongEvent(ctx context.Context, event map[string]interface{}, repo
 				// Only notify if user hasn't been notified already (to avoid duplicates)
 				if !notifiedUsers[sub.UserID] {
 					message := fmt.Sprintf("New song '%s' in genre %s has been added", songName, genre)
+					if restored {
+						message = fmt.Sprintf("Song '%s' in genre %s is available again", songName, genre)
+					}
 					log.Printf("[GENRE] Creating genre notification for user %s: %s", sub.UserID, message)
 					createNotification(cfg.NotificationsServiceURL, sub.UserID, "new_song", message, songID)
 					notifiedUsers[sub.UserID] = true
@@ -680,6 +781,66 @@ func main() {
 		json.NewEncoder(w).Encode(subscriptions)
 	})
 
+	// Delete all subscriptions to an artist (saga-service, when the artist is deleted; X-Internal-Key)
+	// DELETE /subscriptions/internal/artist?artistId={id} - returns the removed subscriptions
+	mux.HandleFunc("/subscriptions/internal/artist", authz.RequireInternalKey(cfg.InternalAPIKey, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		artistID := r.URL.Query().Get("artistId")
+		if artistID == "" {
+			http.Error(w, "artistId parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		removed, err := subscriptionRepo.DeleteByArtistID(ctx, artistID)
+		if err != nil {
+			log.Printf("Error deleting subscriptions to artist %s: %v", artistID, err)
+			http.Error(w, "failed to delete subscriptions", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Deleted %d subscriptions to artist %s", len(removed), artistID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"artistId": artistID,
+			"removed":  removed,
+		})
+	}))
+
+	// Put back subscriptions removed by /subscriptions/internal/artist (saga compensation; X-Internal-Key)
+	// POST /subscriptions/internal/restore {"subscriptions": [...]}
+	mux.HandleFunc("/subscriptions/internal/restore", authz.RequireInternalKey(cfg.InternalAPIKey, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Subscriptions []*model.Subscription `json:"subscriptions"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := subscriptionRepo.Restore(ctx, req.Subscriptions); err != nil {
+			log.Printf("Error restoring subscriptions: %v", err)
+			http.Error(w, "failed to restore subscriptions", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
 	// Subscribe to artist endpoint with synchronous validation
 	mux.HandleFunc("/subscribe-artist", func(w http.ResponseWriter, r *http.Request) {
 		addCORSHeaders(w)
@@ -1043,6 +1204,10 @@ func main() {
 			handleNewAlbumEvent(ctx, event, subscriptionRepo, cfg)
 		case "new_song":
 			handleNewSongEvent(ctx, event, subscriptionRepo, cfg)
+		case "artist_updated":
+			handleArtistUpdatedEvent(ctx, event, subscriptionRepo)
+		case "album_updated":
+			handleAlbumUpdatedEvent(ctx, event, subscriptionRepo, cfg)
 		default:
 			log.Printf("Unknown event type: %s", eventType)
 			http.Error(w, "unknown event type", http.StatusBadRequest)
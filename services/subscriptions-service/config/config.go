@@ -10,6 +10,7 @@ type Config struct {
 	NotificationsServiceURL string
 	RecommendationServiceURL string
 	AnalyticsServiceURL     string
+	InternalAPIKey          string // Expected X-Internal-Key on internal endpoints (saga-service)
 }
 
 func Load() *Config {
@@ -48,6 +49,11 @@ func Load() *Config {
 		analyticsServiceURL = "http://analytics-service:8007"
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match saga-service
+	}
+
 	return &Config{
 		Port:                     port,
 		ContentServiceURL:        contentServiceURL,
@@ -56,5 +62,6 @@ func Load() *Config {
 		NotificationsServiceURL:  notificationsServiceURL,
 		RecommendationServiceURL: recommendationServiceURL,
 		AnalyticsServiceURL:      analyticsServiceURL,
+		InternalAPIKey:           internalAPIKey,
 	}
 }
@@ -180,6 +180,43 @@ func (r *SubscriptionRepository) GetByArtistID(ctx context.Context, artistID str
 	return subscriptions, nil
 }
 
+// DeleteByArtistID deletes all subscriptions to an artist and returns them
+func (r *SubscriptionRepository) DeleteByArtistID(ctx context.Context, artistID string) ([]*model.Subscription, error) {
+	subscriptions, err := r.GetByArtistID(ctx, artistID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return []*model.Subscription{}, nil
+	}
+
+	ids := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		ids = append(ids, subscription.ID)
+	}
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// Restore puts back deleted subscriptions with their original IDs and creation times
+func (r *SubscriptionRepository) Restore(ctx context.Context, subscriptions []*model.Subscription) error {
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": subscription.ID}).
+			SetReplacement(subscription).
+			SetUpsert(true))
+	}
+	_, err := r.collection.BulkWrite(ctx, models)
+	return err
+}
+
 // GetByGenre returns all subscriptions for a specific genre (case-insensitive)
 func (r *SubscriptionRepository) GetByGenre(ctx context.Context, genre string) ([]*model.Subscription, error) {
 	// Normalize genre to lowercase for case-insensitive matching
@@ -221,3 +258,17 @@ func (r *SubscriptionRepository) UpdateArtistName(ctx context.Context, subscript
 	}
 	return nil
 }
+
+// UpdateArtistNameByArtist renames the artist in all subscriptions to it and returns how many were
+// changed (CQRS - 2.9)
+func (r *SubscriptionRepository) UpdateArtistNameByArtist(ctx context.Context, artistID, artistName string) (int64, error) {
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"type": "artist", "artistId": artistID},
+		bson.M{"$set": bson.M{"artistName": artistName}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -12,12 +12,16 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"users-service/config"
+	"users-service/internal/delivery"
 	"users-service/internal/handler"
 	"users-service/internal/logger"
 	"users-service/internal/mail"
 	"users-service/internal/middleware"
 	"users-service/internal/model"
+	"users-service/internal/security"
 	"users-service/internal/store"
+	"users-service/internal/validation"
+	"shared/authz"
 	"shared/tracing"
 )
 
@@ -76,7 +80,12 @@ func main() {
 	log.Println("Connected to MongoDB")
 
 	// Initialize repository
-	userRepo := store.NewUserRepository(dbStore.Database)
+	userRepo := store.NewUserRepository(dbStore.Database, []byte(cfg.TokenHashKey), cfg.OTPMaxAttempts)
+	indexCtx, indexCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := userRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create token indexes: %v", err)
+	}
+	indexCancel()
 
 	// Initialize logger
 	logDir := os.Getenv("LOG_DIR")
@@ -90,19 +99,72 @@ func main() {
 	}
 	defer appLogger.Close()
 
-	// Initialize email service
-	mail.Init(cfg)
+	// Initialize email service (templated emails delivered through a persistent outbox)
+	outboxRepo := store.NewOutboxRepository(dbStore.Database)
+	indexCtx, indexCancel = context.WithTimeout(context.Background(), 10*time.Second)
+	if err := outboxRepo.EnsureIndexes(indexCtx); err != nil {
+		log.Printf("Warning: Failed to create outbox indexes: %v", err)
+	}
+	indexCancel()
+	if err := mail.Init(cfg, outboxRepo); err != nil {
+		log.Fatal("Failed to initialize mail transport:", err)
+	}
+
+	// Initialize breached password screening
+	if err := validation.InitBreachedPasswords(cfg.BreachedPasswordsDir); err != nil {
+		log.Fatal("Failed to initialize breached password screening:", err)
+	}
 
 	// Initialize admin user
 	ctx := context.Background()
 	initAdminUser(ctx, userRepo, cfg)
 
+	// Seed built-in roles (role -> permission mapping embedded in JWTs at login)
+	roleRepo := store.NewRoleRepository(dbStore.Database)
+	if err := roleRepo.SeedDefaults(ctx); err != nil {
+		log.Printf("Warning: Failed to seed roles: %v", err)
+	}
+
+	// Background worker for password expiration reminders
+	bgCtx, cancelBg := context.WithCancel(ctx)
+	defer cancelBg()
+	go handler.StartPasswordReminder(bgCtx, userRepo, cfg)
+	go mail.StartOutboxWorker(bgCtx)
+
+	// Login risk scoring (new device / IP range / impossible travel)
+	loginEventRepo := store.NewLoginEventRepository(dbStore.Database)
+	var geoIP *security.GeoIPDB
+	if cfg.GeoIPDBPath != "" {
+		geoIP, err = security.LoadGeoIPDB(cfg.GeoIPDBPath)
+		if err != nil {
+			log.Printf("Warning: Failed to load GeoIP database %s: %v, location checks disabled", cfg.GeoIPDBPath, err)
+		} else {
+			log.Printf("GeoIP database loaded (%d ranges)", geoIP.Size())
+		}
+	}
+	loginMonitor := handler.NewLoginMonitor(userRepo, loginEventRepo, geoIP, cfg, appLogger)
+
+	// OTP delivery channels (email is the fallback when SMS delivery fails)
+	smsProvider, err := delivery.NewSMSProvider(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize SMS provider:", err)
+	}
+	log.Printf("[SMS] Provider configured: %s", smsProvider.Name())
+	smsChannel := delivery.NewSMSChannel(smsProvider)
+	otpChannels := delivery.NewRegistry(delivery.EmailChannel{}, smsChannel)
+
 	// inicijalizacija handler-a
 	registerHandler := handler.NewRegisterHandler(userRepo, cfg, appLogger)
-	loginHandler := handler.NewLoginHandler(userRepo, cfg, appLogger)
+	loginHandler := handler.NewLoginHandler(userRepo, roleRepo, otpChannels, cfg, appLogger, loginMonitor)
 	passwordHandler := handler.NewPasswordHandler(userRepo, cfg)
-	magicLinkHandler := handler.NewMagicLinkHandler(userRepo, cfg)
+	magicLinkHandler := handler.NewMagicLinkHandler(userRepo, roleRepo, cfg, loginMonitor)
+	loginAlertHandler := handler.NewLoginAlertHandler(userRepo, loginEventRepo, cfg, appLogger)
+	emailChangeHandler := handler.NewEmailChangeHandler(userRepo, cfg, appLogger)
 	verificationHandler := handler.NewVerificationHandler(userRepo)
+	mailAdminHandler := handler.NewMailAdminHandler(outboxRepo, cfg, appLogger)
+	roleAdminHandler := handler.NewRoleAdminHandler(roleRepo, userRepo, cfg, appLogger)
+	impersonationHandler := handler.NewImpersonationHandler(userRepo, roleRepo, cfg, appLogger)
+	phoneHandler := handler.NewPhoneHandler(userRepo, smsChannel, otpChannels, cfg, appLogger)
 
 	// router
 	mux := http.NewServeMux()
@@ -123,12 +185,23 @@ func main() {
 	mux.HandleFunc("/login/request-otp", rateLimit(loginHandler.RequestOTP))
 	mux.HandleFunc("/login/verify-otp", rateLimit(loginHandler.VerifyOTP))
 	mux.HandleFunc("/logout", rateLimit(loginHandler.Logout))
+	mux.HandleFunc("/login/not-me", rateLimit(loginAlertHandler.NotMe))
 
 	// password endpoints (rate limited)
 	mux.HandleFunc("/password/change", rateLimit(passwordHandler.ChangePassword))
 	mux.HandleFunc("/password/reset/request", rateLimit(passwordHandler.RequestPasswordReset))
 	mux.HandleFunc("/password/reset", rateLimit(passwordHandler.ResetPassword))
 
+	// email change endpoints (rate limited)
+	mux.HandleFunc("/email/change", rateLimit(emailChangeHandler.RequestEmailChange))
+	mux.HandleFunc("/email/change/confirm", rateLimit(emailChangeHandler.ConfirmEmailChange))
+	mux.HandleFunc("/email/change/cancel", rateLimit(emailChangeHandler.CancelEmailChange))
+
+	// phone number and OTP delivery channel (rate limited)
+	mux.HandleFunc("/phone", rateLimit(phoneHandler.Phone))
+	mux.HandleFunc("/phone/verify", rateLimit(phoneHandler.VerifyPhone))
+	mux.HandleFunc("/otp/channel", rateLimit(phoneHandler.OTPChannel))
+
 	// email verification endpoint (rate limited)
 	mux.HandleFunc("/verify-email", rateLimit(verificationHandler.VerifyEmail))
 
@@ -136,6 +209,23 @@ func main() {
 	mux.HandleFunc("/recover/request", rateLimit(magicLinkHandler.RequestMagicLink))
 	mux.HandleFunc("/recover/verify", rateLimit(magicLinkHandler.VerifyMagicLink))
 
+	// admin: email delivery status (mail.manage, checked here and by the API gateway)
+	mux.HandleFunc("/admin/mail/outbox", handler.RequirePermission(cfg, authz.MailManage, mailAdminHandler.ListOutbox))
+	mux.HandleFunc("/admin/mail/outbox/", handler.RequirePermission(cfg, authz.MailManage, mailAdminHandler.OutboxEmail))
+	// admin: login report (audit.read, checked here and by the API gateway)
+	mux.HandleFunc("/admin/security/high-risk-logins", handler.RequirePermission(cfg, authz.AuditRead, loginAlertHandler.HighRiskLogins))
+
+	// admin: roles and permissions (roles.manage / users.manage, checked here and by the API gateway)
+	mux.HandleFunc("/admin/roles", handler.RequirePermission(cfg, authz.RolesManage, roleAdminHandler.ListRoles))
+	mux.HandleFunc("/admin/roles/", handler.RequirePermission(cfg, authz.RolesManage, roleAdminHandler.Role))
+	mux.HandleFunc("/admin/users/", handler.RequirePermission(cfg, authz.UsersManage, roleAdminHandler.AssignRole))
+
+	// admin: "view as user" impersonation tokens (users.impersonate, checked here and by the API gateway)
+	mux.HandleFunc("/admin/impersonate", handler.RequirePermission(cfg, authz.UsersImpersonate, impersonationHandler.Impersonate))
+
+	// internal: revoked sessions, polled by the API gateway (X-Internal-Key)
+	mux.HandleFunc("/internal/sessions/revocations", handler.RequireInternalKey(cfg, loginAlertHandler.SessionRevocations))
+
 	log.Println("Users service running on port", cfg.Port)
 	
 	// Support HTTPS if certificates are provided
@@ -148,7 +238,7 @@ func main() {
 			Handler: mux,
 		}
 		// Wrap server handler with tracing middleware
-		server.Handler = tracing.HTTPMiddleware(mux)
+		server.Handler = tracing.HTTPMiddleware(middleware.ImpersonationAudit(appLogger, mux))
 		if err := server.ListenAndServeTLS(certFile, keyFile); err != nil {
 			if appLogger != nil {
 				appLogger.LogTLSFailure("users-service", err.Error(), "")
@@ -158,7 +248,7 @@ func main() {
 	} else {
 		log.Println("Starting HTTP server on port", cfg.Port)
 		// Wrap mux with tracing middleware
-		handler := tracing.HTTPMiddleware(mux)
+		handler := tracing.HTTPMiddleware(middleware.ImpersonationAudit(appLogger, mux))
 		log.Fatal(http.ListenAndServe(":"+cfg.Port, handler))
 	}
 }
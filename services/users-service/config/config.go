@@ -8,10 +8,14 @@ import (
 type Config struct {
 	Port                   string
 	JWTSecret              string
+	InternalAPIKey         string // Expected X-Internal-Key on internal endpoints (called by the API gateway)
 	MongoDBURI             string
 	MongoDBDatabase        string
 	BaseURL                string
-	PasswordExpirationDays int // Number of days until password expires (default 60, can be overridden for testing)
+	PasswordExpirationDays int    // Number of days until password expires (default 60, can be overridden for testing)
+	PasswordHistorySize    int    // Number of previous password hashes that cannot be reused
+	BreachedPasswordsDir   string // Directory with SHA-1 k-anonymity prefix files (empty checks only the built-in list)
+	PasswordReminderDays   int    // Days before PasswordExpiresAt when a reminder email is sent
 	// SMTP Configuration
 	SMTPHost     string
 	SMTPPort     int
@@ -19,6 +23,26 @@ type Config struct {
 	SMTPPassword string
 	SMTPFrom     string // From email address
 	FrontendURL  string // Frontend URL for links in emails
+	// Mail delivery
+	MailTransport     string // smtp, mailhog, file or log (derived from SMTP settings if empty)
+	MailFileDropDir   string // Directory for the file-drop transport (used in tests)
+	MailTemplatesDir  string // Optional directory overriding the embedded email templates
+	MailDefaultLocale string // Locale used when the user has none (sr or en)
+	MailMaxAttempts   int    // Delivery attempts before an outbox email is marked FAILED
+	// Login risk scoring
+	GeoIPDBPath            string // Local GeoIP CSV database (empty disables location checks)
+	LoginAlertThreshold    int    // Risk score at which the user is emailed about the login
+	HighRiskLoginThreshold int    // Risk score at which a login shows up in the admin report
+	// One-time codes and tokens
+	TokenHashKey   string // HMAC key for stored OTPs and tokens (defaults to JWTSecret)
+	OTPMaxAttempts int    // Verification attempts allowed per OTP before it is invalidated
+	// Admin impersonation
+	ImpersonationTokenMinutes int // Lifetime of an impersonation token
+	// SMS delivery of OTPs
+	SMSProvider string // http or log (log when SMS_API_URL is empty)
+	SMSAPIURL   string // HTTP SMS API endpoint (the sms-stub service locally)
+	SMSAPIKey   string // Bearer key for the SMS API
+	SMSSender   string // Sender ID shown on the phone
 }
 
 func Load() *Config {
@@ -32,6 +56,11 @@ func Load() *Config {
 		jwtSecret = "your-secret-key-change-in-production" // Default secret, should be changed in production
 	}
 
+	internalAPIKey := os.Getenv("INTERNAL_API_KEY")
+	if internalAPIKey == "" {
+		internalAPIKey = "your-internal-key-change-in-production" // Default, should match api-gateway
+	}
+
 	mongoURI := os.Getenv("MONGODB_URI")
 	if mongoURI == "" {
 		mongoURI = "mongodb://localhost:27017"
@@ -57,6 +86,26 @@ func Load() *Config {
 		}
 	}
 
+	// Password history - last N hashes are kept and cannot be reused (0 disables history)
+	passwordHistorySize := 5
+	if size := os.Getenv("PASSWORD_HISTORY_SIZE"); size != "" {
+		if parsedSize, err := strconv.Atoi(size); err == nil && parsedSize >= 0 {
+			passwordHistorySize = parsedSize
+		}
+	}
+
+	// Breached password corpus - directory with files named by the first 5 hex chars
+	// of the SHA-1 hash, each line being "SUFFIX:COUNT" (same format as HIBP range API).
+	// The built-in list of common passwords is checked either way.
+	breachedPasswordsDir := os.Getenv("BREACHED_PASSWORDS_DIR")
+
+	passwordReminderDays := 7
+	if days := os.Getenv("PASSWORD_REMINDER_DAYS"); days != "" {
+		if parsedDays, err := strconv.Atoi(days); err == nil && parsedDays > 0 {
+			passwordReminderDays = parsedDays
+		}
+	}
+
 	// SMTP Configuration
 	smtpHost := os.Getenv("SMTP_HOST")
 	// Don't set default - if not configured, will use mock mode
@@ -80,18 +129,115 @@ func Load() *Config {
 		frontendURL = "https://localhost:3000" // Default frontend URL
 	}
 
+	// Mail transport - if not set, derive from SMTP configuration (log when SMTP is not configured)
+	mailTransport := os.Getenv("MAIL_TRANSPORT")
+	if mailTransport == "" {
+		switch {
+		case smtpHost == "":
+			mailTransport = "log"
+		case smtpHost == "mailhog":
+			mailTransport = "mailhog"
+		default:
+			mailTransport = "smtp"
+		}
+	}
+
+	mailFileDropDir := os.Getenv("MAIL_FILE_DROP_DIR")
+	if mailFileDropDir == "" {
+		mailFileDropDir = "./mail-drop"
+	}
+
+	mailDefaultLocale := os.Getenv("MAIL_DEFAULT_LOCALE")
+	if mailDefaultLocale == "" {
+		mailDefaultLocale = "en"
+	}
+
+	mailMaxAttempts := 5
+	if attempts := os.Getenv("MAIL_MAX_ATTEMPTS"); attempts != "" {
+		if parsedAttempts, err := strconv.Atoi(attempts); err == nil && parsedAttempts > 0 {
+			mailMaxAttempts = parsedAttempts
+		}
+	}
+
+	loginAlertThreshold := 40
+	if threshold := os.Getenv("LOGIN_ALERT_THRESHOLD"); threshold != "" {
+		if parsed, err := strconv.Atoi(threshold); err == nil && parsed > 0 {
+			loginAlertThreshold = parsed
+		}
+	}
+
+	highRiskLoginThreshold := 70
+	if threshold := os.Getenv("HIGH_RISK_LOGIN_THRESHOLD"); threshold != "" {
+		if parsed, err := strconv.Atoi(threshold); err == nil && parsed > 0 {
+			highRiskLoginThreshold = parsed
+		}
+	}
+
+	tokenHashKey := os.Getenv("TOKEN_HASH_KEY")
+	if tokenHashKey == "" {
+		tokenHashKey = jwtSecret
+	}
+
+	otpMaxAttempts := 5
+	if attempts := os.Getenv("OTP_MAX_ATTEMPTS"); attempts != "" {
+		if parsed, err := strconv.Atoi(attempts); err == nil && parsed > 0 {
+			otpMaxAttempts = parsed
+		}
+	}
+
+	impersonationTokenMinutes := 15
+	if minutes := os.Getenv("IMPERSONATION_TOKEN_MINUTES"); minutes != "" {
+		if parsed, err := strconv.Atoi(minutes); err == nil && parsed > 0 && parsed <= 60 {
+			impersonationTokenMinutes = parsed
+		}
+	}
+
+	smsAPIURL := os.Getenv("SMS_API_URL")
+	smsProvider := os.Getenv("SMS_PROVIDER")
+	if smsProvider == "" {
+		if smsAPIURL == "" {
+			smsProvider = "log"
+		} else {
+			smsProvider = "http"
+		}
+	}
+
+	smsSender := os.Getenv("SMS_SENDER")
+	if smsSender == "" {
+		smsSender = "MusicStream"
+	}
+
 	return &Config{
-		Port:                   port,
-		JWTSecret:              jwtSecret,
-		MongoDBURI:             mongoURI,
-		MongoDBDatabase:        mongoDB,
-		BaseURL:                baseURL,
-		PasswordExpirationDays: passwordExpirationDays,
-		SMTPHost:               smtpHost,
-		SMTPPort:               smtpPort,
-		SMTPUsername:            smtpUsername,
-		SMTPPassword:            smtpPassword,
-		SMTPFrom:                smtpFrom,
-		FrontendURL:             frontendURL,
+		Port:                      port,
+		JWTSecret:                 jwtSecret,
+		InternalAPIKey:            internalAPIKey,
+		MongoDBURI:                mongoURI,
+		MongoDBDatabase:           mongoDB,
+		BaseURL:                   baseURL,
+		PasswordExpirationDays:    passwordExpirationDays,
+		PasswordHistorySize:       passwordHistorySize,
+		BreachedPasswordsDir:      breachedPasswordsDir,
+		PasswordReminderDays:      passwordReminderDays,
+		SMTPHost:                  smtpHost,
+		SMTPPort:                  smtpPort,
+		SMTPUsername:              smtpUsername,
+		SMTPPassword:              smtpPassword,
+		SMTPFrom:                  smtpFrom,
+		FrontendURL:               frontendURL,
+		MailTransport:             mailTransport,
+		MailFileDropDir:           mailFileDropDir,
+		MailTemplatesDir:          os.Getenv("MAIL_TEMPLATES_DIR"),
+		MailDefaultLocale:         mailDefaultLocale,
+		MailMaxAttempts:           mailMaxAttempts,
+		GeoIPDBPath:               os.Getenv("GEOIP_DB_PATH"),
+		LoginAlertThreshold:       loginAlertThreshold,
+		HighRiskLoginThreshold:    highRiskLoginThreshold,
+		TokenHashKey:              tokenHashKey,
+		OTPMaxAttempts:            otpMaxAttempts,
+		ImpersonationTokenMinutes: impersonationTokenMinutes,
+		SMSProvider:               smsProvider,
+		SMSAPIURL:                 smsAPIURL,
+		SMSAPIKey:                 os.Getenv("SMS_API_KEY"),
+		SMSSender:                 smsSender,
 	}
 }
@@ -0,0 +1,84 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+
+	"users-service/internal/model"
+)
+
+var ErrChannelUnavailable = errors.New("delivery channel not available for user")
+
+// Channel delivers a one-time login code to the user
+type Channel interface {
+	Name() string
+	// Available reports whether the user can receive codes through this channel
+	Available(user *model.User) bool
+	SendOTP(user *model.User, code string) error
+}
+
+// Registry holds the configured channels by name
+type Registry struct {
+	channels map[string]Channel
+	fallback Channel
+}
+
+// NewRegistry creates a registry; the first channel is the fallback used when the preferred one fails
+func NewRegistry(channels ...Channel) *Registry {
+	r := &Registry{channels: make(map[string]Channel)}
+	for _, c := range channels {
+		r.channels[c.Name()] = c
+	}
+	if len(channels) > 0 {
+		r.fallback = channels[0]
+	}
+	return r
+}
+
+// Get returns the channel with the given name
+func (r *Registry) Get(name string) (Channel, bool) {
+	c, ok := r.channels[name]
+	return c, ok
+}
+
+// Preferred returns the user's chosen channel, or the fallback if it is unknown or unavailable
+func (r *Registry) Preferred(user *model.User) Channel {
+	if c, ok := r.channels[user.OTPChannel]; ok && c.Available(user) {
+		return c
+	}
+	return r.fallback
+}
+
+// Result describes how a code was delivered
+type Result struct {
+	Channel      string // channel that delivered the code
+	Preferred    string // channel chosen for the user
+	PreferredErr error  // why the preferred channel failed, if the fallback was used
+}
+
+// SendOTP sends the code through the user's preferred channel and falls back to the default
+// channel if that fails, so a broken SMS provider does not lock users out.
+func (r *Registry) SendOTP(user *model.User, code string) (Result, error) {
+	preferred := r.Preferred(user)
+	if preferred == nil {
+		return Result{}, fmt.Errorf("no delivery channel configured")
+	}
+
+	result := Result{Preferred: preferred.Name()}
+	err := preferred.SendOTP(user, code)
+	if err == nil {
+		result.Channel = preferred.Name()
+		return result, nil
+	}
+	result.PreferredErr = err
+
+	if r.fallback == nil || r.fallback == preferred || !r.fallback.Available(user) {
+		return result, result.PreferredErr
+	}
+
+	if err := r.fallback.SendOTP(user, code); err != nil {
+		return result, err
+	}
+	result.Channel = r.fallback.Name()
+	return result, nil
+}
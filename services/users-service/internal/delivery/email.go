@@ -0,0 +1,17 @@
+package delivery
+
+import (
+	"users-service/internal/mail"
+	"users-service/internal/model"
+)
+
+// EmailChannel queues the code through the mail outbox
+type EmailChannel struct{}
+
+func (EmailChannel) Name() string { return model.OTPChannelEmail }
+
+func (EmailChannel) Available(user *model.User) bool { return user.Email != "" }
+
+func (EmailChannel) SendOTP(user *model.User, code string) error {
+	return mail.SendOTP(user.Email, user.Locale, code)
+}
@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"users-service/config"
+	"users-service/internal/mail"
+	"users-service/internal/model"
+)
+
+// SMSProvider sends a text message to a phone number in E.164 format
+type SMSProvider interface {
+	Name() string
+	Send(to, message string) error
+}
+
+// NewSMSProvider creates the provider selected by SMS_PROVIDER
+func NewSMSProvider(cfg *config.Config) (SMSProvider, error) {
+	switch cfg.SMSProvider {
+	case "http":
+		if cfg.SMSAPIURL == "" {
+			return nil, fmt.Errorf("SMS_API_URL is required for the http SMS provider")
+		}
+		return &HTTPSMSProvider{
+			URL:    cfg.SMSAPIURL,
+			APIKey: cfg.SMSAPIKey,
+			Sender: cfg.SMSSender,
+			client: &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	case "log":
+		return &LogSMSProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider %q", cfg.SMSProvider)
+	}
+}
+
+// HTTPSMSProvider posts messages to a JSON SMS API:
+// POST {url} {"from": "...", "to": "+381...", "message": "..."} with an optional bearer key.
+// Locally the sms-stub service implements the same API.
+type HTTPSMSProvider struct {
+	URL    string
+	APIKey string
+	Sender string
+	client *http.Client
+}
+
+func (p *HTTPSMSProvider) Name() string { return "http" }
+
+func (p *HTTPSMSProvider) Send(to, message string) error {
+	body, err := json.Marshal(map[string]string{
+		"from":    p.Sender,
+		"to":      to,
+		"message": message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SMS API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SMS API returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// LogSMSProvider only logs messages (development without an SMS API)
+type LogSMSProvider struct{}
+
+func (LogSMSProvider) Name() string { return "log" }
+
+func (LogSMSProvider) Send(to, message string) error {
+	log.Printf("[MOCK SMS] To: %s | %s", to, message)
+	return nil
+}
+
+// smsOTPTexts holds the login code message per locale
+var smsOTPTexts = map[string]string{
+	"en": "Your Music Streaming login code is %s. It expires in 5 minutes.",
+	"sr": "Vaš kod za prijavu na Music Streaming je %s. Ističe za 5 minuta.",
+}
+
+// smsPhoneTexts holds the phone verification message per locale
+var smsPhoneTexts = map[string]string{
+	"en": "Your Music Streaming phone verification code is %s.",
+	"sr": "Vaš kod za potvrdu broja telefona na Music Streaming je %s.",
+}
+
+func localizedSMS(texts map[string]string, locale, code string) string {
+	text, ok := texts[mail.NormalizeLocale(locale)]
+	if !ok {
+		text = texts["en"]
+	}
+	return fmt.Sprintf(text, code)
+}
+
+// SMSChannel delivers codes by text message to a verified phone number
+type SMSChannel struct {
+	Provider SMSProvider
+}
+
+func NewSMSChannel(provider SMSProvider) *SMSChannel {
+	return &SMSChannel{Provider: provider}
+}
+
+func (c *SMSChannel) Name() string { return model.OTPChannelSMS }
+
+func (c *SMSChannel) Available(user *model.User) bool {
+	return user.Phone != "" && user.PhoneVerified
+}
+
+func (c *SMSChannel) SendOTP(user *model.User, code string) error {
+	if !c.Available(user) {
+		return ErrChannelUnavailable
+	}
+	return c.Provider.Send(user.Phone, localizedSMS(smsOTPTexts, user.Locale, code))
+}
+
+// SendPhoneVerification sends the code that proves ownership of a not yet verified number
+func (c *SMSChannel) SendPhoneVerification(phone, locale, code string) error {
+	return c.Provider.Send(phone, localizedSMS(smsPhoneTexts, locale, code))
+}
@@ -0,0 +1,6 @@
+package dto
+
+type EmailChangeRequest struct {
+	NewEmail string `json:"newEmail"`
+	Password string `json:"password"`
+}
@@ -8,4 +8,6 @@ type LoginResponse struct {
 	FirstName string `json:"firstName"`
 	LastName  string `json:"lastName"`
 	Role      string `json:"role"`
+	// Permissions lets the frontend show or hide actions, they are enforced server side
+	Permissions []string `json:"permissions"`
 }
@@ -0,0 +1,18 @@
+package dto
+
+type PhoneRequest struct {
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+}
+
+type PhoneRemoveRequest struct {
+	Password string `json:"password"`
+}
+
+type PhoneVerifyRequest struct {
+	Code string `json:"code"`
+}
+
+type OTPChannelRequest struct {
+	Channel string `json:"channel"`
+}
@@ -7,4 +7,5 @@ type RegisterRequest struct {
 	Username        string `json:"username"`
 	Password        string `json:"password"`
 	ConfirmPassword string `json:"confirmPassword"`
+	Locale          string `json:"locale"` // Preferred email language (sr or en)
 }
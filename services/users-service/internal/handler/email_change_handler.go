@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"users-service/config"
+	"users-service/internal/dto"
+	"users-service/internal/logger"
+	"users-service/internal/mail"
+	"users-service/internal/security"
+	"users-service/internal/store"
+	"users-service/internal/validation"
+)
+
+type EmailChangeHandler struct {
+	Repo   *store.UserRepository
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewEmailChangeHandler(repo *store.UserRepository, cfg *config.Config, log *logger.Logger) *EmailChangeHandler {
+	return &EmailChangeHandler{Repo: repo, Config: cfg, Logger: log}
+}
+
+// RequestEmailChange starts an email change for the authenticated user.
+// A confirmation link goes to the new address and a cancel link to the current one.
+func (h *EmailChangeHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := requestUserID(r, h.Config.JWTSecret)
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.EmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	req.NewEmail = validation.SanitizeString(req.NewEmail)
+	if err := validation.ValidateEmail(req.NewEmail); err != nil {
+		if h.Logger != nil {
+			h.Logger.LogValidationFailure("newEmail", err.Error(), req.NewEmail)
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validation.CheckXSS(req.NewEmail); err != nil {
+		if h.Logger != nil {
+			h.Logger.LogValidationFailure("newEmail", "XSS attempt detected", "")
+		}
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.Repo.GetByID(ctx, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	// Re-authenticate with the current password
+	if !security.CheckPassword(user.PasswordHash, req.Password) {
+		if h.Logger != nil {
+			h.Logger.LogAccessControlFailure(user.ID, "/email/change", r.Method, "wrong password for email change")
+		}
+		http.Error(w, "wrong password", http.StatusUnauthorized)
+		return
+	}
+
+	if strings.EqualFold(user.Email, req.NewEmail) {
+		http.Error(w, "new email must be different from the current one", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.Repo.GetByEmail(ctx, req.NewEmail); err == nil {
+		http.Error(w, store.ErrUserExists.Error(), http.StatusConflict)
+		return
+	}
+
+	token, err := security.GenerateVerificationToken()
+	if err != nil {
+		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+		return
+	}
+	cancelToken, err := security.GenerateSecureToken()
+	if err != nil {
+		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.Repo.SetEmailChangeToken(ctx, user.ID, user.Email, req.NewEmail, token, cancelToken); err != nil {
+		http.Error(w, "failed to store email change request", http.StatusInternalServerError)
+		return
+	}
+
+	// Links open confirmation pages, the change is confirmed or cancelled only by the POST they send
+	confirmURL := h.Config.FrontendURL + "/email/change/confirm?token=" + url.QueryEscape(token)
+	cancelURL := h.Config.FrontendURL + "/email/change/cancel?token=" + url.QueryEscape(cancelToken)
+	mail.SendEmailChangeConfirmation(req.NewEmail, user.Locale, confirmURL)
+	mail.SendEmailChangeNotice(user.Email, user.Locale, req.NewEmail, cancelURL)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "confirmation link sent to the new email address",
+	})
+}
+
+// emailChangeToken reads the token of a confirm or cancel request
+func emailChangeToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", false
+	}
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return "", false
+	}
+	if req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return "", false
+	}
+	return req.Token, true
+}
+
+// ConfirmEmailChange applies the change after the new address is confirmed
+// POST /email/change/confirm {"token": "..."}
+func (h *EmailChangeHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token, ok := emailChangeToken(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	entry, ok := h.Repo.GetEmailChangeToken(ctx, token)
+	if !ok || security.IsEmailChangeTokenExpired(entry) {
+		http.Error(w, "invalid or expired email change token", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.Repo.GetByID(ctx, entry.UserID)
+	if err != nil || user.Email != entry.Email {
+		// The address changed in the meantime, this request is stale
+		h.Repo.DeleteEmailChangeToken(ctx, entry.UserID)
+		http.Error(w, "email change request is no longer valid", http.StatusConflict)
+		return
+	}
+
+	// The address may have been taken since the request; the unique email index makes this race-free
+	if err := h.Repo.UpdateEmail(ctx, user.ID, entry.NewEmail); err != nil {
+		if err == store.ErrUserExists {
+			h.Repo.DeleteEmailChangeToken(ctx, user.ID)
+			http.Error(w, err.Error(), http.StatusConflict)
+		} else {
+			http.Error(w, "failed to change email", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.Repo.DeleteEmailChangeToken(ctx, user.ID)
+	// Tokens issued for the old address must not keep working
+	h.Repo.DeleteTokensForEmail(ctx, entry.Email)
+	// Sign out all sessions so every client picks up the new address on next login
+	if err := h.Repo.RevokeSessions(ctx, user.ID, time.Now()); err != nil {
+		if h.Logger != nil {
+			h.Logger.Log(logger.LevelError, logger.EventStateChange, "Failed to revoke sessions after email change",
+				map[string]interface{}{"userID": user.ID, "error": err.Error()})
+		}
+	}
+	mail.SendEmailChanged(entry.Email, user.Locale, entry.NewEmail)
+
+	if h.Logger != nil {
+		h.Logger.Log(logger.LevelAudit, logger.EventStateChange, "Email address changed",
+			map[string]interface{}{
+				"userID":   user.ID,
+				"username": user.Username,
+			})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "email changed successfully, please log in again",
+	})
+}
+
+// CancelEmailChange discards a pending change using the link sent to the current address
+// POST /email/change/cancel {"token": "..."}
+func (h *EmailChangeHandler) CancelEmailChange(w http.ResponseWriter, r *http.Request) {
+	token, ok := emailChangeToken(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	entry, ok := h.Repo.GetEmailChangeByCancelToken(ctx, token)
+	if !ok {
+		http.Error(w, "invalid or already used cancel link", http.StatusNotFound)
+		return
+	}
+
+	if err := h.Repo.DeleteEmailChangeToken(ctx, entry.UserID); err != nil {
+		http.Error(w, "failed to cancel email change", http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.Log(logger.LevelAudit, logger.EventStateChange, "Email change cancelled from current address",
+			map[string]interface{}{
+				"userID": entry.UserID,
+				"ip":     getClientIP(r),
+			})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "email change cancelled",
+	})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"shared/authz"
+	"users-service/config"
+	"users-service/internal/logger"
+	"users-service/internal/security"
+	"users-service/internal/store"
+)
+
+// ImpersonationHandler mints short-lived "view as user" tokens for support staff.
+// The users.impersonate permission is checked by the route middleware and again here; the API
+// gateway and content-service block write requests made with a read-only impersonation token.
+type ImpersonationHandler struct {
+	Repo   *store.UserRepository
+	Roles  *store.RoleRepository
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewImpersonationHandler(repo *store.UserRepository, roles *store.RoleRepository, cfg *config.Config, log *logger.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{Repo: repo, Roles: roles, Config: cfg, Logger: log}
+}
+
+type impersonationRequest struct {
+	UserID     string `json:"userId"`
+	Reason     string `json:"reason"`
+	AllowWrite bool   `json:"allowWrite"`
+}
+
+// Impersonate issues an impersonation token for the target user
+// POST /admin/impersonate
+func (h *ImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req impersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.UserID == "" || req.Reason == "" {
+		http.Error(w, "userId and reason are required", http.StatusBadRequest)
+		return
+	}
+
+	authHeader := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(authHeader) != 2 || authHeader[0] != "Bearer" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	caller, err := security.ValidateToken(authHeader[1], h.Config.JWTSecret)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// No chained impersonation
+	if caller.ImpersonatorID != "" {
+		http.Error(w, "cannot impersonate while impersonating", http.StatusForbidden)
+		return
+	}
+	if caller.UserID == req.UserID {
+		http.Error(w, "cannot impersonate yourself", http.StatusBadRequest)
+		return
+	}
+
+	callerPrincipal := &authz.Principal{UserID: caller.UserID, Role: caller.Role, Permissions: caller.Permissions}
+	if !callerPrincipal.Has(authz.UsersImpersonate) {
+		if h.Logger != nil {
+			h.Logger.LogAccessControlFailure(caller.UserID, "/admin/impersonate", r.Method, "missing "+authz.UsersImpersonate+" permission")
+		}
+		http.Error(w, "forbidden: "+authz.UsersImpersonate+" permission required", http.StatusForbidden)
+		return
+	}
+	if req.AllowWrite && !callerPrincipal.Has(authz.UsersImpersonateWrite) {
+		http.Error(w, "forbidden: "+authz.UsersImpersonateWrite+" permission required", http.StatusForbidden)
+		return
+	}
+
+	ctx := r.Context()
+	target, err := h.Repo.GetByID(ctx, req.UserID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	permissions, err := h.Roles.PermissionsFor(ctx, target.Role)
+	if err != nil {
+		http.Error(w, "failed to resolve permissions", http.StatusInternalServerError)
+		return
+	}
+
+	// Impersonation must never grant the caller permissions they do not already have. Self permissions
+	// (own ratings, subscriptions, activity) are exempt: as the target they only reach the target's data.
+	for _, p := range permissions {
+		if !authz.IsSelfPermission(p) && !callerPrincipal.Has(p) {
+			if h.Logger != nil {
+				h.Logger.LogAccessControlFailure(caller.UserID, "/admin/impersonate", r.Method,
+					"target user has permission "+p+" not held by impersonator")
+			}
+			http.Error(w, "forbidden: target user has permissions you do not have", http.StatusForbidden)
+			return
+		}
+	}
+
+	ttl := time.Duration(h.Config.ImpersonationTokenMinutes) * time.Minute
+	token, expiresAt, err := security.GenerateImpersonationToken(caller.UserID, target.ID, target.Username, target.Role,
+		permissions, req.AllowWrite, ttl, h.Config.JWTSecret)
+	if err != nil {
+		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogImpersonation(caller.UserID, target.ID, "impersonation_started", map[string]interface{}{
+			"reason":     req.Reason,
+			"allowWrite": req.AllowWrite,
+			"expiresAt":  expiresAt.Format(time.RFC3339),
+			"ip":         getClientIP(r),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":          token,
+		"expiresAt":      expiresAt,
+		"userId":         target.ID,
+		"username":       target.Username,
+		"impersonatorId": caller.UserID,
+		"readOnly":       !req.AllowWrite,
+	})
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shared/authz"
+	"users-service/config"
+	"users-service/internal/security"
+)
+
+const testJWTSecret = "test-secret"
+
+func testToken(t *testing.T, userID, role string, permissions []string) string {
+	t.Helper()
+	token, err := security.GenerateToken(userID, userID, role, permissions, testJWTSecret)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	return token
+}
+
+func testImpersonationToken(t *testing.T, impersonatorID, userID string, permissions []string) string {
+	t.Helper()
+	token, _, err := security.GenerateImpersonationToken(impersonatorID, userID, userID, "ADMIN", permissions,
+		false, time.Minute, testJWTSecret)
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken: %v", err)
+	}
+	return token
+}
+
+// The checks below all refuse the request before the handler reaches the repositories
+func TestImpersonateChecks(t *testing.T) {
+	cfg := &config.Config{JWTSecret: testJWTSecret, ImpersonationTokenMinutes: 15}
+	h := NewImpersonationHandler(nil, nil, cfg, nil)
+	admin := []string{authz.UsersManage, authz.UsersImpersonate}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "missing token",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			body:       `{"userId":"target","reason":"support ticket"}`,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "route refuses caller without permission",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			token:      testToken(t, "listener", "USER", []string{authz.RatingsWrite}),
+			body:       `{"userId":"target","reason":"support ticket"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "route refuses impersonation token",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			token:      testImpersonationToken(t, "admin", "other-admin", admin),
+			body:       `{"userId":"target","reason":"support ticket"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "handler refuses caller without permission",
+			handler:    h.Impersonate,
+			token:      testToken(t, "manager", "ADMIN", []string{authz.UsersManage}),
+			body:       `{"userId":"target","reason":"support ticket"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "handler refuses chained impersonation",
+			handler:    h.Impersonate,
+			token:      testImpersonationToken(t, "admin", "other-admin", admin),
+			body:       `{"userId":"target","reason":"support ticket"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "write access requires write permission",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			token:      testToken(t, "admin", "ADMIN", admin),
+			body:       `{"userId":"target","reason":"support ticket","allowWrite":true}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "cannot impersonate yourself",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			token:      testToken(t, "admin", "ADMIN", admin),
+			body:       `{"userId":"admin","reason":"support ticket"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "reason is required",
+			handler:    RequirePermission(cfg, authz.UsersImpersonate, h.Impersonate),
+			token:      testToken(t, "admin", "ADMIN", admin),
+			body:       `{"userId":"target","reason":"  "}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/impersonate", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, strings.TrimSpace(rec.Body.String()))
+			}
+		})
+	}
+}
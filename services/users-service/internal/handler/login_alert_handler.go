@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"users-service/config"
+	"users-service/internal/logger"
+	"users-service/internal/mail"
+	"users-service/internal/security"
+	"users-service/internal/store"
+)
+
+type LoginAlertHandler struct {
+	Repo   *store.UserRepository
+	Events *store.LoginEventRepository
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewLoginAlertHandler(repo *store.UserRepository, events *store.LoginEventRepository, cfg *config.Config, log *logger.Logger) *LoginAlertHandler {
+	return &LoginAlertHandler{
+		Repo:   repo,
+		Events: events,
+		Config: cfg,
+		Logger: log,
+	}
+}
+
+// NotMe handles "this wasn't me" confirmed on the page a login alert links to:
+// all sessions are revoked and the password must be reset before the next login
+// POST /login/not-me {"token": "..."}
+func (h *LoginAlertHandler) NotMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	token := req.Token
+	if token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	entry, ok := h.Repo.GetLoginAlertToken(ctx, token)
+	if !ok || security.IsLoginAlertTokenExpired(entry) {
+		http.Error(w, "invalid or expired link", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.Repo.GetByEmail(ctx, entry.Email)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	if err := h.Repo.RevokeSessions(ctx, user.ID, now); err != nil {
+		http.Error(w, "failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	// Expiring the password blocks OTP and magic link login until the password is reset
+	user.PasswordExpiresAt = now
+	if err := h.Repo.Update(ctx, user); err != nil {
+		http.Error(w, "failed to secure account", http.StatusInternalServerError)
+		return
+	}
+	h.Repo.DeleteLoginAlertTokens(ctx, user.Email)
+
+	resetToken, err := security.GeneratePasswordResetToken()
+	if err == nil {
+		if err := h.Repo.SetPasswordResetToken(ctx, user.Email, resetToken); err == nil {
+			resetURL := h.Config.FrontendURL + "/reset-password?token=" + url.QueryEscape(resetToken)
+			mail.SendPasswordResetEmail(user.Email, user.Locale, resetURL)
+		}
+	}
+
+	if h.Logger != nil {
+		h.Logger.Log(logger.LevelAudit, logger.EventSuspiciousLogin, "User reported login as not theirs - sessions revoked",
+			map[string]interface{}{
+				"username": user.Username,
+				"ip":       getClientIP(r),
+			})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "all sessions have been signed out, check your email to reset your password",
+	})
+}
+
+// HighRiskLogins returns the admin report of high-risk logins
+// GET /admin/security/high-risk-logins?hours=24&minScore=70&limit=100
+func (h *LoginAlertHandler) HighRiskLogins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	hours := 24
+	if v := query.Get("hours"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid hours", http.StatusBadRequest)
+			return
+		}
+		hours = parsed
+	}
+	minScore := h.Config.HighRiskLoginThreshold
+	if v := query.Get("minScore"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 || parsed > 100 {
+			http.Error(w, "minScore must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+		minScore = parsed
+	}
+	limit := int64(100)
+	if v := query.Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed <= 0 || parsed > 1000 {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	events, err := h.Events.GetHighRisk(r.Context(), since, minScore, limit)
+	if err != nil {
+		http.Error(w, "failed to load login report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since":    since,
+		"minScore": minScore,
+		"logins":   events,
+	})
+}
+
+// SessionRevocations lets the API gateway sync revoked sessions
+// GET /internal/sessions/revocations?since=<unix seconds>
+func (h *LoginAlertHandler) SessionRevocations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	since := time.Unix(0, 0)
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+		since = time.Unix(parsed, 0)
+	}
+
+	revocations, err := h.Repo.GetSessionRevocations(r.Context(), since)
+	if err != nil {
+		http.Error(w, "failed to load revocations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(revocations)
+}
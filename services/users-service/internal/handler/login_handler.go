@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -9,27 +10,40 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"users-service/config"
+	"users-service/internal/delivery"
 	"users-service/internal/dto"
 	"users-service/internal/logger"
-	"users-service/internal/mail"
 	"users-service/internal/security"
 	"users-service/internal/store"
 )
 
 type LoginHandler struct {
-	Repo   *store.UserRepository
-	Config *config.Config
-	Logger *logger.Logger
+	Repo     *store.UserRepository
+	Roles    *store.RoleRepository
+	Channels *delivery.Registry
+	Config   *config.Config
+	Logger   *logger.Logger
+	Monitor  *LoginMonitor
 }
 
-func NewLoginHandler(repo *store.UserRepository, cfg *config.Config, log *logger.Logger) *LoginHandler {
+func NewLoginHandler(repo *store.UserRepository, roles *store.RoleRepository, channels *delivery.Registry, cfg *config.Config, log *logger.Logger, monitor *LoginMonitor) *LoginHandler {
 	return &LoginHandler{
-		Repo:   repo,
-		Config: cfg,
-		Logger: log,
+		Repo:     repo,
+		Roles:    roles,
+		Channels: channels,
+		Config:   cfg,
+		Logger:   log,
+		Monitor:  monitor,
 	}
 }
 
+// dummyPasswordHash is compared against when the username does not exist, so unknown and existing
+// accounts take the same time to answer
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+
+// RequestOTP checks the credentials and sends a login code. Unknown users, wrong passwords and locked
+// accounts get the same response, and a valid login always gets the same answer whichever channel
+// delivered the code (or if none could), so the endpoint does not reveal which accounts exist.
 func (h *LoginHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -43,6 +57,7 @@ func (h *LoginHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
 	ipAddress := getClientIP(r)
 	user, err := h.Repo.GetByUsername(ctx, req.Username)
 	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
 		if h.Logger != nil {
 			h.Logger.LogLoginFailure(req.Username, "user not found", ipAddress)
 		}
@@ -50,28 +65,11 @@ func (h *LoginHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if email is verified
-	if !user.Verified {
-		if h.Logger != nil {
-			h.Logger.LogLoginFailure(req.Username, "email not verified", ipAddress)
-		}
-		http.Error(w, "email not verified", http.StatusForbidden)
-		return
-	}
-
 	if time.Now().Before(user.LockedUntil) {
 		if h.Logger != nil {
 			h.Logger.LogLoginFailure(req.Username, "account locked", ipAddress)
 		}
-		http.Error(w, "account locked", http.StatusForbidden)
-		return
-	}
-
-	if time.Now().After(user.PasswordExpiresAt) {
-		if h.Logger != nil {
-			h.Logger.LogLoginFailure(req.Username, "password expired", ipAddress)
-		}
-		http.Error(w, "password expired", http.StatusForbidden)
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
@@ -89,22 +87,54 @@ func (h *LoginHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The account state is only disclosed to someone who knows the password
+	if !user.Verified {
+		if h.Logger != nil {
+			h.Logger.LogLoginFailure(req.Username, "email not verified", ipAddress)
+		}
+		http.Error(w, "email not verified", http.StatusForbidden)
+		return
+	}
+
+	if time.Now().After(user.PasswordExpiresAt) {
+		if h.Logger != nil {
+			h.Logger.LogLoginFailure(req.Username, "password expired", ipAddress)
+		}
+		http.Error(w, "password expired", http.StatusForbidden)
+		return
+	}
+
 	user.FailedLoginAttempts = 0
 	h.Repo.Update(ctx, user)
 
 	otp, _ := security.GenerateOTP()
 	h.Repo.SetOTP(ctx, user.Username, otp)
-	mail.SendOTP(user.Email, otp)
 
-	if h.Logger != nil {
+	// Deliver through the user's preferred channel, falling back to email
+	result, err := h.Channels.SendOTP(user, otp)
+	if result.PreferredErr != nil && h.Logger != nil {
+		h.Logger.LogOTPDeliveryFailure(user.Username, result.Preferred, result.PreferredErr.Error(), result.Channel)
+	}
+	if err != nil {
+		// No channel worked; the user asks for a new code, the response stays the same
+		h.Repo.DeleteOTP(ctx, user.Username)
+		if h.Logger != nil {
+			h.Logger.LogOTPDeliveryFailure(user.Username, result.Preferred, err.Error(), "")
+		}
+	} else if h.Logger != nil {
 		h.Logger.Log(logger.LevelInfo, logger.EventLoginSuccess, "OTP requested successfully",
 			map[string]interface{}{
 				"username": user.Username,
 				"ip":       ipAddress,
+				"channel":  result.Channel,
 			})
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "if the account can receive it, a login code has been sent",
+	})
 }
 
 func (h *LoginHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
@@ -121,16 +151,13 @@ func (h *LoginHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	ipAddress := getClientIP(r)
-	entry, ok := h.Repo.GetOTP(ctx, req.Username)
-	if !ok || security.IsExpired(entry) || entry.Code != req.OTP {
+	if err := h.Repo.VerifyOTP(ctx, req.Username, req.OTP); err != nil {
 		if h.Logger != nil {
-			reason := "invalid OTP"
-			if !ok {
-				reason = "OTP not found"
-			} else if security.IsExpired(entry) {
-				reason = "OTP expired"
-			}
-			h.Logger.LogLoginFailure(req.Username, reason, ipAddress)
+			h.Logger.LogLoginFailure(req.Username, err.Error(), ipAddress)
+		}
+		if errors.Is(err, store.ErrOTPAttemptsExceeded) {
+			http.Error(w, "too many attempts, request a new OTP", http.StatusTooManyRequests)
+			return
 		}
 		http.Error(w, "invalid OTP", http.StatusUnauthorized)
 		return
@@ -144,28 +171,35 @@ func (h *LoginHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate JWT token
-	token, err := security.GenerateToken(user.ID, user.Username, user.Role, h.Config.JWTSecret)
+	permissions, err := h.Roles.PermissionsFor(ctx, user.Role)
+	if err != nil {
+		http.Error(w, "failed to resolve permissions", http.StatusInternalServerError)
+		return
+	}
+	token, err := security.GenerateToken(user.ID, user.Username, user.Role, permissions, h.Config.JWTSecret)
 	if err != nil {
 		http.Error(w, "failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	h.Repo.DeleteOTP(ctx, req.Username)
-
 	// Log successful login
 	if h.Logger != nil {
 		h.Logger.LogLoginSuccess(user.Username, ipAddress)
 	}
 
+	// Fingerprint login context and alert the user if it looks suspicious
+	h.Monitor.RecordLogin(ctx, r, user, "otp")
+
 	// Return token and user info
 	response := dto.LoginResponse{
-		Token:     token,
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
+		Token:       token,
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		Permissions: permissions,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -173,18 +207,19 @@ func (h *LoginHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// getClientIP extracts the client IP address from the request
+// getClientIP extracts the client IP address from the request.
+// The API gateway overwrites X-Real-IP with the address that connected to it and appends that address
+// to X-Forwarded-For, so only X-Real-IP and the right-most X-Forwarded-For hop are trusted; entries
+// to the left of it come from the client and can be spoofed.
 func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header first (for proxies)
+	realIP := strings.TrimSpace(r.Header.Get("X-Real-IP"))
+	if realIP != "" {
+		return realIP
+	}
 	forwarded := r.Header.Get("X-Forwarded-For")
 	if forwarded != "" {
 		ips := strings.Split(forwarded, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	// Check X-Real-IP header
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+		return strings.TrimSpace(ips[len(ips)-1])
 	}
 	// Fallback to RemoteAddr
 	ip := r.RemoteAddr
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"users-service/config"
+	"users-service/internal/logger"
+	"users-service/internal/mail"
+	"users-service/internal/model"
+	"users-service/internal/security"
+	"users-service/internal/store"
+)
+
+// loginHistorySize is how many previous logins are compared against a new one
+const loginHistorySize = 20
+
+// LoginMonitor fingerprints successful logins, scores their risk and alerts the user
+type LoginMonitor struct {
+	Repo   *store.UserRepository
+	Events *store.LoginEventRepository
+	GeoIP  *security.GeoIPDB
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewLoginMonitor(repo *store.UserRepository, events *store.LoginEventRepository, geoIP *security.GeoIPDB, cfg *config.Config, log *logger.Logger) *LoginMonitor {
+	return &LoginMonitor{
+		Repo:   repo,
+		Events: events,
+		GeoIP:  geoIP,
+		Config: cfg,
+		Logger: log,
+	}
+}
+
+// RecordLogin stores the login context and sends an alert email if the login looks suspicious.
+// Failures are logged and never block the login itself.
+func (m *LoginMonitor) RecordLogin(ctx context.Context, r *http.Request, user *model.User, method string) {
+	if m == nil {
+		return
+	}
+
+	ip := getClientIP(r)
+	now := time.Now()
+	current := security.LoginContext{
+		IP:         ip,
+		IPPrefix:   security.IPPrefix(ip),
+		DeviceID:   security.DeviceFingerprint(r.UserAgent(), r.Header.Get("Accept-Language")),
+		UserAgent:  r.UserAgent(),
+		ObservedAt: now,
+	}
+	if location, ok := m.GeoIP.Lookup(ip); ok {
+		current.Location = &location
+	}
+
+	previous, err := m.Events.GetRecentByUser(ctx, user.ID, loginHistorySize)
+	if err != nil {
+		log.Printf("Login monitor: failed to load login history for %s: %v", user.Username, err)
+		previous = nil
+	}
+	history := make([]security.PreviousLogin, 0, len(previous))
+	for _, event := range previous {
+		prev := security.PreviousLogin{
+			DeviceID:   event.DeviceID,
+			IPPrefix:   event.IPPrefix,
+			ObservedAt: event.CreatedAt,
+		}
+		if event.Country != "" && event.Latitude != nil && event.Longitude != nil {
+			prev.Location = &security.GeoLocation{
+				Country:   event.Country,
+				City:      event.City,
+				Latitude:  *event.Latitude,
+				Longitude: *event.Longitude,
+			}
+		}
+		history = append(history, prev)
+	}
+
+	risk := security.AssessLoginRisk(current, history)
+
+	event := &model.LoginEvent{
+		UserID:      user.ID,
+		Username:    user.Username,
+		IP:          ip,
+		IPPrefix:    current.IPPrefix,
+		DeviceID:    current.DeviceID,
+		UserAgent:   current.UserAgent,
+		Method:      method,
+		RiskScore:   risk.Score,
+		RiskFactors: risk.Factors,
+		CreatedAt:   now,
+	}
+	if current.Location != nil {
+		event.Country = current.Location.Country
+		event.City = current.Location.City
+		event.Latitude = &current.Location.Latitude
+		event.Longitude = &current.Location.Longitude
+	}
+
+	if risk.Score >= m.Config.LoginAlertThreshold {
+		if m.Logger != nil {
+			m.Logger.LogSuspiciousLogin(user.Username, ip, risk.Score, risk.Factors)
+		}
+		event.AlertSent = m.sendAlert(ctx, user, event)
+	}
+
+	if err := m.Events.Create(ctx, event); err != nil {
+		log.Printf("Login monitor: failed to store login event for %s: %v", user.Username, err)
+	}
+}
+
+// sendAlert emails the user a description of the login and a "this wasn't me" link
+func (m *LoginMonitor) sendAlert(ctx context.Context, user *model.User, event *model.LoginEvent) bool {
+	token, err := security.GenerateSecureToken()
+	if err != nil {
+		log.Printf("Login monitor: failed to generate alert token: %v", err)
+		return false
+	}
+	if err := m.Repo.SetLoginAlertToken(ctx, user.Email, token); err != nil {
+		log.Printf("Login monitor: failed to store alert token: %v", err)
+		return false
+	}
+
+	location := ""
+	if event.Country != "" {
+		location = event.Country
+		if event.City != "" {
+			location = event.City + ", " + event.Country
+		}
+	}
+	device := event.UserAgent
+	if device == "" {
+		device = "unknown"
+	}
+
+	// Link points directly to the API, the action is complete after one click
+	// The link opens a confirmation page; sessions are revoked only when the user confirms there (POST)
+	notMeURL := m.Config.FrontendURL + "/login/not-me?token=" + url.QueryEscape(token)
+	mail.SendLoginAlert(user.Email, user.Locale, event.CreatedAt, event.IP, location, device,
+		strings.Join(event.RiskFactors, ", "), notMeURL)
+	return true
+}
@@ -14,14 +14,18 @@ import (
 )
 
 type MagicLinkHandler struct {
-	Repo   *store.UserRepository
-	Config *config.Config
+	Repo    *store.UserRepository
+	Roles   *store.RoleRepository
+	Config  *config.Config
+	Monitor *LoginMonitor
 }
 
-func NewMagicLinkHandler(repo *store.UserRepository, cfg *config.Config) *MagicLinkHandler {
+func NewMagicLinkHandler(repo *store.UserRepository, roles *store.RoleRepository, cfg *config.Config, monitor *LoginMonitor) *MagicLinkHandler {
 	return &MagicLinkHandler{
-		Repo:   repo,
-		Config: cfg,
+		Repo:    repo,
+		Roles:   roles,
+		Config:  cfg,
+		Monitor: monitor,
 	}
 }
 
@@ -71,7 +75,7 @@ func (h *MagicLinkHandler) RequestMagicLink(w http.ResponseWriter, r *http.Reque
 	// Note: Link points to frontend, which will call the API
 	encodedToken := url.QueryEscape(token)
 	magicLinkURL := h.Config.FrontendURL + "/verify-magic-link?token=" + encodedToken
-	mail.SendMagicLink(user.Email, magicLinkURL)
+	mail.SendMagicLink(user.Email, user.Locale, magicLinkURL)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -120,7 +124,12 @@ func (h *MagicLinkHandler) VerifyMagicLink(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Generate JWT token
-	jwtToken, err := security.GenerateToken(user.ID, user.Username, user.Role, h.Config.JWTSecret)
+	permissions, err := h.Roles.PermissionsFor(ctx, user.Role)
+	if err != nil {
+		http.Error(w, "failed to resolve permissions", http.StatusInternalServerError)
+		return
+	}
+	jwtToken, err := security.GenerateToken(user.ID, user.Username, user.Role, permissions, h.Config.JWTSecret)
 	if err != nil {
 		http.Error(w, "failed to generate token", http.StatusInternalServerError)
 		return
@@ -129,15 +138,19 @@ func (h *MagicLinkHandler) VerifyMagicLink(w http.ResponseWriter, r *http.Reques
 	// Delete used magic link
 	h.Repo.DeleteMagicLink(ctx, token)
 
+	// Fingerprint login context and alert the user if it looks suspicious
+	h.Monitor.RecordLogin(ctx, r, user, "magic_link")
+
 	// Return token and user info
 	response := dto.LoginResponse{
-		Token:     jwtToken,
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role,
+		Token:       jwtToken,
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Role:        user.Role,
+		Permissions: permissions,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"users-service/config"
+	"users-service/internal/logger"
+	"users-service/internal/model"
+	"users-service/internal/security"
+	"users-service/internal/store"
+)
+
+// MailAdminHandler exposes email delivery status to admins.
+// Routes are wrapped with RequirePermission(mail.manage), the API gateway checks it as well.
+type MailAdminHandler struct {
+	Outbox *store.OutboxRepository
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewMailAdminHandler(outbox *store.OutboxRepository, cfg *config.Config, log *logger.Logger) *MailAdminHandler {
+	return &MailAdminHandler{Outbox: outbox, Config: cfg, Logger: log}
+}
+
+// ListOutbox returns outbox entries with optional status and recipient filters
+// GET /admin/mail/outbox?status=FAILED&to=user@example.com&limit=50
+func (h *MailAdminHandler) ListOutbox(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	status := strings.ToUpper(query.Get("status"))
+	switch status {
+	case "", model.EmailStatusPending, model.EmailStatusSending, model.EmailStatusSent, model.EmailStatusFailed:
+	default:
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+
+	limit := int64(50)
+	if l := query.Get("limit"); l != "" {
+		parsed, err := strconv.ParseInt(l, 10, 64)
+		if err != nil || parsed <= 0 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	ctx := r.Context()
+	emails, err := h.Outbox.List(ctx, status, query.Get("to"), limit)
+	if err != nil {
+		http.Error(w, "failed to list outbox", http.StatusInternalServerError)
+		return
+	}
+	counts, err := h.Outbox.CountByStatus(ctx)
+	if err != nil {
+		http.Error(w, "failed to count outbox", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"emails": emails,
+		"counts": counts,
+	})
+}
+
+// OutboxEmail handles a single outbox entry
+// GET /admin/mail/outbox/{id} - delivery status
+// POST /admin/mail/outbox/{id}/retry - requeue a FAILED email
+func (h *MailAdminHandler) OutboxEmail(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/admin/mail/outbox/")
+	parts := strings.Split(path, "/")
+	id := parts[0]
+	if id == "" {
+		http.Error(w, "email ID is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if len(parts) == 2 && parts[1] == "retry" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := h.Outbox.Retry(ctx, id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if h.Logger != nil {
+			h.Logger.LogAdminActivity(requestUserID(r, h.Config.JWTSecret), "retry_email", "email_outbox", map[string]interface{}{
+				"emailID": id,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "email requeued",
+		})
+		return
+	}
+
+	if len(parts) != 1 || r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	email, err := h.Outbox.GetByID(ctx, id)
+	if err != nil {
+		http.Error(w, "email not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(email)
+}
+
+// requestUserID returns the user ID from the bearer token forwarded by the API gateway
+func requestUserID(r *http.Request, secret string) string {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+	claims, err := security.ValidateToken(parts[1], secret)
+	if err != nil {
+		return ""
+	}
+	return claims.UserID
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
 	"time"
@@ -11,11 +12,14 @@ import (
 	"users-service/config"
 	"users-service/internal/dto"
 	"users-service/internal/mail"
+	"users-service/internal/model"
 	"users-service/internal/security"
 	"users-service/internal/store"
 	"users-service/internal/validation"
 )
 
+var ErrPasswordReused = errors.New("new password must not match any of your recent passwords")
+
 type PasswordHandler struct {
 	Repo   *store.UserRepository
 	Config *config.Config
@@ -28,6 +32,32 @@ func NewPasswordHandler(repo *store.UserRepository, cfg *config.Config) *Passwor
 	}
 }
 
+// checkNewPassword rejects reused and breached passwords
+func (h *PasswordHandler) checkNewPassword(user *model.User, password string) error {
+	if h.Config.PasswordHistorySize > 0 && security.IsPasswordReused(password, user.PasswordHash, user.PasswordHistory) {
+		return ErrPasswordReused
+	}
+	return validation.CheckBreachedPassword(password)
+}
+
+// passwordCheckStatus is the response status for a password refused by checkNewPassword or
+// validation.CheckBreachedPassword: the screening failing is not the client's fault
+func passwordCheckStatus(err error) int {
+	if errors.Is(err, validation.ErrBreachedCheckFailed) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+// applyNewPassword hashes the new password, pushes the old hash to history and resets expiration
+func (h *PasswordHandler) applyNewPassword(user *model.User, password string) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	user.PasswordHistory = security.PushPasswordHistory(user.PasswordHistory, user.PasswordHash, h.Config.PasswordHistorySize)
+	user.PasswordHash = string(hash)
+	user.PasswordChangedAt = time.Now()
+	user.PasswordExpiresAt = time.Now().Add(time.Duration(h.Config.PasswordExpirationDays) * 24 * time.Hour)
+}
+
 // CHANGE PASSWORD (must be 1 day old)
 func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -64,10 +94,12 @@ func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	hash, _ := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-	user.PasswordHash = string(hash)
-	user.PasswordChangedAt = time.Now()
-	user.PasswordExpiresAt = time.Now().Add(time.Duration(h.Config.PasswordExpirationDays) * 24 * time.Hour)
+	if err := h.checkNewPassword(user, req.NewPassword); err != nil {
+		http.Error(w, err.Error(), passwordCheckStatus(err))
+		return
+	}
+
+	h.applyNewPassword(user, req.NewPassword)
 
 	if err := h.Repo.Update(ctx, user); err != nil {
 		http.Error(w, "failed to update password", http.StatusInternalServerError)
@@ -128,7 +160,7 @@ func (h *PasswordHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Re
 	// Note: Link points to frontend, which will call the API
 	encodedToken := url.QueryEscape(token)
 	resetURL := h.Config.FrontendURL + "/reset-password?token=" + encodedToken
-	mail.SendPasswordResetEmail(user.Email, resetURL)
+	mail.SendPasswordResetEmail(user.Email, user.Locale, resetURL)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -175,11 +207,13 @@ func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := h.checkNewPassword(user, req.NewPassword); err != nil {
+		http.Error(w, err.Error(), passwordCheckStatus(err))
+		return
+	}
+
 	// Reset password
-	hash, _ := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
-	user.PasswordHash = string(hash)
-	user.PasswordChangedAt = time.Now()
-	user.PasswordExpiresAt = time.Now().Add(time.Duration(h.Config.PasswordExpirationDays) * 24 * time.Hour)
+	h.applyNewPassword(user, req.NewPassword)
 
 	if err := h.Repo.Update(ctx, user); err != nil {
 		http.Error(w, "failed to reset password", http.StatusInternalServerError)
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"users-service/config"
+	"users-service/internal/mail"
+	"users-service/internal/store"
+)
+
+// StartPasswordReminder starts a background worker that emails users whose password is about to expire
+func StartPasswordReminder(ctx context.Context, repo *store.UserRepository, cfg *config.Config) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	log.Printf("Password reminder started - reminding users %d days before expiration", cfg.PasswordReminderDays)
+
+	sendPasswordReminders(ctx, repo, cfg)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Password reminder stopped")
+			return
+		case <-ticker.C:
+			sendPasswordReminders(ctx, repo, cfg)
+		}
+	}
+}
+
+// sendPasswordReminders sends one reminder per password change to users within the reminder window
+func sendPasswordReminders(ctx context.Context, repo *store.UserRepository, cfg *config.Config) {
+	deadline := time.Now().Add(time.Duration(cfg.PasswordReminderDays) * 24 * time.Hour)
+	users, err := repo.GetUsersWithExpiringPasswords(ctx, deadline)
+	if err != nil {
+		log.Printf("Password reminder: failed to load users: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		changeURL := cfg.FrontendURL + "/change-password"
+		// A reminder that could not be queued stays unmarked and is retried on the next run;
+		// once queued, the outbox retries delivery
+		if err := mail.SendPasswordExpiryReminder(user.Email, user.Locale, user.PasswordExpiresAt, changeURL); err != nil {
+			continue
+		}
+		if err := repo.MarkPasswordReminderSent(ctx, user.ID, time.Now()); err != nil {
+			log.Printf("Password reminder: failed to mark reminder for %s: %v", user.Username, err)
+		}
+	}
+}
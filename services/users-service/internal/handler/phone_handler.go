@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"users-service/config"
+	"users-service/internal/delivery"
+	"users-service/internal/dto"
+	"users-service/internal/logger"
+	"users-service/internal/model"
+	"users-service/internal/security"
+	"users-service/internal/store"
+	"users-service/internal/validation"
+)
+
+// PhoneHandler manages the user's phone number and the channel used for login codes
+type PhoneHandler struct {
+	Repo     *store.UserRepository
+	SMS      *delivery.SMSChannel
+	Channels *delivery.Registry
+	Config   *config.Config
+	Logger   *logger.Logger
+}
+
+func NewPhoneHandler(repo *store.UserRepository, sms *delivery.SMSChannel, channels *delivery.Registry, cfg *config.Config, log *logger.Logger) *PhoneHandler {
+	return &PhoneHandler{Repo: repo, SMS: sms, Channels: channels, Config: cfg, Logger: log}
+}
+
+// Phone handles the user's phone number
+// POST /phone - set a new number and send a verification code to it (requires password)
+// DELETE /phone - remove the number, login codes go back to email (requires password)
+func (h *PhoneHandler) Phone(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r, h.Config.JWTSecret)
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.requestPhoneVerification(w, r, userID)
+	case http.MethodDelete:
+		h.removePhone(w, r, userID)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *PhoneHandler) requestPhoneVerification(w http.ResponseWriter, r *http.Request, userID string) {
+	var req dto.PhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	phone := validation.NormalizePhone(req.Phone)
+	if err := validation.ValidatePhone(phone); err != nil {
+		if h.Logger != nil {
+			h.Logger.LogValidationFailure("phone", err.Error(), "")
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.Repo.GetByID(ctx, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	// Re-authenticate with the current password
+	if !security.CheckPassword(user.PasswordHash, req.Password) {
+		if h.Logger != nil {
+			h.Logger.LogAccessControlFailure(user.ID, "/phone", r.Method, "wrong password for phone change")
+		}
+		http.Error(w, "wrong password", http.StatusUnauthorized)
+		return
+	}
+
+	code, err := security.GenerateOTP()
+	if err != nil {
+		http.Error(w, "failed to generate code", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Repo.SetPhoneVerification(ctx, user.ID, phone, code); err != nil {
+		http.Error(w, "failed to store verification code", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.SMS.SendPhoneVerification(phone, user.Locale, code); err != nil {
+		if h.Logger != nil {
+			h.Logger.LogOTPDeliveryFailure(user.Username, model.OTPChannelSMS, err.Error(), "")
+		}
+		http.Error(w, "failed to send verification code", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "verification code sent",
+	})
+}
+
+func (h *PhoneHandler) removePhone(w http.ResponseWriter, r *http.Request, userID string) {
+	var req dto.PhoneRemoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.Repo.GetByID(ctx, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	// Re-authenticate with the current password, a stolen token alone must not move login codes
+	if !security.CheckPassword(user.PasswordHash, req.Password) {
+		if h.Logger != nil {
+			h.Logger.LogAccessControlFailure(user.ID, "/phone", r.Method, "wrong password for phone removal")
+		}
+		http.Error(w, "wrong password", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.Repo.UpdatePhone(ctx, user.ID, "", false, model.OTPChannelEmail); err != nil {
+		http.Error(w, "failed to remove phone", http.StatusInternalServerError)
+		return
+	}
+	if h.Logger != nil && user.OTPChannel == model.OTPChannelSMS {
+		h.Logger.LogOTPChannelChange(user.ID, model.OTPChannelSMS, model.OTPChannelEmail, getClientIP(r))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "phone removed",
+	})
+}
+
+// VerifyPhone confirms the pending number with the code sent to it
+// POST /phone/verify
+func (h *PhoneHandler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := requestUserID(r, h.Config.JWTSecret)
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.PhoneVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.Repo.GetByID(ctx, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	phone, err := h.Repo.VerifyPhoneCode(ctx, user.ID, req.Code)
+	if err != nil {
+		if h.Logger != nil {
+			h.Logger.LogValidationFailure("phoneCode", err.Error(), "")
+		}
+		if errors.Is(err, store.ErrOTPAttemptsExceeded) {
+			http.Error(w, "too many attempts, request a new code", http.StatusTooManyRequests)
+			return
+		}
+		http.Error(w, "invalid or expired code", http.StatusBadRequest)
+		return
+	}
+
+	channel := user.OTPChannel
+	if channel == "" {
+		channel = model.OTPChannelEmail
+	}
+	if err := h.Repo.UpdatePhone(ctx, user.ID, phone, true, channel); err != nil {
+		http.Error(w, "failed to save phone", http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.Log(logger.LevelAudit, logger.EventOTPDelivery, "Phone number verified",
+			map[string]interface{}{
+				"userID": user.ID,
+				"ip":     getClientIP(r),
+			})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "phone verified",
+	})
+}
+
+// OTPChannel shows or changes the channel used for login codes
+// GET /otp/channel - current channel and the channels available to the user
+// PUT /otp/channel - choose email or sms (sms requires a verified phone)
+func (h *PhoneHandler) OTPChannel(w http.ResponseWriter, r *http.Request) {
+	userID := requestUserID(r, h.Config.JWTSecret)
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.Repo.GetByID(ctx, userID)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		available := []string{}
+		for _, name := range []string{model.OTPChannelEmail, model.OTPChannelSMS} {
+			if c, ok := h.Channels.Get(name); ok && c.Available(user) {
+				available = append(available, name)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"channel":       h.Channels.Preferred(user).Name(),
+			"available":     available,
+			"phone":         maskPhone(user.Phone),
+			"phoneVerified": user.PhoneVerified,
+		})
+
+	case http.MethodPut:
+		var req dto.OTPChannelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		channel, ok := h.Channels.Get(req.Channel)
+		if !ok {
+			http.Error(w, "unknown channel", http.StatusBadRequest)
+			return
+		}
+		if !channel.Available(user) {
+			http.Error(w, "channel not available, verify your phone number first", http.StatusBadRequest)
+			return
+		}
+
+		oldChannel := h.Channels.Preferred(user).Name()
+		if err := h.Repo.UpdateOTPChannel(ctx, user.ID, channel.Name()); err != nil {
+			http.Error(w, "failed to update channel", http.StatusInternalServerError)
+			return
+		}
+		if h.Logger != nil {
+			h.Logger.LogOTPChannelChange(user.ID, oldChannel, channel.Name(), getClientIP(r))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"channel": channel.Name(),
+		})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// maskPhone hides all but the last 3 digits of a phone number
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	masked := []byte(phone)
+	for i := 1; i < len(masked)-3; i++ {
+		masked[i] = '*'
+	}
+	return string(masked)
+}
@@ -152,6 +152,15 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// breached password screening
+	if err := validation.CheckBreachedPassword(req.Password); err != nil {
+		if h.Logger != nil {
+			h.Logger.LogValidationFailure("password", err.Error(), "")
+		}
+		http.Error(w, err.Error(), passwordCheckStatus(err))
+		return
+	}
+
 	// hash lozinke
 	hash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	now := time.Now()
@@ -165,6 +174,7 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 		PasswordHash:      string(hash),
 		Role:              "USER",
 		Verified:          false, // User must verify email first
+		Locale:            mail.NormalizeLocale(req.Locale),
 		PasswordChangedAt: now,
 		PasswordExpiresAt: now.Add(time.Duration(h.Config.PasswordExpirationDays) * 24 * time.Hour),
 		CreatedAt:         now,
@@ -197,7 +207,7 @@ func (h *RegisterHandler) Register(w http.ResponseWriter, r *http.Request) {
 	// Note: Link points to frontend, which will call the API
 	encodedToken := url.QueryEscape(verificationToken)
 	verificationURL := h.Config.FrontendURL + "/verify-email?token=" + encodedToken
-	mail.SendVerificationEmail(user.Email, verificationURL)
+	mail.SendVerificationEmail(user.Email, user.Locale, verificationURL)
 
 	// Use output encoding for response
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"shared/authz"
+	"users-service/config"
+	"users-service/internal/logger"
+	"users-service/internal/model"
+	"users-service/internal/store"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,31}$`)
+
+// RoleAdminHandler manages the role -> permission mapping and user role assignment.
+// Routes are wrapped with RequirePermission (roles.manage / users.manage), the API gateway checks it as well.
+type RoleAdminHandler struct {
+	Roles  *store.RoleRepository
+	Users  *store.UserRepository
+	Config *config.Config
+	Logger *logger.Logger
+}
+
+func NewRoleAdminHandler(roles *store.RoleRepository, users *store.UserRepository, cfg *config.Config, log *logger.Logger) *RoleAdminHandler {
+	return &RoleAdminHandler{Roles: roles, Users: users, Config: cfg, Logger: log}
+}
+
+type roleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type userRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ListRoles returns all roles and the permissions that can be granted
+// GET /admin/roles
+func (h *RoleAdminHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roles, err := h.Roles.List(r.Context())
+	if err != nil {
+		http.Error(w, "failed to list roles", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"roles":       roles,
+		"permissions": authz.AllPermissions,
+	})
+}
+
+// Role handles a single role
+// GET /admin/roles/{name} - role with its permissions
+// PUT /admin/roles/{name} - create the role or replace its permissions
+// DELETE /admin/roles/{name} - delete a role no user has
+func (h *RoleAdminHandler) Role(w http.ResponseWriter, r *http.Request) {
+	name := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/admin/roles/"))
+	if !roleNamePattern.MatchString(name) {
+		http.Error(w, "invalid role name", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		role, err := h.Roles.Get(ctx, name)
+		if err == store.ErrRoleNotFound {
+			http.Error(w, "role not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to load role", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(role)
+
+	case http.MethodPut:
+		var req roleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		permissions := []string{}
+		seen := map[string]bool{}
+		for _, p := range req.Permissions {
+			if !authz.IsKnownPermission(p) {
+				http.Error(w, "unknown permission: "+p, http.StatusBadRequest)
+				return
+			}
+			if !seen[p] {
+				seen[p] = true
+				permissions = append(permissions, p)
+			}
+		}
+
+		role := &model.Role{Name: name, Description: req.Description, Permissions: permissions}
+		if err := h.Roles.Upsert(ctx, role); err != nil {
+			http.Error(w, "failed to save role", http.StatusInternalServerError)
+			return
+		}
+		// Tokens embed the permissions of the role at login, sign its users out so the change applies
+		revoked, err := h.Users.RevokeSessionsByRole(ctx, name, time.Now())
+		if err != nil {
+			http.Error(w, "role saved but failed to revoke sessions of its users", http.StatusInternalServerError)
+			return
+		}
+		if h.Logger != nil {
+			h.Logger.LogAdminActivity(requestUserID(r, h.Config.JWTSecret), "update_role", "role", map[string]interface{}{
+				"role":            name,
+				"permissions":     permissions,
+				"revokedSessions": revoked,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(role)
+
+	case http.MethodDelete:
+		count, err := h.Users.CountByRole(ctx, name)
+		if err != nil {
+			http.Error(w, "failed to check role usage", http.StatusInternalServerError)
+			return
+		}
+		if count > 0 {
+			http.Error(w, "role is assigned to users", http.StatusConflict)
+			return
+		}
+		if err := h.Roles.Delete(ctx, name); err != nil {
+			if err == store.ErrRoleNotFound {
+				http.Error(w, "role not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "failed to delete role", http.StatusInternalServerError)
+			return
+		}
+		if h.Logger != nil {
+			h.Logger.LogAdminActivity(requestUserID(r, h.Config.JWTSecret), "delete_role", "role", map[string]interface{}{
+				"role": name,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "role deleted",
+		})
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AssignRole changes a user's role. Existing sessions are revoked so the
+// new permissions take effect on the next login.
+// PUT /admin/users/{id}/role
+func (h *RoleAdminHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/users/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "role" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	userID := parts[0]
+
+	var req userRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	roleName := strings.ToUpper(strings.TrimSpace(req.Role))
+
+	ctx := r.Context()
+	if _, err := h.Roles.Get(ctx, roleName); err != nil {
+		if err == store.ErrRoleNotFound {
+			http.Error(w, "role not found", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "failed to load role", http.StatusInternalServerError)
+		return
+	}
+
+	// Revokes the user's sessions as well
+	if err := h.Users.UpdateRole(ctx, userID, roleName); err != nil {
+		if err.Error() == "user not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to assign role", http.StatusInternalServerError)
+		return
+	}
+
+	if h.Logger != nil {
+		h.Logger.LogAdminActivity(requestUserID(r, h.Config.JWTSecret), "assign_role", "user", map[string]interface{}{
+			"userID": userID,
+			"role":   roleName,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "role assigned",
+	})
+}
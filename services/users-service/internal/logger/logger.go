@@ -35,6 +35,9 @@ const (
 	EventExpiredToken         EventType = "EXPIRED_TOKEN"
 	EventAdminActivity        EventType = "ADMIN_ACTIVITY"
 	EventTLSFailure           EventType = "TLS_FAILURE"
+	EventImpersonation        EventType = "IMPERSONATION"
+	EventOTPDelivery          EventType = "OTP_DELIVERY"
+	EventSuspiciousLogin      EventType = "SUSPICIOUS_LOGIN"
 )
 
 // Logger is a structured logger with file rotation and security features
@@ -292,6 +295,18 @@ func (l *Logger) LogLoginFailure(username string, reason string, ipAddress strin
 		})
 }
 
+// LogSuspiciousLogin logs a login whose risk score crossed the alert threshold
+func (l *Logger) LogSuspiciousLogin(username string, ipAddress string, score int, factors []string) {
+	l.Log(LevelWarning, EventSuspiciousLogin, "Suspicious login detected",
+		map[string]interface{}{
+			"username":  username,
+			"ip":        ipAddress,
+			"riskScore": score,
+			"factors":   factors,
+			"timestamp": time.Now().Unix(),
+		})
+}
+
 // LogAccessControlFailure logs an access control failure
 func (l *Logger) LogAccessControlFailure(userID string, resource string, action string, reason string) {
 	l.Log(LevelWarning, EventAccessControlFailure, "Access control failure",
@@ -347,6 +362,42 @@ func (l *Logger) LogAdminActivity(adminID string, action string, resource string
 		fields)
 }
 
+// LogImpersonation logs an action performed during an admin impersonation session
+func (l *Logger) LogImpersonation(impersonatorID string, targetUserID string, action string, details map[string]interface{}) {
+	fields := map[string]interface{}{
+		"impersonation":      true,
+		"impersonatorID":     impersonatorID,
+		"impersonatedUserID": targetUserID,
+		"action":             action,
+	}
+	for k, v := range details {
+		fields[k] = v
+	}
+	l.Log(LevelAudit, EventImpersonation, "Impersonation", fields)
+}
+
+// LogOTPChannelChange logs a change of the channel used to deliver login codes
+func (l *Logger) LogOTPChannelChange(userID string, oldChannel string, newChannel string, ipAddress string) {
+	l.Log(LevelAudit, EventOTPDelivery, "OTP channel changed",
+		map[string]interface{}{
+			"userID":     userID,
+			"oldChannel": oldChannel,
+			"newChannel": newChannel,
+			"ip":         ipAddress,
+		})
+}
+
+// LogOTPDeliveryFailure logs a failed attempt to deliver a one-time code
+func (l *Logger) LogOTPDeliveryFailure(username string, channel string, reason string, fallbackChannel string) {
+	l.Log(LevelWarning, EventOTPDelivery, "OTP delivery failed",
+		map[string]interface{}{
+			"username": username,
+			"channel":  channel,
+			"reason":   reason,
+			"fallback": fallbackChannel,
+		})
+}
+
 // LogTLSFailure logs a TLS connection failure
 func (l *Logger) LogTLSFailure(service string, errorMsg string, remoteAddr string) {
 	l.Log(LevelError, EventTLSFailure, "TLS connection failure",
@@ -1,300 +1,216 @@
 package mail
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
 	"log"
-	"net/smtp"
+	"time"
 	"users-service/config"
-
-	"gopkg.in/mail.v2"
+	"users-service/internal/model"
+	"users-service/internal/security"
+	"users-service/internal/store"
 )
 
 var (
 	mailConfig *config.Config
-	mailer     *mail.Dialer
+	transport  Transport
+	outbox     *store.OutboxRepository
 )
 
-// Init initializes the mailer with SMTP configuration
-func Init(cfg *config.Config) {
+// Init initializes the mail subsystem with the configured transport and the persistent outbox
+func Init(cfg *config.Config, outboxRepo *store.OutboxRepository) error {
 	mailConfig = cfg
+	outbox = outboxRepo
+
+	t, err := NewTransport(cfg)
+	if err != nil {
+		return err
+	}
+	transport = t
 
-	// Initialize mailer if SMTP host is provided
-	// MailHog doesn't require authentication, so username/password are optional
-	if cfg.SMTPHost != "" {
-		// Use empty strings for username/password if not provided (for MailHog)
-		username := cfg.SMTPUsername
-		password := cfg.SMTPPassword
-		if username == "" {
-			username = "mailhog"
-		}
-		if password == "" {
-			password = "mailhog"
-		}
-		mailer = mail.NewDialer(cfg.SMTPHost, cfg.SMTPPort, username, password)
-		// MailHog doesn't use TLS, so disable StartTLS for MailHog
-		if cfg.SMTPHost == "mailhog" {
-			mailer.StartTLSPolicy = mail.NoStartTLS
-			// Explicitly disable TLS for MailHog
-			mailer.TLSConfig = &tls.Config{
-				InsecureSkipVerify: true,
-				ServerName:         "",
-			}
-		} else {
-			mailer.StartTLSPolicy = mail.MandatoryStartTLS
-		}
-		log.Printf("[EMAIL] SMTP configured: %s:%d (from: %s)", cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom)
-	} else {
+	switch cfg.MailTransport {
+	case "log":
 		log.Printf("[EMAIL] SMTP not configured - using mock mode")
+	case "file":
+		log.Printf("[EMAIL] File-drop transport configured: %s", cfg.MailFileDropDir)
+	default:
+		log.Printf("[EMAIL] SMTP configured: %s:%d via %s (from: %s)", cfg.SMTPHost, cfg.SMTPPort, cfg.MailTransport, cfg.SMTPFrom)
 	}
+	return nil
 }
 
-// sendEmail is a helper function to send emails
-func sendEmail(to, subject, body string) error {
-	// If SMTP is not configured, use mock mode
-	if mailConfig == nil || mailConfig.SMTPHost == "" {
-		log.Printf("[MOCK EMAIL] To: %s, Subject: %s", to, subject)
-		log.Printf("[MOCK EMAIL] NOTE: SMTP not configured. Email not actually sent. Configure SMTP in docker-compose.yml to send real emails.")
+// IsMockMode reports whether emails are only logged instead of delivered
+func IsMockMode() bool {
+	return mailConfig == nil || mailConfig.MailTransport == "log"
+}
+
+// secretLifetime is how long the one-time code or link of a template stays valid (see UserRepository).
+// Such emails are not delivered, and their bodies not kept, once it has passed.
+var secretLifetime = map[string]time.Duration{
+	TemplateOTP:           5 * time.Minute,
+	TemplateMagicLink:     15 * time.Minute,
+	TemplateVerification:  24 * time.Hour,
+	TemplatePasswordReset: 1 * time.Hour,
+	TemplateLoginAlert:    7 * 24 * time.Hour,
+	TemplateEmailChange:   24 * time.Hour,
+	TemplateEmailNotice:   24 * time.Hour,
+}
+
+// sealKey returns the key outbox bodies are sealed with
+func sealKey() []byte {
+	if mailConfig == nil {
 		return nil
 	}
+	return []byte(mailConfig.TokenHashKey)
+}
 
-	// Use net/smtp directly for MailHog (doesn't support TLS)
-	if mailConfig.SMTPHost == "mailhog" {
-		log.Printf("[EMAIL] Using MailHog direct SMTP for %s", to)
-		return sendEmailViaMailHog(to, subject, body)
+func fromAddress() string {
+	if mailConfig == nil || mailConfig.SMTPFrom == "" {
+		return "noreply@musicstreaming.com"
 	}
+	return mailConfig.SMTPFrom
+}
 
-	// Use gopkg.in/mail.v2 for other SMTP servers (with TLS)
-	m := mail.NewMessage()
-	m.SetHeader("From", mailConfig.SMTPFrom)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/html", body)
-
-	// Send email
-	if err := mailer.DialAndSend(m); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+// enqueue renders the template in the recipient's locale and stores it in the outbox for delivery
+func enqueue(to, locale, templateName string, data interface{}) error {
+	if outbox == nil {
+		return fmt.Errorf("mail subsystem not initialized")
 	}
 
-	log.Printf("[EMAIL] Sent successfully to %s: %s", to, subject)
-	return nil
-}
+	locale = NormalizeLocale(locale)
+	subject, htmlBody, textBody, err := Render(templateName, locale, data)
+	if err != nil {
+		return err
+	}
 
-// sendEmailViaMailHog sends email using net/smtp directly (no TLS required)
-func sendEmailViaMailHog(to, subject, body string) error {
-	addr := fmt.Sprintf("%s:%d", mailConfig.SMTPHost, mailConfig.SMTPPort)
-	log.Printf("[EMAIL] Connecting to MailHog at %s", addr)
-	
-	// Create email message
-	from := mailConfig.SMTPFrom
-	if from == "" {
-		from = "noreply@musicstreaming.com"
+	maxAttempts := 5
+	if mailConfig != nil {
+		maxAttempts = mailConfig.MailMaxAttempts
 	}
-	
-	msg := []byte(fmt.Sprintf("From: %s\r\n", from) +
-		fmt.Sprintf("To: %s\r\n", to) +
-		fmt.Sprintf("Subject: %s\r\n", subject) +
-		"MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=UTF-8\r\n" +
-		"\r\n" +
-		body + "\r\n")
 
-	// Send email via SMTP (no auth, no TLS for MailHog)
-	// nil auth means no authentication
-	err := smtp.SendMail(addr, nil, from, []string{to}, msg)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Bodies carry OTP codes and one-time links, only sealed copies are stored
+	sealedHTML, err := security.Seal(sealKey(), htmlBody)
+	if err != nil {
+		return err
+	}
+	sealedText, err := security.Seal(sealKey(), textBody)
 	if err != nil {
-		log.Printf("[EMAIL ERROR] MailHog send failed: %v", err)
-		return fmt.Errorf("failed to send email via MailHog: %w", err)
+		return err
 	}
 
-	log.Printf("[EMAIL] Sent successfully to %s via MailHog: %s", to, subject)
+	email := &model.OutboxEmail{
+		To:          to,
+		Locale:      locale,
+		Template:    templateName,
+		Subject:     subject,
+		HTMLBody:    sealedHTML,
+		TextBody:    sealedText,
+		MaxAttempts: maxAttempts,
+	}
+	if lifetime, ok := secretLifetime[templateName]; ok {
+		expiresAt := time.Now().Add(lifetime)
+		email.ExpiresAt = &expiresAt
+	}
+	if err := outbox.Enqueue(ctx, email); err != nil {
+		return fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// SendOTP queues OTP code for the user's email
+func SendOTP(email, locale, otp string) error {
+	data := map[string]interface{}{
+		"Code":           otp,
+		"ExpiresMinutes": 5,
+	}
+	if err := enqueue(email, locale, TemplateOTP, data); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue OTP to %s: %v", email, err)
+		return err
+	}
 	return nil
 }
 
-// SendOTP sends OTP code to user's email
-func SendOTP(email, otp string) {
-	subject := "Your OTP Code for Login"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<meta charset="UTF-8">
-			<style>
-				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { background-color: #4CAF50; color: white; padding: 20px; text-align: center; }
-				.content { padding: 20px; background-color: #f9f9f9; }
-				.otp-code { font-size: 32px; font-weight: bold; color: #4CAF50; text-align: center; padding: 20px; background-color: white; border: 2px dashed #4CAF50; margin: 20px 0; }
-				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<div class="header">
-					<h1>Music Streaming Platform</h1>
-				</div>
-				<div class="content">
-					<h2>Your OTP Code</h2>
-					<p>Hello,</p>
-					<p>You have requested to log in to your account. Please use the following OTP code:</p>
-					<div class="otp-code">%s</div>
-					<p>This code will expire in 5 minutes.</p>
-					<p>If you did not request this code, please ignore this email.</p>
-				</div>
-				<div class="footer">
-					<p>This is an automated message, please do not reply.</p>
-				</div>
-			</div>
-		</body>
-		</html>
-	`, otp)
+// SendMagicLink queues magic link for the user's email
+func SendMagicLink(email, locale, link string) {
+	if err := enqueue(email, locale, TemplateMagicLink, map[string]interface{}{"Link": link}); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue magic link to %s: %v", email, err)
+	}
+}
 
-	if err := sendEmail(email, subject, body); err != nil {
-		log.Printf("[EMAIL ERROR] Failed to send OTP to %s: %v", email, err)
+// SendVerificationEmail queues email verification link for the user's email
+func SendVerificationEmail(email, locale, link string) {
+	if err := enqueue(email, locale, TemplateVerification, map[string]interface{}{"Link": link}); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue verification email to %s: %v", email, err)
+		return
+	}
+	// In mock mode, also log the verification link so user can copy it
+	if IsMockMode() {
+		log.Printf("[MOCK EMAIL] Verification link for %s: %s", email, link)
 	}
 }
 
-// SendMagicLink sends magic link to user's email
-func SendMagicLink(email, link string) {
-	subject := "Your Magic Link for Account Recovery"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<meta charset="UTF-8">
-			<style>
-				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { background-color: #2196F3; color: white; padding: 20px; text-align: center; }
-				.content { padding: 20px; background-color: #f9f9f9; }
-				.button { display: inline-block; padding: 12px 24px; background-color: #2196F3; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<div class="header">
-					<h1>Music Streaming Platform</h1>
-				</div>
-				<div class="content">
-					<h2>Account Recovery</h2>
-					<p>Hello,</p>
-					<p>You have requested to recover your account. Click the button below to log in:</p>
-					<p style="text-align: center;">
-						<a href="%s" class="button">Recover Account</a>
-					</p>
-					<p>Or copy and paste this link into your browser:</p>
-					<p style="word-break: break-all; color: #2196F3;">%s</p>
-					<p>This link will expire in 15 minutes.</p>
-					<p>If you did not request this link, please ignore this email.</p>
-				</div>
-				<div class="footer">
-					<p>This is an automated message, please do not reply.</p>
-				</div>
-			</div>
-		</body>
-		</html>
-	`, link, link)
+// SendPasswordResetEmail queues password reset link for the user's email
+func SendPasswordResetEmail(email, locale, link string) {
+	if err := enqueue(email, locale, TemplatePasswordReset, map[string]interface{}{"Link": link}); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue password reset email to %s: %v", email, err)
+	}
+}
 
-	if err := sendEmail(email, subject, body); err != nil {
-		log.Printf("[EMAIL ERROR] Failed to send magic link to %s: %v", email, err)
+// SendPasswordExpiryReminder queues a notice that the user's password expires soon
+func SendPasswordExpiryReminder(email, locale string, expiresAt time.Time, link string) error {
+	data := map[string]interface{}{
+		"ExpiresAt": expiresAt.Format("02.01.2006 15:04"),
+		"Link":      link,
 	}
+	if err := enqueue(email, locale, TemplatePasswordExpiry, data); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue password expiry reminder to %s: %v", email, err)
+		return err
+	}
+	return nil
 }
 
-// SendVerificationEmail sends email verification link to user's email
-func SendVerificationEmail(email, link string) {
-	subject := "Verify Your Email Address"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<meta charset="UTF-8">
-			<style>
-				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { background-color: #FF9800; color: white; padding: 20px; text-align: center; }
-				.content { padding: 20px; background-color: #f9f9f9; }
-				.button { display: inline-block; padding: 12px 24px; background-color: #FF9800; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<div class="header">
-					<h1>Music Streaming Platform</h1>
-				</div>
-				<div class="content">
-					<h2>Verify Your Email</h2>
-					<p>Hello,</p>
-					<p>Thank you for registering! Please verify your email address by clicking the button below:</p>
-					<p style="text-align: center;">
-						<a href="%s" class="button">Verify Email</a>
-					</p>
-					<p>Or copy and paste this link into your browser:</p>
-					<p style="word-break: break-all; color: #FF9800;">%s</p>
-					<p>If you did not create an account, please ignore this email.</p>
-				</div>
-				<div class="footer">
-					<p>This is an automated message, please do not reply.</p>
-				</div>
-			</div>
-		</body>
-		</html>
-	`, link, link)
+// SendLoginAlert queues a suspicious-login alert with a "this wasn't me" link
+func SendLoginAlert(email, locale string, at time.Time, ip, location, device, reasons, link string) {
+	data := map[string]interface{}{
+		"Time":     at.Format("02.01.2006 15:04 MST"),
+		"IP":       ip,
+		"Location": location,
+		"Device":   device,
+		"Reasons":  reasons,
+		"Link":     link,
+	}
+	if err := enqueue(email, locale, TemplateLoginAlert, data); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue login alert to %s: %v", email, err)
+	}
+}
 
-	if err := sendEmail(email, subject, body); err != nil {
-		log.Printf("[EMAIL ERROR] Failed to send verification email to %s: %v", email, err)
-	} else {
-		// In mock mode, also log the verification link so user can copy it
-		if mailConfig == nil || mailConfig.SMTPHost == "" || mailConfig.SMTPUsername == "" || mailConfig.SMTPPassword == "" {
-			log.Printf("[MOCK EMAIL] Verification link for %s: %s", email, link)
-		}
+// SendEmailChangeConfirmation queues the confirmation link for the new email address
+func SendEmailChangeConfirmation(newEmail, locale, link string) {
+	data := map[string]interface{}{"NewEmail": newEmail, "Link": link}
+	if err := enqueue(newEmail, locale, TemplateEmailChange, data); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue email change confirmation to %s: %v", newEmail, err)
 	}
 }
 
-// SendPasswordResetEmail sends password reset link to user's email
-func SendPasswordResetEmail(email, link string) {
-	subject := "Reset Your Password"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<meta charset="UTF-8">
-			<style>
-				body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-				.container { max-width: 600px; margin: 0 auto; padding: 20px; }
-				.header { background-color: #F44336; color: white; padding: 20px; text-align: center; }
-				.content { padding: 20px; background-color: #f9f9f9; }
-				.button { display: inline-block; padding: 12px 24px; background-color: #F44336; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
-				.footer { text-align: center; padding: 20px; color: #666; font-size: 12px; }
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<div class="header">
-					<h1>Music Streaming Platform</h1>
-				</div>
-				<div class="content">
-					<h2>Password Reset Request</h2>
-					<p>Hello,</p>
-					<p>You have requested to reset your password. Click the button below to reset it:</p>
-					<p style="text-align: center;">
-						<a href="%s" class="button">Reset Password</a>
-					</p>
-					<p>Or copy and paste this link into your browser:</p>
-					<p style="word-break: break-all; color: #F44336;">%s</p>
-					<p>This link will expire in 1 hour.</p>
-					<p>If you did not request a password reset, please ignore this email and your password will remain unchanged.</p>
-				</div>
-				<div class="footer">
-					<p>This is an automated message, please do not reply.</p>
-				</div>
-			</div>
-		</body>
-		</html>
-	`, link, link)
+// SendEmailChangeNotice queues a notice with a cancel link for the current email address
+func SendEmailChangeNotice(email, locale, newEmail, cancelLink string) {
+	data := map[string]interface{}{"NewEmail": newEmail, "Link": cancelLink}
+	if err := enqueue(email, locale, TemplateEmailNotice, data); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue email change notice to %s: %v", email, err)
+	}
+}
 
-	if err := sendEmail(email, subject, body); err != nil {
-		log.Printf("[EMAIL ERROR] Failed to send password reset email to %s: %v", email, err)
+// SendEmailChanged queues the final notice to the previous email address
+func SendEmailChanged(oldEmail, locale, newEmail string) {
+	if err := enqueue(oldEmail, locale, TemplateEmailChanged, map[string]interface{}{"NewEmail": newEmail}); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to queue email changed notice to %s: %v", oldEmail, err)
 	}
 }
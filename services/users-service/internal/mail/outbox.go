@@ -0,0 +1,130 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"users-service/internal/model"
+	"users-service/internal/security"
+	"users-service/internal/store"
+)
+
+const (
+	outboxPollInterval = 5 * time.Second
+	retryBaseDelay     = 30 * time.Second
+	retryMaxDelay      = 1 * time.Hour
+)
+
+// wakeup signals the worker that a new email was enqueued so OTPs go out without waiting for the next poll
+var wakeup = make(chan struct{}, 1)
+
+// StartOutboxWorker delivers queued emails until ctx is cancelled
+func StartOutboxWorker(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	log.Printf("[EMAIL] Outbox worker started (transport: %s)", transport.Name())
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("[EMAIL] Outbox worker stopped")
+			return
+		case <-ticker.C:
+		case <-wakeup:
+		}
+		drainOutbox(ctx)
+	}
+}
+
+// drainOutbox sends all emails that are currently due
+func drainOutbox(ctx context.Context) {
+	if expired, err := outbox.ExpireDue(ctx); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to expire outbox emails: %v", err)
+	} else if expired > 0 {
+		log.Printf("[EMAIL] %d outbox emails expired before delivery", expired)
+	}
+
+	for ctx.Err() == nil {
+		email, err := outbox.ClaimNext(ctx)
+		if err != nil {
+			log.Printf("[EMAIL ERROR] Failed to claim outbox email: %v", err)
+			return
+		}
+		if email == nil {
+			return
+		}
+		deliver(ctx, email)
+	}
+}
+
+func deliver(ctx context.Context, email *model.OutboxEmail) {
+	if email.ExpiresAt != nil && time.Now().After(*email.ExpiresAt) {
+		// Picked up by ExpireDue on the next poll
+		if err := outbox.MarkFailed(ctx, email.ID, transport.Name(), "expired before delivery", time.Now(), true); err != nil {
+			log.Printf("[EMAIL ERROR] Failed to update outbox email %s: %v", email.ID, err)
+		}
+		return
+	}
+
+	htmlBody, err := security.Open(sealKey(), email.HTMLBody)
+	var textBody string
+	if err == nil {
+		textBody, err = security.Open(sealKey(), email.TextBody)
+	}
+	if err != nil {
+		// Sealed under another key (TOKEN_HASH_KEY changed); retrying cannot help
+		log.Printf("[EMAIL ERROR] Cannot open outbox email %s to %s: %v", email.ID, email.To, err)
+		if err := outbox.MarkFailed(ctx, email.ID, transport.Name(), "cannot open sealed body", time.Now(), true); err != nil {
+			log.Printf("[EMAIL ERROR] Failed to update outbox email %s: %v", email.ID, err)
+		}
+		return
+	}
+
+	msg := &Message{
+		ID:       email.ID,
+		From:     fromAddress(),
+		To:       email.To,
+		Subject:  email.Subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	}
+
+	if err := transport.Send(msg); err != nil {
+		final := email.Attempts >= email.MaxAttempts
+		next := time.Now().Add(retryDelay(email.Attempts))
+		if final {
+			log.Printf("[EMAIL ERROR] Giving up on %s to %s after %d attempts: %v", email.Template, email.To, email.Attempts, err)
+		} else {
+			log.Printf("[EMAIL ERROR] Failed to send %s to %s (attempt %d/%d), retrying at %s: %v",
+				email.Template, email.To, email.Attempts, email.MaxAttempts, next.Format(time.RFC3339), err)
+		}
+		if err := outbox.MarkFailed(ctx, email.ID, transport.Name(), err.Error(), next, final); err != nil {
+			log.Printf("[EMAIL ERROR] Failed to update outbox email %s: %v", email.ID, err)
+		}
+		return
+	}
+
+	if err := outbox.MarkSent(ctx, email.ID, transport.Name()); err != nil {
+		log.Printf("[EMAIL ERROR] Failed to mark outbox email %s as sent: %v", email.ID, err)
+	}
+	log.Printf("[EMAIL] Sent successfully to %s via %s: %s", email.To, transport.Name(), email.Subject)
+}
+
+// retryDelay returns exponential backoff: 30s, 1m, 2m, 4m ... capped at 1h
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// Outbox returns the outbox repository used by the mail subsystem
+func Outbox() *store.OutboxRepository {
+	return outbox
+}
@@ -0,0 +1,88 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// SupportedLocales lists the locales that have a full set of templates
+var SupportedLocales = []string{"sr", "en"}
+
+// Template names (files templates/{locale}/{name}.html and .txt)
+const (
+	TemplateOTP            = "otp"
+	TemplateMagicLink      = "magic_link"
+	TemplateVerification   = "verification"
+	TemplatePasswordReset  = "password_reset"
+	TemplatePasswordExpiry = "password_expiry"
+	TemplateLoginAlert     = "login_alert"
+	TemplateEmailChange    = "email_change_confirm"
+	TemplateEmailNotice    = "email_change_notice"
+	TemplateEmailChanged   = "email_changed"
+)
+
+// templatesFS returns the template file system - MAIL_TEMPLATES_DIR overrides the embedded templates
+func templatesFS() (fs.FS, error) {
+	if mailConfig != nil && mailConfig.MailTemplatesDir != "" {
+		return os.DirFS(mailConfig.MailTemplatesDir), nil
+	}
+	return fs.Sub(embeddedTemplates, "templates")
+}
+
+// NormalizeLocale maps a requested locale (e.g. "sr-Latn-RS", "EN") to a supported one
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if idx := strings.IndexAny(locale, "-_"); idx != -1 {
+		locale = locale[:idx]
+	}
+	for _, supported := range SupportedLocales {
+		if locale == supported {
+			return locale
+		}
+	}
+	if mailConfig != nil && mailConfig.MailDefaultLocale != "" {
+		return mailConfig.MailDefaultLocale
+	}
+	return "en"
+}
+
+// Render renders subject, HTML and plain-text bodies of a template for the given locale
+func Render(name, locale string, data interface{}) (subject, htmlBody, textBody string, err error) {
+	fsys, err := templatesFS()
+	if err != nil {
+		return "", "", "", err
+	}
+	locale = NormalizeLocale(locale)
+
+	textTmpl, err := texttemplate.ParseFS(fsys, locale+"/"+name+".txt")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to parse text template %s/%s: %w", locale, name, err)
+	}
+	var subjectBuf, textBuf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subjectBuf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render subject %s/%s: %w", locale, name, err)
+	}
+	if err := textTmpl.ExecuteTemplate(&textBuf, "body", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render text body %s/%s: %w", locale, name, err)
+	}
+
+	htmlTmpl, err := htmltemplate.ParseFS(fsys, locale+"/layout.html", locale+"/"+name+".html")
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to parse HTML template %s/%s: %w", locale, name, err)
+	}
+	var htmlBuf bytes.Buffer
+	if err := htmlTmpl.ExecuteTemplate(&htmlBuf, "layout", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render HTML body %s/%s: %w", locale, name, err)
+	}
+
+	return strings.TrimSpace(subjectBuf.String()), htmlBuf.String(), strings.TrimSpace(textBuf.String()) + "\n", nil
+}
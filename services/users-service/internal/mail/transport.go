@@ -0,0 +1,175 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"time"
+
+	"users-service/config"
+
+	"gopkg.in/mail.v2"
+)
+
+// Message is a rendered email ready to be handed to a transport
+type Message struct {
+	ID       string
+	From     string
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Transport delivers a single message
+type Transport interface {
+	Name() string
+	Send(msg *Message) error
+}
+
+// NewTransport creates the transport selected by MAIL_TRANSPORT
+func NewTransport(cfg *config.Config) (Transport, error) {
+	switch cfg.MailTransport {
+	case "smtp":
+		return newSMTPTransport(cfg), nil
+	case "mailhog":
+		return &MailHogTransport{Addr: fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.MailFileDropDir, 0750); err != nil {
+			return nil, fmt.Errorf("failed to create mail drop directory: %w", err)
+		}
+		return &FileDropTransport{Dir: cfg.MailFileDropDir}, nil
+	case "log":
+		return &LogTransport{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", cfg.MailTransport)
+	}
+}
+
+// SMTPTransport sends emails through an SMTP server with mandatory STARTTLS
+type SMTPTransport struct {
+	dialer *mail.Dialer
+}
+
+func newSMTPTransport(cfg *config.Config) *SMTPTransport {
+	dialer := mail.NewDialer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword)
+	dialer.StartTLSPolicy = mail.MandatoryStartTLS
+	dialer.TLSConfig = &tls.Config{ServerName: cfg.SMTPHost}
+	return &SMTPTransport{dialer: dialer}
+}
+
+func (t *SMTPTransport) Name() string { return "smtp" }
+
+func (t *SMTPTransport) Send(msg *Message) error {
+	m := mail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/plain", msg.TextBody)
+	m.AddAlternative("text/html", msg.HTMLBody)
+
+	if err := t.dialer.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// MailHogTransport sends emails using net/smtp directly (no auth, no TLS)
+type MailHogTransport struct {
+	Addr string
+}
+
+func (t *MailHogTransport) Name() string { return "mailhog" }
+
+func (t *MailHogTransport) Send(msg *Message) error {
+	raw, err := buildMIMEMessage(msg)
+	if err != nil {
+		return err
+	}
+	// nil auth means no authentication
+	if err := smtp.SendMail(t.Addr, nil, msg.From, []string{msg.To}, raw); err != nil {
+		return fmt.Errorf("failed to send email via MailHog: %w", err)
+	}
+	return nil
+}
+
+// FileDropTransport writes every message as an .eml file into a directory (for tests)
+type FileDropTransport struct {
+	Dir string
+}
+
+func (t *FileDropTransport) Name() string { return "file" }
+
+func (t *FileDropTransport) Send(msg *Message) error {
+	raw, err := buildMIMEMessage(msg)
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), msg.ID)
+	// Write to a temp file and rename so readers never see a partial message
+	tmp := filepath.Join(t.Dir, "."+filename+".tmp")
+	if err := os.WriteFile(tmp, raw, 0640); err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+	return os.Rename(tmp, filepath.Join(t.Dir, filename))
+}
+
+// LogTransport only logs the message (mock mode when SMTP is not configured)
+type LogTransport struct{}
+
+func (t *LogTransport) Name() string { return "log" }
+
+func (t *LogTransport) Send(msg *Message) error {
+	log.Printf("[MOCK EMAIL] To: %s, Subject: %s", msg.To, msg.Subject)
+	log.Printf("[MOCK EMAIL] NOTE: SMTP not configured. Email not actually sent. Configure SMTP in docker-compose.yml to send real emails.")
+	return nil
+}
+
+// buildMIMEMessage builds a multipart/alternative message with text and HTML parts
+func buildMIMEMessage(msg *Message) ([]byte, error) {
+	boundaryBytes := make([]byte, 12)
+	if _, err := rand.Read(boundaryBytes); err != nil {
+		return nil, err
+	}
+	boundary := "b_" + hex.EncodeToString(boundaryBytes)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@musicstreaming.com>\r\n", msg.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.TextBody},
+		{"text/html", msg.HTMLBody},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=UTF-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
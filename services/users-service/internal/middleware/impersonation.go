@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"shared/authz"
+	"users-service/internal/logger"
+)
+
+// ImpersonationAudit writes an audit entry for every request the API gateway forwarded from an
+// impersonation session, so changes made here can be traced back to the admin
+func ImpersonationAudit(log *logger.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if impersonatorID, userID, ok := authz.ImpersonationFrom(r); ok && log != nil {
+			log.LogImpersonation(impersonatorID, userID, "request", map[string]interface{}{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			})
+		}
+		next.ServeHTTP(w, r)
+	})
+}
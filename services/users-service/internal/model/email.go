@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Email delivery statuses in the outbox
+const (
+	EmailStatusPending = "PENDING"
+	EmailStatusSending = "SENDING"
+	EmailStatusSent    = "SENT"
+	EmailStatusFailed  = "FAILED"
+)
+
+// OutboxEmail is a rendered email waiting for (or done with) delivery
+type OutboxEmail struct {
+	ID       string `json:"id" bson:"_id"`
+	To       string `json:"to" bson:"to"`
+	Locale   string `json:"locale" bson:"locale"`
+	Template string `json:"template" bson:"template"`
+	Subject  string `json:"subject" bson:"subject"`
+
+	// Bodies may contain OTP codes and one-time links: they are stored sealed (security.Seal)
+	// and never exposed over the API
+	HTMLBody string `json:"-" bson:"htmlBody"`
+	TextBody string `json:"-" bson:"textBody"`
+
+	Status        string     `json:"status" bson:"status"`
+	Transport     string     `json:"transport,omitempty" bson:"transport,omitempty"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	MaxAttempts   int        `json:"maxAttempts" bson:"maxAttempts"`
+	LastError     string     `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	NextAttemptAt time.Time  `json:"nextAttemptAt" bson:"nextAttemptAt"`
+	SentAt        *time.Time `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt" bson:"updatedAt"`
+
+	// ExpiresAt is when the one-time code or link in the email stops working; the email is not
+	// delivered after that and its bodies are wiped, as they are once it is sent
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// LoginEvent records the context and risk score of a successful login
+type LoginEvent struct {
+	ID        string `json:"id" bson:"_id"`
+	UserID    string `json:"userId" bson:"userId"`
+	Username  string `json:"username" bson:"username"`
+	IP        string `json:"ip" bson:"ip"`
+	IPPrefix  string `json:"ipPrefix" bson:"ipPrefix"`
+	DeviceID  string `json:"deviceId" bson:"deviceId"`
+	UserAgent string `json:"userAgent" bson:"userAgent"`
+	Method    string `json:"method" bson:"method"` // otp or magic_link
+
+	Country   string   `json:"country,omitempty" bson:"country,omitempty"`
+	City      string   `json:"city,omitempty" bson:"city,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty" bson:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty" bson:"longitude,omitempty"`
+
+	RiskScore   int      `json:"riskScore" bson:"riskScore"`
+	RiskFactors []string `json:"riskFactors" bson:"riskFactors"`
+	AlertSent   bool     `json:"alertSent" bson:"alertSent"`
+
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Role maps a role name (as stored on User.Role) to the permissions it grants
+type Role struct {
+	Name        string    `json:"name" bson:"_id"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Permissions []string  `json:"permissions" bson:"permissions"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
@@ -2,6 +2,12 @@ package model
 
 import "time"
 
+// OTP delivery channels a user can choose from
+const (
+	OTPChannelEmail = "email"
+	OTPChannelSMS   = "sms"
+)
+
 type User struct {
 	ID        string `json:"id" bson:"_id"`
 	FirstName string `json:"firstName" bson:"firstName"`
@@ -12,11 +18,24 @@ type User struct {
 	PasswordHash string `json:"-" bson:"passwordHash"`
 	Role         string `json:"role" bson:"role"`
 	Verified     bool   `json:"verified" bson:"verified"`
+	Locale       string `json:"locale,omitempty" bson:"locale,omitempty"`
+
+	// Phone is stored in E.164 format once verified, OTPChannel defaults to email when empty
+	Phone         string `json:"phone,omitempty" bson:"phone,omitempty"`
+	PhoneVerified bool   `json:"phoneVerified" bson:"phoneVerified"`
+	OTPChannel    string `json:"otpChannel,omitempty" bson:"otpChannel,omitempty"`
 
 	PasswordChangedAt   time.Time `json:"-" bson:"passwordChangedAt"`
 	PasswordExpiresAt   time.Time `json:"-" bson:"passwordExpiresAt"`
 	FailedLoginAttempts int       `json:"-" bson:"failedLoginAttempts"`
 	LockedUntil         time.Time `json:"-" bson:"lockedUntil"`
 
+	// PasswordHistory holds bcrypt hashes of previous passwords (newest first)
+	PasswordHistory        []string  `json:"-" bson:"passwordHistory,omitempty"`
+	PasswordReminderSentAt time.Time `json:"-" bson:"passwordReminderSentAt,omitempty"`
+
+	// SessionsRevokedAt invalidates every token issued before this time
+	SessionsRevokedAt time.Time `json:"-" bson:"sessionsRevokedAt,omitempty"`
+
 	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
 }
@@ -0,0 +1,136 @@
+package security
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeoLocation is the approximate location of an IP address
+type GeoLocation struct {
+	Country   string
+	City      string
+	Latitude  float64
+	Longitude float64
+}
+
+type geoRange struct {
+	start    net.IP
+	end      net.IP
+	location GeoLocation
+}
+
+// GeoIPDB is an in-memory IP range database loaded from a local CSV file.
+// Each line has the form: ip_start,ip_end,country_code,city,latitude,longitude
+// (the column layout of the free DB-IP "city lite" CSV export, without the continent/region columns).
+type GeoIPDB struct {
+	ranges []geoRange
+}
+
+// LoadGeoIPDB loads the CSV database from path
+func LoadGeoIPDB(path string) (*GeoIPDB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	db := &GeoIPDB{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("geoip line %d: %w", line, err)
+		}
+		if len(record) < 6 || strings.HasPrefix(record[0], "#") {
+			continue
+		}
+
+		start := normalizeIP(net.ParseIP(strings.TrimSpace(record[0])))
+		end := normalizeIP(net.ParseIP(strings.TrimSpace(record[1])))
+		if start == nil || end == nil || len(start) != len(end) {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+		lon, _ := strconv.ParseFloat(strings.TrimSpace(record[5]), 64)
+
+		db.ranges = append(db.ranges, geoRange{
+			start: start,
+			end:   end,
+			location: GeoLocation{
+				Country:   strings.ToUpper(strings.TrimSpace(record[2])),
+				City:      strings.TrimSpace(record[3]),
+				Latitude:  lat,
+				Longitude: lon,
+			},
+		})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return compareIP(db.ranges[i].start, db.ranges[j].start) < 0
+	})
+	return db, nil
+}
+
+// Lookup returns the location of ip, or false if it is not in the database
+func (db *GeoIPDB) Lookup(ip string) (GeoLocation, bool) {
+	if db == nil {
+		return GeoLocation{}, false
+	}
+	parsed := normalizeIP(net.ParseIP(ip))
+	if parsed == nil {
+		return GeoLocation{}, false
+	}
+
+	// Find the last range whose start is <= ip
+	idx := sort.Search(len(db.ranges), func(i int) bool {
+		return compareIP(db.ranges[i].start, parsed) > 0
+	}) - 1
+	if idx < 0 {
+		return GeoLocation{}, false
+	}
+	r := db.ranges[idx]
+	if len(r.start) != len(parsed) || compareIP(parsed, r.end) > 0 {
+		return GeoLocation{}, false
+	}
+	return r.location, true
+}
+
+// Size returns the number of loaded ranges
+func (db *GeoIPDB) Size() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.ranges)
+}
+
+// normalizeIP returns 4-byte form for IPv4 and 16-byte form for IPv6
+func normalizeIP(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip.To16()
+}
+
+func compareIP(a, b net.IP) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return bytes.Compare(a, b)
+}
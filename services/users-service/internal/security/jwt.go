@@ -16,17 +16,23 @@ type Claims struct {
 	UserID   string `json:"userId"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// Permissions granted by the role at login, enforced by the shared authz middleware
+	Permissions []string `json:"permissions"`
+	// Set only on impersonation tokens: the admin acting as UserID and whether writes are allowed
+	ImpersonatorID     string `json:"impersonatorId,omitempty"`
+	ImpersonationWrite bool   `json:"impersonationWrite,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken generates a JWT token for a user
-func GenerateToken(userID, username, role, secret string) (string, error) {
+func GenerateToken(userID, username, role string, permissions []string, secret string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
 
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -43,6 +49,34 @@ func GenerateToken(userID, username, role, secret string) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateImpersonationToken generates a short-lived token that lets an admin act as the target user
+func GenerateImpersonationToken(impersonatorID, userID, username, role string, permissions []string, allowWrite bool, ttl time.Duration, secret string) (string, time.Time, error) {
+	now := time.Now()
+	expirationTime := now.Add(ttl)
+
+	claims := &Claims{
+		UserID:             userID,
+		Username:           username,
+		Role:               role,
+		Permissions:        permissions,
+		ImpersonatorID:     impersonatorID,
+		ImpersonationWrite: allowWrite,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expirationTime, nil
+}
+
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString, secret string) (*Claims, error) {
 	claims := &Claims{}
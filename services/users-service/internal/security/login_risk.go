@@ -0,0 +1,156 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+// Risk factors detected for a login
+const (
+	RiskNewDevice        = "NEW_DEVICE"
+	RiskNewIPRange       = "NEW_IP_RANGE"
+	RiskNewCountry       = "NEW_COUNTRY"
+	RiskImpossibleTravel = "IMPOSSIBLE_TRAVEL"
+)
+
+// riskWeights are added to the score for each detected factor (score is capped at 100)
+var riskWeights = map[string]int{
+	RiskNewDevice:        40,
+	RiskNewIPRange:       20,
+	RiskNewCountry:       30,
+	RiskImpossibleTravel: 60,
+}
+
+// maxTravelSpeedKmh is roughly the cruising speed of a commercial airliner
+const maxTravelSpeedKmh = 900.0
+
+// LoginContext describes where a login came from
+type LoginContext struct {
+	IP         string
+	IPPrefix   string
+	DeviceID   string
+	UserAgent  string
+	Location   *GeoLocation
+	ObservedAt time.Time
+}
+
+// PreviousLogin is the subset of login history needed for risk scoring
+type PreviousLogin struct {
+	DeviceID   string
+	IPPrefix   string
+	Location   *GeoLocation
+	ObservedAt time.Time
+}
+
+// LoginRisk is the result of risk scoring
+type LoginRisk struct {
+	Score   int
+	Factors []string
+}
+
+// DeviceFingerprint derives a stable device identifier from request headers.
+// It is intentionally coarse: the same browser on the same machine gets the same ID.
+func DeviceFingerprint(userAgent, acceptLanguage string) string {
+	lang := acceptLanguage
+	if idx := strings.Index(lang, ","); idx != -1 {
+		lang = lang[:idx]
+	}
+	sum := sha256.Sum256([]byte(strings.TrimSpace(userAgent) + "|" + strings.ToLower(strings.TrimSpace(lang))))
+	return hex.EncodeToString(sum[:16])
+}
+
+// IPPrefix returns the /24 network for IPv4 and the /48 network for IPv6
+func IPPrefix(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// AssessLoginRisk scores a login against the user's login history.
+// The first ever login is not scored since there is nothing to compare to.
+func AssessLoginRisk(current LoginContext, history []PreviousLogin) LoginRisk {
+	risk := LoginRisk{Factors: []string{}}
+	if len(history) == 0 {
+		return risk
+	}
+
+	knownDevice := false
+	knownPrefix := false
+	knownCountry := false
+	for _, prev := range history {
+		if prev.DeviceID == current.DeviceID {
+			knownDevice = true
+		}
+		if prev.IPPrefix == current.IPPrefix {
+			knownPrefix = true
+		}
+		if current.Location != nil && prev.Location != nil && prev.Location.Country == current.Location.Country {
+			knownCountry = true
+		}
+	}
+
+	if !knownDevice {
+		risk.Factors = append(risk.Factors, RiskNewDevice)
+	}
+	if !knownPrefix {
+		risk.Factors = append(risk.Factors, RiskNewIPRange)
+	}
+	if current.Location != nil && !knownCountry {
+		risk.Factors = append(risk.Factors, RiskNewCountry)
+	}
+
+	// history is ordered newest first - compare with the most recent located login
+	for _, prev := range history {
+		if prev.Location == nil || current.Location == nil {
+			continue
+		}
+		if IsImpossibleTravel(*prev.Location, prev.ObservedAt, *current.Location, current.ObservedAt) {
+			risk.Factors = append(risk.Factors, RiskImpossibleTravel)
+		}
+		break
+	}
+
+	for _, factor := range risk.Factors {
+		risk.Score += riskWeights[factor]
+	}
+	if risk.Score > 100 {
+		risk.Score = 100
+	}
+	return risk
+}
+
+// IsImpossibleTravel reports whether moving between two locations in the given time
+// would require travelling faster than a commercial flight
+func IsImpossibleTravel(from GeoLocation, fromTime time.Time, to GeoLocation, toTime time.Time) bool {
+	distance := HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	if distance < 100 {
+		// Nearby locations are within GeoIP accuracy
+		return false
+	}
+	hours := toTime.Sub(fromTime).Hours()
+	if hours <= 0 {
+		return true
+	}
+	return distance/hours > maxTravelSpeedKmh
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometers
+func HaversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -64,3 +64,30 @@ func IsVerificationTokenExpired(e VerificationTokenEntry) bool {
 func IsPasswordResetTokenExpired(e PasswordResetTokenEntry) bool {
 	return time.Now().After(e.ExpiresAt)
 }
+
+// LoginAlertTokenEntry represents a "this wasn't me" token sent with a suspicious-login alert
+type LoginAlertTokenEntry struct {
+	Token     string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// IsLoginAlertTokenExpired checks if login alert token is expired
+func IsLoginAlertTokenExpired(e LoginAlertTokenEntry) bool {
+	return time.Now().After(e.ExpiresAt)
+}
+
+// EmailChangeTokenEntry represents a pending email address change
+type EmailChangeTokenEntry struct {
+	Token       string
+	CancelToken string
+	UserID      string
+	Email       string // current address
+	NewEmail    string
+	ExpiresAt   time.Time
+}
+
+// IsEmailChangeTokenExpired checks if email change token is expired
+func IsEmailChangeTokenExpired(e EmailChangeTokenEntry) bool {
+	return time.Now().After(e.ExpiresAt)
+}
@@ -8,8 +8,10 @@ import (
 )
 
 type OTPEntry struct {
-	Code      string
-	ExpiresAt time.Time
+	CodeHash    string
+	Attempts    int
+	MaxAttempts int
+	ExpiresAt   time.Time
 }
 
 func GenerateOTP() (string, error) {
@@ -10,3 +10,28 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(hash, password string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
+
+// IsPasswordReused checks the password against the current hash and the stored history
+func IsPasswordReused(password, currentHash string, history []string) bool {
+	if currentHash != "" && CheckPassword(currentHash, password) {
+		return true
+	}
+	for _, hash := range history {
+		if CheckPassword(hash, password) {
+			return true
+		}
+	}
+	return false
+}
+
+// PushPasswordHistory prepends the previous hash to the history and keeps at most size entries
+func PushPasswordHistory(history []string, previousHash string, size int) []string {
+	if size <= 0 {
+		return nil
+	}
+	updated := append([]string{previousHash}, history...)
+	if len(updated) > size {
+		updated = updated[:size]
+	}
+	return updated
+}
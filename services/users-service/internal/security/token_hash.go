@@ -0,0 +1,20 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken returns the hex-encoded HMAC-SHA256 of a token or OTP under the given key.
+// A keyed hash is used so a leaked database alone is not enough to brute-force 6-digit codes.
+func HashToken(key []byte, token string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TokenHashEqual compares two token hashes in constant time
+func TokenHashEqual(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"users-service/internal/model"
+)
+
+type LoginEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewLoginEventRepository(db *mongo.Database) *LoginEventRepository {
+	return &LoginEventRepository{
+		collection: db.Collection("login_events"),
+	}
+}
+
+func (r *LoginEventRepository) Create(ctx context.Context, event *model.LoginEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	_, err := r.collection.InsertOne(ctx, event)
+	return err
+}
+
+// GetRecentByUser returns the user's most recent logins, newest first
+func (r *LoginEventRepository) GetRecentByUser(ctx context.Context, userID string, limit int64) ([]*model.LoginEvent, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []*model.LoginEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetHighRisk returns logins since the given time with a risk score of at least minScore
+func (r *LoginEventRepository) GetHighRisk(ctx context.Context, since time.Time, minScore int, limit int64) ([]*model.LoginEvent, error) {
+	filter := bson.M{
+		"createdAt": bson.M{"$gte": since},
+		"riskScore": bson.M{"$gte": minScore},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "riskScore", Value: -1}, {Key: "createdAt", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []*model.LoginEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
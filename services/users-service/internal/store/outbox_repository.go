@@ -0,0 +1,240 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"users-service/internal/model"
+)
+
+// sendingTimeout is how long an email may stay in SENDING before another worker may reclaim it
+const sendingTimeout = 5 * time.Minute
+
+// outboxRetention is how long outbox entries are kept, delivered or not, before MongoDB removes them
+const outboxRetention = 7 * 24 * time.Hour
+
+type OutboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOutboxRepository(db *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{
+		collection: db.Collection("email_outbox"),
+	}
+}
+
+// EnsureIndexes creates the index used to claim due emails and a TTL index that removes entries
+// after outboxRetention, so sealed bodies and recipient addresses do not pile up
+func (r *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "createdAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(outboxRetention.Seconds()))},
+	})
+	return err
+}
+
+func (r *OutboxRepository) Enqueue(ctx context.Context, email *model.OutboxEmail) error {
+	now := time.Now()
+	if email.ID == "" {
+		email.ID = uuid.NewString()
+	}
+	email.Status = model.EmailStatusPending
+	email.CreatedAt = now
+	email.UpdatedAt = now
+	if email.NextAttemptAt.IsZero() {
+		email.NextAttemptAt = now
+	}
+
+	_, err := r.collection.InsertOne(ctx, email)
+	return err
+}
+
+// ClaimNext atomically marks the next due email as SENDING and returns it.
+// Returns nil, nil when nothing is due.
+func (r *OutboxRepository) ClaimNext(ctx context.Context) (*model.OutboxEmail, error) {
+	now := time.Now()
+	filter := bson.M{
+		"$or": []bson.M{
+			{"status": model.EmailStatusPending, "nextAttemptAt": bson.M{"$lte": now}},
+			{"status": model.EmailStatusSending, "updatedAt": bson.M{"$lt": now.Add(-sendingTimeout)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": model.EmailStatusSending, "updatedAt": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var email model.OutboxEmail
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &email, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id, transport string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":    model.EmailStatusSent,
+			"transport": transport,
+			"sentAt":    now,
+			"updatedAt": now,
+			"lastError": "",
+			// Delivered bodies are no longer needed, drop the codes and links they carry
+			"htmlBody": "",
+			"textBody": "",
+		},
+	})
+	return err
+}
+
+// ExpireDue gives up on emails whose one-time code or link has expired and wipes their bodies
+func (r *OutboxRepository) ExpireDue(ctx context.Context) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(ctx, bson.M{
+		"expiresAt": bson.M{"$lte": now},
+		"status":    bson.M{"$in": []string{model.EmailStatusPending, model.EmailStatusFailed}},
+		"htmlBody":  bson.M{"$ne": ""},
+	}, bson.M{
+		"$set": bson.M{
+			"status":    model.EmailStatusFailed,
+			"lastError": "expired before delivery",
+			"htmlBody":  "",
+			"textBody":  "",
+			"updatedAt": now,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// MarkFailed schedules a retry at nextAttemptAt, or gives up when final is true
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id, transport, errMsg string, nextAttemptAt time.Time, final bool) error {
+	status := model.EmailStatusPending
+	if final {
+		status = model.EmailStatusFailed
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"status":        status,
+			"transport":     transport,
+			"lastError":     errMsg,
+			"nextAttemptAt": nextAttemptAt,
+			"updatedAt":     time.Now(),
+		},
+	})
+	return err
+}
+
+// Retry puts a failed email back into the queue with a fresh attempt budget.
+// Emails whose code or link has expired cannot be retried.
+func (r *OutboxRepository) Retry(ctx context.Context, id string) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id":      id,
+		"status":   model.EmailStatusFailed,
+		"htmlBody": bson.M{"$ne": ""},
+		"$or": []bson.M{
+			{"expiresAt": bson.M{"$exists": false}},
+			{"expiresAt": bson.M{"$gt": now}},
+		},
+	}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":        model.EmailStatusPending,
+			"attempts":      0,
+			"nextAttemptAt": now,
+			"updatedAt":     now,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("failed email not found or expired")
+	}
+	return nil
+}
+
+func (r *OutboxRepository) GetByID(ctx context.Context, id string) (*model.OutboxEmail, error) {
+	var email model.OutboxEmail
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&email)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("not found")
+		}
+		return nil, err
+	}
+	return &email, nil
+}
+
+// List returns outbox entries filtered by status and recipient, newest first
+func (r *OutboxRepository) List(ctx context.Context, status, to string, limit int64) ([]*model.OutboxEmail, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if to != "" {
+		filter["to"] = to
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	emails := []*model.OutboxEmail{}
+	if err := cursor.All(ctx, &emails); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// CountByStatus returns the number of outbox entries per status
+func (r *OutboxRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$status"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	counts := map[string]int64{}
+	for cursor.Next(ctx) {
+		var row struct {
+			Status string `bson:"_id"`
+			Count  int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts[row.Status] = row.Count
+	}
+	return counts, cursor.Err()
+}
@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"shared/authz"
+	"users-service/internal/model"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+type RoleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRoleRepository(db *mongo.Database) *RoleRepository {
+	return &RoleRepository{
+		collection: db.Collection("roles"),
+	}
+}
+
+// SeedDefaults inserts the built-in roles that do not exist yet.
+// Existing roles are left untouched so admin edits survive restarts.
+func (r *RoleRepository) SeedDefaults(ctx context.Context) error {
+	now := time.Now()
+	for name, permissions := range authz.DefaultRolePermissions {
+		_, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": name},
+			bson.M{"$setOnInsert": bson.M{"permissions": permissions, "updatedAt": now}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RoleRepository) List(ctx context.Context) ([]model.Role, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	roles := []model.Role{}
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) Get(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Upsert creates the role or replaces its permissions
+func (r *RoleRepository) Upsert(ctx context.Context, role *model.Role) error {
+	role.UpdatedAt = time.Now()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": role.Name}, role, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, name string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrRoleNotFound
+	}
+	return nil
+}
+
+// PermissionsFor resolves the permissions embedded in the JWT at login.
+// An unknown role grants nothing; the result is never nil so the token carries an explicit list.
+func (r *RoleRepository) PermissionsFor(ctx context.Context, name string) ([]string, error) {
+	role, err := r.Get(ctx, name)
+	if err == ErrRoleNotFound {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if role.Permissions == nil {
+		return []string{}, nil
+	}
+	return role.Permissions, nil
+}
@@ -16,20 +16,73 @@ import (
 
 var ErrUserExists = errors.New("user already exists")
 
+var (
+	ErrOTPNotFound         = errors.New("OTP not found")
+	ErrOTPExpired          = errors.New("OTP expired")
+	ErrOTPInvalid          = errors.New("invalid OTP")
+	ErrOTPAttemptsExceeded = errors.New("too many OTP attempts")
+)
+
 type UserRepository struct {
 	usersCollection    *mongo.Collection
 	otpsCollection     *mongo.Collection
 	magicLinksCollection *mongo.Collection
+	phoneCodesCollection *mongo.Collection
+	tokenHashKey       []byte
+	otpMaxAttempts     int
 }
 
-func NewUserRepository(db *mongo.Database) *UserRepository {
+// NewUserRepository creates the repository. OTPs and one-time tokens are never stored in plaintext,
+// only their HMAC-SHA256 under tokenHashKey, so a database leak does not expose usable codes.
+func NewUserRepository(db *mongo.Database, tokenHashKey []byte, otpMaxAttempts int) *UserRepository {
 	return &UserRepository{
 		usersCollection:      db.Collection("users"),
 		otpsCollection:       db.Collection("otps"),
 		magicLinksCollection: db.Collection("magic_links"),
+		phoneCodesCollection: db.Collection("phone_verifications"),
+		tokenHashKey:         tokenHashKey,
+		otpMaxAttempts:       otpMaxAttempts,
 	}
 }
 
+// EnsureIndexes creates lookup indexes and TTL indexes so expired OTPs and tokens are purged by MongoDB
+func (r *UserRepository) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.otpsCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := r.magicLinksCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}},
+		{Keys: bson.D{{Key: "cancelTokenHash", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "type", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	}); err != nil {
+		return err
+	}
+
+	if _, err := r.phoneCodesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0),
+	}); err != nil {
+		return err
+	}
+
+	// Unique usernames and emails are enforced by MongoDB, the lookups in Create and UpdateEmail
+	// only give a friendly error; concurrent writes fail with a duplicate key error (ErrUserExists)
+	_, err := r.usersCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+	return err
+}
+
+// hashToken returns the keyed hash under which a token or OTP is stored
+func (r *UserRepository) hashToken(token string) string {
+	return security.HashToken(r.tokenHashKey, token)
+}
+
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 	// Check if user with same username or email already exists
 	var existingUser model.User
@@ -53,6 +106,9 @@ func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
 	}
 
 	_, err = r.usersCollection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
+	}
 	return err
 }
 
@@ -101,6 +157,8 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 			"failedLoginAttempts": user.FailedLoginAttempts,
 			"lockedUntil":        user.LockedUntil,
 			"verified":           user.Verified,
+			"passwordHistory":    user.PasswordHistory,
+			"passwordReminderSentAt": user.PasswordReminderSentAt,
 		},
 	}
 
@@ -114,42 +172,198 @@ func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+// GetUsersWithExpiringPasswords returns verified users whose password expires before the deadline
+// and who have not been reminded since their last password change
+func (r *UserRepository) GetUsersWithExpiringPasswords(ctx context.Context, deadline time.Time) ([]*model.User, error) {
+	filter := bson.M{
+		"verified": true,
+		"passwordExpiresAt": bson.M{
+			"$gt":  time.Now(),
+			"$lte": deadline,
+		},
+		"$expr": bson.M{
+			"$lt": []interface{}{
+				bson.M{"$ifNull": []interface{}{"$passwordReminderSentAt", time.Time{}}},
+				"$passwordChangedAt",
+			},
+		},
+	}
+
+	cursor, err := r.usersCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*model.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// MarkPasswordReminderSent records that the expiry reminder was sent
+func (r *UserRepository) MarkPasswordReminderSent(ctx context.Context, userID string, sentAt time.Time) error {
+	_, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"passwordReminderSentAt": sentAt},
+	})
+	return err
+}
+
+// RevokeSessions invalidates all tokens issued to the user before the given time
+func (r *UserRepository) RevokeSessions(ctx context.Context, userID string, at time.Time) error {
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"sessionsRevokedAt": at},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// UpdateRole assigns a role to the user and, in the same write, revokes the user's sessions so tokens
+// carrying the old permissions stop working
+func (r *UserRepository) UpdateRole(ctx context.Context, userID, role string) error {
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"role": role, "sessionsRevokedAt": time.Now()},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// RevokeSessionsByRole revokes the sessions of every user with the role, e.g. after its permissions changed
+func (r *UserRepository) RevokeSessionsByRole(ctx context.Context, role string, at time.Time) (int64, error) {
+	result, err := r.usersCollection.UpdateMany(ctx, bson.M{"role": role}, bson.M{
+		"$set": bson.M{"sessionsRevokedAt": at},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// CountByRole returns the number of users that have the role
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	return r.usersCollection.CountDocuments(ctx, bson.M{"role": role})
+}
+
+// SessionRevocation is a user whose tokens issued before RevokedAt are no longer valid
+type SessionRevocation struct {
+	UserID    string    `json:"userId" bson:"_id"`
+	RevokedAt time.Time `json:"revokedAt" bson:"sessionsRevokedAt"`
+}
+
+// GetSessionRevocations returns users whose sessions were revoked after since
+func (r *UserRepository) GetSessionRevocations(ctx context.Context, since time.Time) ([]SessionRevocation, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "sessionsRevokedAt": 1})
+	cursor, err := r.usersCollection.Find(ctx, bson.M{"sessionsRevokedAt": bson.M{"$gt": since}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revocations := []SessionRevocation{}
+	if err := cursor.All(ctx, &revocations); err != nil {
+		return nil, err
+	}
+	return revocations, nil
+}
+
 func (r *UserRepository) SetOTP(ctx context.Context, username, code string) error {
 	entry := security.OTPEntry{
-		Code:      code,
-		ExpiresAt: time.Now().Add(5 * time.Minute),
+		CodeHash:    r.hashToken(code),
+		MaxAttempts: r.otpMaxAttempts,
+		ExpiresAt:   time.Now().Add(5 * time.Minute),
 	}
 
-	// Use upsert to replace existing OTP for username
+	// Use upsert to replace existing OTP for username (a new code resets the attempt counter)
 	_, err := r.otpsCollection.ReplaceOne(
 		ctx,
 		bson.M{"username": username},
 		bson.M{
-			"username":  username,
-			"code":      entry.Code,
-			"expiresAt": entry.ExpiresAt,
+			"username":    username,
+			"codeHash":    entry.CodeHash,
+			"attempts":    0,
+			"maxAttempts": entry.MaxAttempts,
+			"expiresAt":   entry.ExpiresAt,
 		},
 		options.Replace().SetUpsert(true),
 	)
 	return err
 }
 
-func (r *UserRepository) GetOTP(ctx context.Context, username string) (security.OTPEntry, bool) {
-	var result struct {
-		Username  string    `bson:"username"`
-		Code      string    `bson:"code"`
-		ExpiresAt time.Time `bson:"expiresAt"`
+// VerifyOTP checks the code and consumes it on success.
+// Every attempt is counted, once maxAttempts is reached the OTP is invalidated.
+func (r *UserRepository) VerifyOTP(ctx context.Context, username, code string) error {
+	_, err := r.verifyCode(ctx, r.otpsCollection, bson.M{"username": username}, code)
+	return err
+}
+
+// verifyCode counts an attempt against the hashed code matching filter and deletes the entry
+// once it is used, expired or out of attempts. The matched document is returned on success.
+func (r *UserRepository) verifyCode(ctx context.Context, collection *mongo.Collection, filter bson.M, code string) (bson.M, error) {
+	var doc bson.M
+
+	// Count the attempt atomically before comparing, so parallel guesses cannot exceed the limit
+	err := collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, ErrOTPNotFound
 	}
 
-	err := r.otpsCollection.FindOne(ctx, bson.M{"username": username}).Decode(&result)
+	var result struct {
+		CodeHash    string    `bson:"codeHash"`
+		Attempts    int       `bson:"attempts"`
+		MaxAttempts int       `bson:"maxAttempts"`
+		ExpiresAt   time.Time `bson:"expiresAt"`
+	}
+	raw, err := bson.Marshal(doc)
 	if err != nil {
-		return security.OTPEntry{}, false
+		return nil, err
+	}
+	if err := bson.Unmarshal(raw, &result); err != nil {
+		return nil, err
 	}
 
-	return security.OTPEntry{
-		Code:      result.Code,
-		ExpiresAt: result.ExpiresAt,
-	}, true
+	entry := security.OTPEntry{
+		CodeHash:    result.CodeHash,
+		Attempts:    result.Attempts,
+		MaxAttempts: result.MaxAttempts,
+		ExpiresAt:   result.ExpiresAt,
+	}
+
+	if security.IsExpired(entry) {
+		collection.DeleteOne(ctx, filter)
+		return nil, ErrOTPExpired
+	}
+	if entry.MaxAttempts > 0 && entry.Attempts > entry.MaxAttempts {
+		collection.DeleteOne(ctx, filter)
+		return nil, ErrOTPAttemptsExceeded
+	}
+	if !security.TokenHashEqual(entry.CodeHash, r.hashToken(code)) {
+		if entry.MaxAttempts > 0 && entry.Attempts >= entry.MaxAttempts {
+			collection.DeleteOne(ctx, filter)
+			return nil, ErrOTPAttemptsExceeded
+		}
+		return nil, ErrOTPInvalid
+	}
+
+	if _, err := collection.DeleteOne(ctx, filter); err != nil {
+		return nil, err
+	}
+	return doc, nil
 }
 
 func (r *UserRepository) DeleteOTP(ctx context.Context, username string) error {
@@ -157,6 +371,68 @@ func (r *UserRepository) DeleteOTP(ctx context.Context, username string) error {
 	return err
 }
 
+// Phone verification methods (one pending number per user, keyed by user ID)
+func (r *UserRepository) SetPhoneVerification(ctx context.Context, userID, phone, code string) error {
+	entry := security.OTPEntry{
+		CodeHash:    r.hashToken(code),
+		MaxAttempts: r.otpMaxAttempts,
+		ExpiresAt:   time.Now().Add(10 * time.Minute),
+	}
+
+	_, err := r.phoneCodesCollection.ReplaceOne(
+		ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"_id":         userID,
+			"phone":       phone,
+			"codeHash":    entry.CodeHash,
+			"attempts":    0,
+			"maxAttempts": entry.MaxAttempts,
+			"expiresAt":   entry.ExpiresAt,
+		},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// VerifyPhoneCode checks the code sent to the pending number and returns that number
+func (r *UserRepository) VerifyPhoneCode(ctx context.Context, userID, code string) (string, error) {
+	doc, err := r.verifyCode(ctx, r.phoneCodesCollection, bson.M{"_id": userID}, code)
+	if err != nil {
+		return "", err
+	}
+	phone, _ := doc["phone"].(string)
+	return phone, nil
+}
+
+// UpdatePhone sets the user's phone number and OTP channel
+func (r *UserRepository) UpdatePhone(ctx context.Context, userID, phone string, verified bool, otpChannel string) error {
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"phone": phone, "phoneVerified": verified, "otpChannel": otpChannel},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// UpdateOTPChannel sets the channel used to deliver login codes
+func (r *UserRepository) UpdateOTPChannel(ctx context.Context, userID, channel string) error {
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"otpChannel": channel},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
 // Magic Link methods
 func (r *UserRepository) SetMagicLink(ctx context.Context, email, token string) error {
 	entry := security.MagicLinkEntry{
@@ -166,12 +442,13 @@ func (r *UserRepository) SetMagicLink(ctx context.Context, email, token string)
 	}
 
 	// Use upsert to replace existing magic link for email
+	// (magic links are the only entries without a type, other token types share the collection)
 	_, err := r.magicLinksCollection.ReplaceOne(
 		ctx,
-		bson.M{"email": email},
+		bson.M{"email": email, "type": bson.M{"$exists": false}},
 		bson.M{
 			"email":     entry.Email,
-			"token":     entry.Token,
+			"tokenHash": r.hashToken(entry.Token),
 			"expiresAt": entry.ExpiresAt,
 		},
 		options.Replace().SetUpsert(true),
@@ -182,24 +459,23 @@ func (r *UserRepository) SetMagicLink(ctx context.Context, email, token string)
 func (r *UserRepository) GetMagicLink(ctx context.Context, token string) (security.MagicLinkEntry, bool) {
 	var result struct {
 		Email     string    `bson:"email"`
-		Token     string    `bson:"token"`
 		ExpiresAt time.Time `bson:"expiresAt"`
 	}
 
-	err := r.magicLinksCollection.FindOne(ctx, bson.M{"token": token}).Decode(&result)
+	err := r.magicLinksCollection.FindOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": bson.M{"$exists": false}}).Decode(&result)
 	if err != nil {
 		return security.MagicLinkEntry{}, false
 	}
 
 	return security.MagicLinkEntry{
-		Token:     result.Token,
+		Token:     token,
 		Email:     result.Email,
 		ExpiresAt: result.ExpiresAt,
 	}, true
 }
 
 func (r *UserRepository) DeleteMagicLink(ctx context.Context, token string) error {
-	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"token": token})
+	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": bson.M{"$exists": false}})
 	return err
 }
 
@@ -216,7 +492,7 @@ func (r *UserRepository) SetVerificationToken(ctx context.Context, email, token
 		bson.M{"email": email, "type": "verification"},
 		bson.M{
 			"email":     entry.Email,
-			"token":     entry.Token,
+			"tokenHash": r.hashToken(entry.Token),
 			"expiresAt": entry.ExpiresAt,
 			"type":      "verification",
 		},
@@ -228,24 +504,23 @@ func (r *UserRepository) SetVerificationToken(ctx context.Context, email, token
 func (r *UserRepository) GetVerificationToken(ctx context.Context, token string) (security.VerificationTokenEntry, bool) {
 	var result struct {
 		Email     string    `bson:"email"`
-		Token     string    `bson:"token"`
 		ExpiresAt time.Time `bson:"expiresAt"`
 	}
 
-	err := r.magicLinksCollection.FindOne(ctx, bson.M{"token": token, "type": "verification"}).Decode(&result)
+	err := r.magicLinksCollection.FindOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": "verification"}).Decode(&result)
 	if err != nil {
 		return security.VerificationTokenEntry{}, false
 	}
 
 	return security.VerificationTokenEntry{
-		Token:     result.Token,
+		Token:     token,
 		Email:     result.Email,
 		ExpiresAt: result.ExpiresAt,
 	}, true
 }
 
 func (r *UserRepository) DeleteVerificationToken(ctx context.Context, token string) error {
-	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"token": token, "type": "verification"})
+	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": "verification"})
 	return err
 }
 
@@ -262,7 +537,7 @@ func (r *UserRepository) SetPasswordResetToken(ctx context.Context, email, token
 		bson.M{"email": email, "type": "password_reset"},
 		bson.M{
 			"email":     entry.Email,
-			"token":     entry.Token,
+			"tokenHash": r.hashToken(entry.Token),
 			"expiresAt": entry.ExpiresAt,
 			"type":      "password_reset",
 		},
@@ -274,23 +549,164 @@ func (r *UserRepository) SetPasswordResetToken(ctx context.Context, email, token
 func (r *UserRepository) GetPasswordResetToken(ctx context.Context, token string) (security.PasswordResetTokenEntry, bool) {
 	var result struct {
 		Email     string    `bson:"email"`
-		Token     string    `bson:"token"`
 		ExpiresAt time.Time `bson:"expiresAt"`
 	}
 
-	err := r.magicLinksCollection.FindOne(ctx, bson.M{"token": token, "type": "password_reset"}).Decode(&result)
+	err := r.magicLinksCollection.FindOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": "password_reset"}).Decode(&result)
 	if err != nil {
 		return security.PasswordResetTokenEntry{}, false
 	}
 
 	return security.PasswordResetTokenEntry{
-		Token:     result.Token,
+		Token:     token,
 		Email:     result.Email,
 		ExpiresAt: result.ExpiresAt,
 	}, true
 }
 
 func (r *UserRepository) DeletePasswordResetToken(ctx context.Context, token string) error {
-	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"token": token, "type": "password_reset"})
+	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": "password_reset"})
+	return err
+}
+
+// Login alert token methods ("this wasn't me" links)
+func (r *UserRepository) SetLoginAlertToken(ctx context.Context, email, token string) error {
+	entry := security.LoginAlertTokenEntry{
+		Token:     token,
+		Email:     email,
+		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // Alert link stays valid for 7 days
+	}
+
+	// Each alert gets its own token, so older alerts stay actionable
+	_, err := r.magicLinksCollection.InsertOne(ctx, bson.M{
+		"email":     entry.Email,
+		"tokenHash": r.hashToken(entry.Token),
+		"expiresAt": entry.ExpiresAt,
+		"type":      "login_alert",
+	})
+	return err
+}
+
+func (r *UserRepository) GetLoginAlertToken(ctx context.Context, token string) (security.LoginAlertTokenEntry, bool) {
+	var result struct {
+		Email     string    `bson:"email"`
+		ExpiresAt time.Time `bson:"expiresAt"`
+	}
+
+	err := r.magicLinksCollection.FindOne(ctx, bson.M{"tokenHash": r.hashToken(token), "type": "login_alert"}).Decode(&result)
+	if err != nil {
+		return security.LoginAlertTokenEntry{}, false
+	}
+
+	return security.LoginAlertTokenEntry{
+		Token:     token,
+		Email:     result.Email,
+		ExpiresAt: result.ExpiresAt,
+	}, true
+}
+
+// DeleteLoginAlertTokens removes all outstanding alert tokens for the email
+func (r *UserRepository) DeleteLoginAlertTokens(ctx context.Context, email string) error {
+	_, err := r.magicLinksCollection.DeleteMany(ctx, bson.M{"email": email, "type": "login_alert"})
 	return err
-}
\ No newline at end of file
+}
+
+// Email change token methods
+func (r *UserRepository) SetEmailChangeToken(ctx context.Context, userID, email, newEmail, token, cancelToken string) error {
+	entry := security.EmailChangeTokenEntry{
+		Token:       token,
+		CancelToken: cancelToken,
+		UserID:      userID,
+		Email:       email,
+		NewEmail:    newEmail,
+		ExpiresAt:   time.Now().Add(24 * time.Hour), // Email change must be confirmed within 24 hours
+	}
+
+	// Only one pending change per user, a new request replaces the previous one
+	_, err := r.magicLinksCollection.ReplaceOne(
+		ctx,
+		bson.M{"userId": userID, "type": "email_change"},
+		bson.M{
+			"userId":          entry.UserID,
+			"email":           entry.Email,
+			"newEmail":        entry.NewEmail,
+			"tokenHash":       r.hashToken(entry.Token),
+			"cancelTokenHash": r.hashToken(entry.CancelToken),
+			"expiresAt":       entry.ExpiresAt,
+			"type":            "email_change",
+		},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *UserRepository) getEmailChange(ctx context.Context, filter bson.M) (security.EmailChangeTokenEntry, bool) {
+	var result struct {
+		UserID    string    `bson:"userId"`
+		Email     string    `bson:"email"`
+		NewEmail  string    `bson:"newEmail"`
+		ExpiresAt time.Time `bson:"expiresAt"`
+	}
+
+	filter["type"] = "email_change"
+	err := r.magicLinksCollection.FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		return security.EmailChangeTokenEntry{}, false
+	}
+
+	// Only hashes are stored, the plaintext tokens cannot be recovered
+	return security.EmailChangeTokenEntry{
+		UserID:    result.UserID,
+		Email:     result.Email,
+		NewEmail:  result.NewEmail,
+		ExpiresAt: result.ExpiresAt,
+	}, true
+}
+
+func (r *UserRepository) GetEmailChangeToken(ctx context.Context, token string) (security.EmailChangeTokenEntry, bool) {
+	return r.getEmailChange(ctx, bson.M{"tokenHash": r.hashToken(token)})
+}
+
+func (r *UserRepository) GetEmailChangeByCancelToken(ctx context.Context, cancelToken string) (security.EmailChangeTokenEntry, bool) {
+	return r.getEmailChange(ctx, bson.M{"cancelTokenHash": r.hashToken(cancelToken)})
+}
+
+func (r *UserRepository) DeleteEmailChangeToken(ctx context.Context, userID string) error {
+	_, err := r.magicLinksCollection.DeleteOne(ctx, bson.M{"userId": userID, "type": "email_change"})
+	return err
+}
+
+// UpdateEmail changes the user's email address if no other user has it
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID, newEmail string) error {
+	var existingUser model.User
+	err := r.usersCollection.FindOne(ctx, bson.M{"email": newEmail, "_id": bson.M{"$ne": userID}}).Decode(&existingUser)
+	if err == nil {
+		return ErrUserExists
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{"email": newEmail},
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// DeleteTokensForEmail removes outstanding magic links, verification and reset tokens for an address
+func (r *UserRepository) DeleteTokensForEmail(ctx context.Context, email string) error {
+	_, err := r.magicLinksCollection.DeleteMany(ctx, bson.M{
+		"email": email,
+		"type":  bson.M{"$ne": "email_change"},
+	})
+	return err
+}
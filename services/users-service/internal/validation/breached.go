@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrBreachedPassword = errors.New(
+	"password has appeared in a known data breach, please choose a different one",
+)
+
+// ErrBreachedCheckFailed is returned when the corpus cannot be read; the password is refused rather
+// than accepted unchecked
+var ErrBreachedCheckFailed = errors.New(
+	"password screening is temporarily unavailable, please try again later",
+)
+
+// commonPasswordsList is the built-in list of the most common breached passwords. It is always
+// checked, so screening works even without the full corpus.
+//
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+var commonPasswords = parseCommonPasswords(commonPasswordsList)
+
+// breachedPasswordsDir holds SHA-1 k-anonymity prefix files.
+// Each file is named by the first 5 hex characters of the SHA-1 hash (e.g. "5BAA6")
+// and contains lines in the form "SUFFIX:COUNT", the same format as the HIBP range API,
+// so the corpus can be downloaded once and used fully offline.
+var breachedPasswordsDir string
+
+func parseCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
+
+// InitBreachedPasswords configures the full breached-password corpus. Without a directory only the
+// built-in list is checked; a configured directory that is missing or has no prefix files is an error,
+// so a broken volume mount cannot silently weaken screening.
+func InitBreachedPasswords(dir string) error {
+	breachedPasswordsDir = ""
+	if dir == "" {
+		log.Printf("[PASSWORD] WARNING: BREACHED_PASSWORDS_DIR not set - screening only against the built-in list of %d common passwords", len(commonPasswords))
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("breached password corpus %s: %w", dir, err)
+	}
+	files := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && len(entry.Name()) == 5 {
+			files++
+		}
+	}
+	if files == 0 {
+		return fmt.Errorf("breached password corpus %s has no prefix files", dir)
+	}
+
+	breachedPasswordsDir = dir
+	log.Printf("[PASSWORD] Breached password corpus loaded from %s (%d prefix files) plus %d common passwords", dir, files, len(commonPasswords))
+	return nil
+}
+
+// CheckBreachedPassword returns ErrBreachedPassword if the password is a common password, or a common
+// password with digits and symbols appended, or if it is found in the full corpus
+func CheckBreachedPassword(password string) error {
+	lower := strings.ToLower(password)
+	if _, ok := commonPasswords[lower]; ok {
+		return ErrBreachedPassword
+	}
+	if base := strings.TrimRight(lower, "0123456789!@#$%^&*.?_-"); base != lower {
+		if _, ok := commonPasswords[base]; ok && len(base) >= 4 {
+			return ErrBreachedPassword
+		}
+	}
+
+	if breachedPasswordsDir == "" {
+		return nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(breachedPasswordsDir, prefix))
+	if errors.Is(err, fs.ErrNotExist) {
+		// Missing prefix file means no breached hash shares this prefix
+		return nil
+	}
+	if err != nil {
+		log.Printf("[PASSWORD] ERROR: breached password corpus unreadable: %v", err)
+		return ErrBreachedCheckFailed
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate := line
+		if idx := strings.Index(line, ":"); idx != -1 {
+			candidate = line[:idx]
+		}
+		if strings.EqualFold(candidate, suffix) {
+			return ErrBreachedPassword
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("[PASSWORD] ERROR: breached password corpus unreadable: %v", err)
+		return ErrBreachedCheckFailed
+	}
+	return nil
+}
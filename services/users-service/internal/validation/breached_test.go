@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func hashPrefixSuffix(password string) (string, string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:5], hash[5:]
+}
+
+func TestCheckBreachedPassword(t *testing.T) {
+	const password = "Tr0ub4dor&3-unique"
+	prefix, suffix := hashPrefixSuffix(password)
+
+	tests := []struct {
+		name     string
+		password string
+		// corpus prepares the prefix file of password in dir
+		corpus  func(t *testing.T, dir string)
+		wantErr error
+	}{
+		{
+			name:     "common password",
+			password: "password",
+			corpus:   func(t *testing.T, dir string) {},
+			wantErr:  ErrBreachedPassword,
+		},
+		{
+			name:     "common password with appended digits",
+			password: "password123!",
+			corpus:   func(t *testing.T, dir string) {},
+			wantErr:  ErrBreachedPassword,
+		},
+		{
+			name:     "found in corpus",
+			password: password,
+			corpus: func(t *testing.T, dir string) {
+				writeCorpusFile(t, dir, prefix, "0000000000000000000000000000000000A:1\n"+strings.ToLower(suffix)+":42\n")
+			},
+			wantErr: ErrBreachedPassword,
+		},
+		{
+			name:     "not in prefix file",
+			password: password,
+			corpus: func(t *testing.T, dir string) {
+				writeCorpusFile(t, dir, prefix, "0000000000000000000000000000000000A:1\n")
+			},
+			wantErr: nil,
+		},
+		{
+			name:     "missing prefix file",
+			password: password,
+			corpus:   func(t *testing.T, dir string) {},
+			wantErr:  nil,
+		},
+		{
+			name:     "prefix file cannot be opened",
+			password: password,
+			corpus: func(t *testing.T, dir string) {
+				// A symlink pointing to itself fails to open with an error other than not-exist
+				if err := os.Symlink(prefix, filepath.Join(dir, prefix)); err != nil {
+					t.Fatalf("Symlink: %v", err)
+				}
+			},
+			wantErr: ErrBreachedCheckFailed,
+		},
+		{
+			name:     "prefix file cannot be read",
+			password: password,
+			corpus: func(t *testing.T, dir string) {
+				if err := os.Mkdir(filepath.Join(dir, prefix), 0o755); err != nil {
+					t.Fatalf("Mkdir: %v", err)
+				}
+			},
+			wantErr: ErrBreachedCheckFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			// InitBreachedPasswords requires at least one prefix file
+			writeCorpusFile(t, dir, "00000", "")
+			tt.corpus(t, dir)
+			if err := InitBreachedPasswords(dir); err != nil {
+				t.Fatalf("InitBreachedPasswords: %v", err)
+			}
+			t.Cleanup(func() { breachedPasswordsDir = "" })
+
+			if err := CheckBreachedPassword(tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckBreachedPassword error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func writeCorpusFile(t *testing.T, dir, prefix, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, prefix), []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
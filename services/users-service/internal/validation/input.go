@@ -15,6 +15,7 @@ var (
 	ErrSpecialChars    = errors.New("input contains invalid special characters")
 	ErrSQLInjection    = errors.New("input contains potentially dangerous SQL characters")
 	ErrXSS             = errors.New("input contains potentially dangerous XSS characters")
+	ErrInvalidPhone    = errors.New("phone number must be in international format, e.g. +381641234567")
 )
 
 // Email validation using regex
@@ -37,6 +38,37 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
+// NormalizePhone strips spaces, dashes and parentheses and converts a leading 00 to +
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(phone) {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	normalized := b.String()
+	if strings.HasPrefix(normalized, "00") {
+		normalized = "+" + normalized[2:]
+	}
+	return normalized
+}
+
+// Phone validation - E.164 format (+ followed by 8-15 digits)
+func ValidatePhone(phone string) error {
+	if phone == "" {
+		return errors.New("phone is required")
+	}
+
+	phoneRegex := regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	if !phoneRegex.MatchString(phone) {
+		return ErrInvalidPhone
+	}
+
+	return nil
+}
+
 // Username validation - whitelist approach
 func ValidateUsername(username string) error {
 	if username == "" {